@@ -2,9 +2,10 @@ package middleware
 
 import (
 	"errors"
-	"simple-blog-system/config"
 	"strings"
 
+	"simple-blog-system/pkg/signing"
+
 	jwt "github.com/golang-jwt/jwt/v5"
 )
 
@@ -12,23 +13,23 @@ type JWTClaims struct {
 	jwt.RegisteredClaims
 	ID       string `json:"id"`
 	Username string `json:"username"`
+	Role     string `json:"role"`
+	// SessionId is the login session the token was issued for
+	SessionId string `json:"sid"`
 }
 
-func ParseJWTToken(tokenString string) (*JWTClaims, error) {
-	configData := config.GetConfig()
-	secretKey := configData.JWT.SigningKey
+// ParseJWTToken verifies the bearer token of an Authorization header against the keys of signer
+func ParseJWTToken(signer signing.ISigner, authHeader string) (*JWTClaims, error) {
+	tokenString, ok := strings.CutPrefix(authHeader, "Bearer ")
+	if !ok {
+		return nil, errors.New("invalid jwt token")
+	}
 
-	tokenString = strings.Split(tokenString, "Bearer ")[1]
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (i interface{}, err error) {
-		return []byte(secretKey), nil
-	})
+	claims := &JWTClaims{}
+	err := signer.Parse(tokenString, claims)
 	if err != nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
-		return claims, nil
-	}
-
-	return nil, errors.New("invalid jwt token")
+	return claims, nil
 }
@@ -2,7 +2,14 @@ package middleware
 
 import (
 	"net/http"
+	"simple-blog-system/internal/app/user/model"
+	"simple-blog-system/internal/app/user/port"
+	"simple-blog-system/pkg/apperror"
+	"simple-blog-system/pkg/authorization"
 	"simple-blog-system/pkg/helper"
+	"simple-blog-system/pkg/signing"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -31,7 +38,9 @@ func CORSMiddleware() gin.HandlerFunc {
 	}
 }
 
-func JWTAuthMiddleware() gin.HandlerFunc {
+// JWTAuthMiddleware accepts tokens signed with a key of signer that carry a jti which is not in revocations and belong
+// to an active account, api tokens are accepted in their place and limited to their scopes by RequireScope
+func JWTAuthMiddleware(signer signing.ISigner, revocations port.IRevocationStore, accounts port.IAccountStatus, apiTokens port.IApiTokenAuthenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.Request.Header.Get("Authorization")
 		if len(authHeader) == 0 {
@@ -43,7 +52,12 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		claims, err := ParseJWTToken(authHeader)
+		if token, ok := strings.CutPrefix(authHeader, "Bearer "); ok && strings.HasPrefix(token, model.ApiTokenPrefix) {
+			apiTokenAuth(c, apiTokens, accounts, token)
+			return
+		}
+
+		claims, err := ParseJWTToken(signer, authHeader)
 		if err != nil {
 			requestID, _ := c.Get("requestID")
 			helper.SaveAuditLog(c, "token has invalid claims")
@@ -55,7 +69,127 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		revoked, err := tokenRevoked(c, revocations, claims.RegisteredClaims.ID)
+		if err != nil {
+			helper.ResponseError(c, err)
+			return
+		}
+		if revoked {
+			requestID, _ := c.Get("requestID")
+			helper.SaveAuditLog(c, "token has been revoked")
+			c.AbortWithStatusJSON(http.StatusForbidden, helper.Response{
+				Message:   "token has been revoked",
+				Success:   false,
+				RequestId: requestID,
+			})
+			return
+		}
+
+		role, ok := accountActive(c, accounts, claims.Username)
+		if !ok {
+			return
+		}
+
 		c.Set("id", claims.ID)
 		c.Set("username", claims.Username)
+		c.Set("role", role)
+		c.Set("sid", claims.SessionId)
+	}
+}
+
+// apiTokenAuth accepts api tokens that are neither revoked nor expired and belong to an active account,
+// the request acts as the user with the scopes of the token
+func apiTokenAuth(c *gin.Context, apiTokens port.IApiTokenAuthenticator, accounts port.IAccountStatus, token string) {
+	ip, _, _ := strings.Cut(helper.GetIpAddress(c), ",")
+	identity, err := apiTokens.AuthenticateApiToken(c.Request.Context(), token, strings.TrimSpace(ip))
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+	if identity == nil {
+		requestID, _ := c.Get("requestID")
+		helper.SaveAuditLog(c, "api token is invalid")
+		c.AbortWithStatusJSON(http.StatusForbidden, helper.Response{
+			Message:   "invalid or expired api token",
+			Success:   false,
+			RequestId: requestID,
+		})
+		return
+	}
+
+	role, ok := accountActive(c, accounts, identity.Username)
+	if !ok {
+		return
+	}
+
+	c.Set("id", identity.UserId)
+	c.Set("username", identity.Username)
+	c.Set("role", role)
+	c.Set("apiToken", identity.TokenId)
+	c.Set("scopes", identity.Scopes)
+}
+
+// accountActive aborts requests of suspended and deleted accounts and returns the role the account has now,
+// so a changed role counts without waiting for the token to expire
+func accountActive(c *gin.Context, accounts port.IAccountStatus, username string) (string, bool) {
+	active, role, err := accounts.Lookup(c.Request.Context(), username)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return "", false
+	}
+	if !active {
+		requestID, _ := c.Get("requestID")
+		helper.SaveAuditLog(c, "account is not active")
+		c.AbortWithStatusJSON(http.StatusForbidden, helper.Response{
+			Message:   "account is not active",
+			Success:   false,
+			RequestId: requestID,
+		})
+		return "", false
+	}
+
+	return role, true
+}
+
+// tokenRevoked treats a token without a jti as revoked since it could not be revoked otherwise
+func tokenRevoked(c *gin.Context, revocations port.IRevocationStore, jti string) (bool, error) {
+	if jti == "" {
+		return true, nil
+	}
+
+	return revocations.IsRevoked(c.Request.Context(), jti)
+}
+
+// RequireRole only lets through requests whose account has one of the given roles
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		for _, r := range roles {
+			if r == role {
+				c.Next()
+				return
+			}
+		}
+
+		helper.ResponseError(c, authorization.ErrForbidden)
+	}
+}
+
+// RequireScope only lets through requests with an api token that has the scope of resource the method needs,
+// requests with a JWT are not limited
+func RequireScope(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("apiToken") == "" {
+			c.Next()
+			return
+		}
+
+		scope := authorization.Scope(resource, c.Request.Method)
+		if slices.Contains(c.GetStringSlice("scopes"), scope) {
+			c.Next()
+			return
+		}
+
+		helper.ResponseError(c, apperror.Forbidden("the api token lacks the "+scope+" scope"))
 	}
 }
@@ -11,7 +11,9 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"simple-blog-system/pkg/authorization"
 	"simple-blog-system/pkg/constants"
+	"simple-blog-system/pkg/scheduler"
 	"simple-blog-system/pkg/validations"
 
 	"simple-blog-system/cmd/rest/middleware"
@@ -20,7 +22,11 @@ import (
 	"simple-blog-system/internal/setup"
 
 	commentServer "simple-blog-system/internal/app/comment/server"
+	feedServer "simple-blog-system/internal/app/feed/server"
 	postServer "simple-blog-system/internal/app/post/server"
+	searchServer "simple-blog-system/internal/app/search/server"
+	sitemapServer "simple-blog-system/internal/app/sitemap/server"
+	taxonomyServer "simple-blog-system/internal/app/taxonomy/server"
 	userServer "simple-blog-system/internal/app/user/server"
 
 	_ "simple-blog-system/docs"
@@ -48,7 +54,7 @@ func StartServer(setupData setup.SetupData) {
 
 	initPublicRoute(router, setupData.InternalApp)
 
-	router.Use(middleware.JWTAuthMiddleware())
+	router.Use(middleware.JWTAuthMiddleware(setupData.InternalApp.Services.Signer, setupData.InternalApp.Services.Revocations, setupData.InternalApp.Services.Accounts, setupData.InternalApp.Services.UserService))
 
 	initRoute(router, setupData.InternalApp)
 
@@ -58,6 +64,10 @@ func StartServer(setupData setup.SetupData) {
 		Handler: router,
 	}
 
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	startJobs(jobCtx, setupData.InternalApp)
+
 	go func() {
 		// service connections
 		if err := httpServer.ListenAndServe(); err != nil {
@@ -74,6 +84,7 @@ func StartServer(setupData setup.SetupData) {
 	<-quit
 
 	log.Println("Shutdown Server ...")
+	stopJobs()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -87,15 +98,51 @@ func StartServer(setupData setup.SetupData) {
 	log.Println("Server exiting")
 }
 
+// startJobs starts the background jobs of the server process
+func startJobs(ctx context.Context, internalAppStruct setup.InternalAppStruct) {
+	conf := config.GetConfig().Scheduler
+	if !conf.Enabled {
+		return
+	}
+
+	go scheduler.Every(ctx, "publish scheduled posts", conf.Interval, func(ctx context.Context) error {
+		_, err := internalAppStruct.Services.PostService.PublishDuePosts(ctx)
+		return err
+	})
+
+	go scheduler.Every(ctx, "purge expired sessions", conf.Interval, func(ctx context.Context) error {
+		_, err := internalAppStruct.Services.UserService.PurgeExpiredSessions(ctx)
+		return err
+	})
+
+	go scheduler.Every(ctx, "delete accounts", conf.Interval, func(ctx context.Context) error {
+		_, err := internalAppStruct.Services.UserService.DeleteDueAccounts(ctx)
+		return err
+	})
+}
+
 func initRoute(router *gin.Engine, internalAppStruct setup.InternalAppStruct) {
 	apiRouter := router.Group("/v1/api")
-	userServer.Routes.NewProfile(apiRouter.Group("/profile"), internalAppStruct.Handler.UserHandler)
-	postServer.Routes.New(apiRouter.Group("/post"), internalAppStruct.Handler.PostHandler)
-	commentServer.Routes.New(apiRouter.Group("/comment"), internalAppStruct.Handler.CommentHandler)
+	// api tokens can not get profile:write or any admin scope, changing the account and the admin API take a login
+	userServer.Routes.NewProfile(apiRouter.Group("/profile", middleware.RequireScope(authorization.ResourceProfile)), internalAppStruct.Handler.UserHandler)
+	userServer.Routes.NewAdmin(apiRouter.Group("/admin/user", middleware.RequireScope(authorization.ResourceAdmin), middleware.RequireRole(authorization.RoleAdmin)), internalAppStruct.Handler.UserHandler)
+	postServer.Routes.New(apiRouter.Group("/post", middleware.RequireScope(authorization.ResourcePosts)), internalAppStruct.Handler.PostHandler)
+	commentServer.Routes.New(apiRouter.Group("/comment", middleware.RequireScope(authorization.ResourceComments)), internalAppStruct.Handler.CommentHandler)
+	commentServer.Routes.NewModeration(apiRouter.Group("/comment/moderation", middleware.RequireScope(authorization.ResourceComments), middleware.RequireRole(authorization.RoleAdmin, authorization.RoleEditor)), internalAppStruct.Handler.CommentHandler)
+	commentServer.Routes.NewPost(apiRouter.Group("/post", middleware.RequireScope(authorization.ResourceComments)), internalAppStruct.Handler.CommentHandler)
+	taxonomyServer.Routes.NewTag(apiRouter.Group("/tag", middleware.RequireScope(authorization.ResourceTaxonomy)), internalAppStruct.Handler.TaxonomyHandler)
+	taxonomyServer.Routes.NewCategory(apiRouter.Group("/category", middleware.RequireScope(authorization.ResourceTaxonomy)), internalAppStruct.Handler.TaxonomyHandler)
+	searchServer.Routes.New(apiRouter.Group("/search", middleware.RequireScope(authorization.ResourceSearch)), internalAppStruct.Handler.SearchHandler)
 }
 
 func initPublicRoute(router *gin.Engine, internalAppStruct setup.InternalAppStruct) {
+	userServer.Routes.NewWellKnown(router.Group("/.well-known"), internalAppStruct.Handler.UserHandler)
+	feedServer.Routes.New(router.Group(""), internalAppStruct.Handler.FeedHandler)
+	sitemapServer.Routes.New(router.Group(""), internalAppStruct.Handler.SitemapHandler)
+
 	apiRouter := router.Group("/v1/public-api")
 
 	userServer.Routes.New(apiRouter.Group("/user"), internalAppStruct.Handler.UserHandler)
+	postServer.Routes.NewPublic(apiRouter.Group("/post"), internalAppStruct.Handler.PostHandler)
+	commentServer.Routes.NewPublicPost(apiRouter.Group("/post"), internalAppStruct.Handler.CommentHandler)
 }
@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
@@ -28,14 +30,180 @@ type (
 	}
 
 	jwt struct {
+		// SigningKey signs with HS256 while there is no SigningKeyFile
 		SigningKey string
+		// SigningKeyFile is the PEM private key access tokens are signed with, VerificationKeyFiles are PEM keys
+		// they are accepted with too, the keys before and after a rotation
+		SigningKeyFile       string
+		VerificationKeyFiles []string
+		// Issuer and Audience are put into every token and checked on every request
+		Issuer          string
+		Audience        string
+		AccessTokenTTL  time.Duration
+		RefreshTokenTTL time.Duration
+		// RevocationCacheTTL is how long a replica trusts its cached answer for a token, 0 turns the cache off
+		RevocationCacheTTL time.Duration
+	}
+
+	password struct {
+		// ResetTTL is how long a reset token can be used
+		ResetTTL time.Duration
+		// ResetURL is the page of the frontend that takes the token, the token is appended as ?token=
+		ResetURL string
+	}
+
+	login struct {
+		// AttemptDriver is postgres or memory, memory counts on every replica on its own
+		AttemptDriver string
+		// UserFreeAttempts and IpFreeAttempts are the failures that go without a wait
+		UserFreeAttempts int
+		IpFreeAttempts   int
+		// UserLockoutAfter and IpLockoutAfter are the failures that lock out for LockoutDuration, 0 turns the lockout off
+		UserLockoutAfter int
+		IpLockoutAfter   int
+		// BackoffBase doubles with every failure past the free ones up to BackoffMax
+		BackoffBase     time.Duration
+		BackoffMax      time.Duration
+		LockoutDuration time.Duration
+		// AttemptWindow is how long a failure is remembered
+		AttemptWindow time.Duration
+	}
+
+	account struct {
+		// DeletionGrace is how long an account whose owner deleted it can still be kept by logging in
+		DeletionGrace time.Duration
+		// DeletionContent is anonymize or delete, the posts and comments of a deleted account either move to [deleted] or go with it
+		DeletionContent string
+		// StatusCacheTTL is how long a token of a suspended or deleted account can still be used at most
+		StatusCacheTTL time.Duration
+	}
+
+	mfa struct {
+		// Issuer is the name authenticator apps show next to the username
+		Issuer string
+		// ChallengeTTL is how long the mfa token of a login waits for the code
+		ChallengeTTL time.Duration
+	}
+
+	apiToken struct {
+		// TTL is the lifetime of a token created without expires_at
+		TTL time.Duration
+		// MaxTTL is the longest lifetime a token can be created with
+		MaxTTL time.Duration
+	}
+
+	oidc struct {
+		// Issuer of the identity provider, single sign-on is off without it
+		Issuer       string
+		ClientID     string
+		ClientSecret string
+		// RedirectURL is registered with the identity provider, it gets the code and state of a sign in
+		RedirectURL string
+		// Scopes asked for besides openid
+		Scopes []string
+		// AutoProvision creates a user for an identity that is linked to nobody, with DefaultRole
+		AutoProvision bool
+		// LinkByEmail links an identity to the user with the same verified email address
+		LinkByEmail bool
+		DefaultRole string
+		// StateTTL is how long a sign in waits for the callback
+		StateTTL time.Duration
+	}
+
+	emailVerification struct {
+		// Required keeps users without a verified email address from publishing posts and commenting
+		Required bool
+		// TTL is how long a verification token can be used
+		TTL time.Duration
+		// URL is the page that takes the token, the token is appended as ?token=
+		URL string
+	}
+
+	mail struct {
+		// Driver is smtp, file or log
+		Driver string
+		From   string
+		// Dir is where the file driver writes the messages to
+		Dir          string
+		SMTPHost     string
+		SMTPPort     int
+		SMTPUsername string
+		SMTPPassword string
+	}
+
+	search struct {
+		// Driver is postgres or memory
+		Driver string
+	}
+
+	comment struct {
+		// MaxDepth is how deep replies can be nested, top level comments are at depth 0
+		MaxDepth int
+		// Moderation is off, approval or trusted, posts can override it
+		Moderation string
+		// TrustedAfter is how many approved comments let a user skip review in the trusted mode
+		TrustedAfter int
+	}
+
+	contentFilter struct {
+		BannedWords []string
+		// MaxLinks above which content goes to review, 0 turns the check off
+		MaxLinks int
+		// DuplicateWindow is how far back the same text of a user is rejected, 0 turns the check off
+		DuplicateWindow time.Duration
+		// SpamRejectAt and SpamReviewAt are spam probabilities of the classifier
+		SpamRejectAt float64
+		SpamReviewAt float64
+		// SpamMinDocuments of both spam and ham have to be learned before the classifier is used
+		SpamMinDocuments int
+	}
+
+	site struct {
+		Title       string
+		Description string
+		// BaseURL is where readers find the blog, feeds link to BaseURL/post/{slug}
+		BaseURL string
+	}
+
+	feed struct {
+		// Items is the number of posts in a feed, MaxItems the most a feed can be asked for with ?limit=
+		Items    int
+		MaxItems int
+	}
+
+	sitemap struct {
+		// CacheTTL is how long a sitemap is served before it is rendered again, post changes on the same replica drop it at once
+		CacheTTL time.Duration
+		// RobotsDisallow are the path prefixes robots.txt keeps crawlers away from
+		RobotsDisallow []string
+	}
+
+	scheduler struct {
+		Enabled bool
+		// Interval between two runs of the background jobs
+		Interval time.Duration
 	}
 
 	Config struct {
-		DB   DB
-		App  app
-		Http http
-		JWT  jwt
+		DB            DB
+		App           app
+		Http          http
+		JWT           jwt
+		Password      password
+		Login         login
+		Account       account
+		Mfa           mfa
+		ApiToken      apiToken
+		Oidc          oidc
+		Verification  emailVerification
+		Mail          mail
+		Search        search
+		Comment       comment
+		ContentFilter contentFilter
+		Site          site
+		Feed          feed
+		Sitemap       sitemap
+		Scheduler     scheduler
 	}
 )
 
@@ -71,9 +239,163 @@ func InitConfig() {
 			Port: getRequiredInt("APP_PORT"),
 		},
 		JWT: jwt{
-			SigningKey: getRequiredString("SIGNING_KEY"),
+			SigningKey:           getString("SIGNING_KEY", ""),
+			SigningKeyFile:       getString("JWT_SIGNING_KEY_FILE", ""),
+			VerificationKeyFiles: getList("JWT_VERIFICATION_KEY_FILES"),
+			Issuer:               getString("JWT_ISSUER", "simple-blog-system"),
+			Audience:             getString("JWT_AUDIENCE", "simple-blog-system"),
+			AccessTokenTTL:       getDuration("JWT_ACCESS_TOKEN_TTL", time.Hour),
+			RefreshTokenTTL:      getDuration("JWT_REFRESH_TOKEN_TTL", 30*24*time.Hour),
+			RevocationCacheTTL:   getDuration("JWT_REVOCATION_CACHE_TTL", 30*time.Second),
+		},
+		Password: password{
+			ResetTTL: getDuration("PASSWORD_RESET_TTL", time.Hour),
+			ResetURL: getString("PASSWORD_RESET_URL", ""),
+		},
+		Login: login{
+			AttemptDriver:    getString("LOGIN_ATTEMPT_DRIVER", "postgres"),
+			UserFreeAttempts: getInt("LOGIN_USER_FREE_ATTEMPTS", 3),
+			IpFreeAttempts:   getInt("LOGIN_IP_FREE_ATTEMPTS", 10),
+			UserLockoutAfter: getInt("LOGIN_USER_LOCKOUT_AFTER", 10),
+			IpLockoutAfter:   getInt("LOGIN_IP_LOCKOUT_AFTER", 50),
+			BackoffBase:      getDuration("LOGIN_BACKOFF_BASE", time.Second),
+			BackoffMax:       getDuration("LOGIN_BACKOFF_MAX", 15*time.Minute),
+			LockoutDuration:  getDuration("LOGIN_LOCKOUT_DURATION", time.Hour),
+			AttemptWindow:    getDuration("LOGIN_ATTEMPT_WINDOW", 24*time.Hour),
+		},
+		Account: account{
+			DeletionGrace:   getDuration("ACCOUNT_DELETION_GRACE", 30*24*time.Hour),
+			DeletionContent: getString("ACCOUNT_DELETION_CONTENT", "anonymize"),
+			StatusCacheTTL:  getDuration("ACCOUNT_STATUS_CACHE_TTL", 30*time.Second),
+		},
+		Mfa: mfa{
+			Issuer:       getString("MFA_ISSUER", "simple-blog-system"),
+			ChallengeTTL: getDuration("MFA_CHALLENGE_TTL", 5*time.Minute),
+		},
+		ApiToken: apiToken{
+			TTL:    getDuration("API_TOKEN_TTL", 90*24*time.Hour),
+			MaxTTL: getDuration("API_TOKEN_MAX_TTL", 365*24*time.Hour),
+		},
+		Oidc: oidc{
+			Issuer:        getString("OIDC_ISSUER", ""),
+			ClientID:      getString("OIDC_CLIENT_ID", ""),
+			ClientSecret:  getString("OIDC_CLIENT_SECRET", ""),
+			RedirectURL:   getString("OIDC_REDIRECT_URL", ""),
+			Scopes:        getList("OIDC_SCOPES"),
+			AutoProvision: getBool("OIDC_AUTO_PROVISION", true),
+			LinkByEmail:   getBool("OIDC_LINK_BY_EMAIL", true),
+			DefaultRole:   getString("OIDC_DEFAULT_ROLE", "author"),
+			StateTTL:      getDuration("OIDC_STATE_TTL", 10*time.Minute),
+		},
+		Verification: emailVerification{
+			Required: getBool("EMAIL_VERIFICATION_REQUIRED", false),
+			TTL:      getDuration("EMAIL_VERIFICATION_TTL", 48*time.Hour),
+			URL:      getString("EMAIL_VERIFICATION_URL", ""),
+		},
+		Mail: mail{
+			Driver:       getString("MAIL_DRIVER", "log"),
+			From:         getString("MAIL_FROM", "no-reply@localhost"),
+			Dir:          getString("MAIL_DIR", "mail"),
+			SMTPHost:     getString("SMTP_HOST", "localhost"),
+			SMTPPort:     getInt("SMTP_PORT", 587),
+			SMTPUsername: getString("SMTP_USERNAME", ""),
+			SMTPPassword: getString("SMTP_PASSWORD", ""),
+		},
+		Search: search{
+			Driver: getString("SEARCH_DRIVER", "postgres"),
+		},
+		Comment: comment{
+			MaxDepth:     getInt("COMMENT_MAX_DEPTH", 5),
+			Moderation:   getString("COMMENT_MODERATION", "off"),
+			TrustedAfter: getInt("COMMENT_TRUSTED_AFTER", 3),
+		},
+		ContentFilter: contentFilter{
+			BannedWords:      getList("CONTENT_FILTER_BANNED_WORDS"),
+			MaxLinks:         getInt("CONTENT_FILTER_MAX_LINKS", 3),
+			DuplicateWindow:  getDuration("CONTENT_FILTER_DUPLICATE_WINDOW", 24*time.Hour),
+			SpamRejectAt:     getFloat("CONTENT_FILTER_SPAM_REJECT_AT", 0.95),
+			SpamReviewAt:     getFloat("CONTENT_FILTER_SPAM_REVIEW_AT", 0.7),
+			SpamMinDocuments: getInt("CONTENT_FILTER_SPAM_MIN_DOCUMENTS", 20),
 		},
+		Site: site{
+			Title:       getString("SITE_TITLE", "Simple Blog"),
+			Description: getString("SITE_DESCRIPTION", ""),
+			BaseURL:     getString("SITE_BASE_URL", "http://localhost:8089"),
+		},
+		Feed: feed{
+			Items:    getInt("FEED_ITEMS", 20),
+			MaxItems: getInt("FEED_MAX_ITEMS", 100),
+		},
+		Sitemap: sitemap{
+			CacheTTL:       getDuration("SITEMAP_CACHE_TTL", time.Hour),
+			RobotsDisallow: getListOr("ROBOTS_DISALLOW", []string{"/v1/api/", "/swagger/"}),
+		},
+		Scheduler: scheduler{
+			Enabled:  getBool("SCHEDULER_ENABLED", true),
+			Interval: getDuration("SCHEDULER_INTERVAL", 30*time.Second),
+		},
+	}
+}
+
+func getString(key string, fallback string) string {
+	if viper.IsSet(key) {
+		return viper.GetString(key)
+	}
+
+	return fallback
+}
+
+func getInt(key string, fallback int) int {
+	if viper.IsSet(key) {
+		return viper.GetInt(key)
+	}
+
+	return fallback
+}
+
+func getFloat(key string, fallback float64) float64 {
+	if viper.IsSet(key) {
+		return viper.GetFloat64(key)
 	}
+
+	return fallback
+}
+
+// getList reads a comma separated list, blank entries are dropped
+func getList(key string) []string {
+	res := []string{}
+	for _, item := range strings.Split(viper.GetString(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			res = append(res, item)
+		}
+	}
+
+	return res
+}
+
+// getListOr reads a list like getList and falls back when key is not set at all, set and empty is an empty list
+func getListOr(key string, fallback []string) []string {
+	if viper.IsSet(key) {
+		return getList(key)
+	}
+
+	return fallback
+}
+
+func getBool(key string, fallback bool) bool {
+	if viper.IsSet(key) {
+		return viper.GetBool(key)
+	}
+
+	return fallback
+}
+
+func getDuration(key string, fallback time.Duration) time.Duration {
+	if viper.IsSet(key) {
+		return viper.GetDuration(key)
+	}
+
+	return fallback
 }
 
 func getRequiredString(key string) string {
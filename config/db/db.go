@@ -2,10 +2,12 @@ package db
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/go-openapi/strfmt"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -34,6 +36,12 @@ func (db dbConfig) CloseConnection() error {
 	return db.ConnectionDB.Close()
 }
 
+// IsUniqueViolation reports whether err is postgres rejecting a row that breaks a unique index
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
 func Init(dsn string) (dbConfig, error) {
 	var (
 		dbConfigVar dbConfig
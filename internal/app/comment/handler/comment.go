@@ -1,10 +1,12 @@
 package handler
 
 import (
+	"simple-blog-system/internal/app/comment/model"
 	"simple-blog-system/internal/app/comment/payload"
 	"simple-blog-system/internal/app/comment/port"
 	"simple-blog-system/pkg/helper"
-	"strconv"
+	"simple-blog-system/pkg/pagination"
+	"simple-blog-system/pkg/validations"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -132,29 +134,22 @@ func (h *handler) DeleteComment(c *gin.Context) {
 // @Tags comment
 // @Accept json
 // @Produce json
-// @Param page path int true "Page"
-// @Param limit path int true "Limit"
+// @Param cursor query string false "Cursor from meta.next_cursor or meta.prev_cursor"
+// @Param page query int false "Page number, switches to offset mode with total and total_pages"
+// @Param limit query int false "Limit, default 10 and at most 100"
 // @Success 200 {object} helper.Response
 // @Failure 400 {object} helper.Response
 // @Router /api/comment [get]
 func (h *handler) GetAllComment(c *gin.Context) {
 	username := c.GetString("username")
 
-	pageStr := c.Query("page")
-	page, err := strconv.Atoi(pageStr)
+	page, err := pagination.FromContext(c)
 	if err != nil {
 		helper.ResponseError(c, err)
 		return
 	}
 
-	limitStr := c.Query("limit")
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil {
-		helper.ResponseError(c, err)
-		return
-	}
-
-	res, err := h.commentService.GetAllComment(c.Request.Context(), username, page, limit)
+	res, meta, err := h.commentService.GetAllComment(c.Request.Context(), username, page)
 	if err != nil {
 		helper.ResponseError(c, err)
 		return
@@ -163,6 +158,7 @@ func (h *handler) GetAllComment(c *gin.Context) {
 	helper.ResponseData(c, &helper.Response{
 		Message: "get successfully",
 		Data:    res,
+		Meta:    meta,
 	})
 }
 
@@ -190,3 +186,230 @@ func (h *handler) GetCommentById(c *gin.Context) {
 		Data:    res,
 	})
 }
+
+// @Summary Get Post Comments
+// @Description Get the comments of one post
+// @Tags comment
+// @Accept json
+// @Produce json
+// @Param id path string true "Post ID"
+// @Param sort query string false "oldest (default) or newest first" Enums(oldest, newest)
+// @Param cursor query string false "Cursor from meta.next_cursor or meta.prev_cursor"
+// @Param page query int false "Page number, switches to offset mode with total and total_pages"
+// @Param limit query int false "Limit, default 10 and at most 100"
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Router /api/post/{id}/comments [get]
+func (h *handler) GetPostComments(c *gin.Context) {
+	username := c.GetString("username")
+
+	page, err := postCommentsPage(c)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	res, meta, err := h.commentService.GetPostComments(c.Request.Context(), username, c.Param("id"), page)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "get successfully",
+		Data:    res,
+		Meta:    meta,
+	})
+}
+
+// @Summary Get Public Post Comments
+// @Description Get the comments of one published post without logging in
+// @Tags comment
+// @Accept json
+// @Produce json
+// @Param id path string true "Post ID"
+// @Param sort query string false "oldest (default) or newest first" Enums(oldest, newest)
+// @Param cursor query string false "Cursor from meta.next_cursor or meta.prev_cursor"
+// @Param page query int false "Page number, switches to offset mode with total and total_pages"
+// @Param limit query int false "Limit, default 10 and at most 100"
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Router /public-api/post/{id}/comments [get]
+func (h *handler) GetPublicPostComments(c *gin.Context) {
+	page, err := postCommentsPage(c)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	res, meta, err := h.commentService.GetPublicPostComments(c.Request.Context(), c.Param("id"), page)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "get successfully",
+		Data:    res,
+		Meta:    meta,
+	})
+}
+
+// postCommentsPage reads the page and the sort order of a post comment listing
+func postCommentsPage(c *gin.Context) (pagination.Page, error) {
+	var request payload.PostCommentsRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		return pagination.Page{}, err
+	}
+
+	err := validations.New().Struct(request)
+	if err != nil {
+		return pagination.Page{}, validations.BadRequest(err)
+	}
+
+	page, err := pagination.FromContext(c)
+	if err != nil {
+		return pagination.Page{}, err
+	}
+
+	page.Ascending = request.Sort != "newest"
+	return page, nil
+}
+
+// @Summary Get Comment Tree
+// @Description Get the comments of a post nested under the comment they reply to, deleted comments with replies show as "[deleted]"
+// @Tags comment
+// @Accept json
+// @Produce json
+// @Param id path string true "Post ID"
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Router /api/post/{id}/comments/tree [get]
+func (h *handler) GetCommentTree(c *gin.Context) {
+	username := c.GetString("username")
+
+	postId := c.Param("id")
+
+	res, err := h.commentService.GetCommentTree(c.Request.Context(), username, postId)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "get successfully",
+		Data:    res,
+	})
+}
+
+// @Summary Get Moderation Queue
+// @Description Get the comments waiting for review oldest first, only for admins and editors
+// @Tags comment
+// @Accept json
+// @Produce json
+// @Param status query string false "pending (default), rejected or spam" Enums(pending, rejected, spam)
+// @Param cursor query string false "Cursor from meta.next_cursor or meta.prev_cursor"
+// @Param page query int false "Page number, switches to offset mode with total and total_pages"
+// @Param limit query int false "Limit, default 10 and at most 100"
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Failure 403 {object} helper.Response
+// @Router /api/comment/moderation [get]
+func (h *handler) GetModerationQueue(c *gin.Context) {
+	username := c.GetString("username")
+
+	var request payload.ModerationQueueRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	err := validations.New().Struct(request)
+	if err != nil {
+		helper.ResponseError(c, validations.BadRequest(err))
+		return
+	}
+
+	page, err := pagination.FromContext(c)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+	page.Ascending = true
+
+	res, meta, err := h.commentService.GetModerationQueue(c.Request.Context(), username, request.Status, page)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "get successfully",
+		Data:    res,
+		Meta:    meta,
+	})
+}
+
+// @Summary Approve Comments
+// @Description Approve comments so everyone can see them, only for admins and editors
+// @Tags comment
+// @Accept json
+// @Produce json
+// @Param comment body payload.ModerationRequest true "Comment IDs"
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Failure 403 {object} helper.Response
+// @Router /api/comment/moderation/approve [post]
+func (h *handler) ApproveComments(c *gin.Context) {
+	h.moderateComments(c, true)
+}
+
+// @Summary Reject Comments
+// @Description Reject comments, or mark them as spam, so only their author can see them, only for admins and editors
+// @Tags comment
+// @Accept json
+// @Produce json
+// @Param comment body payload.ModerationRequest true "Comment IDs"
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Failure 403 {object} helper.Response
+// @Router /api/comment/moderation/reject [post]
+func (h *handler) RejectComments(c *gin.Context) {
+	h.moderateComments(c, false)
+}
+
+// moderateComments approves or rejects the comments of a bulk moderation request
+func (h *handler) moderateComments(c *gin.Context, approve bool) {
+	username := c.GetString("username")
+
+	var request payload.ModerationRequest
+	if err := c.ShouldBind(&request); err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	err := validations.New().Struct(request)
+	if err != nil {
+		helper.ResponseError(c, validations.BadRequest(err))
+		return
+	}
+
+	status := model.StatusApproved
+	if !approve {
+		status = model.StatusRejected
+		if request.Spam {
+			status = model.StatusSpam
+		}
+	}
+
+	res, err := h.commentService.ModerateComments(c.Request.Context(), username, request.Ids, status)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "update successfully",
+		Data:    res,
+	})
+}
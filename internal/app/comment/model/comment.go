@@ -9,12 +9,23 @@ import (
 	"gorm.io/gorm"
 )
 
+// Moderation state of a comment, only approved comments are shown to everyone
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+	StatusSpam     = "spam"
+)
+
 type CommentModel struct {
 	ID        strfmt.UUID4 `json:"id" gorm:"type:uuid;default:uuid_generate_v4()"`
 	Username  string       `json:"username" validate:"required"`
 	Comment   string       `json:"comment" validate:"required"`
 	PostId    string       `json:"post_id" validate:"required"`
 	Post      model.PostModel
+	ParentId  *string        `json:"parent_id" gorm:"default:null"`
+	Depth     int            `json:"depth"`
+	Status    string         `json:"status"`
 	CreatedBy string         `json:"created_by"`
 	UpdatedBy string         `json:"updated_by" gorm:"default:null"`
 	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
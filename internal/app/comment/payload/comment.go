@@ -1,6 +1,97 @@
 package payload
 
+import (
+	"time"
+
+	"simple-blog-system/internal/app/comment/model"
+)
+
+// DeletedPlaceholder replaces the text of a deleted comment that still has replies
+const DeletedPlaceholder = "[deleted]"
+
 type CommentRequest struct {
 	Comment string `json:"comment" validate:"required"`
 	PostId  string `json:"post_id" validate:"required"`
+	// ParentId replies to a comment of the same post, it is only read when the comment is created
+	ParentId *string `json:"parent_id" validate:"omitempty,max=50"`
+}
+
+type PostCommentsRequest struct {
+	// Sort is oldest (default) or newest first
+	Sort string `form:"sort" validate:"omitempty,oneof=oldest newest"`
+}
+
+// CommentFilter limits a comment listing by moderation state
+type CommentFilter struct {
+	// Status lists the states to show, empty shows every state
+	Status []string
+	// Username shows the comments of this user in every state, so authors see their own comments waiting for review
+	Username string
+	// PublishedOnly keeps the listing to comments of published posts and the posts of Username, like the posts themselves
+	PublishedOnly bool
+}
+
+// Allows tells whether comment passes the filter
+func (f CommentFilter) Allows(comment model.CommentModel) bool {
+	if len(f.Status) == 0 || (f.Username != "" && comment.Username == f.Username) {
+		return true
+	}
+
+	for _, status := range f.Status {
+		if comment.Status == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+type ModerationQueueRequest struct {
+	// Status is pending (default), rejected or spam
+	Status string `form:"status" validate:"omitempty,oneof=pending rejected spam"`
+}
+
+type ModerationRequest struct {
+	Ids []string `json:"ids" validate:"required,min=1,max=100,dive,required,max=50"`
+	// Spam marks rejected comments as spam, it is ignored when approving
+	Spam bool `json:"spam"`
+}
+
+type CommentNode struct {
+	ID        string         `json:"id"`
+	Username  string         `json:"username"`
+	Comment   string         `json:"comment"`
+	ParentId  *string        `json:"parent_id"`
+	Depth     int            `json:"depth"`
+	Deleted   bool           `json:"deleted"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	Replies   []*CommentNode `json:"replies"`
+}
+
+// NewCommentNode hides the author and text of a deleted comment
+func NewCommentNode(comment model.CommentModel) *CommentNode {
+	node := &CommentNode{
+		ID:        string(comment.ID),
+		Username:  comment.Username,
+		Comment:   comment.Comment,
+		ParentId:  comment.ParentId,
+		Depth:     comment.Depth,
+		CreatedAt: comment.CreatedAt,
+		UpdatedAt: comment.UpdatedAt,
+		Replies:   []*CommentNode{},
+	}
+
+	if comment.DeletedAt.Valid {
+		node.Hide()
+	}
+
+	return node
+}
+
+// Hide shows the comment as deleted, used for deleted comments and for comments the reader may not see
+func (n *CommentNode) Hide() {
+	n.Username = ""
+	n.Comment = DeletedPlaceholder
+	n.Deleted = true
 }
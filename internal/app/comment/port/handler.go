@@ -20,4 +20,22 @@ type ICommentHandler interface {
 
 	// (GET /comment/:id)
 	GetCommentById(ctx *gin.Context)
+
+	// (GET /post/:id/comments)
+	GetPostComments(ctx *gin.Context)
+
+	// (GET /public-api/post/:id/comments)
+	GetPublicPostComments(ctx *gin.Context)
+
+	// (GET /post/:id/comments/tree)
+	GetCommentTree(ctx *gin.Context)
+
+	// (GET /comment/moderation)
+	GetModerationQueue(ctx *gin.Context)
+
+	// (POST /comment/moderation/approve)
+	ApproveComments(ctx *gin.Context)
+
+	// (POST /comment/moderation/reject)
+	RejectComments(ctx *gin.Context)
 }
@@ -3,12 +3,21 @@ package port
 import (
 	"context"
 	"simple-blog-system/internal/app/comment/model"
+	"simple-blog-system/internal/app/comment/payload"
+	"simple-blog-system/pkg/pagination"
 )
 
 type ICommentRepository interface {
 	InsertComment(ctx context.Context, comment model.CommentModel) (model.CommentModel, error)
 	UpdateComment(ctx context.Context, comment model.CommentModel) (res model.CommentModel, err error)
 	DeleteComment(ctx context.Context, comment model.CommentModel) (err error)
+	AnonymizeUserComments(ctx context.Context, username string, placeholder string) (res []model.CommentModel, err error)
+	DeleteUserComments(ctx context.Context, username string) (res []model.CommentModel, err error)
 	GetCommentById(ctx context.Context, id string) (res *model.CommentModel, err error)
-	GetAllComment(ctx context.Context, page int, limit int) (res []model.CommentModel, err error)
+	GetAllComment(ctx context.Context, filter payload.CommentFilter, page pagination.Page) (res []model.CommentModel, meta pagination.Meta, err error)
+	GetCommentsByPostId(ctx context.Context, postId string, filter payload.CommentFilter, page pagination.Page) (res []model.CommentModel, meta pagination.Meta, err error)
+	GetCommentThread(ctx context.Context, postId string) (res []model.CommentModel, err error)
+	GetCommentsByIds(ctx context.Context, ids []string) (res []model.CommentModel, err error)
+	UpdateCommentStatus(ctx context.Context, ids []string, status string, updatedBy string) (err error)
+	CountUserComments(ctx context.Context, username string, status string) (total int64, err error)
 }
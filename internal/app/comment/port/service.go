@@ -4,12 +4,21 @@ import (
 	"context"
 	"simple-blog-system/internal/app/comment/model"
 	"simple-blog-system/internal/app/comment/payload"
+	"simple-blog-system/pkg/pagination"
 )
 
 type ICommentService interface {
 	AddComment(ctx context.Context, username string, param payload.CommentRequest) (res *model.CommentModel, err error)
 	UpdateComment(ctx context.Context, username string, id string, param payload.CommentRequest) (res *model.CommentModel, err error)
 	DeleteComment(ctx context.Context, username string, id string) (res *model.CommentModel, err error)
-	GetAllComment(ctx context.Context, username string, page int, limit int) (res []model.CommentModel, err error)
+	GetAllComment(ctx context.Context, username string, page pagination.Page) (res []model.CommentModel, meta pagination.Meta, err error)
 	GetCommentById(ctx context.Context, username string, id string) (res *model.CommentModel, err error)
+	GetPostComments(ctx context.Context, username string, postId string, page pagination.Page) (res []model.CommentModel, meta pagination.Meta, err error)
+	GetPublicPostComments(ctx context.Context, postId string, page pagination.Page) (res []model.CommentModel, meta pagination.Meta, err error)
+	GetCommentTree(ctx context.Context, username string, postId string) (res []*payload.CommentNode, err error)
+	GetModerationQueue(ctx context.Context, username string, status string, page pagination.Page) (res []model.CommentModel, meta pagination.Meta, err error)
+	ModerateComments(ctx context.Context, username string, ids []string, status string) (res []model.CommentModel, err error)
+	// AnonymizeUserContent and DeleteUserContent take care of the comments of a deleted account
+	AnonymizeUserContent(ctx context.Context, username string, placeholder string) (int, error)
+	DeleteUserContent(ctx context.Context, username string) (int, error)
 }
@@ -5,10 +5,16 @@ import (
 
 	"simple-blog-system/config/db"
 	"simple-blog-system/pkg/cache"
+	"simple-blog-system/pkg/pagination"
 	"simple-blog-system/pkg/transaction"
 
 	"simple-blog-system/internal/app/comment/model"
+	"simple-blog-system/internal/app/comment/payload"
 	"simple-blog-system/internal/app/comment/port"
+	postModel "simple-blog-system/internal/app/post/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type repository struct {
@@ -46,10 +52,106 @@ func (r repository) DeleteComment(ctx context.Context, comment model.CommentMode
 	return err
 }
 
-func (r repository) GetAllComment(ctx context.Context, page int, limit int) (res []model.CommentModel, err error) {
-	offset := (page - 1) * limit
+// AnonymizeUserComments hands the comments of username over to placeholder and returns them
+func (r repository) AnonymizeUserComments(ctx context.Context, username string, placeholder string) (res []model.CommentModel, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Model(&res).Clauses(clause.Returning{}).Where("username = ?", username).Update("username", placeholder).Error
+	return res, err
+}
+
+// DeleteUserComments soft deletes the comments of username and returns them
+func (r repository) DeleteUserComments(ctx context.Context, username string) (res []model.CommentModel, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Clauses(clause.Returning{}).Where("username = ?", username).Delete(&res).Error
+	return res, err
+}
+
+func (r repository) GetAllComment(ctx context.Context, filter payload.CommentFilter, page pagination.Page) (res []model.CommentModel, meta pagination.Meta, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	query := applyCommentFilter(trx.Model(&model.CommentModel{}), filter)
+
+	total, err := page.Count(query)
+	if err != nil {
+		return nil, meta, err
+	}
+
+	err = query.Preload("Post").Scopes(page.Scope("comments")).Find(&res).Error
+	if err != nil {
+		return nil, meta, err
+	}
+
+	res, meta = pagination.Paginate(page, res, total, commentKey)
+	return res, meta, nil
+}
+
+// GetCommentsByPostId lists the comments of one post without preloading the post
+func (r repository) GetCommentsByPostId(ctx context.Context, postId string, filter payload.CommentFilter, page pagination.Page) (res []model.CommentModel, meta pagination.Meta, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	query := applyCommentFilter(trx.Model(&model.CommentModel{}).Where("comments.post_id = ?", postId), filter)
+
+	total, err := page.Count(query)
+	if err != nil {
+		return nil, meta, err
+	}
+
+	err = query.Scopes(page.Scope("comments")).Find(&res).Error
+	if err != nil {
+		return nil, meta, err
+	}
+
+	res, meta = pagination.Paginate(page, res, total, commentKey)
+	return res, meta, nil
+}
+
+// GetCommentThread returns every comment of a post oldest first, deleted comments included so their replies keep a parent
+func (r repository) GetCommentThread(ctx context.Context, postId string) (res []model.CommentModel, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Unscoped().Where("post_id = ?", postId).Order("created_at ASC, id ASC").Find(&res).Error
+	return res, err
+}
 
+// GetCommentsByIds returns the comments found among ids with their post
+func (r repository) GetCommentsByIds(ctx context.Context, ids []string) (res []model.CommentModel, err error) {
 	trx := transaction.GetTrxContext(ctx, r.db)
-	err = trx.Preload("Post").Limit(limit).Offset(offset).Find(&res).Error
+	err = trx.Preload("Post").Where("id IN ?", ids).Find(&res).Error
 	return res, err
 }
+
+// UpdateCommentStatus moves every comment of ids to status in one statement
+func (r repository) UpdateCommentStatus(ctx context.Context, ids []string, status string, updatedBy string) (err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Model(&model.CommentModel{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+		"status":     status,
+		"updated_by": updatedBy,
+	}).Error
+	return err
+}
+
+// CountUserComments counts the comments of a user in one moderation state
+func (r repository) CountUserComments(ctx context.Context, username string, status string) (total int64, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Model(&model.CommentModel{}).Where("username = ? AND status = ?", username, status).Count(&total).Error
+	return total, err
+}
+
+// applyCommentFilter keeps the comments in one of the filter states, plus every comment of filter.Username
+func applyCommentFilter(query *gorm.DB, filter payload.CommentFilter) *gorm.DB {
+	if filter.PublishedOnly {
+		query = query.Where("comments.post_id IN (SELECT posts.id FROM posts WHERE posts.deleted_at IS NULL AND (posts.status = ? OR posts.username = ?))",
+			postModel.StatusPublish, filter.Username)
+	}
+
+	if len(filter.Status) == 0 {
+		return query
+	}
+
+	if filter.Username != "" {
+		return query.Where("comments.status IN ? OR comments.username = ?", filter.Status, filter.Username)
+	}
+
+	return query.Where("comments.status IN ?", filter.Status)
+}
+
+func commentKey(comment model.CommentModel) pagination.Key {
+	return pagination.Key{CreatedAt: comment.CreatedAt, ID: string(comment.ID)}
+}
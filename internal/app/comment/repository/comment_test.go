@@ -9,6 +9,8 @@ import (
 
 	"simple-blog-system/config/db"
 	"simple-blog-system/internal/app/comment/model"
+	"simple-blog-system/internal/app/comment/payload"
+	"simple-blog-system/pkg/pagination"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/go-openapi/strfmt"
@@ -74,6 +76,8 @@ func (suite *CommentRepositoryTestSuite) TestInsertComment_Success() {
 			comment.Username,
 			comment.Comment,
 			comment.PostId,
+			comment.Depth,
+			comment.Status,
 			comment.CreatedBy,
 			sqlmock.AnyArg(), // CreatedAt
 			sqlmock.AnyArg(), // UpdatedAt
@@ -112,6 +116,8 @@ func (suite *CommentRepositoryTestSuite) TestInsertComment_Error() {
 			comment.Username,
 			comment.Comment,
 			comment.PostId,
+			comment.Depth,
+			comment.Status,
 			comment.CreatedBy,
 			sqlmock.AnyArg(),
 			sqlmock.AnyArg(),
@@ -149,6 +155,9 @@ func (suite *CommentRepositoryTestSuite) TestUpdateComment_Success() {
 			comment.Username,
 			comment.Comment,
 			comment.PostId,
+			comment.ParentId,
+			comment.Depth,
+			comment.Status,
 			comment.CreatedBy,
 			comment.UpdatedBy,
 			sqlmock.AnyArg(), // CreatedAt
@@ -188,6 +197,9 @@ func (suite *CommentRepositoryTestSuite) TestUpdateComment_Error() {
 			comment.Username,
 			comment.Comment,
 			comment.PostId,
+			comment.ParentId,
+			comment.Depth,
+			comment.Status,
 			comment.CreatedBy,
 			comment.UpdatedBy,
 			sqlmock.AnyArg(),
@@ -341,8 +353,7 @@ func (suite *CommentRepositoryTestSuite) TestDeleteComment_NotFound() {
 
 func (suite *CommentRepositoryTestSuite) TestGetAllComment_Success() {
 	ctx := context.Background()
-	page := 1
-	limit := 10
+	page := pagination.Page{Limit: 10}
 	now := time.Now()
 
 	comment1 := model.CommentModel{
@@ -370,8 +381,8 @@ func (suite *CommentRepositoryTestSuite) TestGetAllComment_Success() {
 		AddRow(comment1.ID, comment1.Username, comment1.Comment, comment1.PostId, comment1.CreatedBy, nil, comment1.CreatedAt, comment1.UpdatedAt).
 		AddRow(comment2.ID, comment2.Username, comment2.Comment, comment2.PostId, comment2.CreatedBy, nil, comment2.CreatedAt, comment2.UpdatedAt)
 
-	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "comments" WHERE "comments"."deleted_at" IS NULL LIMIT`)).
-		WithArgs(limit).
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "comments" WHERE "comments"."deleted_at" IS NULL ORDER BY comments.created_at DESC, comments.id DESC LIMIT $1`)).
+		WithArgs(page.Limit + 1).
 		WillReturnRows(commentRows)
 
 	// Expect the preload query for Posts
@@ -383,7 +394,7 @@ func (suite *CommentRepositoryTestSuite) TestGetAllComment_Success() {
 		WithArgs("post-1", "post-2").
 		WillReturnRows(postRows)
 
-	result, err := suite.repository.GetAllComment(ctx, page, limit)
+	result, _, err := suite.repository.GetAllComment(ctx, payload.CommentFilter{}, page)
 
 	assert.NoError(suite.T(), err)
 	assert.Len(suite.T(), result, 2)
@@ -394,32 +405,31 @@ func (suite *CommentRepositoryTestSuite) TestGetAllComment_Success() {
 
 func (suite *CommentRepositoryTestSuite) TestGetAllComment_EmptyResult() {
 	ctx := context.Background()
-	page := 1
-	limit := 10
+	page := pagination.Page{Limit: 10}
 
 	commentRows := sqlmock.NewRows([]string{"id", "username", "comment", "post_id", "created_by", "updated_by", "created_at", "updated_at"})
 
-	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "comments" WHERE "comments"."deleted_at" IS NULL LIMIT`)).
-		WithArgs(limit).
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "comments" WHERE "comments"."deleted_at" IS NULL ORDER BY comments.created_at DESC, comments.id DESC LIMIT $1`)).
+		WithArgs(page.Limit + 1).
 		WillReturnRows(commentRows)
 
-	result, err := suite.repository.GetAllComment(ctx, page, limit)
+	result, meta, err := suite.repository.GetAllComment(ctx, payload.CommentFilter{}, page)
 
 	assert.NoError(suite.T(), err)
 	assert.Len(suite.T(), result, 0)
+	assert.Empty(suite.T(), meta.NextCursor)
 	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
 }
 
 func (suite *CommentRepositoryTestSuite) TestGetAllComment_Error() {
 	ctx := context.Background()
-	page := 1
-	limit := 10
+	page := pagination.Page{Limit: 10}
 
-	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "comments" WHERE "comments"."deleted_at" IS NULL LIMIT`)).
-		WithArgs(limit).
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "comments" WHERE "comments"."deleted_at" IS NULL ORDER BY comments.created_at DESC, comments.id DESC LIMIT $1`)).
+		WithArgs(page.Limit + 1).
 		WillReturnError(gorm.ErrInvalidDB)
 
-	result, err := suite.repository.GetAllComment(ctx, page, limit)
+	result, _, err := suite.repository.GetAllComment(ctx, payload.CommentFilter{}, page)
 
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), gorm.ErrInvalidDB, err)
@@ -429,19 +439,196 @@ func (suite *CommentRepositoryTestSuite) TestGetAllComment_Error() {
 
 func (suite *CommentRepositoryTestSuite) TestGetAllComment_Pagination() {
 	ctx := context.Background()
-	page := 2
-	limit := 5
-	offset := 5
+	page := pagination.Page{Limit: 5, Number: 2}
 
 	commentRows := sqlmock.NewRows([]string{"id", "username", "comment", "post_id", "created_by", "updated_by", "created_at", "updated_at"})
 
-	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "comments" WHERE "comments"."deleted_at" IS NULL LIMIT $1 OFFSET $2`)).
-		WithArgs(limit, offset).
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "comments" WHERE "comments"."deleted_at" IS NULL`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(12))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "comments" WHERE "comments"."deleted_at" IS NULL ORDER BY comments.created_at DESC, comments.id DESC LIMIT $1 OFFSET $2`)).
+		WithArgs(5, 5).
 		WillReturnRows(commentRows)
 
-	result, err := suite.repository.GetAllComment(ctx, page, limit)
+	result, meta, err := suite.repository.GetAllComment(ctx, payload.CommentFilter{}, page)
 
 	assert.NoError(suite.T(), err)
 	assert.Len(suite.T(), result, 0)
+	assert.Equal(suite.T(), int64(12), *meta.Total)
+	assert.Equal(suite.T(), 3, *meta.TotalPages)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *CommentRepositoryTestSuite) TestGetCommentThread() {
+	ctx := context.Background()
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "username", "comment", "post_id", "parent_id", "depth", "created_at", "deleted_at"}).
+		AddRow("comment-1", "alice", "Root", "post-123", nil, 0, now, now).
+		AddRow("comment-2", "bob", "Reply", "post-123", "comment-1", 1, now, nil)
+
+	suite.mock.ExpectQuery(`SELECT \* FROM "comments" WHERE post_id = \$1 ORDER BY created_at ASC, id ASC$`).
+		WithArgs("post-123").
+		WillReturnRows(rows)
+
+	result, err := suite.repository.GetCommentThread(ctx, "post-123")
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 2)
+	assert.True(suite.T(), result[0].DeletedAt.Valid)
+	assert.Equal(suite.T(), "comment-1", *result[1].ParentId)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *CommentRepositoryTestSuite) TestGetCommentsByPostId_OldestFirst() {
+	ctx := context.Background()
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "username", "comment", "post_id", "created_at"}).
+		AddRow("comment-1", "alice", "First", "post-123", now.Add(-time.Hour)).
+		AddRow("comment-2", "bob", "Second", "post-123", now).
+		AddRow("comment-3", "carol", "Third", "post-123", now.Add(time.Minute))
+
+	suite.mock.ExpectQuery(`SELECT \* FROM "comments" WHERE comments.post_id = \$1 AND "comments"."deleted_at" IS NULL ORDER BY comments.created_at ASC, comments.id ASC LIMIT \$2`).
+		WithArgs("post-123", 3).
+		WillReturnRows(rows)
+
+	result, meta, err := suite.repository.GetCommentsByPostId(ctx, "post-123", payload.CommentFilter{}, pagination.Page{Limit: 2, Ascending: true})
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 2)
+	assert.Equal(suite.T(), "First", result[0].Comment)
+	assert.NotEmpty(suite.T(), meta.NextCursor)
+
+	page, ok := pagination.Page{Limit: 2, Ascending: true}.Next(meta)
+	assert.True(suite.T(), ok)
+
+	suite.mock.ExpectQuery(`SELECT \* FROM "comments" WHERE comments.post_id = \$1 AND \(comments.created_at, comments.id\) > \(\$2, \$3\) AND "comments"."deleted_at" IS NULL ORDER BY comments.created_at ASC, comments.id ASC LIMIT \$4`).
+		WithArgs("post-123", sqlmock.AnyArg(), "comment-2", 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "comment", "post_id", "created_at"}).
+			AddRow("comment-3", "carol", "Third", "post-123", now.Add(time.Minute)))
+
+	result, meta, err = suite.repository.GetCommentsByPostId(ctx, "post-123", payload.CommentFilter{}, page)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 1)
+	assert.Empty(suite.T(), meta.NextCursor)
+	assert.NotEmpty(suite.T(), meta.PrevCursor)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *CommentRepositoryTestSuite) TestGetCommentsByPostId_VisibleFilter() {
+	ctx := context.Background()
+	filter := payload.CommentFilter{Status: []string{model.StatusApproved}, Username: "alice"}
+
+	suite.mock.ExpectQuery(`SELECT \* FROM "comments" WHERE comments.post_id = \$1 AND \(comments.status IN \(\$2\) OR comments.username = \$3\) AND "comments"."deleted_at" IS NULL ORDER BY comments.created_at DESC, comments.id DESC LIMIT \$4`).
+		WithArgs("post-123", model.StatusApproved, "alice", 11).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "comment", "post_id", "status"}).
+			AddRow("comment-1", "alice", "Mine", "post-123", model.StatusPending))
+
+	result, _, err := suite.repository.GetCommentsByPostId(ctx, "post-123", filter, pagination.Page{Limit: 10})
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 1)
+	assert.Equal(suite.T(), model.StatusPending, result[0].Status)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *CommentRepositoryTestSuite) TestGetAllComment_StatusFilter() {
+	ctx := context.Background()
+	filter := payload.CommentFilter{Status: []string{model.StatusPending}}
+
+	suite.mock.ExpectQuery(`SELECT \* FROM "comments" WHERE comments.status IN \(\$1\) AND "comments"."deleted_at" IS NULL ORDER BY comments.created_at ASC, comments.id ASC LIMIT \$2`).
+		WithArgs(model.StatusPending, 11).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "comment", "post_id", "status"}))
+
+	result, _, err := suite.repository.GetAllComment(ctx, filter, pagination.Page{Limit: 10, Ascending: true})
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 0)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *CommentRepositoryTestSuite) TestGetAllComment_PublishedOnly() {
+	ctx := context.Background()
+	filter := payload.CommentFilter{Status: []string{model.StatusApproved}, Username: "alice", PublishedOnly: true}
+
+	suite.mock.ExpectQuery(`SELECT \* FROM "comments" WHERE \(comments.post_id IN \(SELECT posts.id FROM posts WHERE posts.deleted_at IS NULL AND \(posts.status = \$1 OR posts.username = \$2\)\)\) AND \(comments.status IN \(\$3\) OR comments.username = \$4\) AND "comments"."deleted_at" IS NULL ORDER BY comments.created_at DESC, comments.id DESC LIMIT \$5`).
+		WithArgs("PUBLISH", "alice", model.StatusApproved, "alice", 11).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "comment", "post_id", "status"}))
+
+	result, _, err := suite.repository.GetAllComment(ctx, filter, pagination.Page{Limit: 10})
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 0)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *CommentRepositoryTestSuite) TestUpdateCommentStatus() {
+	ctx := context.Background()
+	ids := []string{"comment-1", "comment-2"}
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(`UPDATE "comments" SET "status"=\$1,"updated_by"=\$2,"updated_at"=\$3 WHERE id IN \(\$4,\$5\) AND "comments"."deleted_at" IS NULL`).
+		WithArgs(model.StatusSpam, "editor", sqlmock.AnyArg(), "comment-1", "comment-2").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.UpdateCommentStatus(ctx, ids, model.StatusSpam, "editor")
+
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *CommentRepositoryTestSuite) TestCountUserComments() {
+	ctx := context.Background()
+
+	suite.mock.ExpectQuery(`SELECT count\(\*\) FROM "comments" WHERE \(username = \$1 AND status = \$2\) AND "comments"."deleted_at" IS NULL`).
+		WithArgs("alice", model.StatusApproved).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+
+	total, err := suite.repository.CountUserComments(ctx, "alice", model.StatusApproved)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(4), total)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *CommentRepositoryTestSuite) TestAnonymizeUserComments_Success() {
+	ctx := context.Background()
+
+	rows := sqlmock.NewRows([]string{"id", "username", "comment", "status"}).
+		AddRow("123e4567-e89b-12d3-a456-426614174000", "[deleted]", "Nice read", "APPROVED")
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`UPDATE "comments" SET "username"=$1,"updated_at"=$2 WHERE username = $3 AND "comments"."deleted_at" IS NULL RETURNING *`)).
+		WithArgs("[deleted]", sqlmock.AnyArg(), "testuser").
+		WillReturnRows(rows)
+	suite.mock.ExpectCommit()
+
+	comments, err := suite.repository.AnonymizeUserComments(ctx, "testuser", "[deleted]")
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), comments, 1)
+	assert.Equal(suite.T(), "[deleted]", comments[0].Username)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *CommentRepositoryTestSuite) TestDeleteUserComments_Success() {
+	ctx := context.Background()
+
+	rows := sqlmock.NewRows([]string{"id", "username", "comment", "status"}).
+		AddRow("123e4567-e89b-12d3-a456-426614174000", "testuser", "Nice read", "APPROVED")
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`UPDATE "comments" SET "deleted_at"=$1 WHERE username = $2 AND "comments"."deleted_at" IS NULL RETURNING *`)).
+		WithArgs(sqlmock.AnyArg(), "testuser").
+		WillReturnRows(rows)
+	suite.mock.ExpectCommit()
+
+	comments, err := suite.repository.DeleteUserComments(ctx, "testuser")
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), comments, 1)
+	assert.Equal(suite.T(), strfmt.UUID4("123e4567-e89b-12d3-a456-426614174000"), comments[0].ID)
 	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
 }
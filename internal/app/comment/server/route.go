@@ -21,3 +21,21 @@ func (r routes) New(router *gin.RouterGroup, handler port.ICommentHandler) {
 	router.GET("/", handler.GetAllComment)
 	router.GET("/:id", handler.GetCommentById)
 }
+
+// NewModeration registers the moderation queue endpoints, the router is expected to only let moderators through
+func (r routes) NewModeration(router *gin.RouterGroup, handler port.ICommentHandler) {
+	router.GET("", handler.GetModerationQueue)
+	router.POST("/approve", handler.ApproveComments)
+	router.POST("/reject", handler.RejectComments)
+}
+
+// NewPost registers the comment endpoints nested under a post
+func (r routes) NewPost(router *gin.RouterGroup, handler port.ICommentHandler) {
+	router.GET("/:id/comments", handler.GetPostComments)
+	router.GET("/:id/comments/tree", handler.GetCommentTree)
+}
+
+// NewPublicPost registers the comment endpoints nested under a post that need no login
+func (r routes) NewPublicPost(router *gin.RouterGroup, handler port.ICommentHandler) {
+	router.GET("/:id/comments", handler.GetPublicPostComments)
+}
@@ -3,27 +3,57 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"simple-blog-system/internal/app/comment/model"
 	"simple-blog-system/internal/app/comment/payload"
 	"simple-blog-system/internal/app/comment/port"
+	filterModel "simple-blog-system/internal/app/filter/model"
+	filterPayload "simple-blog-system/internal/app/filter/payload"
+	filterPort "simple-blog-system/internal/app/filter/port"
+	postModel "simple-blog-system/internal/app/post/model"
 	postPort "simple-blog-system/internal/app/post/port"
+	searchModel "simple-blog-system/internal/app/search/model"
+	searchPort "simple-blog-system/internal/app/search/port"
+	userModel "simple-blog-system/internal/app/user/model"
 	userPort "simple-blog-system/internal/app/user/port"
+	"simple-blog-system/pkg/apperror"
+	"simple-blog-system/pkg/authorization"
+	"simple-blog-system/pkg/pagination"
 
-	"github.com/go-openapi/strfmt"
+	"github.com/rs/zerolog/log"
 )
 
+var errParentOtherPost = apperror.BadRequest("parent comment belongs to another post")
+
 type service struct {
 	commentRepo port.ICommentRepository
 	userRepo    userPort.IUserRepository
 	postRepo    postPort.IPostRepository
+	searchIndex searchPort.ISearchIndex
+	// contentFilter inspects comments of users that are not moderators before they are saved
+	contentFilter filterPort.ILearningFilter
+	// maxDepth is the deepest level a reply can be at, top level comments are at 0
+	maxDepth int
+	// moderation is the mode of posts that have none of their own
+	moderation string
+	// trustedAfter is how many approved comments make a user trusted in the trusted mode
+	trustedAfter int
+	// requireVerifiedEmail keeps users without a verified email address from commenting
+	requireVerifiedEmail bool
 }
 
-func New(commentRepo port.ICommentRepository, userRepo userPort.IUserRepository, postRepo postPort.IPostRepository) port.ICommentService {
+func New(commentRepo port.ICommentRepository, userRepo userPort.IUserRepository, postRepo postPort.IPostRepository, searchIndex searchPort.ISearchIndex, contentFilter filterPort.ILearningFilter, maxDepth int, moderation string, trustedAfter int, requireVerifiedEmail bool) port.ICommentService {
 	return &service{
-		commentRepo: commentRepo,
-		userRepo:    userRepo,
-		postRepo:    postRepo,
+		commentRepo:          commentRepo,
+		userRepo:             userRepo,
+		postRepo:             postRepo,
+		searchIndex:          searchIndex,
+		contentFilter:        contentFilter,
+		maxDepth:             maxDepth,
+		moderation:           moderation,
+		trustedAfter:         trustedAfter,
+		requireVerifiedEmail: requireVerifiedEmail,
 	}
 }
 
@@ -33,23 +63,58 @@ func (s *service) AddComment(ctx context.Context, username string, param payload
 		return nil, errors.New("user not found")
 	}
 
+	if s.requireVerifiedEmail && !authorization.IsModerator(users[0].Role) && !users[0].EmailVerified() {
+		return nil, authorization.ErrEmailNotVerified
+	}
+
 	comment := model.CommentModel{
 		Username:  users[0].Username,
 		Comment:   param.Comment,
 		PostId:    param.PostId,
 		CreatedBy: username,
 	}
-	comment, qerr = s.commentRepo.InsertComment(ctx, comment)
+
+	// the post is checked first so a post the user may not see answers the same whatever else is sent
+	post, qerr := s.postRepo.GetPostById(ctx, param.PostId)
+	if qerr != nil || !canViewPost(users[0], *post) {
+		return nil, errors.New("post not found")
+	}
+
+	if param.ParentId != nil && *param.ParentId != "" {
+		parent, qerr := s.commentRepo.GetCommentById(ctx, *param.ParentId)
+		if qerr != nil || !visibleTo(users[0]).Allows(*parent) || !canViewPost(users[0], parent.Post) {
+			return nil, errors.New("parent comment not found")
+		}
+
+		if parent.PostId != param.PostId {
+			return nil, errParentOtherPost
+		}
+
+		if parent.Depth >= s.maxDepth {
+			return nil, apperror.BadRequest(fmt.Sprintf("replies can not be nested deeper than %d levels", s.maxDepth))
+		}
+
+		parentId := string(parent.ID)
+		comment.ParentId = &parentId
+		comment.Depth = parent.Depth + 1
+	}
+
+	comment.Status, qerr = s.initialStatus(ctx, users[0], *post)
 	if qerr != nil {
 		return nil, qerr
 	}
+	comment.Status = s.filterComment(ctx, users[0], comment)
 
-	post, qerr := s.postRepo.GetPostById(ctx, comment.PostId)
+	comment, qerr = s.commentRepo.InsertComment(ctx, comment)
 	if qerr != nil {
 		return nil, qerr
 	}
 	comment.Post = *post
 
+	if comment.Status == model.StatusApproved {
+		s.indexComment(ctx, comment)
+	}
+
 	return &comment, nil
 }
 
@@ -59,13 +124,40 @@ func (s *service) UpdateComment(ctx context.Context, username string, id string,
 		return nil, errors.New("user not found")
 	}
 
+	if s.requireVerifiedEmail && !authorization.IsModerator(users[0].Role) && !users[0].EmailVerified() {
+		return nil, authorization.ErrEmailNotVerified
+	}
+
+	existing, qerr := s.commentRepo.GetCommentById(ctx, id)
+	if qerr != nil || !canViewPost(users[0], existing.Post) {
+		return nil, errors.New("comment not found")
+	}
+
+	if !authorization.CanManage(users[0].Role, users[0].Username, existing.Username) {
+		return nil, authorization.ErrForbidden
+	}
+
 	comment := model.CommentModel{
-		ID:        strfmt.UUID4(id),
-		Username:  users[0].Username,
+		ID:        existing.ID,
+		Username:  existing.Username,
 		Comment:   param.Comment,
-		PostId:    param.PostId,
-		CreatedBy: username,
+		PostId:    existing.PostId,
+		ParentId:  existing.ParentId,
+		Depth:     existing.Depth,
+		Status:    existing.Status,
+		CreatedBy: existing.CreatedBy,
+		UpdatedBy: username,
+		CreatedAt: existing.CreatedAt,
 	}
+
+	// an edited comment is reviewed again like a new one, so an approved comment can not be rewritten past moderation
+	if existing.Status == model.StatusApproved {
+		comment.Status, qerr = s.initialStatus(ctx, users[0], existing.Post)
+		if qerr != nil {
+			return nil, qerr
+		}
+	}
+	comment.Status = s.filterComment(ctx, users[0], comment)
 	comment, qerr = s.commentRepo.UpdateComment(ctx, comment)
 	if qerr != nil {
 		return nil, qerr
@@ -77,6 +169,12 @@ func (s *service) UpdateComment(ctx context.Context, username string, id string,
 	}
 	comment.Post = *post
 
+	if comment.Status == model.StatusApproved {
+		s.indexComment(ctx, comment)
+	} else if existing.Status == model.StatusApproved {
+		s.unindexComment(ctx, string(comment.ID))
+	}
+
 	return &comment, nil
 }
 
@@ -91,26 +189,37 @@ func (s *service) DeleteComment(ctx context.Context, username string, id string)
 		return nil, errors.New("comment not found")
 	}
 
+	if !authorization.CanManage(users[0].Role, users[0].Username, comment.Username) {
+		return nil, authorization.ErrForbidden
+	}
+
 	err = s.commentRepo.DeleteComment(ctx, *comment)
 	if err != nil {
 		return nil, err
 	}
 
+	err = s.searchIndex.Remove(ctx, searchModel.TypeComment, id)
+	if err != nil {
+		log.Error().Err(err).Str("comment_id", id).Msg("failed to remove comment from search index")
+	}
+
 	return comment, nil
 }
 
-func (s *service) GetAllComment(ctx context.Context, username string, page int, limit int) (res []model.CommentModel, err error) {
+func (s *service) GetAllComment(ctx context.Context, username string, page pagination.Page) (res []model.CommentModel, meta pagination.Meta, err error) {
 	users, qerr := s.userRepo.GetUserByUsername(ctx, username)
 	if len(users) == 0 || qerr != nil {
-		return nil, errors.New("user not found")
+		return nil, meta, errors.New("user not found")
 	}
 
-	post, err := s.commentRepo.GetAllComment(ctx, page, limit)
+	filter := visibleTo(users[0])
+	filter.PublishedOnly = !authorization.IsModerator(users[0].Role)
+	post, meta, err := s.commentRepo.GetAllComment(ctx, filter, page)
 	if err != nil {
-		return nil, errors.New("comment not found")
+		return nil, meta, errors.New("comment not found")
 	}
 
-	return post, nil
+	return post, meta, nil
 }
 
 func (s *service) GetCommentById(ctx context.Context, username string, id string) (res *model.CommentModel, err error) {
@@ -124,5 +233,322 @@ func (s *service) GetCommentById(ctx context.Context, username string, id string
 		return nil, errors.New("post not found")
 	}
 
+	if !visibleTo(users[0]).Allows(*comment) || !canViewPost(users[0], comment.Post) {
+		return nil, errors.New("comment not found")
+	}
+
 	return comment, nil
 }
+
+func (s *service) GetPostComments(ctx context.Context, username string, postId string, page pagination.Page) (res []model.CommentModel, meta pagination.Meta, err error) {
+	users, qerr := s.userRepo.GetUserByUsername(ctx, username)
+	if len(users) == 0 || qerr != nil {
+		return nil, meta, errors.New("user not found")
+	}
+
+	post, err := s.postRepo.GetPostById(ctx, postId)
+	if err != nil || !canViewPost(users[0], *post) {
+		return nil, meta, errors.New("post not found")
+	}
+
+	return s.commentRepo.GetCommentsByPostId(ctx, postId, visibleTo(users[0]), page)
+}
+
+// GetPublicPostComments lists the approved comments of a published post, other posts answer as not found
+func (s *service) GetPublicPostComments(ctx context.Context, postId string, page pagination.Page) (res []model.CommentModel, meta pagination.Meta, err error) {
+	post, err := s.postRepo.GetPostById(ctx, postId)
+	if err != nil || post.Status != postModel.StatusPublish {
+		return nil, meta, errors.New("post not found")
+	}
+
+	filter := payload.CommentFilter{Status: []string{model.StatusApproved}}
+	return s.commentRepo.GetCommentsByPostId(ctx, postId, filter, page)
+}
+
+// GetCommentTree nests the comments of a post under the comment they reply to,
+// deleted comments and comments the user may not see stay as a placeholder while they have replies
+func (s *service) GetCommentTree(ctx context.Context, username string, postId string) (res []*payload.CommentNode, err error) {
+	users, qerr := s.userRepo.GetUserByUsername(ctx, username)
+	if len(users) == 0 || qerr != nil {
+		return nil, errors.New("user not found")
+	}
+
+	post, err := s.postRepo.GetPostById(ctx, postId)
+	if err != nil || !canViewPost(users[0], *post) {
+		return nil, errors.New("post not found")
+	}
+
+	comments, err := s.commentRepo.GetCommentThread(ctx, postId)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildCommentTree(comments, visibleTo(users[0])), nil
+}
+
+// GetModerationQueue lists the comments in one moderation state oldest first, pending by default
+func (s *service) GetModerationQueue(ctx context.Context, username string, status string, page pagination.Page) (res []model.CommentModel, meta pagination.Meta, err error) {
+	err = s.moderator(ctx, username)
+	if err != nil {
+		return nil, meta, err
+	}
+
+	if status == "" {
+		status = model.StatusPending
+	}
+
+	return s.commentRepo.GetAllComment(ctx, payload.CommentFilter{Status: []string{status}}, page)
+}
+
+// ModerateComments moves every comment of ids to status, approved comments become searchable and the others leave the index
+func (s *service) ModerateComments(ctx context.Context, username string, ids []string, status string) (res []model.CommentModel, err error) {
+	err = s.moderator(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := s.commentRepo.GetCommentsByIds(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]bool, len(comments))
+	for _, comment := range comments {
+		found[string(comment.ID)] = true
+	}
+	for _, id := range ids {
+		if !found[id] {
+			return nil, fmt.Errorf("comment %s not found", id)
+		}
+	}
+
+	err = s.commentRepo.UpdateCommentStatus(ctx, ids, status, username)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range comments {
+		s.learn(ctx, comments[i], status)
+
+		comments[i].Status = status
+		comments[i].UpdatedBy = username
+
+		if status == model.StatusApproved {
+			s.indexComment(ctx, comments[i])
+			continue
+		}
+
+		s.unindexComment(ctx, string(comments[i].ID))
+	}
+
+	return comments, nil
+}
+
+// AnonymizeUserContent hands the comments of a deleted account over to placeholder
+func (s *service) AnonymizeUserContent(ctx context.Context, username string, placeholder string) (int, error) {
+	comments, err := s.commentRepo.AnonymizeUserComments(ctx, username, placeholder)
+	if err != nil || len(comments) == 0 {
+		return 0, err
+	}
+
+	approved := make([]string, 0, len(comments))
+	for _, comment := range comments {
+		if comment.Status == model.StatusApproved {
+			approved = append(approved, string(comment.ID))
+		}
+	}
+	if len(approved) == 0 {
+		return len(comments), nil
+	}
+
+	// the documents carry the title of the post, so the comments are read again with it
+	indexed, err := s.commentRepo.GetCommentsByIds(ctx, approved)
+	if err != nil {
+		log.Error().Err(err).Str("username", username).Msg("failed to reindex anonymized comments")
+		return len(comments), nil
+	}
+	for _, comment := range indexed {
+		if comment.Post.ID != "" {
+			s.indexComment(ctx, comment)
+		}
+	}
+
+	return len(comments), nil
+}
+
+// DeleteUserContent deletes the comments of a deleted account, replies of other users stay under a deleted placeholder
+func (s *service) DeleteUserContent(ctx context.Context, username string) (int, error) {
+	comments, err := s.commentRepo.DeleteUserComments(ctx, username)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, comment := range comments {
+		s.unindexComment(ctx, string(comment.ID))
+	}
+
+	return len(comments), nil
+}
+
+// moderator checks that the user exists and may moderate comments
+func (s *service) moderator(ctx context.Context, username string) error {
+	users, err := s.userRepo.GetUserByUsername(ctx, username)
+	if len(users) == 0 || err != nil {
+		return errors.New("user not found")
+	}
+
+	if !authorization.IsModerator(users[0].Role) {
+		return authorization.ErrForbidden
+	}
+
+	return nil
+}
+
+// initialStatus picks the state of a new comment from the moderation mode of its post, moderators are never held for review
+func (s *service) initialStatus(ctx context.Context, user userModel.AuthUserModel, post postModel.PostModel) (string, error) {
+	mode := s.moderation
+	if post.CommentModeration != nil {
+		mode = *post.CommentModeration
+	}
+
+	if authorization.IsModerator(user.Role) {
+		return model.StatusApproved, nil
+	}
+
+	switch mode {
+	case postModel.CommentModerationApproval:
+		return model.StatusPending, nil
+	case postModel.CommentModerationTrusted:
+		approved, err := s.commentRepo.CountUserComments(ctx, user.Username, model.StatusApproved)
+		if err != nil {
+			return "", err
+		}
+
+		if approved < int64(s.trustedAfter) {
+			return model.StatusPending, nil
+		}
+	}
+
+	return model.StatusApproved, nil
+}
+
+// filterComment runs the content filters on comments of users that are not moderators,
+// rejected comments are saved as rejected, doubtful ones and those the filters could not check wait for review
+func (s *service) filterComment(ctx context.Context, user userModel.AuthUserModel, comment model.CommentModel) string {
+	if authorization.IsModerator(user.Role) || comment.Status == model.StatusRejected || comment.Status == model.StatusSpam {
+		return comment.Status
+	}
+
+	verdict, err := s.contentFilter.Check(ctx, filterPayload.Content{
+		ID:       string(comment.ID),
+		Type:     filterPayload.TypeComment,
+		Username: comment.Username,
+		Text:     comment.Comment,
+	})
+	if err != nil {
+		log.Error().Err(err).Str("username", comment.Username).Msg("content filter failed on comment, held for review")
+		return model.StatusPending
+	}
+
+	switch verdict.Action {
+	case filterModel.ActionReject:
+		log.Info().Str("username", comment.Username).Str("filter", verdict.Filter).Str("reason", verdict.Reason).Msg("comment rejected by content filter")
+		return model.StatusRejected
+	case filterModel.ActionReview:
+		log.Info().Str("username", comment.Username).Str("filter", verdict.Filter).Str("reason", verdict.Reason).Msg("comment held for review by content filter")
+		return model.StatusPending
+	}
+
+	return comment.Status
+}
+
+// learn trains the content filters with a moderator decision, only spam and approvals say something about the content
+func (s *service) learn(ctx context.Context, comment model.CommentModel, status string) {
+	if comment.Status == status || (status != model.StatusSpam && status != model.StatusApproved) {
+		return
+	}
+
+	err := s.contentFilter.Learn(ctx, filterPayload.Content{
+		ID:       string(comment.ID),
+		Type:     filterPayload.TypeComment,
+		Username: comment.Username,
+		Text:     comment.Comment,
+	}, status == model.StatusSpam)
+	if err != nil {
+		log.Error().Err(err).Str("comment_id", string(comment.ID)).Msg("failed to train content filter")
+	}
+}
+
+// canViewPost keeps the comments of drafts and scheduled posts to their author and moderators, like the post itself
+func canViewPost(user userModel.AuthUserModel, post postModel.PostModel) bool {
+	return post.Status == postModel.StatusPublish || authorization.CanManage(user.Role, user.Username, post.Username)
+}
+
+// visibleTo moderators see every comment, other users see approved comments and their own
+func visibleTo(user userModel.AuthUserModel) payload.CommentFilter {
+	if authorization.IsModerator(user.Role) {
+		return payload.CommentFilter{}
+	}
+
+	return payload.CommentFilter{Status: []string{model.StatusApproved}, Username: user.Username}
+}
+
+// buildCommentTree expects comments oldest first so replies keep their order
+func buildCommentTree(comments []model.CommentModel, filter payload.CommentFilter) []*payload.CommentNode {
+	nodes := make(map[string]*payload.CommentNode, len(comments))
+	for _, comment := range comments {
+		node := payload.NewCommentNode(comment)
+		if !filter.Allows(comment) {
+			node.Hide()
+		}
+		nodes[string(comment.ID)] = node
+	}
+
+	roots := []*payload.CommentNode{}
+	for _, comment := range comments {
+		node := nodes[string(comment.ID)]
+
+		var parent *payload.CommentNode
+		if comment.ParentId != nil {
+			parent = nodes[*comment.ParentId]
+		}
+
+		if parent != nil {
+			parent.Replies = append(parent.Replies, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	return pruneDeleted(roots)
+}
+
+// pruneDeleted drops deleted comments that have no reply left to show
+func pruneDeleted(nodes []*payload.CommentNode) []*payload.CommentNode {
+	kept := []*payload.CommentNode{}
+	for _, node := range nodes {
+		node.Replies = pruneDeleted(node.Replies)
+		if node.Deleted && len(node.Replies) == 0 {
+			continue
+		}
+		kept = append(kept, node)
+	}
+
+	return kept
+}
+
+// indexComment updates the search index, the comment is already saved so a failure is only logged
+func (s *service) indexComment(ctx context.Context, comment model.CommentModel) {
+	err := s.searchIndex.Index(ctx, searchModel.NewCommentDocument(comment))
+	if err != nil {
+		log.Error().Err(err).Str("comment_id", string(comment.ID)).Msg("failed to index comment")
+	}
+}
+
+// unindexComment removes a comment that is no longer visible to everyone from the search index
+func (s *service) unindexComment(ctx context.Context, id string) {
+	err := s.searchIndex.Remove(ctx, searchModel.TypeComment, id)
+	if err != nil {
+		log.Error().Err(err).Str("comment_id", id).Msg("failed to remove comment from search index")
+	}
+}
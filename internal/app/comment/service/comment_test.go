@@ -8,8 +8,18 @@ import (
 
 	"simple-blog-system/internal/app/comment/model"
 	"simple-blog-system/internal/app/comment/payload"
+	filterModel "simple-blog-system/internal/app/filter/model"
+	filterPayload "simple-blog-system/internal/app/filter/payload"
+	filterService "simple-blog-system/internal/app/filter/service"
 	postModel "simple-blog-system/internal/app/post/model"
+	postPayload "simple-blog-system/internal/app/post/payload"
+	searchModel "simple-blog-system/internal/app/search/model"
+	searchPayload "simple-blog-system/internal/app/search/payload"
+	searchRepository "simple-blog-system/internal/app/search/repository"
 	userModel "simple-blog-system/internal/app/user/model"
+	"simple-blog-system/pkg/apperror"
+	"simple-blog-system/pkg/authorization"
+	"simple-blog-system/pkg/pagination"
 
 	"github.com/go-openapi/strfmt"
 	"github.com/stretchr/testify/assert"
@@ -46,14 +56,79 @@ func (m *MockCommentRepository) DeleteComment(ctx context.Context, comment model
 	return args.Error(0)
 }
 
-func (m *MockCommentRepository) GetAllComment(ctx context.Context, page int, limit int) ([]model.CommentModel, error) {
-	args := m.Called(ctx, page, limit)
+func (m *MockCommentRepository) GetAllComment(ctx context.Context, filter payload.CommentFilter, page pagination.Page) ([]model.CommentModel, pagination.Meta, error) {
+	args := m.Called(ctx, filter, page)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(pagination.Meta), args.Error(2)
+	}
+	return args.Get(0).([]model.CommentModel), args.Get(1).(pagination.Meta), args.Error(2)
+}
+
+func (m *MockCommentRepository) GetCommentsByPostId(ctx context.Context, postId string, filter payload.CommentFilter, page pagination.Page) ([]model.CommentModel, pagination.Meta, error) {
+	args := m.Called(ctx, postId, filter, page)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(pagination.Meta), args.Error(2)
+	}
+	return args.Get(0).([]model.CommentModel), args.Get(1).(pagination.Meta), args.Error(2)
+}
+
+func (m *MockCommentRepository) GetCommentThread(ctx context.Context, postId string) ([]model.CommentModel, error) {
+	args := m.Called(ctx, postId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.CommentModel), args.Error(1)
+}
+
+func (m *MockCommentRepository) GetCommentsByIds(ctx context.Context, ids []string) ([]model.CommentModel, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.CommentModel), args.Error(1)
+}
+
+func (m *MockCommentRepository) UpdateCommentStatus(ctx context.Context, ids []string, status string, updatedBy string) error {
+	args := m.Called(ctx, ids, status, updatedBy)
+	return args.Error(0)
+}
+
+func (m *MockCommentRepository) CountUserComments(ctx context.Context, username string, status string) (int64, error) {
+	args := m.Called(ctx, username, status)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockCommentRepository) AnonymizeUserComments(ctx context.Context, username string, placeholder string) ([]model.CommentModel, error) {
+	args := m.Called(ctx, username, placeholder)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.CommentModel), args.Error(1)
+}
+
+func (m *MockCommentRepository) DeleteUserComments(ctx context.Context, username string) ([]model.CommentModel, error) {
+	args := m.Called(ctx, username)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]model.CommentModel), args.Error(1)
 }
 
+// Mock for ILearningFilter
+type MockContentFilter struct {
+	mock.Mock
+}
+
+func (m *MockContentFilter) Check(ctx context.Context, content filterPayload.Content) (filterModel.Verdict, error) {
+	args := m.Called(ctx, content)
+	return args.Get(0).(filterModel.Verdict), args.Error(1)
+}
+
+func (m *MockContentFilter) Learn(ctx context.Context, content filterPayload.Content, spam bool) error {
+	args := m.Called(ctx, content, spam)
+	return args.Error(0)
+}
+
 // Mock for IUserRepository
 type MockUserRepository struct {
 	mock.Mock
@@ -85,6 +160,67 @@ func (m *MockUserRepository) UpdateLastLogin(ctx context.Context, user userModel
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) UpdateRole(ctx context.Context, username string, role string) error {
+	args := m.Called(ctx, username, role)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdatePassword(ctx context.Context, username string, password string) error {
+	args := m.Called(ctx, username, password)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateEmail(ctx context.Context, username string, email string) error {
+	args := m.Called(ctx, username, email)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) VerifyEmail(ctx context.Context, username string, email string) (bool, error) {
+	args := m.Called(ctx, username, email)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) IsUsernameTaken(ctx context.Context, username string) (bool, error) {
+	args := m.Called(ctx, username)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) SuspendUser(ctx context.Context, username string, reason string, until *time.Time) error {
+	args := m.Called(ctx, username, reason, until)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ReactivateUser(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ScheduleDeletion(ctx context.Context, username string, deleteAfter *time.Time) error {
+	args := m.Called(ctx, username, deleteAfter)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetUsersDueForDeletion(ctx context.Context, now time.Time, limit int) ([]userModel.AuthUserModel, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]userModel.AuthUserModel), args.Error(1)
+}
+
+func (m *MockUserRepository) DeleteUser(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetUserByEmail(ctx context.Context, email string) ([]userModel.AuthUserModel, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]userModel.AuthUserModel), args.Error(1)
+}
+
 // Mock for IPostRepository
 type MockPostRepository struct {
 	mock.Mock
@@ -113,8 +249,100 @@ func (m *MockPostRepository) DeletePost(ctx context.Context, post postModel.Post
 	return args.Error(0)
 }
 
-func (m *MockPostRepository) GetAllPost(ctx context.Context, page int, limit int) ([]postModel.PostModel, error) {
-	args := m.Called(ctx, page, limit)
+func (m *MockPostRepository) GetAllPost(ctx context.Context, filter postPayload.PostFilter, page pagination.Page) ([]postModel.PostModel, pagination.Meta, error) {
+	args := m.Called(ctx, filter, page)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(pagination.Meta), args.Error(2)
+	}
+	return args.Get(0).([]postModel.PostModel), args.Get(1).(pagination.Meta), args.Error(2)
+}
+
+func (m *MockPostRepository) GetPublishedSlugs(ctx context.Context) ([]postModel.PostModel, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]postModel.PostModel), args.Error(1)
+}
+
+func (m *MockPostRepository) GetDueScheduledPosts(ctx context.Context, now time.Time, limit int) ([]postModel.PostModel, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]postModel.PostModel), args.Error(1)
+}
+
+func (m *MockPostRepository) InsertRevision(ctx context.Context, revision postModel.PostRevisionModel) (postModel.PostRevisionModel, error) {
+	args := m.Called(ctx, revision)
+	return args.Get(0).(postModel.PostRevisionModel), args.Error(1)
+}
+
+func (m *MockPostRepository) GetRevisions(ctx context.Context, postId string, page pagination.Page) ([]postModel.PostRevisionModel, pagination.Meta, error) {
+	args := m.Called(ctx, postId, page)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(pagination.Meta), args.Error(2)
+	}
+	return args.Get(0).([]postModel.PostRevisionModel), args.Get(1).(pagination.Meta), args.Error(2)
+}
+
+func (m *MockPostRepository) GetRevision(ctx context.Context, postId string, revision int) (*postModel.PostRevisionModel, error) {
+	args := m.Called(ctx, postId, revision)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*postModel.PostRevisionModel), args.Error(1)
+}
+
+func (m *MockPostRepository) CountComments(ctx context.Context, postIds []string) (map[string]int64, error) {
+	args := m.Called(ctx, postIds)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+
+func (m *MockPostRepository) GetPostBySlug(ctx context.Context, slug string) (*postModel.PostModel, error) {
+	args := m.Called(ctx, slug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*postModel.PostModel), args.Error(1)
+}
+
+func (m *MockPostRepository) IsSlugTaken(ctx context.Context, slug string, excludePostId string) (bool, error) {
+	args := m.Called(ctx, slug, excludePostId)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPostRepository) GetSlugHistory(ctx context.Context, slug string) (*postModel.PostSlugHistoryModel, error) {
+	args := m.Called(ctx, slug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*postModel.PostSlugHistoryModel), args.Error(1)
+}
+
+func (m *MockPostRepository) InsertSlugHistory(ctx context.Context, history postModel.PostSlugHistoryModel) (postModel.PostSlugHistoryModel, error) {
+	args := m.Called(ctx, history)
+	return args.Get(0).(postModel.PostSlugHistoryModel), args.Error(1)
+}
+
+func (m *MockPostRepository) DeleteSlugHistory(ctx context.Context, postId string, slug string) error {
+	args := m.Called(ctx, postId, slug)
+	return args.Error(0)
+}
+
+func (m *MockPostRepository) AnonymizeUserPosts(ctx context.Context, username string, placeholder string) ([]postModel.PostModel, error) {
+	args := m.Called(ctx, username, placeholder)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]postModel.PostModel), args.Error(1)
+}
+
+func (m *MockPostRepository) DeleteUserPosts(ctx context.Context, username string) ([]postModel.PostModel, error) {
+	args := m.Called(ctx, username)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -136,9 +364,12 @@ func (suite *CommentServiceTestSuite) SetupTest() {
 	suite.userRepo = new(MockUserRepository)
 	suite.postRepo = new(MockPostRepository)
 	suite.service = &service{
-		commentRepo: suite.commentRepo,
-		userRepo:    suite.userRepo,
-		postRepo:    suite.postRepo,
+		commentRepo:   suite.commentRepo,
+		userRepo:      suite.userRepo,
+		postRepo:      suite.postRepo,
+		searchIndex:   searchRepository.NewMemoryIndex(),
+		contentFilter: filterService.New(),
+		maxDepth:      2,
 	}
 	suite.ctx = context.Background()
 }
@@ -175,6 +406,7 @@ func (suite *CommentServiceTestSuite) TestAddComment_Success() {
 		Username: "postuser",
 		Title:    "Test Post",
 		Body:     "Test Body",
+		Status:   postModel.StatusPublish,
 	}
 
 	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
@@ -241,6 +473,7 @@ func (suite *CommentServiceTestSuite) TestAddComment_InsertCommentError() {
 	}
 
 	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, param.PostId).Return(&postModel.PostModel{ID: strfmt.UUID4("post-123"), Status: postModel.StatusPublish}, nil)
 	suite.commentRepo.On("InsertComment", suite.ctx, mock.Anything).Return(model.CommentModel{}, errors.New("insert error"))
 
 	result, err := suite.service.AddComment(suite.ctx, username, param)
@@ -254,8 +487,7 @@ func (suite *CommentServiceTestSuite) TestAddComment_InsertCommentError() {
 
 func (suite *CommentServiceTestSuite) TestAddComment_GetPostError() {
 	username := "testuser"
-	now := time.Now()
-	
+
 	param := payload.CommentRequest{
 		Comment: "Test comment",
 		PostId:  "post-123",
@@ -266,17 +498,7 @@ func (suite *CommentServiceTestSuite) TestAddComment_GetPostError() {
 		Username: username,
 	}
 
-	comment := model.CommentModel{
-		ID:        strfmt.UUID4("comment-123"),
-		Username:  username,
-		Comment:   param.Comment,
-		PostId:    param.PostId,
-		CreatedBy: username,
-		CreatedAt: now,
-	}
-
 	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
-	suite.commentRepo.On("InsertComment", suite.ctx, mock.Anything).Return(comment, nil)
 	suite.postRepo.On("GetPostById", suite.ctx, param.PostId).Return(nil, errors.New("post not found"))
 
 	result, err := suite.service.AddComment(suite.ctx, username, param)
@@ -293,7 +515,7 @@ func (suite *CommentServiceTestSuite) TestUpdateComment_Success() {
 	username := "testuser"
 	commentID := "comment-123"
 	now := time.Now()
-	
+
 	param := payload.CommentRequest{
 		Comment: "Updated comment",
 		PostId:  "post-123",
@@ -304,6 +526,15 @@ func (suite *CommentServiceTestSuite) TestUpdateComment_Success() {
 		Username: username,
 	}
 
+	existing := model.CommentModel{
+		ID:        strfmt.UUID4(commentID),
+		Username:  username,
+		Comment:   "Test comment",
+		PostId:    param.PostId,
+		CreatedBy: username,
+		Post:      postModel.PostModel{ID: strfmt.UUID4("post-123"), Status: postModel.StatusPublish},
+	}
+
 	comment := model.CommentModel{
 		ID:        strfmt.UUID4(commentID),
 		Username:  username,
@@ -321,8 +552,9 @@ func (suite *CommentServiceTestSuite) TestUpdateComment_Success() {
 	}
 
 	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.commentRepo.On("GetCommentById", suite.ctx, commentID).Return(&existing, nil)
 	suite.commentRepo.On("UpdateComment", suite.ctx, mock.MatchedBy(func(c model.CommentModel) bool {
-		return c.Username == username && c.Comment == param.Comment && c.PostId == param.PostId
+		return c.Username == username && c.Comment == param.Comment && c.PostId == param.PostId && c.UpdatedBy == username
 	})).Return(comment, nil)
 	suite.postRepo.On("GetPostById", suite.ctx, param.PostId).Return(&post, nil)
 
@@ -337,6 +569,37 @@ func (suite *CommentServiceTestSuite) TestUpdateComment_Success() {
 	suite.postRepo.AssertExpectations(suite.T())
 }
 
+func (suite *CommentServiceTestSuite) TestUpdateComment_NotOwnerForbidden() {
+	username := "testuser"
+	commentID := "comment-123"
+	param := payload.CommentRequest{
+		Comment: "Updated comment",
+		PostId:  "post-123",
+	}
+
+	user := userModel.AuthUserModel{
+		ID:       strfmt.UUID4("user-123"),
+		Username: username,
+		Role:     authorization.RoleAuthor,
+	}
+
+	existing := model.CommentModel{
+		ID:       strfmt.UUID4(commentID),
+		Username: "someoneelse",
+		PostId:   param.PostId,
+		Post:     postModel.PostModel{ID: strfmt.UUID4("post-123"), Status: postModel.StatusPublish},
+	}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.commentRepo.On("GetCommentById", suite.ctx, commentID).Return(&existing, nil)
+
+	result, err := suite.service.UpdateComment(suite.ctx, username, commentID, param)
+
+	assert.ErrorIs(suite.T(), err, authorization.ErrForbidden)
+	assert.Nil(suite.T(), result)
+	suite.commentRepo.AssertNotCalled(suite.T(), "UpdateComment", mock.Anything, mock.Anything)
+}
+
 func (suite *CommentServiceTestSuite) TestUpdateComment_UserNotFound() {
 	username := "nonexistent"
 	commentID := "comment-123"
@@ -368,7 +631,15 @@ func (suite *CommentServiceTestSuite) TestUpdateComment_UpdateError() {
 		Username: username,
 	}
 
+	existing := model.CommentModel{
+		ID:       strfmt.UUID4(commentID),
+		Username: username,
+		PostId:   param.PostId,
+		Post:     postModel.PostModel{ID: strfmt.UUID4("post-123"), Status: postModel.StatusPublish},
+	}
+
 	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.commentRepo.On("GetCommentById", suite.ctx, commentID).Return(&existing, nil)
 	suite.commentRepo.On("UpdateComment", suite.ctx, mock.Anything).Return(model.CommentModel{}, errors.New("update error"))
 
 	result, err := suite.service.UpdateComment(suite.ctx, username, commentID, param)
@@ -380,72 +651,83 @@ func (suite *CommentServiceTestSuite) TestUpdateComment_UpdateError() {
 	suite.commentRepo.AssertExpectations(suite.T())
 }
 
-func (suite *CommentServiceTestSuite) TestDeleteComment_Success() {
+func (suite *CommentServiceTestSuite) TestUpdateComment_DraftPostOfOtherUser() {
 	username := "testuser"
 	commentID := "comment-123"
+	param := payload.CommentRequest{Comment: "Edited", PostId: "post-123"}
 
-	user := userModel.AuthUserModel{
-		ID:       strfmt.UUID4("user-123"),
+	user := userModel.AuthUserModel{Username: username, Role: authorization.RoleAuthor}
+	existing := model.CommentModel{
+		ID:       strfmt.UUID4(commentID),
 		Username: username,
-	}
-
-	comment := model.CommentModel{
-		ID:        strfmt.UUID4(commentID),
-		Username:  username,
-		Comment:   "Test comment",
-		PostId:    "post-123",
-		CreatedBy: username,
+		PostId:   param.PostId,
+		Status:   model.StatusApproved,
+		Post:     postModel.PostModel{ID: strfmt.UUID4("post-123"), Username: "alice", Status: postModel.StatusDraft},
 	}
 
 	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
-	suite.commentRepo.On("GetCommentById", suite.ctx, commentID).Return(&comment, nil)
-	suite.commentRepo.On("DeleteComment", suite.ctx, comment).Return(nil)
+	suite.commentRepo.On("GetCommentById", suite.ctx, commentID).Return(&existing, nil)
 
-	result, err := suite.service.DeleteComment(suite.ctx, username, commentID)
+	result, err := suite.service.UpdateComment(suite.ctx, username, commentID, param)
 
-	assert.NoError(suite.T(), err)
-	assert.NotNil(suite.T(), result)
-	assert.Equal(suite.T(), comment.ID, result.ID)
-	suite.userRepo.AssertExpectations(suite.T())
-	suite.commentRepo.AssertExpectations(suite.T())
+	assert.EqualError(suite.T(), err, "comment not found")
+	assert.Nil(suite.T(), result)
+	suite.commentRepo.AssertNotCalled(suite.T(), "UpdateComment", mock.Anything, mock.Anything)
 }
 
-func (suite *CommentServiceTestSuite) TestDeleteComment_UserNotFound() {
-	username := "nonexistent"
+func (suite *CommentServiceTestSuite) TestUpdateComment_ApprovalModeReviewsEdit() {
+	username := "testuser"
 	commentID := "comment-123"
+	param := payload.CommentRequest{Comment: "Something else entirely", PostId: "post-123"}
 
-	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{}, nil)
+	suite.service.moderation = postModel.CommentModerationApproval
+	user := userModel.AuthUserModel{Username: username, Role: authorization.RoleReader}
+	post := postModel.PostModel{ID: strfmt.UUID4("post-123"), Title: "Test Post", Status: postModel.StatusPublish}
+	existing := model.CommentModel{ID: strfmt.UUID4(commentID), Username: username, Comment: "Nice post", PostId: param.PostId, Status: model.StatusApproved, Post: post}
+	assert.NoError(suite.T(), suite.service.searchIndex.Index(suite.ctx, searchModel.NewCommentDocument(existing)))
 
-	result, err := suite.service.DeleteComment(suite.ctx, username, commentID)
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.commentRepo.On("GetCommentById", suite.ctx, commentID).Return(&existing, nil)
+	suite.commentRepo.On("UpdateComment", suite.ctx, mock.MatchedBy(func(c model.CommentModel) bool {
+		return c.Status == model.StatusPending
+	})).Return(model.CommentModel{ID: existing.ID, Username: username, Comment: param.Comment, PostId: param.PostId, Status: model.StatusPending}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, param.PostId).Return(&post, nil)
 
-	assert.Error(suite.T(), err)
-	assert.Nil(suite.T(), result)
-	assert.Equal(suite.T(), "user not found", err.Error())
-	suite.userRepo.AssertExpectations(suite.T())
+	result, err := suite.service.UpdateComment(suite.ctx, username, commentID, param)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.StatusPending, result.Status)
+	found, _, err := suite.service.searchIndex.Search(suite.ctx, searchPayload.SearchRequest{Query: "nice"}, pagination.Page{Limit: 10})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), found, 0)
+	suite.commentRepo.AssertExpectations(suite.T())
 }
 
-func (suite *CommentServiceTestSuite) TestDeleteComment_CommentNotFound() {
-	username := "testuser"
-	commentID := "nonexistent"
+func (suite *CommentServiceTestSuite) TestUpdateComment_ModeratorEditStaysApproved() {
+	username := "editor"
+	commentID := "comment-123"
+	param := payload.CommentRequest{Comment: "Fixed a typo", PostId: "post-123"}
 
-	user := userModel.AuthUserModel{
-		ID:       strfmt.UUID4("user-123"),
-		Username: username,
-	}
+	suite.service.moderation = postModel.CommentModerationApproval
+	user := userModel.AuthUserModel{Username: username, Role: authorization.RoleEditor}
+	post := postModel.PostModel{ID: strfmt.UUID4("post-123"), Status: postModel.StatusPublish}
+	existing := model.CommentModel{ID: strfmt.UUID4(commentID), Username: "alice", PostId: param.PostId, Status: model.StatusApproved, Post: post}
 
 	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
-	suite.commentRepo.On("GetCommentById", suite.ctx, commentID).Return(nil, gorm.ErrRecordNotFound)
+	suite.commentRepo.On("GetCommentById", suite.ctx, commentID).Return(&existing, nil)
+	suite.commentRepo.On("UpdateComment", suite.ctx, mock.MatchedBy(func(c model.CommentModel) bool {
+		return c.Status == model.StatusApproved
+	})).Return(model.CommentModel{ID: existing.ID, Username: "alice", Comment: param.Comment, PostId: param.PostId, Status: model.StatusApproved}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, param.PostId).Return(&post, nil)
 
-	result, err := suite.service.DeleteComment(suite.ctx, username, commentID)
+	result, err := suite.service.UpdateComment(suite.ctx, username, commentID, param)
 
-	assert.Error(suite.T(), err)
-	assert.Nil(suite.T(), result)
-	assert.Equal(suite.T(), "comment not found", err.Error())
-	suite.userRepo.AssertExpectations(suite.T())
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.StatusApproved, result.Status)
 	suite.commentRepo.AssertExpectations(suite.T())
 }
 
-func (suite *CommentServiceTestSuite) TestDeleteComment_DeleteError() {
+func (suite *CommentServiceTestSuite) TestDeleteComment_Success() {
 	username := "testuser"
 	commentID := "comment-123"
 
@@ -464,30 +746,147 @@ func (suite *CommentServiceTestSuite) TestDeleteComment_DeleteError() {
 
 	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
 	suite.commentRepo.On("GetCommentById", suite.ctx, commentID).Return(&comment, nil)
-	suite.commentRepo.On("DeleteComment", suite.ctx, comment).Return(errors.New("delete error"))
+	suite.commentRepo.On("DeleteComment", suite.ctx, comment).Return(nil)
 
 	result, err := suite.service.DeleteComment(suite.ctx, username, commentID)
 
-	assert.Error(suite.T(), err)
-	assert.Nil(suite.T(), result)
-	assert.Equal(suite.T(), "delete error", err.Error())
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), result)
+	assert.Equal(suite.T(), comment.ID, result.ID)
 	suite.userRepo.AssertExpectations(suite.T())
 	suite.commentRepo.AssertExpectations(suite.T())
 }
 
-func (suite *CommentServiceTestSuite) TestGetAllComment_Success() {
-	username := "testuser"
-	page := 1
-	limit := 10
+func (suite *CommentServiceTestSuite) TestDeleteComment_EditorCanDeleteAnyComment() {
+	username := "editor"
+	commentID := "comment-123"
 
 	user := userModel.AuthUserModel{
-		ID:       strfmt.UUID4("user-123"),
+		ID:       strfmt.UUID4("user-456"),
 		Username: username,
+		Role:     authorization.RoleEditor,
 	}
 
-	comments := []model.CommentModel{
-		{
-			ID:       strfmt.UUID4("comment-1"),
+	comment := model.CommentModel{
+		ID:       strfmt.UUID4(commentID),
+		Username: "owner",
+		PostId:   "post-123",
+	}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.commentRepo.On("GetCommentById", suite.ctx, commentID).Return(&comment, nil)
+	suite.commentRepo.On("DeleteComment", suite.ctx, comment).Return(nil)
+
+	result, err := suite.service.DeleteComment(suite.ctx, username, commentID)
+
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), result)
+	suite.commentRepo.AssertExpectations(suite.T())
+}
+
+func (suite *CommentServiceTestSuite) TestDeleteComment_NotOwnerForbidden() {
+	username := "testuser"
+	commentID := "comment-123"
+
+	user := userModel.AuthUserModel{
+		ID:       strfmt.UUID4("user-123"),
+		Username: username,
+		Role:     authorization.RoleReader,
+	}
+
+	comment := model.CommentModel{
+		ID:       strfmt.UUID4(commentID),
+		Username: "owner",
+		PostId:   "post-123",
+	}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.commentRepo.On("GetCommentById", suite.ctx, commentID).Return(&comment, nil)
+
+	result, err := suite.service.DeleteComment(suite.ctx, username, commentID)
+
+	assert.ErrorIs(suite.T(), err, authorization.ErrForbidden)
+	assert.Nil(suite.T(), result)
+	suite.commentRepo.AssertNotCalled(suite.T(), "DeleteComment", mock.Anything, mock.Anything)
+}
+
+func (suite *CommentServiceTestSuite) TestDeleteComment_UserNotFound() {
+	username := "nonexistent"
+	commentID := "comment-123"
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{}, nil)
+
+	result, err := suite.service.DeleteComment(suite.ctx, username, commentID)
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), "user not found", err.Error())
+	suite.userRepo.AssertExpectations(suite.T())
+}
+
+func (suite *CommentServiceTestSuite) TestDeleteComment_CommentNotFound() {
+	username := "testuser"
+	commentID := "nonexistent"
+
+	user := userModel.AuthUserModel{
+		ID:       strfmt.UUID4("user-123"),
+		Username: username,
+	}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.commentRepo.On("GetCommentById", suite.ctx, commentID).Return(nil, gorm.ErrRecordNotFound)
+
+	result, err := suite.service.DeleteComment(suite.ctx, username, commentID)
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), "comment not found", err.Error())
+	suite.userRepo.AssertExpectations(suite.T())
+	suite.commentRepo.AssertExpectations(suite.T())
+}
+
+func (suite *CommentServiceTestSuite) TestDeleteComment_DeleteError() {
+	username := "testuser"
+	commentID := "comment-123"
+
+	user := userModel.AuthUserModel{
+		ID:       strfmt.UUID4("user-123"),
+		Username: username,
+	}
+
+	comment := model.CommentModel{
+		ID:        strfmt.UUID4(commentID),
+		Username:  username,
+		Comment:   "Test comment",
+		PostId:    "post-123",
+		CreatedBy: username,
+	}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.commentRepo.On("GetCommentById", suite.ctx, commentID).Return(&comment, nil)
+	suite.commentRepo.On("DeleteComment", suite.ctx, comment).Return(errors.New("delete error"))
+
+	result, err := suite.service.DeleteComment(suite.ctx, username, commentID)
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), "delete error", err.Error())
+	suite.userRepo.AssertExpectations(suite.T())
+	suite.commentRepo.AssertExpectations(suite.T())
+}
+
+func (suite *CommentServiceTestSuite) TestGetAllComment_Success() {
+	username := "testuser"
+	page := pagination.Page{Limit: 10}
+
+	user := userModel.AuthUserModel{
+		ID:       strfmt.UUID4("user-123"),
+		Username: username,
+	}
+
+	comments := []model.CommentModel{
+		{
+			ID:       strfmt.UUID4("comment-1"),
 			Username: username,
 			Comment:  "Comment 1",
 			PostId:   "post-1",
@@ -501,9 +900,9 @@ func (suite *CommentServiceTestSuite) TestGetAllComment_Success() {
 	}
 
 	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
-	suite.commentRepo.On("GetAllComment", suite.ctx, page, limit).Return(comments, nil)
+	suite.commentRepo.On("GetAllComment", suite.ctx, mock.Anything, page).Return(comments, pagination.Meta{}, nil)
 
-	result, err := suite.service.GetAllComment(suite.ctx, username, page, limit)
+	result, _, err := suite.service.GetAllComment(suite.ctx, username, page)
 
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), result)
@@ -515,12 +914,11 @@ func (suite *CommentServiceTestSuite) TestGetAllComment_Success() {
 
 func (suite *CommentServiceTestSuite) TestGetAllComment_UserNotFound() {
 	username := "nonexistent"
-	page := 1
-	limit := 10
+	page := pagination.Page{Limit: 10}
 
 	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{}, nil)
 
-	result, err := suite.service.GetAllComment(suite.ctx, username, page, limit)
+	result, _, err := suite.service.GetAllComment(suite.ctx, username, page)
 
 	assert.Error(suite.T(), err)
 	assert.Nil(suite.T(), result)
@@ -530,8 +928,7 @@ func (suite *CommentServiceTestSuite) TestGetAllComment_UserNotFound() {
 
 func (suite *CommentServiceTestSuite) TestGetAllComment_Error() {
 	username := "testuser"
-	page := 1
-	limit := 10
+	page := pagination.Page{Limit: 10}
 
 	user := userModel.AuthUserModel{
 		ID:       strfmt.UUID4("user-123"),
@@ -539,9 +936,9 @@ func (suite *CommentServiceTestSuite) TestGetAllComment_Error() {
 	}
 
 	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
-	suite.commentRepo.On("GetAllComment", suite.ctx, page, limit).Return(nil, errors.New("database error"))
+	suite.commentRepo.On("GetAllComment", suite.ctx, mock.Anything, page).Return(nil, pagination.Meta{}, errors.New("database error"))
 
-	result, err := suite.service.GetAllComment(suite.ctx, username, page, limit)
+	result, _, err := suite.service.GetAllComment(suite.ctx, username, page)
 
 	assert.Error(suite.T(), err)
 	assert.Nil(suite.T(), result)
@@ -565,6 +962,7 @@ func (suite *CommentServiceTestSuite) TestGetCommentById_Success() {
 		Comment:   "Test comment",
 		PostId:    "post-123",
 		CreatedBy: username,
+		Post:      postModel.PostModel{ID: strfmt.UUID4("post-123"), Status: postModel.StatusPublish},
 	}
 
 	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
@@ -613,3 +1011,711 @@ func (suite *CommentServiceTestSuite) TestGetCommentById_CommentNotFound() {
 	suite.userRepo.AssertExpectations(suite.T())
 	suite.commentRepo.AssertExpectations(suite.T())
 }
+
+func (suite *CommentServiceTestSuite) TestAddComment_Reply() {
+	username := "testuser"
+	parentId := "comment-1"
+
+	param := payload.CommentRequest{
+		Comment:  "A reply",
+		PostId:   "post-123",
+		ParentId: &parentId,
+	}
+
+	user := userModel.AuthUserModel{ID: strfmt.UUID4("user-123"), Username: username}
+	post := postModel.PostModel{ID: strfmt.UUID4("post-123"), Title: "Test Post", Status: postModel.StatusPublish}
+	parent := model.CommentModel{ID: strfmt.UUID4(parentId), PostId: "post-123", Depth: 1, Status: model.StatusApproved, Post: post}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.commentRepo.On("GetCommentById", suite.ctx, parentId).Return(&parent, nil)
+	suite.commentRepo.On("InsertComment", suite.ctx, mock.MatchedBy(func(c model.CommentModel) bool {
+		return c.ParentId != nil && *c.ParentId == parentId && c.Depth == 2
+	})).Return(model.CommentModel{ID: strfmt.UUID4("comment-2"), PostId: "post-123", ParentId: &parentId, Depth: 2}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, "post-123").Return(&post, nil)
+
+	result, err := suite.service.AddComment(suite.ctx, username, param)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, result.Depth)
+	suite.commentRepo.AssertExpectations(suite.T())
+}
+
+func (suite *CommentServiceTestSuite) TestAddComment_ReplyTooDeep() {
+	username := "testuser"
+	parentId := "comment-1"
+
+	param := payload.CommentRequest{
+		Comment:  "A reply",
+		PostId:   "post-123",
+		ParentId: &parentId,
+	}
+
+	user := userModel.AuthUserModel{ID: strfmt.UUID4("user-123"), Username: username}
+	post := postModel.PostModel{ID: strfmt.UUID4("post-123"), Status: postModel.StatusPublish}
+	parent := model.CommentModel{ID: strfmt.UUID4(parentId), PostId: "post-123", Depth: 2, Status: model.StatusApproved, Post: post}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, param.PostId).Return(&post, nil)
+	suite.commentRepo.On("GetCommentById", suite.ctx, parentId).Return(&parent, nil)
+
+	result, err := suite.service.AddComment(suite.ctx, username, param)
+
+	var appErr *apperror.Error
+	assert.ErrorAs(suite.T(), err, &appErr)
+	assert.Equal(suite.T(), 400, appErr.Code)
+	assert.Nil(suite.T(), result)
+	suite.commentRepo.AssertNotCalled(suite.T(), "InsertComment", mock.Anything, mock.Anything)
+}
+
+func (suite *CommentServiceTestSuite) TestAddComment_ReplyOtherPost() {
+	username := "testuser"
+	parentId := "comment-1"
+
+	param := payload.CommentRequest{
+		Comment:  "A reply",
+		PostId:   "post-123",
+		ParentId: &parentId,
+	}
+
+	user := userModel.AuthUserModel{ID: strfmt.UUID4("user-123"), Username: username}
+	post := postModel.PostModel{ID: strfmt.UUID4("post-123"), Status: postModel.StatusPublish}
+	parent := model.CommentModel{ID: strfmt.UUID4(parentId), PostId: "post-456", Status: model.StatusApproved, Post: postModel.PostModel{ID: strfmt.UUID4("post-456"), Status: postModel.StatusPublish}}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, param.PostId).Return(&post, nil)
+	suite.commentRepo.On("GetCommentById", suite.ctx, parentId).Return(&parent, nil)
+
+	result, err := suite.service.AddComment(suite.ctx, username, param)
+
+	assert.ErrorIs(suite.T(), err, errParentOtherPost)
+	assert.Nil(suite.T(), result)
+}
+
+func (suite *CommentServiceTestSuite) TestGetCommentTree() {
+	username := "testuser"
+	postId := "post-123"
+	now := time.Now()
+	root, reply, gone := "comment-1", "comment-2", "comment-3"
+
+	user := userModel.AuthUserModel{ID: strfmt.UUID4("user-123"), Username: username}
+	post := postModel.PostModel{ID: strfmt.UUID4(postId), Status: postModel.StatusPublish}
+	deleted := gorm.DeletedAt{Time: now, Valid: true}
+
+	comments := []model.CommentModel{
+		{ID: strfmt.UUID4(root), Username: "alice", Comment: "Root", PostId: postId, Status: model.StatusApproved, DeletedAt: deleted},
+		{ID: strfmt.UUID4(reply), Username: "bob", Comment: "Reply", PostId: postId, Status: model.StatusApproved, ParentId: &root, Depth: 1},
+		{ID: strfmt.UUID4(gone), Username: "carol", Comment: "Gone", PostId: postId, Status: model.StatusApproved, DeletedAt: deleted},
+		{ID: strfmt.UUID4("comment-4"), Username: "dave", Comment: "Second", PostId: postId, Status: model.StatusApproved},
+	}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, postId).Return(&post, nil)
+	suite.commentRepo.On("GetCommentThread", suite.ctx, postId).Return(comments, nil)
+
+	result, err := suite.service.GetCommentTree(suite.ctx, username, postId)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 2)
+	assert.True(suite.T(), result[0].Deleted)
+	assert.Equal(suite.T(), payload.DeletedPlaceholder, result[0].Comment)
+	assert.Empty(suite.T(), result[0].Username)
+	assert.Len(suite.T(), result[0].Replies, 1)
+	assert.Equal(suite.T(), "Reply", result[0].Replies[0].Comment)
+	assert.Equal(suite.T(), "Second", result[1].Comment)
+	assert.Empty(suite.T(), result[1].Replies)
+}
+
+func (suite *CommentServiceTestSuite) TestGetCommentTree_PostNotFound() {
+	username := "testuser"
+
+	user := userModel.AuthUserModel{ID: strfmt.UUID4("user-123"), Username: username}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, "post-404").Return(nil, errors.New("record not found"))
+
+	result, err := suite.service.GetCommentTree(suite.ctx, username, "post-404")
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	suite.commentRepo.AssertNotCalled(suite.T(), "GetCommentThread", mock.Anything, mock.Anything)
+}
+
+func (suite *CommentServiceTestSuite) TestGetPostComments_Success() {
+	username := "testuser"
+	postId := "post-123"
+	page := pagination.Page{Limit: 10, Ascending: true}
+
+	user := userModel.AuthUserModel{ID: strfmt.UUID4("user-123"), Username: username}
+	post := postModel.PostModel{ID: strfmt.UUID4(postId), Status: postModel.StatusPublish}
+	comments := []model.CommentModel{{ID: strfmt.UUID4("comment-1"), PostId: postId, Comment: "First"}}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, postId).Return(&post, nil)
+	visible := payload.CommentFilter{Status: []string{model.StatusApproved}, Username: username}
+	suite.commentRepo.On("GetCommentsByPostId", suite.ctx, postId, visible, page).Return(comments, pagination.Meta{Limit: 10}, nil)
+
+	result, meta, err := suite.service.GetPostComments(suite.ctx, username, postId, page)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 1)
+	assert.Equal(suite.T(), 10, meta.Limit)
+	suite.commentRepo.AssertExpectations(suite.T())
+}
+
+func (suite *CommentServiceTestSuite) TestGetPublicPostComments_Published() {
+	postId := "post-123"
+	page := pagination.Page{Limit: 10}
+
+	post := postModel.PostModel{ID: strfmt.UUID4(postId), Status: postModel.StatusPublish}
+
+	suite.postRepo.On("GetPostById", suite.ctx, postId).Return(&post, nil)
+	approved := payload.CommentFilter{Status: []string{model.StatusApproved}}
+	suite.commentRepo.On("GetCommentsByPostId", suite.ctx, postId, approved, page).Return([]model.CommentModel{}, pagination.Meta{Limit: 10}, nil)
+
+	result, _, err := suite.service.GetPublicPostComments(suite.ctx, postId, page)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 0)
+	suite.commentRepo.AssertExpectations(suite.T())
+}
+
+func (suite *CommentServiceTestSuite) TestGetPublicPostComments_DraftNotFound() {
+	postId := "post-123"
+
+	post := postModel.PostModel{ID: strfmt.UUID4(postId), Status: postModel.StatusDraft}
+
+	suite.postRepo.On("GetPostById", suite.ctx, postId).Return(&post, nil)
+
+	result, _, err := suite.service.GetPublicPostComments(suite.ctx, postId, pagination.Page{Limit: 10})
+
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), "post not found", err.Error())
+	assert.Nil(suite.T(), result)
+	suite.commentRepo.AssertNotCalled(suite.T(), "GetCommentsByPostId", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *CommentServiceTestSuite) TestAddComment_ApprovalModeHoldsComment() {
+	username := "testuser"
+	suite.service.moderation = postModel.CommentModerationApproval
+
+	param := payload.CommentRequest{Comment: "Test comment", PostId: "post-123"}
+	user := userModel.AuthUserModel{ID: strfmt.UUID4("user-123"), Username: username, Role: authorization.RoleReader}
+	post := postModel.PostModel{ID: strfmt.UUID4("post-123"), Status: postModel.StatusPublish}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, param.PostId).Return(&post, nil)
+	suite.commentRepo.On("InsertComment", suite.ctx, mock.MatchedBy(func(c model.CommentModel) bool {
+		return c.Status == model.StatusPending
+	})).Return(model.CommentModel{ID: strfmt.UUID4("comment-1"), PostId: param.PostId, Status: model.StatusPending}, nil)
+
+	result, err := suite.service.AddComment(suite.ctx, username, param)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.StatusPending, result.Status)
+	suite.commentRepo.AssertExpectations(suite.T())
+}
+
+func (suite *CommentServiceTestSuite) TestAddComment_ModeratorSkipsReview() {
+	username := "editor"
+	suite.service.moderation = postModel.CommentModerationApproval
+
+	param := payload.CommentRequest{Comment: "Test comment", PostId: "post-123"}
+	user := userModel.AuthUserModel{ID: strfmt.UUID4("user-123"), Username: username, Role: authorization.RoleEditor}
+	post := postModel.PostModel{ID: strfmt.UUID4("post-123")}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, param.PostId).Return(&post, nil)
+	suite.commentRepo.On("InsertComment", suite.ctx, mock.MatchedBy(func(c model.CommentModel) bool {
+		return c.Status == model.StatusApproved
+	})).Return(model.CommentModel{ID: strfmt.UUID4("comment-1"), PostId: param.PostId, Status: model.StatusApproved}, nil)
+
+	result, err := suite.service.AddComment(suite.ctx, username, param)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.StatusApproved, result.Status)
+}
+
+func (suite *CommentServiceTestSuite) TestAddComment_TrustedMode() {
+	suite.service.trustedAfter = 3
+	mode := postModel.CommentModerationTrusted
+	post := postModel.PostModel{ID: strfmt.UUID4("post-123"), CommentModeration: &mode, Status: postModel.StatusPublish}
+	param := payload.CommentRequest{Comment: "Test comment", PostId: "post-123"}
+
+	for username, expected := range map[string]string{"regular": model.StatusApproved, "newcomer": model.StatusPending} {
+		approved := int64(5)
+		if username == "newcomer" {
+			approved = 1
+		}
+
+		user := userModel.AuthUserModel{Username: username, Role: authorization.RoleReader}
+		suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+		suite.commentRepo.On("CountUserComments", suite.ctx, username, model.StatusApproved).Return(approved, nil)
+		suite.postRepo.On("GetPostById", suite.ctx, param.PostId).Return(&post, nil)
+		suite.commentRepo.On("InsertComment", suite.ctx, mock.MatchedBy(func(c model.CommentModel) bool {
+			return c.Username == username && c.Status == expected
+		})).Return(model.CommentModel{ID: strfmt.UUID4("comment-" + username), Username: username, Status: expected}, nil).Once()
+
+		result, err := suite.service.AddComment(suite.ctx, username, param)
+
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), expected, result.Status, username)
+	}
+	suite.commentRepo.AssertExpectations(suite.T())
+}
+
+func (suite *CommentServiceTestSuite) TestAddComment_ReplyToHiddenComment() {
+	username := "testuser"
+	parentId := "comment-1"
+
+	param := payload.CommentRequest{Comment: "A reply", PostId: "post-123", ParentId: &parentId}
+	user := userModel.AuthUserModel{Username: username, Role: authorization.RoleReader}
+	post := postModel.PostModel{ID: strfmt.UUID4("post-123"), Status: postModel.StatusPublish}
+	parent := model.CommentModel{ID: strfmt.UUID4(parentId), Username: "spammer", PostId: "post-123", Status: model.StatusSpam, Post: post}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, param.PostId).Return(&post, nil)
+	suite.commentRepo.On("GetCommentById", suite.ctx, parentId).Return(&parent, nil)
+
+	result, err := suite.service.AddComment(suite.ctx, username, param)
+
+	assert.EqualError(suite.T(), err, "parent comment not found")
+	assert.Nil(suite.T(), result)
+	suite.commentRepo.AssertNotCalled(suite.T(), "InsertComment", mock.Anything, mock.Anything)
+}
+
+func (suite *CommentServiceTestSuite) TestAddComment_ReplyOnDraftPostOfOtherUser() {
+	username := "testuser"
+	parentId := "comment-1"
+
+	param := payload.CommentRequest{Comment: "A reply", PostId: "post-123", ParentId: &parentId}
+	user := userModel.AuthUserModel{Username: username, Role: authorization.RoleAuthor}
+	post := postModel.PostModel{ID: strfmt.UUID4("post-123"), Username: "alice", Status: postModel.StatusDraft}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, param.PostId).Return(&post, nil)
+
+	result, err := suite.service.AddComment(suite.ctx, username, param)
+
+	assert.EqualError(suite.T(), err, "post not found")
+	assert.Nil(suite.T(), result)
+	suite.commentRepo.AssertNotCalled(suite.T(), "GetCommentById", mock.Anything, mock.Anything)
+}
+
+func (suite *CommentServiceTestSuite) TestAddComment_ReplyToCommentOfHiddenPost() {
+	username := "testuser"
+	parentId := "comment-1"
+
+	param := payload.CommentRequest{Comment: "A reply", PostId: "post-123", ParentId: &parentId}
+	user := userModel.AuthUserModel{Username: username, Role: authorization.RoleAuthor}
+	post := postModel.PostModel{ID: strfmt.UUID4("post-123"), Status: postModel.StatusPublish}
+	draft := postModel.PostModel{ID: strfmt.UUID4("post-456"), Username: "alice", Status: postModel.StatusDraft}
+	parent := model.CommentModel{ID: strfmt.UUID4(parentId), Username: "alice", PostId: "post-456", Status: model.StatusApproved, Post: draft}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, param.PostId).Return(&post, nil)
+	suite.commentRepo.On("GetCommentById", suite.ctx, parentId).Return(&parent, nil)
+
+	result, err := suite.service.AddComment(suite.ctx, username, param)
+
+	assert.EqualError(suite.T(), err, "parent comment not found")
+	assert.Nil(suite.T(), result)
+}
+
+func (suite *CommentServiceTestSuite) TestAddComment_DraftPostOfOtherUser() {
+	username := "testuser"
+	param := payload.CommentRequest{Comment: "Found it", PostId: "post-123"}
+	user := userModel.AuthUserModel{Username: username, Role: authorization.RoleAuthor}
+
+	for _, status := range []string{postModel.StatusDraft, postModel.StatusScheduled} {
+		post := postModel.PostModel{ID: strfmt.UUID4("post-123"), Username: "alice", Status: status}
+
+		suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil).Once()
+		suite.postRepo.On("GetPostById", suite.ctx, param.PostId).Return(&post, nil).Once()
+
+		result, err := suite.service.AddComment(suite.ctx, username, param)
+
+		assert.EqualError(suite.T(), err, "post not found", status)
+		assert.Nil(suite.T(), result)
+	}
+	suite.commentRepo.AssertNotCalled(suite.T(), "InsertComment", mock.Anything, mock.Anything)
+}
+
+func (suite *CommentServiceTestSuite) TestAddComment_OwnDraftPost() {
+	username := "testuser"
+	param := payload.CommentRequest{Comment: "Note to self", PostId: "post-123"}
+	user := userModel.AuthUserModel{Username: username, Role: authorization.RoleAuthor}
+	post := postModel.PostModel{ID: strfmt.UUID4("post-123"), Username: username, Status: postModel.StatusDraft}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, param.PostId).Return(&post, nil)
+	suite.commentRepo.On("InsertComment", suite.ctx, mock.Anything).Return(model.CommentModel{ID: strfmt.UUID4("comment-1"), PostId: param.PostId}, nil)
+
+	result, err := suite.service.AddComment(suite.ctx, username, param)
+
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), result)
+}
+
+func (suite *CommentServiceTestSuite) TestGetAllComment_ModeratorSeesEveryState() {
+	username := "editor"
+	page := pagination.Page{Limit: 10}
+	user := userModel.AuthUserModel{Username: username, Role: authorization.RoleEditor}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.commentRepo.On("GetAllComment", suite.ctx, payload.CommentFilter{}, page).Return([]model.CommentModel{}, pagination.Meta{}, nil)
+
+	_, _, err := suite.service.GetAllComment(suite.ctx, username, page)
+
+	assert.NoError(suite.T(), err)
+	suite.commentRepo.AssertExpectations(suite.T())
+}
+
+func (suite *CommentServiceTestSuite) TestGetCommentById_PendingOfOtherUser() {
+	username := "testuser"
+	user := userModel.AuthUserModel{Username: username, Role: authorization.RoleReader}
+	comment := model.CommentModel{ID: strfmt.UUID4("comment-1"), Username: "someone", Status: model.StatusPending}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.commentRepo.On("GetCommentById", suite.ctx, "comment-1").Return(&comment, nil)
+
+	result, err := suite.service.GetCommentById(suite.ctx, username, "comment-1")
+
+	assert.EqualError(suite.T(), err, "comment not found")
+	assert.Nil(suite.T(), result)
+}
+
+func (suite *CommentServiceTestSuite) TestGetAllComment_KeepsToVisiblePosts() {
+	username := "testuser"
+	page := pagination.Page{Limit: 10}
+	user := userModel.AuthUserModel{Username: username, Role: authorization.RoleReader}
+	filter := payload.CommentFilter{Status: []string{model.StatusApproved}, Username: username, PublishedOnly: true}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.commentRepo.On("GetAllComment", suite.ctx, filter, page).Return([]model.CommentModel{}, pagination.Meta{}, nil)
+
+	_, _, err := suite.service.GetAllComment(suite.ctx, username, page)
+
+	assert.NoError(suite.T(), err)
+	suite.commentRepo.AssertExpectations(suite.T())
+}
+
+func (suite *CommentServiceTestSuite) TestGetCommentById_DraftPostOfOtherUser() {
+	username := "testuser"
+	user := userModel.AuthUserModel{Username: username, Role: authorization.RoleAuthor}
+	comment := model.CommentModel{
+		ID:       strfmt.UUID4("comment-1"),
+		Username: username,
+		Status:   model.StatusApproved,
+		Post:     postModel.PostModel{ID: strfmt.UUID4("post-123"), Username: "alice", Title: "Secret", Status: postModel.StatusDraft},
+	}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.commentRepo.On("GetCommentById", suite.ctx, "comment-1").Return(&comment, nil)
+
+	result, err := suite.service.GetCommentById(suite.ctx, username, "comment-1")
+
+	assert.EqualError(suite.T(), err, "comment not found")
+	assert.Nil(suite.T(), result)
+}
+
+func (suite *CommentServiceTestSuite) TestGetCommentTree_HidesUnapproved() {
+	username := "testuser"
+	postId := "post-123"
+	root := "comment-1"
+
+	user := userModel.AuthUserModel{Username: username, Role: authorization.RoleReader}
+	post := postModel.PostModel{ID: strfmt.UUID4(postId), Status: postModel.StatusPublish}
+	comments := []model.CommentModel{
+		{ID: strfmt.UUID4(root), Username: "alice", Comment: "Rejected", PostId: postId, Status: model.StatusRejected},
+		{ID: strfmt.UUID4("comment-2"), Username: username, Comment: "Mine", PostId: postId, ParentId: &root, Depth: 1, Status: model.StatusPending},
+		{ID: strfmt.UUID4("comment-3"), Username: "bob", Comment: "Pending", PostId: postId, Status: model.StatusPending},
+	}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, postId).Return(&post, nil)
+	suite.commentRepo.On("GetCommentThread", suite.ctx, postId).Return(comments, nil)
+
+	result, err := suite.service.GetCommentTree(suite.ctx, username, postId)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 1)
+	assert.Equal(suite.T(), payload.DeletedPlaceholder, result[0].Comment)
+	assert.Len(suite.T(), result[0].Replies, 1)
+	assert.Equal(suite.T(), "Mine", result[0].Replies[0].Comment)
+}
+
+func (suite *CommentServiceTestSuite) TestGetModerationQueue_Success() {
+	username := "editor"
+	page := pagination.Page{Limit: 10, Ascending: true}
+	user := userModel.AuthUserModel{Username: username, Role: authorization.RoleEditor}
+	pending := payload.CommentFilter{Status: []string{model.StatusPending}}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.commentRepo.On("GetAllComment", suite.ctx, pending, page).Return([]model.CommentModel{{ID: strfmt.UUID4("comment-1")}}, pagination.Meta{}, nil)
+
+	result, _, err := suite.service.GetModerationQueue(suite.ctx, username, "", page)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 1)
+	suite.commentRepo.AssertExpectations(suite.T())
+}
+
+func (suite *CommentServiceTestSuite) TestGetModerationQueue_Forbidden() {
+	username := "testuser"
+	user := userModel.AuthUserModel{Username: username, Role: authorization.RoleAuthor}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+
+	result, _, err := suite.service.GetModerationQueue(suite.ctx, username, "", pagination.Page{Limit: 10})
+
+	assert.ErrorIs(suite.T(), err, authorization.ErrForbidden)
+	assert.Nil(suite.T(), result)
+}
+
+func (suite *CommentServiceTestSuite) TestModerateComments_Approve() {
+	username := "editor"
+	ids := []string{"comment-1", "comment-2"}
+	user := userModel.AuthUserModel{Username: username, Role: authorization.RoleEditor}
+	comments := []model.CommentModel{
+		{ID: strfmt.UUID4("comment-1"), Comment: "First", Status: model.StatusPending},
+		{ID: strfmt.UUID4("comment-2"), Comment: "Second", Status: model.StatusPending},
+	}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.commentRepo.On("GetCommentsByIds", suite.ctx, ids).Return(comments, nil)
+	suite.commentRepo.On("UpdateCommentStatus", suite.ctx, ids, model.StatusApproved, username).Return(nil)
+
+	result, err := suite.service.ModerateComments(suite.ctx, username, ids, model.StatusApproved)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 2)
+	assert.Equal(suite.T(), model.StatusApproved, result[1].Status)
+	suite.commentRepo.AssertExpectations(suite.T())
+}
+
+func (suite *CommentServiceTestSuite) TestModerateComments_NotFound() {
+	username := "editor"
+	ids := []string{"comment-1", "comment-404"}
+	user := userModel.AuthUserModel{Username: username, Role: authorization.RoleAdmin}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.commentRepo.On("GetCommentsByIds", suite.ctx, ids).Return([]model.CommentModel{{ID: strfmt.UUID4("comment-1")}}, nil)
+
+	result, err := suite.service.ModerateComments(suite.ctx, username, ids, model.StatusSpam)
+
+	assert.EqualError(suite.T(), err, "comment comment-404 not found")
+	assert.Nil(suite.T(), result)
+	suite.commentRepo.AssertNotCalled(suite.T(), "UpdateCommentStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *CommentServiceTestSuite) TestAddComment_FilterRejects() {
+	username := "testuser"
+	contentFilter := new(MockContentFilter)
+	suite.service.contentFilter = contentFilter
+
+	param := payload.CommentRequest{Comment: "Cheap pills", PostId: "post-123"}
+	user := userModel.AuthUserModel{Username: username, Role: authorization.RoleReader}
+	post := postModel.PostModel{ID: strfmt.UUID4("post-123"), Status: postModel.StatusPublish}
+	verdict := filterModel.Verdict{Action: filterModel.ActionReject, Filter: "banned_words"}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, param.PostId).Return(&post, nil)
+	contentFilter.On("Check", suite.ctx, filterPayload.Content{Type: filterPayload.TypeComment, Username: username, Text: param.Comment}).Return(verdict, nil)
+	suite.commentRepo.On("InsertComment", suite.ctx, mock.MatchedBy(func(c model.CommentModel) bool {
+		return c.Status == model.StatusRejected
+	})).Return(model.CommentModel{ID: strfmt.UUID4("comment-1"), Username: username, Status: model.StatusRejected}, nil)
+
+	result, err := suite.service.AddComment(suite.ctx, username, param)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.StatusRejected, result.Status)
+	contentFilter.AssertExpectations(suite.T())
+	suite.commentRepo.AssertExpectations(suite.T())
+}
+
+func (suite *CommentServiceTestSuite) TestAddComment_FilterHoldsForReview() {
+	username := "testuser"
+	contentFilter := new(MockContentFilter)
+	suite.service.contentFilter = contentFilter
+
+	param := payload.CommentRequest{Comment: "http://a http://b http://c http://d", PostId: "post-123"}
+	user := userModel.AuthUserModel{Username: username, Role: authorization.RoleReader}
+	post := postModel.PostModel{ID: strfmt.UUID4("post-123"), Status: postModel.StatusPublish}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, param.PostId).Return(&post, nil)
+	contentFilter.On("Check", suite.ctx, mock.Anything).Return(filterModel.Verdict{Action: filterModel.ActionReview}, nil)
+	suite.commentRepo.On("InsertComment", suite.ctx, mock.MatchedBy(func(c model.CommentModel) bool {
+		return c.Status == model.StatusPending
+	})).Return(model.CommentModel{ID: strfmt.UUID4("comment-1"), Status: model.StatusPending}, nil)
+
+	result, err := suite.service.AddComment(suite.ctx, username, param)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.StatusPending, result.Status)
+}
+
+func (suite *CommentServiceTestSuite) TestAddComment_FilterFailsHoldsForReview() {
+	username := "testuser"
+	contentFilter := new(MockContentFilter)
+	suite.service.contentFilter = contentFilter
+
+	param := payload.CommentRequest{Comment: "Nice post", PostId: "post-123"}
+	user := userModel.AuthUserModel{Username: username, Role: authorization.RoleReader}
+	post := postModel.PostModel{ID: strfmt.UUID4("post-123"), Status: postModel.StatusPublish}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, param.PostId).Return(&post, nil)
+	contentFilter.On("Check", suite.ctx, mock.Anything).Return(filterModel.Verdict{}, errors.New("database error"))
+	suite.commentRepo.On("InsertComment", suite.ctx, mock.MatchedBy(func(c model.CommentModel) bool {
+		return c.Status == model.StatusPending
+	})).Return(model.CommentModel{ID: strfmt.UUID4("comment-1"), Status: model.StatusPending}, nil)
+
+	result, err := suite.service.AddComment(suite.ctx, username, param)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.StatusPending, result.Status)
+	suite.commentRepo.AssertExpectations(suite.T())
+}
+
+func (suite *CommentServiceTestSuite) TestModerateComments_SpamTrainsFilter() {
+	username := "editor"
+	contentFilter := new(MockContentFilter)
+	suite.service.contentFilter = contentFilter
+
+	ids := []string{"comment-1", "comment-2"}
+	user := userModel.AuthUserModel{Username: username, Role: authorization.RoleEditor}
+	comments := []model.CommentModel{
+		{ID: strfmt.UUID4("comment-1"), Username: "spammer", Comment: "Cheap pills", Status: model.StatusPending},
+		{ID: strfmt.UUID4("comment-2"), Username: "spammer", Comment: "Old spam", Status: model.StatusSpam},
+	}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.commentRepo.On("GetCommentsByIds", suite.ctx, ids).Return(comments, nil)
+	suite.commentRepo.On("UpdateCommentStatus", suite.ctx, ids, model.StatusSpam, username).Return(nil)
+	contentFilter.On("Learn", suite.ctx, filterPayload.Content{ID: "comment-1", Type: filterPayload.TypeComment, Username: "spammer", Text: "Cheap pills"}, true).Return(nil).Once()
+
+	_, err := suite.service.ModerateComments(suite.ctx, username, ids, model.StatusSpam)
+
+	assert.NoError(suite.T(), err)
+	contentFilter.AssertExpectations(suite.T())
+}
+
+func (suite *CommentServiceTestSuite) TestAddComment_UnverifiedEmail() {
+	username := "testuser"
+	suite.service.requireVerifiedEmail = true
+
+	param := payload.CommentRequest{Comment: "Test comment", PostId: "post-123"}
+	user := userModel.AuthUserModel{ID: strfmt.UUID4("user-123"), Username: username, Email: "test@example.com", Role: authorization.RoleReader}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+
+	result, err := suite.service.AddComment(suite.ctx, username, param)
+
+	assert.ErrorIs(suite.T(), err, authorization.ErrEmailNotVerified)
+	assert.Nil(suite.T(), result)
+	suite.commentRepo.AssertNotCalled(suite.T(), "InsertComment", mock.Anything, mock.Anything)
+}
+
+func (suite *CommentServiceTestSuite) TestUpdateComment_UnverifiedEmail() {
+	username := "testuser"
+	suite.service.requireVerifiedEmail = true
+
+	param := payload.CommentRequest{Comment: "Edited comment", PostId: "post-123"}
+	user := userModel.AuthUserModel{ID: strfmt.UUID4("user-123"), Username: username, Email: "test@example.com", Role: authorization.RoleReader}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+
+	result, err := suite.service.UpdateComment(suite.ctx, username, "comment-123", param)
+
+	assert.ErrorIs(suite.T(), err, authorization.ErrEmailNotVerified)
+	assert.Nil(suite.T(), result)
+	suite.commentRepo.AssertNotCalled(suite.T(), "UpdateComment", mock.Anything, mock.Anything)
+}
+
+func (suite *CommentServiceTestSuite) TestAddComment_VerifiedEmail() {
+	username := "testuser"
+	suite.service.requireVerifiedEmail = true
+	verifiedAt := time.Now()
+
+	param := payload.CommentRequest{Comment: "Test comment", PostId: "post-123"}
+	user := userModel.AuthUserModel{ID: strfmt.UUID4("user-123"), Username: username, Email: "test@example.com", EmailVerifiedAt: &verifiedAt, Role: authorization.RoleReader}
+	post := postModel.PostModel{ID: strfmt.UUID4("post-123"), Status: postModel.StatusPublish}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, param.PostId).Return(&post, nil)
+	suite.commentRepo.On("InsertComment", suite.ctx, mock.Anything).Return(model.CommentModel{ID: strfmt.UUID4("comment-1"), PostId: param.PostId, Status: model.StatusApproved}, nil)
+
+	result, err := suite.service.AddComment(suite.ctx, username, param)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.StatusApproved, result.Status)
+}
+
+func (suite *CommentServiceTestSuite) TestAnonymizeUserContent_ReindexesApproved() {
+	post := postModel.PostModel{ID: strfmt.UUID4("post-1"), Title: "Post"}
+	suite.commentRepo.On("AnonymizeUserComments", suite.ctx, "testuser", "[deleted]").Return([]model.CommentModel{
+		{ID: strfmt.UUID4("comment-1"), Username: "[deleted]", Status: model.StatusApproved},
+		{ID: strfmt.UUID4("comment-2"), Username: "[deleted]", Status: model.StatusPending},
+	}, nil)
+	suite.commentRepo.On("GetCommentsByIds", suite.ctx, []string{"comment-1"}).Return([]model.CommentModel{
+		{ID: strfmt.UUID4("comment-1"), Username: "[deleted]", Comment: "Nice read", Status: model.StatusApproved, PostId: "post-1", Post: post},
+	}, nil)
+
+	count, err := suite.service.AnonymizeUserContent(suite.ctx, "testuser", "[deleted]")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, count)
+	found, _, err := suite.service.searchIndex.Search(suite.ctx, searchPayload.SearchRequest{Query: "nice", Author: "[deleted]"}, pagination.Page{Limit: 10})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), found, 1)
+	suite.commentRepo.AssertExpectations(suite.T())
+}
+
+func (suite *CommentServiceTestSuite) TestDeleteUserContent_Unindexes() {
+	comment := model.CommentModel{ID: strfmt.UUID4("comment-1"), Username: "testuser", Comment: "Nice read", Status: model.StatusApproved, PostId: "post-1", Post: postModel.PostModel{ID: strfmt.UUID4("post-1")}}
+	suite.service.indexComment(suite.ctx, comment)
+	suite.commentRepo.On("DeleteUserComments", suite.ctx, "testuser").Return([]model.CommentModel{comment}, nil)
+
+	count, err := suite.service.DeleteUserContent(suite.ctx, "testuser")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, count)
+	found, _, err := suite.service.searchIndex.Search(suite.ctx, searchPayload.SearchRequest{Query: "nice"}, pagination.Page{Limit: 10})
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), found)
+}
+
+func (suite *CommentServiceTestSuite) TestGetPostComments_DraftOfOtherUser() {
+	username := "testuser"
+	postId := "post-123"
+
+	user := userModel.AuthUserModel{Username: username, Role: authorization.RoleAuthor}
+	post := postModel.PostModel{ID: strfmt.UUID4(postId), Username: "otheruser", Status: postModel.StatusDraft}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, postId).Return(&post, nil)
+
+	result, _, err := suite.service.GetPostComments(suite.ctx, username, postId, pagination.Page{Limit: 10})
+
+	assert.EqualError(suite.T(), err, "post not found")
+	assert.Nil(suite.T(), result)
+	suite.commentRepo.AssertNotCalled(suite.T(), "GetCommentsByPostId", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *CommentServiceTestSuite) TestGetCommentTree_OwnDraft() {
+	username := "testuser"
+	postId := "post-123"
+
+	user := userModel.AuthUserModel{Username: username, Role: authorization.RoleAuthor}
+	post := postModel.PostModel{ID: strfmt.UUID4(postId), Username: username, Status: postModel.StatusDraft}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, postId).Return(&post, nil)
+	suite.commentRepo.On("GetCommentThread", suite.ctx, postId).Return([]model.CommentModel{}, nil)
+
+	result, err := suite.service.GetCommentTree(suite.ctx, username, postId)
+
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), result)
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"time"
+
+	"simple-blog-system/internal/app/feed/payload"
+	"simple-blog-system/internal/app/feed/port"
+	"simple-blog-system/pkg/helper"
+	"simple-blog-system/pkg/validations"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxAge is how long readers and proxies may keep a feed before asking again with its ETag
+const maxAge = 5 * time.Minute
+
+type handler struct {
+	feedService port.IFeedService
+}
+
+func New(feedService port.IFeedService) port.IFeedHandler {
+	return &handler{
+		feedService: feedService,
+	}
+}
+
+// @Summary RSS Feed
+// @Description RSS 2.0 feed of the newest published posts, /author/{username}/feed.xml and /tag/{tag}/feed.xml narrow it to an author or a tag. Answers 304 for a matching If-None-Match or If-Modified-Since
+// @Tags feed
+// @Produce xml
+// @Param limit query int false "Number of posts, FEED_ITEMS by default and at most FEED_MAX_ITEMS"
+// @Success 200 {string} string
+// @Success 304 {string} string
+// @Failure 400 {object} helper.Response
+// @Router /feed.xml [get]
+func (h *handler) RSS(c *gin.Context) {
+	h.render(c, payload.FormatRSS)
+}
+
+// @Summary Atom Feed
+// @Description Atom 1.0 feed of the newest published posts, /author/{username}/atom.xml and /tag/{tag}/atom.xml narrow it to an author or a tag. Answers 304 for a matching If-None-Match or If-Modified-Since
+// @Tags feed
+// @Produce xml
+// @Param limit query int false "Number of posts, FEED_ITEMS by default and at most FEED_MAX_ITEMS"
+// @Success 200 {string} string
+// @Success 304 {string} string
+// @Failure 400 {object} helper.Response
+// @Router /atom.xml [get]
+func (h *handler) Atom(c *gin.Context) {
+	h.render(c, payload.FormatAtom)
+}
+
+// @Summary JSON Feed
+// @Description JSON Feed 1.1 of the newest published posts, /author/{username}/feed.json and /tag/{tag}/feed.json narrow it to an author or a tag. Answers 304 for a matching If-None-Match or If-Modified-Since
+// @Tags feed
+// @Produce json
+// @Param limit query int false "Number of posts, FEED_ITEMS by default and at most FEED_MAX_ITEMS"
+// @Success 200 {string} string
+// @Success 304 {string} string
+// @Failure 400 {object} helper.Response
+// @Router /feed.json [get]
+func (h *handler) JSONFeed(c *gin.Context) {
+	h.render(c, payload.FormatJSON)
+}
+
+func (h *handler) render(c *gin.Context, format string) {
+	var request payload.FeedRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	err := validations.New().Struct(request)
+	if err != nil {
+		helper.ResponseError(c, validations.BadRequest(err))
+		return
+	}
+
+	request.Format = format
+	request.Author = c.Param("username")
+	request.Tag = c.Param("tag")
+
+	res, err := h.feedService.Render(c.Request.Context(), request)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseConditional(c, res.ContentType, res.Body, res.LastModified, maxAge)
+}
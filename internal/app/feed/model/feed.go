@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+// DocumentModel is a rendered feed, LastModified is the newest change of its posts and zero when it has none
+type DocumentModel struct {
+	ContentType  string
+	Body         []byte
+	LastModified time.Time
+}
@@ -0,0 +1,17 @@
+package payload
+
+// Formats a feed can be rendered in
+const (
+	FormatRSS  = "rss"
+	FormatAtom = "atom"
+	FormatJSON = "json"
+)
+
+type FeedRequest struct {
+	Format string `form:"-"`
+	// Author and Tag narrow the feed to the posts of a username or a tag slug, they come from the path
+	Author string `form:"-"`
+	Tag    string `form:"-"`
+	// Limit is the number of posts, FEED_ITEMS by default and at most FEED_MAX_ITEMS
+	Limit int `form:"limit" validate:"omitempty,min=1"`
+}
@@ -0,0 +1,17 @@
+package port
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type IFeedHandler interface {
+
+	// (GET /feed.xml)
+	RSS(ctx *gin.Context)
+
+	// (GET /atom.xml)
+	Atom(ctx *gin.Context)
+
+	// (GET /feed.json)
+	JSONFeed(ctx *gin.Context)
+}
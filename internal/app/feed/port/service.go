@@ -0,0 +1,13 @@
+package port
+
+import (
+	"context"
+
+	"simple-blog-system/internal/app/feed/model"
+	"simple-blog-system/internal/app/feed/payload"
+)
+
+type IFeedService interface {
+	// Render builds the feed of the newest published posts in the requested format
+	Render(ctx context.Context, param payload.FeedRequest) (res *model.DocumentModel, err error)
+}
@@ -0,0 +1,24 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"simple-blog-system/internal/app/feed/port"
+)
+
+type (
+	routes struct{}
+)
+
+var (
+	Routes routes
+)
+
+// New registers the feeds of every published post, of an author and of a tag
+func (r routes) New(router *gin.RouterGroup, handler port.IFeedHandler) {
+	for _, prefix := range []string{"", "/author/:username", "/tag/:tag"} {
+		router.GET(prefix+"/feed.xml", handler.RSS)
+		router.GET(prefix+"/atom.xml", handler.Atom)
+		router.GET(prefix+"/feed.json", handler.JSONFeed)
+	}
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+
+	"simple-blog-system/internal/app/feed/model"
+	"simple-blog-system/internal/app/feed/payload"
+	"simple-blog-system/internal/app/feed/port"
+	postModel "simple-blog-system/internal/app/post/model"
+	postPayload "simple-blog-system/internal/app/post/payload"
+	postPort "simple-blog-system/internal/app/post/port"
+	taxonomyPort "simple-blog-system/internal/app/taxonomy/port"
+	"simple-blog-system/pkg/feed"
+	"simple-blog-system/pkg/helper"
+	"simple-blog-system/pkg/pagination"
+)
+
+type Options struct {
+	// SiteTitle and SiteDescription describe the blog in every feed
+	SiteTitle       string
+	SiteDescription string
+	// BaseURL is where the blog is served, posts link to BaseURL/post/{slug}
+	BaseURL string
+	// Items is the number of posts in a feed without ?limit=, MaxItems the most a feed can be asked for
+	Items    int
+	MaxItems int
+}
+
+type service struct {
+	postRepo     postPort.IPostRepository
+	taxonomyRepo taxonomyPort.ITaxonomyRepository
+	options      Options
+}
+
+func New(postRepo postPort.IPostRepository, taxonomyRepo taxonomyPort.ITaxonomyRepository, options Options) port.IFeedService {
+	options.BaseURL = strings.TrimSuffix(options.BaseURL, "/")
+
+	return &service{
+		postRepo:     postRepo,
+		taxonomyRepo: taxonomyRepo,
+		options:      options,
+	}
+}
+
+// Render lists the newest published posts, of one author or tag when asked for, and renders them in param.Format
+func (s *service) Render(ctx context.Context, param payload.FeedRequest) (res *model.DocumentModel, err error) {
+	limit := s.options.Items
+	if param.Limit > 0 {
+		limit = min(param.Limit, s.options.MaxItems)
+	}
+
+	filter := postPayload.PostFilter{
+		Author:        param.Author,
+		Tag:           helper.Slugify(param.Tag),
+		PublishedOnly: true,
+	}
+	posts, _, err := s.postRepo.GetAllPost(ctx, filter, pagination.Page{Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+
+	channel, err := s.channel(ctx, param, filter.Tag, posts)
+	if err != nil {
+		return nil, err
+	}
+
+	res = &model.DocumentModel{LastModified: channel.Updated}
+	switch param.Format {
+	case payload.FormatRSS:
+		res.ContentType = feed.ContentTypeRSS
+		res.Body, err = feed.RSS(channel)
+	case payload.FormatAtom:
+		res.ContentType = feed.ContentTypeAtom
+		res.Body, err = feed.Atom(channel)
+	case payload.FormatJSON:
+		res.ContentType = feed.ContentTypeJSON
+		res.Body, err = feed.JSON(channel)
+	default:
+		return nil, errors.New("unknown feed format " + param.Format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// channel turns posts into the feed, the tags of the posts become the categories of the items
+func (s *service) channel(ctx context.Context, param payload.FeedRequest, tag string, posts []postModel.PostModel) (feed.Feed, error) {
+	channel := feed.Feed{
+		Title:       s.options.SiteTitle,
+		Description: s.options.SiteDescription,
+		Link:        s.options.BaseURL + "/",
+		FeedURL:     s.options.BaseURL + feedPath(param.Author, tag, param.Format),
+		Items:       []feed.Item{},
+	}
+	if param.Author != "" {
+		channel.Title += " - " + param.Author
+	}
+	if tag != "" {
+		channel.Title += " - #" + tag
+	}
+
+	if len(posts) == 0 {
+		return channel, nil
+	}
+
+	postIds := make([]string, 0, len(posts))
+	for _, post := range posts {
+		postIds = append(postIds, string(post.ID))
+	}
+
+	postTags, err := s.taxonomyRepo.GetPostTags(ctx, postIds)
+	if err != nil {
+		return channel, err
+	}
+
+	tags := map[string][]string{}
+	for _, postTag := range postTags {
+		tags[postTag.PostId] = append(tags[postTag.PostId], postTag.Tag.Name)
+	}
+
+	for _, post := range posts {
+		published := post.CreatedAt
+		if post.PublishAt != nil {
+			published = *post.PublishAt
+		}
+
+		channel.Items = append(channel.Items, feed.Item{
+			ID:         "urn:uuid:" + string(post.ID),
+			Title:      post.Title,
+			Link:       s.options.BaseURL + "/post/" + url.PathEscape(post.Slug),
+			Content:    post.Body,
+			Author:     post.Username,
+			Categories: tags[string(post.ID)],
+			Published:  published,
+			Updated:    post.UpdatedAt,
+		})
+
+		if post.UpdatedAt.After(channel.Updated) {
+			channel.Updated = post.UpdatedAt
+		}
+	}
+
+	return channel, nil
+}
+
+// feedPath is the path the feed is served from, the feed URL of the document
+func feedPath(author string, tag string, format string) string {
+	prefix := ""
+	if author != "" {
+		prefix = "/author/" + url.PathEscape(author)
+	} else if tag != "" {
+		prefix = "/tag/" + url.PathEscape(tag)
+	}
+
+	switch format {
+	case payload.FormatAtom:
+		return prefix + "/atom.xml"
+	case payload.FormatJSON:
+		return prefix + "/feed.json"
+	}
+
+	return prefix + "/feed.xml"
+}
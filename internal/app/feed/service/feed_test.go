@@ -0,0 +1,417 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"simple-blog-system/internal/app/feed/payload"
+	postModel "simple-blog-system/internal/app/post/model"
+	postPayload "simple-blog-system/internal/app/post/payload"
+	taxonomyModel "simple-blog-system/internal/app/taxonomy/model"
+	"simple-blog-system/pkg/pagination"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// Mock for IPostRepository
+type MockPostRepository struct {
+	mock.Mock
+}
+
+func (m *MockPostRepository) InsertPost(ctx context.Context, post postModel.PostModel) (postModel.PostModel, error) {
+	args := m.Called(ctx, post)
+	return args.Get(0).(postModel.PostModel), args.Error(1)
+}
+
+func (m *MockPostRepository) UpdatePost(ctx context.Context, post postModel.PostModel) (postModel.PostModel, error) {
+	args := m.Called(ctx, post)
+	return args.Get(0).(postModel.PostModel), args.Error(1)
+}
+
+func (m *MockPostRepository) GetPostById(ctx context.Context, id string) (*postModel.PostModel, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*postModel.PostModel), args.Error(1)
+}
+
+func (m *MockPostRepository) DeletePost(ctx context.Context, post postModel.PostModel) error {
+	args := m.Called(ctx, post)
+	return args.Error(0)
+}
+
+func (m *MockPostRepository) GetAllPost(ctx context.Context, filter postPayload.PostFilter, page pagination.Page) ([]postModel.PostModel, pagination.Meta, error) {
+	args := m.Called(ctx, filter, page)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(pagination.Meta), args.Error(2)
+	}
+	return args.Get(0).([]postModel.PostModel), args.Get(1).(pagination.Meta), args.Error(2)
+}
+
+func (m *MockPostRepository) GetPublishedSlugs(ctx context.Context) ([]postModel.PostModel, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]postModel.PostModel), args.Error(1)
+}
+
+func (m *MockPostRepository) GetDueScheduledPosts(ctx context.Context, now time.Time, limit int) ([]postModel.PostModel, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]postModel.PostModel), args.Error(1)
+}
+
+func (m *MockPostRepository) InsertRevision(ctx context.Context, revision postModel.PostRevisionModel) (postModel.PostRevisionModel, error) {
+	args := m.Called(ctx, revision)
+	return args.Get(0).(postModel.PostRevisionModel), args.Error(1)
+}
+
+func (m *MockPostRepository) GetRevisions(ctx context.Context, postId string, page pagination.Page) ([]postModel.PostRevisionModel, pagination.Meta, error) {
+	args := m.Called(ctx, postId, page)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(pagination.Meta), args.Error(2)
+	}
+	return args.Get(0).([]postModel.PostRevisionModel), args.Get(1).(pagination.Meta), args.Error(2)
+}
+
+func (m *MockPostRepository) GetRevision(ctx context.Context, postId string, revision int) (*postModel.PostRevisionModel, error) {
+	args := m.Called(ctx, postId, revision)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*postModel.PostRevisionModel), args.Error(1)
+}
+
+func (m *MockPostRepository) CountComments(ctx context.Context, postIds []string) (map[string]int64, error) {
+	args := m.Called(ctx, postIds)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+
+func (m *MockPostRepository) GetPostBySlug(ctx context.Context, slug string) (*postModel.PostModel, error) {
+	args := m.Called(ctx, slug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*postModel.PostModel), args.Error(1)
+}
+
+func (m *MockPostRepository) IsSlugTaken(ctx context.Context, slug string, excludePostId string) (bool, error) {
+	args := m.Called(ctx, slug, excludePostId)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPostRepository) GetSlugHistory(ctx context.Context, slug string) (*postModel.PostSlugHistoryModel, error) {
+	args := m.Called(ctx, slug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*postModel.PostSlugHistoryModel), args.Error(1)
+}
+
+func (m *MockPostRepository) InsertSlugHistory(ctx context.Context, history postModel.PostSlugHistoryModel) (postModel.PostSlugHistoryModel, error) {
+	args := m.Called(ctx, history)
+	return args.Get(0).(postModel.PostSlugHistoryModel), args.Error(1)
+}
+
+func (m *MockPostRepository) DeleteSlugHistory(ctx context.Context, postId string, slug string) error {
+	args := m.Called(ctx, postId, slug)
+	return args.Error(0)
+}
+
+func (m *MockPostRepository) AnonymizeUserPosts(ctx context.Context, username string, placeholder string) ([]postModel.PostModel, error) {
+	args := m.Called(ctx, username, placeholder)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]postModel.PostModel), args.Error(1)
+}
+
+func (m *MockPostRepository) DeleteUserPosts(ctx context.Context, username string) ([]postModel.PostModel, error) {
+	args := m.Called(ctx, username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]postModel.PostModel), args.Error(1)
+}
+
+// Mock for ITaxonomyRepository
+type MockTaxonomyRepository struct {
+	mock.Mock
+}
+
+func (m *MockTaxonomyRepository) GetAllTag(ctx context.Context) ([]taxonomyModel.TagModel, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]taxonomyModel.TagModel), args.Error(1)
+}
+
+func (m *MockTaxonomyRepository) FindOrCreateTags(ctx context.Context, tags []taxonomyModel.TagModel) ([]taxonomyModel.TagModel, error) {
+	args := m.Called(ctx, tags)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]taxonomyModel.TagModel), args.Error(1)
+}
+
+func (m *MockTaxonomyRepository) GetPostTags(ctx context.Context, postIds []string) ([]taxonomyModel.PostTagModel, error) {
+	args := m.Called(ctx, postIds)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]taxonomyModel.PostTagModel), args.Error(1)
+}
+
+func (m *MockTaxonomyRepository) ReplacePostTags(ctx context.Context, postId string, tagIds []string) error {
+	args := m.Called(ctx, postId, tagIds)
+	return args.Error(0)
+}
+
+func (m *MockTaxonomyRepository) RefreshTagCounts(ctx context.Context, tagIds []string) error {
+	args := m.Called(ctx, tagIds)
+	return args.Error(0)
+}
+
+func (m *MockTaxonomyRepository) GetAllCategory(ctx context.Context) ([]taxonomyModel.CategoryModel, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]taxonomyModel.CategoryModel), args.Error(1)
+}
+
+func (m *MockTaxonomyRepository) GetCategoryById(ctx context.Context, id string) (*taxonomyModel.CategoryModel, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*taxonomyModel.CategoryModel), args.Error(1)
+}
+
+func (m *MockTaxonomyRepository) GetCategoriesByIds(ctx context.Context, ids []string) ([]taxonomyModel.CategoryModel, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]taxonomyModel.CategoryModel), args.Error(1)
+}
+
+func (m *MockTaxonomyRepository) InsertCategory(ctx context.Context, category taxonomyModel.CategoryModel) (taxonomyModel.CategoryModel, error) {
+	args := m.Called(ctx, category)
+	return args.Get(0).(taxonomyModel.CategoryModel), args.Error(1)
+}
+
+func (m *MockTaxonomyRepository) CountPostsByCategory(ctx context.Context) (map[string]int64, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+
+type FeedServiceTestSuite struct {
+	suite.Suite
+	postRepo     *MockPostRepository
+	taxonomyRepo *MockTaxonomyRepository
+	service      *service
+	ctx          context.Context
+}
+
+func (suite *FeedServiceTestSuite) SetupTest() {
+	suite.postRepo = new(MockPostRepository)
+	suite.taxonomyRepo = new(MockTaxonomyRepository)
+	suite.service = New(suite.postRepo, suite.taxonomyRepo, Options{
+		SiteTitle:       "Simple Blog",
+		SiteDescription: "Posts about Go",
+		BaseURL:         "https://blog.example.com/",
+		Items:           20,
+		MaxItems:        50,
+	}).(*service)
+	suite.ctx = context.Background()
+}
+
+func TestFeedServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(FeedServiceTestSuite))
+}
+
+func (suite *FeedServiceTestSuite) posts() []postModel.PostModel {
+	scheduled := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	return []postModel.PostModel{
+		{
+			ID:        strfmt.UUID4("post-2"),
+			Username:  "alice",
+			Title:     "Generics <in> Go",
+			Slug:      "generics-in-go",
+			Body:      "Type parameters & constraints",
+			Status:    postModel.StatusPublish,
+			PublishAt: &scheduled,
+			CreatedAt: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+			UpdatedAt: time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			ID:        strfmt.UUID4("post-1"),
+			Username:  "bob",
+			Title:     "Hello",
+			Slug:      "hello",
+			Body:      "First post",
+			Status:    postModel.StatusPublish,
+			CreatedAt: time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC),
+			UpdatedAt: time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC),
+		},
+	}
+}
+
+func (suite *FeedServiceTestSuite) expectPosts(filter postPayload.PostFilter, limit int) {
+	suite.postRepo.On("GetAllPost", suite.ctx, filter, pagination.Page{Limit: limit}).Return(suite.posts(), pagination.Meta{}, nil)
+	suite.taxonomyRepo.On("GetPostTags", suite.ctx, []string{"post-2", "post-1"}).Return([]taxonomyModel.PostTagModel{
+		{PostId: "post-2", TagId: "tag-1", Tag: taxonomyModel.TagModel{Name: "Go", Slug: "go"}},
+		{PostId: "post-2", TagId: "tag-2", Tag: taxonomyModel.TagModel{Name: "Generics", Slug: "generics"}},
+	}, nil)
+}
+
+func (suite *FeedServiceTestSuite) TestRender_RSS() {
+	suite.expectPosts(postPayload.PostFilter{PublishedOnly: true}, 20)
+
+	res, err := suite.service.Render(suite.ctx, payload.FeedRequest{Format: payload.FormatRSS})
+
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "application/rss+xml; charset=utf-8", res.ContentType)
+	assert.Equal(suite.T(), time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC), res.LastModified)
+
+	var doc struct {
+		Channel struct {
+			Title         string `xml:"title"`
+			LastBuildDate string `xml:"lastBuildDate"`
+			Items         []struct {
+				Title      string   `xml:"title"`
+				Link       string   `xml:"link"`
+				GUID       string   `xml:"guid"`
+				Creator    string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+				Categories []string `xml:"category"`
+				PubDate    string   `xml:"pubDate"`
+				Body       string   `xml:"description"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	suite.Require().NoError(xml.Unmarshal(res.Body, &doc))
+	assert.Equal(suite.T(), "Simple Blog", doc.Channel.Title)
+	assert.Contains(suite.T(), string(res.Body), "<link>https://blog.example.com/</link>")
+	assert.Equal(suite.T(), "Wed, 04 Mar 2026 09:00:00 +0000", doc.Channel.LastBuildDate)
+	suite.Require().Len(doc.Channel.Items, 2)
+	assert.Equal(suite.T(), "Generics <in> Go", doc.Channel.Items[0].Title)
+	assert.Equal(suite.T(), "https://blog.example.com/post/generics-in-go", doc.Channel.Items[0].Link)
+	assert.Equal(suite.T(), "urn:uuid:post-2", doc.Channel.Items[0].GUID)
+	assert.Equal(suite.T(), "alice", doc.Channel.Items[0].Creator)
+	assert.Equal(suite.T(), []string{"Go", "Generics"}, doc.Channel.Items[0].Categories)
+	// scheduled posts are dated by when they went out, not when they were written
+	assert.Equal(suite.T(), "Mon, 02 Mar 2026 09:00:00 +0000", doc.Channel.Items[0].PubDate)
+	assert.Equal(suite.T(), "Type parameters & constraints", doc.Channel.Items[0].Body)
+	assert.Empty(suite.T(), doc.Channel.Items[1].Categories)
+	assert.Contains(suite.T(), string(res.Body), `<atom:link href="https://blog.example.com/feed.xml" rel="self" type="application/rss+xml">`)
+}
+
+func (suite *FeedServiceTestSuite) TestRender_Atom() {
+	suite.expectPosts(postPayload.PostFilter{PublishedOnly: true}, 20)
+
+	res, err := suite.service.Render(suite.ctx, payload.FeedRequest{Format: payload.FormatAtom})
+
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "application/atom+xml; charset=utf-8", res.ContentType)
+
+	var doc struct {
+		XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+		ID      string   `xml:"id"`
+		Updated string   `xml:"updated"`
+		Entries []struct {
+			ID        string `xml:"id"`
+			Published string `xml:"published"`
+			Updated   string `xml:"updated"`
+			Author    string `xml:"author>name"`
+			Link      struct {
+				Href string `xml:"href,attr"`
+			} `xml:"link"`
+		} `xml:"entry"`
+	}
+	suite.Require().NoError(xml.Unmarshal(res.Body, &doc))
+	assert.Equal(suite.T(), "https://blog.example.com/atom.xml", doc.ID)
+	assert.Equal(suite.T(), "2026-03-04T09:00:00Z", doc.Updated)
+	suite.Require().Len(doc.Entries, 2)
+	assert.Equal(suite.T(), "urn:uuid:post-1", doc.Entries[1].ID)
+	assert.Equal(suite.T(), "2026-02-01T09:00:00Z", doc.Entries[1].Published)
+	assert.Equal(suite.T(), "bob", doc.Entries[1].Author)
+	assert.Equal(suite.T(), "https://blog.example.com/post/hello", doc.Entries[1].Link.Href)
+}
+
+func (suite *FeedServiceTestSuite) TestRender_JSONFeedOfAuthor() {
+	suite.expectPosts(postPayload.PostFilter{Author: "alice", PublishedOnly: true}, 20)
+
+	res, err := suite.service.Render(suite.ctx, payload.FeedRequest{Format: payload.FormatJSON, Author: "alice"})
+
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "application/feed+json; charset=utf-8", res.ContentType)
+
+	var doc map[string]interface{}
+	suite.Require().NoError(json.Unmarshal(res.Body, &doc))
+	assert.Equal(suite.T(), "https://jsonfeed.org/version/1.1", doc["version"])
+	assert.Equal(suite.T(), "Simple Blog - alice", doc["title"])
+	assert.Equal(suite.T(), "https://blog.example.com/author/alice/feed.json", doc["feed_url"])
+	items := doc["items"].([]interface{})
+	suite.Require().Len(items, 2)
+	first := items[0].(map[string]interface{})
+	assert.Equal(suite.T(), "Type parameters & constraints", first["content_text"])
+	assert.Equal(suite.T(), "2026-03-02T09:00:00Z", first["date_published"])
+	assert.Equal(suite.T(), []interface{}{map[string]interface{}{"name": "alice"}}, first["authors"])
+	assert.Equal(suite.T(), []interface{}{"Go", "Generics"}, first["tags"])
+}
+
+func (suite *FeedServiceTestSuite) TestRender_TagFeedCapsLimit() {
+	suite.postRepo.On("GetAllPost", suite.ctx, postPayload.PostFilter{Tag: "web-dev", PublishedOnly: true}, pagination.Page{Limit: 50}).Return([]postModel.PostModel{}, pagination.Meta{}, nil)
+
+	res, err := suite.service.Render(suite.ctx, payload.FeedRequest{Format: payload.FormatAtom, Tag: "Web Dev", Limit: 500})
+
+	suite.Require().NoError(err)
+	assert.True(suite.T(), res.LastModified.IsZero())
+	assert.Contains(suite.T(), string(res.Body), "<title>Simple Blog - #web-dev</title>")
+	assert.Contains(suite.T(), string(res.Body), `<link href="https://blog.example.com/tag/web-dev/atom.xml" rel="self" type="application/atom+xml">`)
+	assert.NotContains(suite.T(), string(res.Body), "<entry>")
+	suite.taxonomyRepo.AssertNotCalled(suite.T(), "GetPostTags", mock.Anything, mock.Anything)
+}
+
+func (suite *FeedServiceTestSuite) TestRender_SameBodyForSamePosts() {
+	suite.expectPosts(postPayload.PostFilter{PublishedOnly: true}, 5)
+
+	first, err := suite.service.Render(suite.ctx, payload.FeedRequest{Format: payload.FormatRSS, Limit: 5})
+	suite.Require().NoError(err)
+	second, err := suite.service.Render(suite.ctx, payload.FeedRequest{Format: payload.FormatRSS, Limit: 5})
+	suite.Require().NoError(err)
+
+	// the ETag is a hash of the body, so nothing in it may change between two requests
+	assert.Equal(suite.T(), first.Body, second.Body)
+	assert.True(suite.T(), strings.HasPrefix(string(first.Body), `<?xml version="1.0" encoding="UTF-8"?>`))
+}
+
+func (suite *FeedServiceTestSuite) TestRender_RepositoryError() {
+	suite.postRepo.On("GetAllPost", suite.ctx, postPayload.PostFilter{PublishedOnly: true}, pagination.Page{Limit: 20}).Return(nil, pagination.Meta{}, errors.New("database error"))
+
+	res, err := suite.service.Render(suite.ctx, payload.FeedRequest{Format: payload.FormatRSS})
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "database error")
+}
@@ -0,0 +1,52 @@
+package model
+
+// Action a content filter asks for, ordered from the mildest to the strictest
+const (
+	ActionAllow  = "allow"
+	ActionReview = "review"
+	ActionReject = "reject"
+)
+
+var actionRank = map[string]int{
+	ActionAllow:  0,
+	ActionReview: 1,
+	ActionReject: 2,
+}
+
+// Verdict is the outcome of a content filter, Filter and Reason explain anything but allow
+type Verdict struct {
+	Action string `json:"action"`
+	Filter string `json:"filter,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+func Allow() Verdict {
+	return Verdict{Action: ActionAllow}
+}
+
+// StricterThan tells whether v asks for a stricter action than other
+func (v Verdict) StricterThan(other Verdict) bool {
+	return actionRank[v.Action] > actionRank[other.Action]
+}
+
+// SpamTokenModel counts the spam and ham documents a token was learned from
+type SpamTokenModel struct {
+	Token string `json:"token" gorm:"primaryKey"`
+	Spam  int64  `json:"spam"`
+	Ham   int64  `json:"ham"`
+}
+
+func (u SpamTokenModel) TableName() string {
+	return "spam_tokens"
+}
+
+// SpamDocumentsModel counts the spam and ham documents learned so far, the table has a single row
+type SpamDocumentsModel struct {
+	ID   int   `json:"id"`
+	Spam int64 `json:"spam"`
+	Ham  int64 `json:"ham"`
+}
+
+func (u SpamDocumentsModel) TableName() string {
+	return "spam_documents"
+}
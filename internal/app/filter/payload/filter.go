@@ -0,0 +1,26 @@
+package payload
+
+const (
+	TypePost    = "post"
+	TypeComment = "comment"
+)
+
+// Content is the text a filter inspects
+type Content struct {
+	// ID is set when existing content is edited so it is not counted as its own duplicate
+	ID       string
+	Type     string
+	Username string
+	// Title of a post, comments have none
+	Title string
+	Text  string
+}
+
+// FullText is the title and the text, duplicates are only looked for in the text
+func (c Content) FullText() string {
+	if c.Title == "" {
+		return c.Text
+	}
+
+	return c.Title + "\n" + c.Text
+}
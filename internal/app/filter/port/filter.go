@@ -0,0 +1,24 @@
+package port
+
+import (
+	"context"
+
+	"simple-blog-system/internal/app/filter/model"
+	"simple-blog-system/internal/app/filter/payload"
+)
+
+// IContentFilter inspects content before it is saved
+type IContentFilter interface {
+	Check(ctx context.Context, content payload.Content) (res model.Verdict, err error)
+}
+
+// ILearner is a content filter that learns from moderator decisions
+type ILearner interface {
+	Learn(ctx context.Context, content payload.Content, spam bool) (err error)
+}
+
+// ILearningFilter is a content filter that can be trained, the chain of every filter is one as well
+type ILearningFilter interface {
+	IContentFilter
+	ILearner
+}
@@ -0,0 +1,16 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"simple-blog-system/internal/app/filter/model"
+	"simple-blog-system/internal/app/filter/payload"
+)
+
+type IFilterRepository interface {
+	CountDuplicates(ctx context.Context, content payload.Content, since time.Time) (total int64, err error)
+	GetSpamDocuments(ctx context.Context) (res model.SpamDocumentsModel, err error)
+	GetSpamTokens(ctx context.Context, tokens []string) (res []model.SpamTokenModel, err error)
+	LearnSpamTokens(ctx context.Context, tokens []string, spam bool) (err error)
+}
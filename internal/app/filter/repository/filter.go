@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"simple-blog-system/config/db"
+	"simple-blog-system/pkg/transaction"
+
+	"simple-blog-system/internal/app/filter/model"
+	"simple-blog-system/internal/app/filter/payload"
+	"simple-blog-system/internal/app/filter/port"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// spamDocumentsId is the only row of spam_documents
+const spamDocumentsId = 1
+
+type repository struct {
+	db *db.GormDB
+}
+
+func NewRepository(db *db.GormDB) port.IFilterRepository {
+	return repository{db: db}
+}
+
+// CountDuplicates counts the posts or comments of the same user with the same text created since
+func (r repository) CountDuplicates(ctx context.Context, content payload.Content, since time.Time) (total int64, err error) {
+	table, column := "comments", "comment"
+	if content.Type == payload.TypePost {
+		table, column = "posts", "body"
+	}
+
+	trx := transaction.GetTrxContext(ctx, r.db)
+	query := trx.Table(table).Where("username = ? AND "+column+" = ? AND created_at >= ? AND deleted_at IS NULL", content.Username, content.Text, since)
+	if content.ID != "" {
+		query = query.Where("id <> ?", content.ID)
+	}
+
+	err = query.Count(&total).Error
+	return total, err
+}
+
+func (r repository) GetSpamDocuments(ctx context.Context) (res model.SpamDocumentsModel, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Where("id = ?", spamDocumentsId).Limit(1).Find(&res).Error
+	return res, err
+}
+
+func (r repository) GetSpamTokens(ctx context.Context, tokens []string) (res []model.SpamTokenModel, err error) {
+	if len(tokens) == 0 {
+		return res, nil
+	}
+
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Where("token IN ?", tokens).Find(&res).Error
+	return res, err
+}
+
+// LearnSpamTokens counts one more spam or ham document and every token of it in one transaction
+func (r repository) LearnSpamTokens(ctx context.Context, tokens []string, spam bool) (err error) {
+	column := "ham"
+	if spam {
+		column = "spam"
+	}
+
+	trx := transaction.GetTrxContext(ctx, r.db)
+	return trx.Transaction(func(tx *gorm.DB) error {
+		if len(tokens) > 0 {
+			rows := make([]model.SpamTokenModel, 0, len(tokens))
+			for _, token := range tokens {
+				row := model.SpamTokenModel{Token: token, Ham: 1}
+				if spam {
+					row = model.SpamTokenModel{Token: token, Spam: 1}
+				}
+				rows = append(rows, row)
+			}
+
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "token"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{column: gorm.Expr("spam_tokens." + column + " + 1")}),
+			}).Create(&rows).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&model.SpamDocumentsModel{}).Where("id = ?", spamDocumentsId).UpdateColumn(column, gorm.Expr(column+" + 1")).Error
+	})
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"simple-blog-system/config/db"
+	"simple-blog-system/internal/app/filter/payload"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type FilterRepositoryTestSuite struct {
+	suite.Suite
+	db         *gorm.DB
+	mock       sqlmock.Sqlmock
+	repository repository
+}
+
+func (suite *FilterRepositoryTestSuite) SetupTest() {
+	var (
+		sqlDB *sql.DB
+		err   error
+	)
+
+	sqlDB, suite.mock, err = sqlmock.New()
+	assert.NoError(suite.T(), err)
+
+	suite.db, err = gorm.Open(postgres.New(postgres.Config{
+		Conn: sqlDB,
+	}), &gorm.Config{})
+	assert.NoError(suite.T(), err)
+
+	gormDB := &db.GormDB{DB: suite.db}
+	suite.repository = repository{db: gormDB}
+}
+
+func (suite *FilterRepositoryTestSuite) TearDownTest() {
+	sqlDB, err := suite.db.DB()
+	assert.NoError(suite.T(), err)
+	sqlDB.Close()
+}
+
+func TestFilterRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(FilterRepositoryTestSuite))
+}
+
+func (suite *FilterRepositoryTestSuite) TestCountDuplicates_Comment() {
+	ctx := context.Background()
+	since := time.Now().Add(-time.Hour)
+	content := payload.Content{Type: payload.TypeComment, Username: "alice", Text: "Buy now"}
+
+	suite.mock.ExpectQuery(`SELECT count\(\*\) FROM "comments" WHERE username = \$1 AND comment = \$2 AND created_at >= \$3 AND deleted_at IS NULL`).
+		WithArgs("alice", "Buy now", since).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	total, err := suite.repository.CountDuplicates(ctx, content, since)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(2), total)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *FilterRepositoryTestSuite) TestCountDuplicates_EditedPost() {
+	ctx := context.Background()
+	since := time.Now().Add(-time.Hour)
+	content := payload.Content{ID: "post-1", Type: payload.TypePost, Username: "alice", Title: "Title", Text: "Body"}
+
+	suite.mock.ExpectQuery(`SELECT count\(\*\) FROM "posts" WHERE \(username = \$1 AND body = \$2 AND created_at >= \$3 AND deleted_at IS NULL\) AND id <> \$4`).
+		WithArgs("alice", "Body", since, "post-1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	total, err := suite.repository.CountDuplicates(ctx, content, since)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(0), total)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *FilterRepositoryTestSuite) TestGetSpamDocuments() {
+	ctx := context.Background()
+
+	suite.mock.ExpectQuery(`SELECT \* FROM "spam_documents" WHERE id = \$1 LIMIT \$2`).
+		WithArgs(spamDocumentsId, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "spam", "ham"}).AddRow(1, 12, 40))
+
+	result, err := suite.repository.GetSpamDocuments(ctx)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(12), result.Spam)
+	assert.Equal(suite.T(), int64(40), result.Ham)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *FilterRepositoryTestSuite) TestGetSpamTokens() {
+	ctx := context.Background()
+
+	suite.mock.ExpectQuery(`SELECT \* FROM "spam_tokens" WHERE token IN \(\$1,\$2\)`).
+		WithArgs("cheap", "pills").
+		WillReturnRows(sqlmock.NewRows([]string{"token", "spam", "ham"}).AddRow("cheap", 9, 1))
+
+	result, err := suite.repository.GetSpamTokens(ctx, []string{"cheap", "pills"})
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 1)
+	assert.Equal(suite.T(), int64(9), result[0].Spam)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *FilterRepositoryTestSuite) TestGetSpamTokens_Empty() {
+	result, err := suite.repository.GetSpamTokens(context.Background(), []string{})
+
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), result)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *FilterRepositoryTestSuite) TestLearnSpamTokens() {
+	ctx := context.Background()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(`INSERT INTO "spam_tokens" \("token","spam","ham"\) VALUES \(\$1,\$2,\$3\),\(\$4,\$5,\$6\) ON CONFLICT \("token"\) DO UPDATE SET "spam"=spam_tokens.spam \+ 1`).
+		WithArgs("cheap", 1, 0, "pills", 1, 0).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	suite.mock.ExpectExec(`UPDATE "spam_documents" SET "spam"=spam \+ 1 WHERE id = \$1`).
+		WithArgs(spamDocumentsId).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.LearnSpamTokens(ctx, []string{"cheap", "pills"}, true)
+
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *FilterRepositoryTestSuite) TestLearnSpamTokens_Error() {
+	ctx := context.Background()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(`INSERT INTO "spam_tokens"`).
+		WillReturnError(gorm.ErrInvalidDB)
+	suite.mock.ExpectRollback()
+
+	err := suite.repository.LearnSpamTokens(ctx, []string{"hello"}, false)
+
+	assert.Equal(suite.T(), gorm.ErrInvalidDB, err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"simple-blog-system/internal/app/filter/model"
+	"simple-blog-system/internal/app/filter/payload"
+	"simple-blog-system/internal/app/filter/port"
+)
+
+const filterBannedWords = "banned_words"
+
+type bannedWords struct {
+	words map[string]bool
+}
+
+// NewBannedWords rejects content that contains one of words, matched as whole words ignoring case
+func NewBannedWords(words []string) port.IContentFilter {
+	filter := bannedWords{words: make(map[string]bool, len(words))}
+	for _, word := range words {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word != "" {
+			filter.words[word] = true
+		}
+	}
+
+	return filter
+}
+
+func (f bannedWords) Check(ctx context.Context, content payload.Content) (res model.Verdict, err error) {
+	for _, word := range words(content.FullText()) {
+		if f.words[word] {
+			return model.Verdict{
+				Action: model.ActionReject,
+				Filter: filterBannedWords,
+				Reason: fmt.Sprintf("contains the banned word %q", word),
+			}, nil
+		}
+	}
+
+	return model.Allow(), nil
+}
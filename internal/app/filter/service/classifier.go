@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	"simple-blog-system/internal/app/filter/model"
+	"simple-blog-system/internal/app/filter/payload"
+	"simple-blog-system/internal/app/filter/port"
+)
+
+const (
+	filterClassifier = "classifier"
+
+	// minTokenLength and maxTokenLength skip short words that say nothing and junk that is no word
+	minTokenLength = 3
+	maxTokenLength = 64
+	// maxTokens bounds the lookup of long texts
+	maxTokens = 200
+)
+
+type classifier struct {
+	filterRepo port.IFilterRepository
+	// rejectAt and reviewAt are spam probabilities between 0 and 1
+	rejectAt float64
+	reviewAt float64
+	// minDocuments of both spam and ham are needed before the classifier has a say
+	minDocuments int64
+}
+
+// NewClassifier scores content with a naive Bayes classifier trained on moderator decisions
+func NewClassifier(filterRepo port.IFilterRepository, rejectAt float64, reviewAt float64, minDocuments int) port.ILearningFilter {
+	return classifier{
+		filterRepo:   filterRepo,
+		rejectAt:     rejectAt,
+		reviewAt:     reviewAt,
+		minDocuments: int64(minDocuments),
+	}
+}
+
+func (f classifier) Check(ctx context.Context, content payload.Content) (res model.Verdict, err error) {
+	score, err := f.score(ctx, content)
+	if err != nil {
+		return res, err
+	}
+
+	verdict := model.Verdict{Filter: filterClassifier, Reason: fmt.Sprintf("spam score %.2f", score)}
+	switch {
+	case score >= f.rejectAt:
+		verdict.Action = model.ActionReject
+	case score >= f.reviewAt:
+		verdict.Action = model.ActionReview
+	default:
+		return model.Allow(), nil
+	}
+
+	return verdict, nil
+}
+
+func (f classifier) Learn(ctx context.Context, content payload.Content, spam bool) (err error) {
+	return f.filterRepo.LearnSpamTokens(ctx, tokens(content.FullText()), spam)
+}
+
+// score is the probability of content being spam, 0 while the classifier has not learned enough
+func (f classifier) score(ctx context.Context, content payload.Content) (float64, error) {
+	docs, err := f.filterRepo.GetSpamDocuments(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if docs.Spam < f.minDocuments || docs.Ham < f.minDocuments {
+		return 0, nil
+	}
+
+	known, err := f.filterRepo.GetSpamTokens(ctx, tokens(content.FullText()))
+	if err != nil {
+		return 0, err
+	}
+
+	// log probabilities keep long texts from rounding to zero, unseen tokens are left out
+	total := float64(docs.Spam + docs.Ham)
+	logSpam := math.Log(float64(docs.Spam) / total)
+	logHam := math.Log(float64(docs.Ham) / total)
+	for _, token := range known {
+		logSpam += math.Log(float64(token.Spam+1) / float64(docs.Spam+2))
+		logHam += math.Log(float64(token.Ham+1) / float64(docs.Ham+2))
+	}
+
+	return 1 / (1 + math.Exp(logHam-logSpam)), nil
+}
+
+// words splits text into lower case words
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// tokens returns the distinct words of text the classifier learns from
+func tokens(text string) []string {
+	seen := map[string]bool{}
+	res := []string{}
+	for _, word := range words(text) {
+		length := len([]rune(word))
+		if length < minTokenLength || length > maxTokenLength || seen[word] {
+			continue
+		}
+
+		seen[word] = true
+		res = append(res, word)
+		if len(res) == maxTokens {
+			break
+		}
+	}
+
+	return res
+}
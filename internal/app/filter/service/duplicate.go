@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"simple-blog-system/internal/app/filter/model"
+	"simple-blog-system/internal/app/filter/payload"
+	"simple-blog-system/internal/app/filter/port"
+)
+
+const filterDuplicate = "duplicate"
+
+type duplicate struct {
+	filterRepo port.IFilterRepository
+	window     time.Duration
+}
+
+// NewDuplicate rejects content a user already posted with the same text within window
+func NewDuplicate(filterRepo port.IFilterRepository, window time.Duration) port.IContentFilter {
+	return duplicate{filterRepo: filterRepo, window: window}
+}
+
+func (f duplicate) Check(ctx context.Context, content payload.Content) (res model.Verdict, err error) {
+	if strings.TrimSpace(content.Text) == "" {
+		return model.Allow(), nil
+	}
+
+	total, err := f.filterRepo.CountDuplicates(ctx, content, time.Now().Add(-f.window))
+	if err != nil {
+		return res, err
+	}
+
+	if total > 0 {
+		return model.Verdict{
+			Action: model.ActionReject,
+			Filter: filterDuplicate,
+			Reason: "the same text was already posted",
+		}, nil
+	}
+
+	return model.Allow(), nil
+}
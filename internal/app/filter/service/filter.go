@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"simple-blog-system/internal/app/filter/model"
+	"simple-blog-system/internal/app/filter/payload"
+	"simple-blog-system/internal/app/filter/port"
+
+	"github.com/rs/zerolog/log"
+)
+
+type service struct {
+	filters []port.IContentFilter
+}
+
+// New chains filters, content gets the strictest verdict of them
+func New(filters ...port.IContentFilter) port.ILearningFilter {
+	return &service{filters: filters}
+}
+
+// Check never fails, a filter that fails sends the content to review so nothing is published unchecked
+func (s *service) Check(ctx context.Context, content payload.Content) (res model.Verdict, err error) {
+	res = model.Allow()
+	for _, filter := range s.filters {
+		verdict, err := filter.Check(ctx, content)
+		if err != nil {
+			log.Error().Err(err).Str("type", content.Type).Msg("content filter failed")
+			verdict = model.Verdict{Action: model.ActionReview, Reason: "content could not be checked"}
+		}
+
+		if verdict.StricterThan(res) {
+			res = verdict
+		}
+		if res.Action == model.ActionReject {
+			break
+		}
+	}
+
+	return res, nil
+}
+
+// Learn passes a moderator decision to every filter that learns
+func (s *service) Learn(ctx context.Context, content payload.Content, spam bool) (err error) {
+	var errs []error
+	for _, filter := range s.filters {
+		if learner, ok := filter.(port.ILearner); ok {
+			errs = append(errs, learner.Learn(ctx, content, spam))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"simple-blog-system/internal/app/filter/model"
+	"simple-blog-system/internal/app/filter/payload"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// Mock for IFilterRepository
+type MockFilterRepository struct {
+	mock.Mock
+}
+
+func (m *MockFilterRepository) CountDuplicates(ctx context.Context, content payload.Content, since time.Time) (int64, error) {
+	args := m.Called(ctx, content, since)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockFilterRepository) GetSpamDocuments(ctx context.Context) (model.SpamDocumentsModel, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(model.SpamDocumentsModel), args.Error(1)
+}
+
+func (m *MockFilterRepository) GetSpamTokens(ctx context.Context, tokens []string) ([]model.SpamTokenModel, error) {
+	args := m.Called(ctx, tokens)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.SpamTokenModel), args.Error(1)
+}
+
+func (m *MockFilterRepository) LearnSpamTokens(ctx context.Context, tokens []string, spam bool) error {
+	args := m.Called(ctx, tokens, spam)
+	return args.Error(0)
+}
+
+// stubFilter answers every check with the same verdict
+type stubFilter struct {
+	verdict model.Verdict
+	err     error
+	checked *int
+}
+
+func (f stubFilter) Check(ctx context.Context, content payload.Content) (model.Verdict, error) {
+	if f.checked != nil {
+		*f.checked++
+	}
+	return f.verdict, f.err
+}
+
+type FilterServiceTestSuite struct {
+	suite.Suite
+	filterRepo *MockFilterRepository
+	ctx        context.Context
+}
+
+func (suite *FilterServiceTestSuite) SetupTest() {
+	suite.filterRepo = new(MockFilterRepository)
+	suite.ctx = context.Background()
+}
+
+func TestFilterServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(FilterServiceTestSuite))
+}
+
+func (suite *FilterServiceTestSuite) TestCheck_StrictestVerdictWins() {
+	review := model.Verdict{Action: model.ActionReview, Filter: "links"}
+	reject := model.Verdict{Action: model.ActionReject, Filter: "banned_words"}
+	checked := 0
+
+	filter := New(
+		stubFilter{verdict: model.Allow()},
+		stubFilter{verdict: review},
+		stubFilter{verdict: reject},
+		stubFilter{verdict: model.Allow(), checked: &checked},
+	)
+
+	result, err := filter.Check(suite.ctx, payload.Content{Text: "text"})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), reject, result)
+	assert.Equal(suite.T(), 0, checked, "filters after a reject are skipped")
+}
+
+func (suite *FilterServiceTestSuite) TestCheck_FailingFilterSendsToReview() {
+	filter := New(stubFilter{err: errors.New("database error")})
+
+	result, err := filter.Check(suite.ctx, payload.Content{Text: "text"})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.ActionReview, result.Action)
+}
+
+func (suite *FilterServiceTestSuite) TestCheck_NoFilters() {
+	result, err := New().Check(suite.ctx, payload.Content{Text: "text"})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.Allow(), result)
+}
+
+func (suite *FilterServiceTestSuite) TestLearn_OnlyLearners() {
+	suite.filterRepo.On("LearnSpamTokens", suite.ctx, []string{"cheap", "pills"}, true).Return(nil)
+
+	filter := New(NewLinkLimit(1), NewClassifier(suite.filterRepo, 0.9, 0.6, 1))
+
+	err := filter.Learn(suite.ctx, payload.Content{Text: "Cheap pills"}, true)
+
+	assert.NoError(suite.T(), err)
+	suite.filterRepo.AssertExpectations(suite.T())
+}
+
+func (suite *FilterServiceTestSuite) TestBannedWords() {
+	filter := NewBannedWords([]string{" Casino ", "", "viagra"})
+
+	result, err := filter.Check(suite.ctx, payload.Content{Title: "Hello", Text: "Visit our CASINO today!"})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.ActionReject, result.Action)
+	assert.Equal(suite.T(), filterBannedWords, result.Filter)
+
+	result, err = filter.Check(suite.ctx, payload.Content{Text: "Casinos are whole other words"})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.ActionAllow, result.Action)
+}
+
+func (suite *FilterServiceTestSuite) TestLinkLimit() {
+	filter := NewLinkLimit(2)
+
+	result, err := filter.Check(suite.ctx, payload.Content{Text: "see https://a.example and www.b.example"})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.ActionAllow, result.Action)
+
+	result, err = filter.Check(suite.ctx, payload.Content{Text: "http://a.example http://b.example HTTPS://c.example"})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.ActionReview, result.Action)
+	assert.Equal(suite.T(), filterLinks, result.Filter)
+}
+
+func (suite *FilterServiceTestSuite) TestDuplicate() {
+	content := payload.Content{Type: payload.TypeComment, Username: "alice", Text: "Buy now"}
+	suite.filterRepo.On("CountDuplicates", suite.ctx, content, mock.MatchedBy(func(since time.Time) bool {
+		return time.Since(since) >= time.Hour
+	})).Return(int64(1), nil)
+
+	result, err := NewDuplicate(suite.filterRepo, time.Hour).Check(suite.ctx, content)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.ActionReject, result.Action)
+	assert.Equal(suite.T(), filterDuplicate, result.Filter)
+}
+
+func (suite *FilterServiceTestSuite) TestDuplicate_BlankText() {
+	result, err := NewDuplicate(suite.filterRepo, time.Hour).Check(suite.ctx, payload.Content{Text: "  "})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.ActionAllow, result.Action)
+	suite.filterRepo.AssertNotCalled(suite.T(), "CountDuplicates", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *FilterServiceTestSuite) TestClassifier_NotTrained() {
+	suite.filterRepo.On("GetSpamDocuments", suite.ctx).Return(model.SpamDocumentsModel{Spam: 3, Ham: 50}, nil)
+
+	result, err := NewClassifier(suite.filterRepo, 0.9, 0.6, 10).Check(suite.ctx, payload.Content{Text: "cheap pills"})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.ActionAllow, result.Action)
+	suite.filterRepo.AssertNotCalled(suite.T(), "GetSpamTokens", mock.Anything, mock.Anything)
+}
+
+func (suite *FilterServiceTestSuite) TestClassifier_Scores() {
+	suite.filterRepo.On("GetSpamDocuments", suite.ctx).Return(model.SpamDocumentsModel{Spam: 20, Ham: 20}, nil)
+	suite.filterRepo.On("GetSpamTokens", suite.ctx, []string{"cheap", "pills", "online"}).Return([]model.SpamTokenModel{
+		{Token: "cheap", Spam: 15, Ham: 1},
+		{Token: "pills", Spam: 18, Ham: 0},
+		{Token: "online", Spam: 10, Ham: 8},
+	}, nil)
+	suite.filterRepo.On("GetSpamTokens", suite.ctx, []string{"great", "post", "thanks"}).Return([]model.SpamTokenModel{
+		{Token: "great", Spam: 1, Ham: 12},
+		{Token: "thanks", Spam: 0, Ham: 15},
+	}, nil)
+
+	classifier := NewClassifier(suite.filterRepo, 0.9, 0.6, 10)
+
+	result, err := classifier.Check(suite.ctx, payload.Content{Text: "Cheap pills online, cheap!"})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.ActionReject, result.Action)
+	assert.Equal(suite.T(), filterClassifier, result.Filter)
+
+	result, err = classifier.Check(suite.ctx, payload.Content{Text: "Great post, thanks"})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.ActionAllow, result.Action)
+}
+
+func (suite *FilterServiceTestSuite) TestClassifier_Error() {
+	suite.filterRepo.On("GetSpamDocuments", suite.ctx).Return(model.SpamDocumentsModel{}, errors.New("database error"))
+
+	_, err := NewClassifier(suite.filterRepo, 0.9, 0.6, 10).Check(suite.ctx, payload.Content{Text: "text"})
+
+	assert.Error(suite.T(), err)
+}
+
+func (suite *FilterServiceTestSuite) TestTokens() {
+	assert.Equal(suite.T(), []string{"hello", "world", "ünïcode"}, tokens("Hello, hello WORLD! an ok ünïcode"))
+}
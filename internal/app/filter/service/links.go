@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"simple-blog-system/internal/app/filter/model"
+	"simple-blog-system/internal/app/filter/payload"
+	"simple-blog-system/internal/app/filter/port"
+)
+
+const filterLinks = "links"
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+`)
+
+type linkLimit struct {
+	max int
+}
+
+// NewLinkLimit sends content with more than max links to review
+func NewLinkLimit(max int) port.IContentFilter {
+	return linkLimit{max: max}
+}
+
+func (f linkLimit) Check(ctx context.Context, content payload.Content) (res model.Verdict, err error) {
+	links := len(linkPattern.FindAllStringIndex(content.FullText(), -1))
+	if links > f.max {
+		return model.Verdict{
+			Action: model.ActionReview,
+			Filter: filterLinks,
+			Reason: fmt.Sprintf("has %d links, at most %d are allowed without review", links, f.max),
+		}, nil
+	}
+
+	return model.Allow(), nil
+}
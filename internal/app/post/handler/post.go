@@ -4,10 +4,10 @@ import (
 	"simple-blog-system/internal/app/post/payload"
 	"simple-blog-system/internal/app/post/port"
 	"simple-blog-system/pkg/helper"
-	"strconv"
+	"simple-blog-system/pkg/pagination"
+	"simple-blog-system/pkg/validations"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 )
 
 type handler struct {
@@ -30,6 +30,8 @@ func New(postService port.IPostService) port.IPostHandler {
 // @Param post body payload.PostRequest true "Param Post"
 // @Success 200 {object} helper.Response
 // @Failure 400 {object} helper.Response
+// @Failure 409 {object} helper.Response
+// @Failure 422 {object} helper.Response
 // @Router /api/post [post]
 func (h *handler) AddPost(c *gin.Context) {
 	username := c.GetString("username")
@@ -42,10 +44,9 @@ func (h *handler) AddPost(c *gin.Context) {
 		return
 	}
 
-	validate := validator.New()
-	err := validate.Struct(postRequest)
+	err := validations.New().Struct(postRequest)
 	if err != nil {
-		helper.ResponseError(c, err)
+		helper.ResponseError(c, validations.BadRequest(err))
 		return
 	}
 
@@ -69,6 +70,8 @@ func (h *handler) AddPost(c *gin.Context) {
 // @Param post body payload.PostRequest true "Param Post"
 // @Success 200 {object} helper.Response
 // @Failure 400 {object} helper.Response
+// @Failure 409 {object} helper.Response
+// @Failure 422 {object} helper.Response
 // @Router /api/post/{id} [put]
 func (h *handler) UpdatePost(c *gin.Context) {
 	username := c.GetString("username")
@@ -81,10 +84,9 @@ func (h *handler) UpdatePost(c *gin.Context) {
 		return
 	}
 
-	validate := validator.New()
-	err := validate.Struct(postRequest)
+	err := validations.New().Struct(postRequest)
 	if err != nil {
-		helper.ResponseError(c, err)
+		helper.ResponseError(c, validations.BadRequest(err))
 		return
 	}
 
@@ -128,33 +130,33 @@ func (h *handler) DeletePost(c *gin.Context) {
 }
 
 // @Summary Get All Post
-// @Description Get All Post
+// @Description Get the published posts and the own drafts and scheduled posts, editors and admins get every post
 // @Tags post
 // @Accept json
 // @Produce json
-// @Param page path int true "Page"
-// @Param limit path int true "Limit"
+// @Param cursor query string false "Cursor from meta.next_cursor or meta.prev_cursor"
+// @Param page query int false "Page number, switches to offset mode with total and total_pages"
+// @Param limit query int false "Limit, default 10 and at most 100"
+// @Param tag query string false "Tag slug"
+// @Param category query string false "Category slug, includes its sub categories"
+// @Param status query string false "Status" Enums(PUBLISH, DRAFT)
+// @Param author query string false "Author username"
+// @Param created_after query string false "Created on or after date (2006-01-02)"
+// @Param created_before query string false "Created before date (2006-01-02)"
+// @Param sort query string false "Comma separated created_at, updated_at, title, status, prefix - for descending"
 // @Success 200 {object} helper.Response
 // @Failure 400 {object} helper.Response
 // @Router /api/post [get]
 func (h *handler) GetAllPost(c *gin.Context) {
 	username := c.GetString("username")
 
-	pageStr := c.Query("page")
-	page, err := strconv.Atoi(pageStr)
-	if err != nil {
-		helper.ResponseError(c, err)
-		return
-	}
-
-	limitStr := c.Query("limit")
-	limit, err := strconv.Atoi(limitStr)
+	page, filter, err := postListing(c)
 	if err != nil {
 		helper.ResponseError(c, err)
 		return
 	}
 
-	res, err := h.postService.GetAllPost(c.Request.Context(), username, page, limit)
+	res, meta, err := h.postService.GetAllPost(c.Request.Context(), username, filter, page)
 	if err != nil {
 		helper.ResponseError(c, err)
 		return
@@ -163,6 +165,7 @@ func (h *handler) GetAllPost(c *gin.Context) {
 	helper.ResponseData(c, &helper.Response{
 		Message: "get successfully",
 		Data:    res,
+		Meta:    meta,
 	})
 }
 
@@ -190,3 +193,131 @@ func (h *handler) GetById(c *gin.Context) {
 		Data:    res,
 	})
 }
+
+// @Summary Get Post By Slug
+// @Description Get Post By Slug, old slugs resolve to the post with redirect true and the canonical slug
+// @Tags post
+// @Accept json
+// @Produce json
+// @Param slug path string true "Slug"
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Router /api/post/slug/{slug} [get]
+func (h *handler) GetBySlug(c *gin.Context) {
+	username := c.GetString("username")
+
+	slug := c.Param("slug")
+
+	res, err := h.postService.GetBySlug(c.Request.Context(), username, slug)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "get successfully",
+		Data:    res,
+	})
+}
+
+// @Summary Get Public Posts
+// @Description Get the published posts without logging in
+// @Tags post
+// @Accept json
+// @Produce json
+// @Param cursor query string false "Cursor from meta.next_cursor or meta.prev_cursor"
+// @Param page query int false "Page number, switches to offset mode with total and total_pages"
+// @Param limit query int false "Limit, default 10 and at most 100"
+// @Param tag query string false "Tag slug"
+// @Param category query string false "Category slug, includes its sub categories"
+// @Param author query string false "Author username"
+// @Param created_after query string false "Created on or after date (2006-01-02)"
+// @Param created_before query string false "Created before date (2006-01-02)"
+// @Param sort query string false "Comma separated created_at, updated_at, title, status, prefix - for descending"
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Router /public-api/post [get]
+func (h *handler) GetPublicPosts(c *gin.Context) {
+	page, filter, err := postListing(c)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	res, meta, err := h.postService.GetPublicPosts(c.Request.Context(), filter, page)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "get successfully",
+		Data:    res,
+		Meta:    meta,
+	})
+}
+
+// @Summary Get Public Post ID
+// @Description Get one published post without logging in
+// @Tags post
+// @Accept json
+// @Produce json
+// @Param id path string true "Post ID"
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Router /public-api/post/{id} [get]
+func (h *handler) GetPublicById(c *gin.Context) {
+	res, err := h.postService.GetPublicById(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "get successfully",
+		Data:    res,
+	})
+}
+
+// @Summary Get Public Post By Slug
+// @Description Get one published post by slug without logging in, old slugs resolve to the post with redirect true and the canonical slug
+// @Tags post
+// @Accept json
+// @Produce json
+// @Param slug path string true "Slug"
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Router /public-api/post/slug/{slug} [get]
+func (h *handler) GetPublicBySlug(c *gin.Context) {
+	res, err := h.postService.GetPublicBySlug(c.Request.Context(), c.Param("slug"))
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "get successfully",
+		Data:    res,
+	})
+}
+
+// postListing reads the page and the filter of a post listing
+func postListing(c *gin.Context) (pagination.Page, payload.PostFilter, error) {
+	var filter payload.PostFilter
+
+	page, err := pagination.FromContext(c)
+	if err != nil {
+		return page, filter, err
+	}
+
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		return page, filter, err
+	}
+
+	err = validations.New().Struct(filter)
+	if err != nil {
+		return page, filter, validations.BadRequest(err)
+	}
+
+	return page, filter, nil
+}
@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"strconv"
+
+	"simple-blog-system/internal/app/post/payload"
+	"simple-blog-system/pkg/apperror"
+	"simple-blog-system/pkg/helper"
+	"simple-blog-system/pkg/pagination"
+	"simple-blog-system/pkg/validations"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errInvalidRevision = apperror.BadRequest("revision must be a positive number")
+
+// @Summary Get Post Revisions
+// @Description Get the revisions of a post, newest first (owner, admin or editor)
+// @Tags post
+// @Accept json
+// @Produce json
+// @Param id path string true "Post ID"
+// @Param cursor query string false "Cursor from meta.next_cursor or meta.prev_cursor"
+// @Param page query int false "Page number, switches to offset mode with total and total_pages"
+// @Param limit query int false "Limit, default 10 and at most 100"
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Router /api/post/{id}/revisions [get]
+func (h *handler) GetRevisions(c *gin.Context) {
+	username := c.GetString("username")
+
+	page, err := pagination.FromContext(c)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	res, meta, err := h.postService.GetRevisions(c.Request.Context(), username, c.Param("id"), page)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "get successfully",
+		Data:    res,
+		Meta:    meta,
+	})
+}
+
+// @Summary Get Post Revision
+// @Description Get one revision of a post (owner, admin or editor)
+// @Tags post
+// @Accept json
+// @Produce json
+// @Param id path string true "Post ID"
+// @Param revision path int true "Revision number"
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Router /api/post/{id}/revisions/{revision} [get]
+func (h *handler) GetRevision(c *gin.Context) {
+	username := c.GetString("username")
+
+	revision, err := revisionParam(c)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	res, err := h.postService.GetRevision(c.Request.Context(), username, c.Param("id"), revision)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "get successfully",
+		Data:    res,
+	})
+}
+
+// @Summary Diff Post Revisions
+// @Description Line based diff of the title and body between two revisions of a post (owner, admin or editor)
+// @Tags post
+// @Accept json
+// @Produce json
+// @Param id path string true "Post ID"
+// @Param from query int true "Revision to diff from"
+// @Param to query int true "Revision to diff to"
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Router /api/post/{id}/revisions/diff [get]
+func (h *handler) DiffRevisions(c *gin.Context) {
+	username := c.GetString("username")
+	var (
+		diffRequest payload.RevisionDiffRequest
+	)
+
+	if err := c.ShouldBindQuery(&diffRequest); err != nil {
+		helper.ResponseError(c, apperror.BadRequest("from and to must be revision numbers"))
+		return
+	}
+
+	err := validations.New().Struct(diffRequest)
+	if err != nil {
+		helper.ResponseError(c, validations.BadRequest(err))
+		return
+	}
+
+	res, err := h.postService.DiffRevisions(c.Request.Context(), username, c.Param("id"), diffRequest.From, diffRequest.To)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "get successfully",
+		Data:    res,
+	})
+}
+
+// @Summary Restore Post Revision
+// @Description Restore the title and body of an older revision as the current version, recorded as a new revision (owner, admin or editor)
+// @Tags post
+// @Accept json
+// @Produce json
+// @Param id path string true "Post ID"
+// @Param revision path int true "Revision number"
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Router /api/post/{id}/revisions/{revision}/restore [post]
+func (h *handler) RestoreRevision(c *gin.Context) {
+	username := c.GetString("username")
+
+	revision, err := revisionParam(c)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	res, err := h.postService.RestoreRevision(c.Request.Context(), username, c.Param("id"), revision)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "restore successfully",
+		Data:    res,
+	})
+}
+
+func revisionParam(c *gin.Context) (int, error) {
+	revision, err := strconv.Atoi(c.Param("revision"))
+	if err != nil || revision < 1 {
+		return 0, errInvalidRevision
+	}
+
+	return revision, nil
+}
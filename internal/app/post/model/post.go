@@ -3,21 +3,43 @@ package model
 import (
 	"time"
 
+	taxonomyModel "simple-blog-system/internal/app/taxonomy/model"
+
 	"github.com/go-openapi/strfmt"
 	"gorm.io/gorm"
 )
 
+const (
+	StatusPublish   = "PUBLISH"
+	StatusDraft     = "DRAFT"
+	StatusScheduled = "SCHEDULED"
+)
+
+// Comment moderation modes, a post without its own mode follows the global one
+const (
+	CommentModerationOff      = "off"
+	CommentModerationApproval = "approval"
+	CommentModerationTrusted  = "trusted"
+)
+
 type PostModel struct {
-	ID        strfmt.UUID4   `json:"id" gorm:"type:uuid;default:uuid_generate_v4()"`
-	Username  string         `json:"username" validate:"required"`
-	Title     string         `json:"title" validate:"required"`
-	Body      string         `json:"body" validate:"required"`
-	Status    string         `json:"status"`
-	CreatedBy string         `json:"created_by"`
-	UpdatedBy string         `json:"updated_by" gorm:"default:null"`
-	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
-	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"default:null"`
+	ID                strfmt.UUID4                 `json:"id" gorm:"type:uuid;default:uuid_generate_v4()"`
+	Username          string                       `json:"username" validate:"required"`
+	Title             string                       `json:"title" validate:"required"`
+	Slug              string                       `json:"slug"`
+	Body              string                       `json:"body" validate:"required"`
+	Status            string                       `json:"status"`
+	PublishAt         *time.Time                   `json:"publish_at" gorm:"default:null"`
+	CategoryId        *string                      `json:"category_id" gorm:"default:null"`
+	Category          *taxonomyModel.CategoryModel `json:"category,omitempty" gorm:"-"`
+	Tags              []taxonomyModel.TagModel     `json:"tags" gorm:"-"`
+	CommentCount      int64                        `json:"comment_count" gorm:"-"`
+	CommentModeration *string                      `json:"comment_moderation" gorm:"default:null"`
+	CreatedBy         string                       `json:"created_by"`
+	UpdatedBy         string                       `json:"updated_by" gorm:"default:null"`
+	CreatedAt         time.Time                    `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time                    `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt         gorm.DeletedAt               `json:"deleted_at" gorm:"default:null"`
 }
 
 func (u PostModel) TableName() string {
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+// PostRevisionModel is the content of a post after one of its writes, revision 1 is the post as created
+type PostRevisionModel struct {
+	ID        strfmt.UUID4 `json:"id" gorm:"type:uuid;default:uuid_generate_v4()"`
+	PostId    string       `json:"post_id"`
+	Revision  int          `json:"revision"`
+	Title     string       `json:"title"`
+	Body      string       `json:"body"`
+	CreatedBy string       `json:"created_by"`
+	CreatedAt time.Time    `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (u PostRevisionModel) TableName() string {
+	return "post_revisions"
+}
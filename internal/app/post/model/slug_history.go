@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+type PostSlugHistoryModel struct {
+	ID        strfmt.UUID4 `json:"id" gorm:"type:uuid;default:uuid_generate_v4()"`
+	PostId    string       `json:"post_id"`
+	Slug      string       `json:"slug"`
+	CreatedAt time.Time    `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (u PostSlugHistoryModel) TableName() string {
+	return "post_slug_history"
+}
@@ -1,7 +1,61 @@
 package payload
 
+import (
+	"simple-blog-system/internal/app/post/model"
+	"simple-blog-system/pkg/helper"
+
+	"github.com/go-openapi/strfmt"
+)
+
 type PostRequest struct {
 	Title  string `json:"title" validate:"required"`
 	Body   string `json:"body"  validate:"required"`
-	Status string `json:"status" validate:"required,oneof=PUBLISH DRAFT"`
+	Status string `json:"status" validate:"required,oneof=PUBLISH DRAFT SCHEDULED"`
+	// PublishAt is required for SCHEDULED posts and ignored otherwise
+	PublishAt *strfmt.DateTime `json:"publish_at" validate:"required_if=Status SCHEDULED,omitempty,time_after_now"`
+	// Tags replaces the tags of the post, absent keeps them and an empty list removes them
+	Tags []string `json:"tags" validate:"omitempty,max=10,dive,required,max=50"`
+	// CategoryId absent keeps the category and an empty string removes it
+	CategoryId *string `json:"category_id"`
+	// CommentModeration absent keeps the mode of the post and an empty string falls back to the global mode
+	CommentModeration *string `json:"comment_moderation" validate:"omitempty,oneof=off approval trusted"`
+}
+
+// PostSortFields are the fields a post listing can be sorted by, keep in sync with the sort tag of PostFilter
+var PostSortFields = []string{"created_at", "updated_at", "title", "status"}
+
+type PostFilter struct {
+	Tag      string `form:"tag" validate:"omitempty,max=50"`
+	Category string `form:"category" validate:"omitempty,max=50"`
+	Status   string `form:"status" validate:"omitempty,oneof=PUBLISH DRAFT SCHEDULED"`
+	Author   string `form:"author" validate:"omitempty,max=50"`
+	// CreatedAfter and CreatedBefore are dates, after includes the given day and before excludes it
+	CreatedAfter  string `form:"created_after" validate:"omitempty,datetime=2006-01-02"`
+	CreatedBefore string `form:"created_before" validate:"omitempty,datetime=2006-01-02"`
+	// Sort is a comma separated list of fields, prefixed with "-" for descending order
+	//
+	// Ex: -created_at,title
+	Sort string `form:"sort" validate:"omitempty,sort_fields=created_at updated_at title status"`
+	// PublishedOnly and Owner are set by the service and not the request, they keep a listing to published posts
+	// and the posts of Owner in every state
+	PublishedOnly bool   `form:"-"`
+	Owner         string `form:"-"`
+}
+
+type PostSlugResponse struct {
+	Post          *model.PostModel `json:"post"`
+	CanonicalSlug string           `json:"canonical_slug"`
+	Redirect      bool             `json:"redirect"`
+}
+
+type RevisionDiffRequest struct {
+	From int `form:"from" validate:"required,min=1"`
+	To   int `form:"to" validate:"required,min=1"`
+}
+
+type RevisionDiffResponse struct {
+	From  *model.PostRevisionModel `json:"from"`
+	To    *model.PostRevisionModel `json:"to"`
+	Title []helper.DiffLine        `json:"title"`
+	Body  []helper.DiffLine        `json:"body"`
 }
@@ -20,4 +20,28 @@ type IPostHandler interface {
 
 	// (GET /post/:id)
 	GetById(ctx *gin.Context)
+
+	// (GET /post/slug/:slug)
+	GetBySlug(ctx *gin.Context)
+
+	// (GET /public-api/post)
+	GetPublicPosts(ctx *gin.Context)
+
+	// (GET /public-api/post/:id)
+	GetPublicById(ctx *gin.Context)
+
+	// (GET /public-api/post/slug/:slug)
+	GetPublicBySlug(ctx *gin.Context)
+
+	// (GET /post/:id/revisions)
+	GetRevisions(ctx *gin.Context)
+
+	// (GET /post/:id/revisions/:revision)
+	GetRevision(ctx *gin.Context)
+
+	// (GET /post/:id/revisions/diff)
+	DiffRevisions(ctx *gin.Context)
+
+	// (POST /post/:id/revisions/:revision/restore)
+	RestoreRevision(ctx *gin.Context)
 }
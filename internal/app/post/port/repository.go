@@ -2,13 +2,34 @@ package port
 
 import (
 	"context"
+	"time"
+
 	"simple-blog-system/internal/app/post/model"
+	"simple-blog-system/internal/app/post/payload"
+	"simple-blog-system/pkg/apperror"
+	"simple-blog-system/pkg/pagination"
 )
 
+// ErrSlugTaken is returned when another post took the slug between the check and the write
+var ErrSlugTaken = apperror.Conflict("another post took the slug, try again")
+
 type IPostRepository interface {
 	InsertPost(ctx context.Context, post model.PostModel) (model.PostModel, error)
 	UpdatePost(ctx context.Context, post model.PostModel) (res model.PostModel, err error)
 	DeletePost(ctx context.Context, post model.PostModel) (err error)
+	AnonymizeUserPosts(ctx context.Context, username string, placeholder string) (res []model.PostModel, err error)
+	DeleteUserPosts(ctx context.Context, username string) (res []model.PostModel, err error)
 	GetPostById(ctx context.Context, id string) (res *model.PostModel, err error)
-	GetAllPost(ctx context.Context, page int, limit int) (res []model.PostModel, err error)
+	GetAllPost(ctx context.Context, filter payload.PostFilter, page pagination.Page) (res []model.PostModel, meta pagination.Meta, err error)
+	GetPublishedSlugs(ctx context.Context) (res []model.PostModel, err error)
+	GetDueScheduledPosts(ctx context.Context, now time.Time, limit int) (res []model.PostModel, err error)
+	CountComments(ctx context.Context, postIds []string) (res map[string]int64, err error)
+	GetPostBySlug(ctx context.Context, slug string) (res *model.PostModel, err error)
+	IsSlugTaken(ctx context.Context, slug string, excludePostId string) (taken bool, err error)
+	GetSlugHistory(ctx context.Context, slug string) (res *model.PostSlugHistoryModel, err error)
+	InsertSlugHistory(ctx context.Context, history model.PostSlugHistoryModel) (model.PostSlugHistoryModel, error)
+	DeleteSlugHistory(ctx context.Context, postId string, slug string) (err error)
+	InsertRevision(ctx context.Context, revision model.PostRevisionModel) (res model.PostRevisionModel, err error)
+	GetRevisions(ctx context.Context, postId string, page pagination.Page) (res []model.PostRevisionModel, meta pagination.Meta, err error)
+	GetRevision(ctx context.Context, postId string, revision int) (res *model.PostRevisionModel, err error)
 }
@@ -4,12 +4,26 @@ import (
 	"context"
 	"simple-blog-system/internal/app/post/model"
 	"simple-blog-system/internal/app/post/payload"
+	"simple-blog-system/pkg/pagination"
 )
 
 type IPostService interface {
 	AddPost(ctx context.Context, username string, param payload.PostRequest) (res *model.PostModel, err error)
 	UpdatePost(ctx context.Context, username string, id string, param payload.PostRequest) (res *model.PostModel, err error)
 	DeletePost(ctx context.Context, username string, id string) (res *model.PostModel, err error)
-	GetAllPost(ctx context.Context, username string, page int, limit int) (res []model.PostModel, err error)
+	GetAllPost(ctx context.Context, username string, filter payload.PostFilter, page pagination.Page) (res []model.PostModel, meta pagination.Meta, err error)
 	GetById(ctx context.Context, username string, id string) (res *model.PostModel, err error)
+	GetBySlug(ctx context.Context, username string, slug string) (res *payload.PostSlugResponse, err error)
+	// GetPublicPosts, GetPublicById and GetPublicBySlug serve readers without a login and only see published posts
+	GetPublicPosts(ctx context.Context, filter payload.PostFilter, page pagination.Page) (res []model.PostModel, meta pagination.Meta, err error)
+	GetPublicById(ctx context.Context, id string) (res *model.PostModel, err error)
+	GetPublicBySlug(ctx context.Context, slug string) (res *payload.PostSlugResponse, err error)
+	PublishDuePosts(ctx context.Context) (published int, err error)
+	GetRevisions(ctx context.Context, username string, id string, page pagination.Page) (res []model.PostRevisionModel, meta pagination.Meta, err error)
+	GetRevision(ctx context.Context, username string, id string, revision int) (res *model.PostRevisionModel, err error)
+	DiffRevisions(ctx context.Context, username string, id string, from int, to int) (res *payload.RevisionDiffResponse, err error)
+	RestoreRevision(ctx context.Context, username string, id string, revision int) (res *model.PostModel, err error)
+	// AnonymizeUserContent and DeleteUserContent take care of the posts of a deleted account
+	AnonymizeUserContent(ctx context.Context, username string, placeholder string) (int, error)
+	DeleteUserContent(ctx context.Context, username string) (int, error)
 }
@@ -2,12 +2,21 @@ package repository
 
 import (
 	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"simple-blog-system/config/db"
+	"simple-blog-system/pkg/apperror"
 	"simple-blog-system/pkg/cache"
+	"simple-blog-system/pkg/pagination"
 	"simple-blog-system/pkg/transaction"
+	"simple-blog-system/pkg/validations"
 
+	commentModel "simple-blog-system/internal/app/comment/model"
 	"simple-blog-system/internal/app/post/model"
+	"simple-blog-system/internal/app/post/payload"
 	"simple-blog-system/internal/app/post/port"
 )
 
@@ -23,6 +32,9 @@ func NewRepository(db *db.GormDB) port.IPostRepository {
 func (r repository) InsertPost(ctx context.Context, post model.PostModel) (model.PostModel, error) {
 	trx := transaction.GetTrxContext(ctx, r.db)
 	qres := trx.Create(&post).Error
+	if db.IsUniqueViolation(qres) {
+		return post, port.ErrSlugTaken
+	}
 
 	return post, qres
 }
@@ -30,6 +42,9 @@ func (r repository) InsertPost(ctx context.Context, post model.PostModel) (model
 func (r repository) UpdatePost(ctx context.Context, post model.PostModel) (res model.PostModel, err error) {
 	trx := transaction.GetTrxContext(ctx, r.db)
 	qres := trx.Save(&post).Error
+	if db.IsUniqueViolation(qres) {
+		return post, port.ErrSlugTaken
+	}
 
 	return post, qres
 }
@@ -46,10 +61,189 @@ func (r repository) DeletePost(ctx context.Context, post model.PostModel) (err e
 	return err
 }
 
-func (r repository) GetAllPost(ctx context.Context, page int, limit int) (res []model.PostModel, err error) {
-	offset := (page - 1) * limit
+// AnonymizeUserPosts hands the posts of username over to placeholder and returns them
+func (r repository) AnonymizeUserPosts(ctx context.Context, username string, placeholder string) (res []model.PostModel, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Model(&res).Clauses(clause.Returning{}).Where("username = ?", username).Update("username", placeholder).Error
+	return res, err
+}
+
+// DeleteUserPosts soft deletes the posts of username and returns them
+func (r repository) DeleteUserPosts(ctx context.Context, username string) (res []model.PostModel, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Clauses(clause.Returning{}).Where("username = ?", username).Delete(&res).Error
+	return res, err
+}
+
+func (r repository) GetAllPost(ctx context.Context, filter payload.PostFilter, page pagination.Page) (res []model.PostModel, meta pagination.Meta, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	query := applyPostFilter(trx.Model(&model.PostModel{}), filter)
+
+	total, err := page.Count(query)
+	if err != nil {
+		return nil, meta, err
+	}
+
+	sort, err := validations.ParseSort(filter.Sort, payload.PostSortFields...)
+	if err != nil {
+		return nil, meta, err
+	}
+
+	// the requested order goes first, created_at and id from the page scope break ties
+	for _, field := range sort {
+		query = query.Order(clause.OrderByColumn{Column: clause.Column{Table: "posts", Name: field.Field}, Desc: field.Desc})
+	}
+
+	err = query.Scopes(page.Scope("posts")).Find(&res).Error
+	if err != nil {
+		return nil, meta, err
+	}
+
+	res, meta = pagination.Paginate(page, res, total, postKey)
+	return res, meta, nil
+}
+
+// GetPublishedSlugs lists the id, slug and updated_at of every published post, oldest first so the pages of a sitemap stay put
+func (r repository) GetPublishedSlugs(ctx context.Context) (res []model.PostModel, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Model(&model.PostModel{}).Select("id", "slug", "updated_at").Where("status = ?", model.StatusPublish).Order("created_at, id").Find(&res).Error
+	return res, err
+}
+
+func postKey(post model.PostModel) pagination.Key {
+	return pagination.Key{CreatedAt: post.CreatedAt, ID: string(post.ID)}
+}
+
+// applyPostFilter narrows posts to a tag slug, a category slug including its sub categories, a status, the posts a user may see, an author and a creation date range
+func applyPostFilter(query *gorm.DB, filter payload.PostFilter) *gorm.DB {
+	if filter.Tag != "" {
+		query = query.Where("posts.id IN (SELECT post_tags.post_id FROM post_tags JOIN tags ON tags.id = post_tags.tag_id WHERE tags.slug = ?)", filter.Tag)
+	}
+
+	if filter.Category != "" {
+		query = query.Where(`posts.category_id IN (
+			WITH RECURSIVE tree AS (
+				SELECT id FROM categories WHERE slug = ? AND deleted_at IS NULL
+				UNION ALL
+				SELECT categories.id FROM categories JOIN tree ON categories.parent_id = tree.id WHERE categories.deleted_at IS NULL
+			)
+			SELECT id FROM tree
+		)`, filter.Category)
+	}
+
+	if filter.Status != "" {
+		query = query.Where("posts.status = ?", filter.Status)
+	}
+
+	if filter.PublishedOnly && filter.Owner != "" {
+		query = query.Where("posts.status = ? OR posts.username = ?", model.StatusPublish, filter.Owner)
+	} else if filter.PublishedOnly {
+		query = query.Where("posts.status = ?", model.StatusPublish)
+	}
+
+	if filter.Author != "" {
+		query = query.Where("posts.username = ?", filter.Author)
+	}
+
+	if filter.CreatedAfter != "" {
+		date, err := time.Parse(time.DateOnly, filter.CreatedAfter)
+		if err != nil {
+			query.AddError(apperror.BadRequest("created_after must be a date like 2006-01-02"))
+		} else {
+			query = query.Where("posts.created_at >= ?", date)
+		}
+	}
+
+	if filter.CreatedBefore != "" {
+		date, err := time.Parse(time.DateOnly, filter.CreatedBefore)
+		if err != nil {
+			query.AddError(apperror.BadRequest("created_before must be a date like 2006-01-02"))
+		} else {
+			query = query.Where("posts.created_at < ?", date)
+		}
+	}
+
+	return query
+}
+
+// GetDueScheduledPosts locks scheduled posts whose publish_at has passed, rows locked by another replica are skipped
+func (r repository) GetDueScheduledPosts(ctx context.Context, now time.Time, limit int) (res []model.PostModel, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate, Options: clause.LockingOptionsSkipLocked}).
+		Where("status = ? AND publish_at <= ?", model.StatusScheduled, now).
+		Order("publish_at ASC").
+		Limit(limit).
+		Find(&res).Error
+
+	return res, err
+}
+
+// CountComments returns the number of approved comments of each post, posts without comments are left out
+func (r repository) CountComments(ctx context.Context, postIds []string) (res map[string]int64, err error) {
+	res = map[string]int64{}
+	if len(postIds) == 0 {
+		return res, nil
+	}
+
+	var counts []struct {
+		PostId string
+		Total  int64
+	}
+
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Table("comments").
+		Select("post_id, COUNT(*) AS total").
+		Where("post_id IN ? AND status = ? AND deleted_at IS NULL", postIds, commentModel.StatusApproved).
+		Group("post_id").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, count := range counts {
+		res[count.PostId] = count.Total
+	}
+
+	return res, nil
+}
+
+func (r repository) GetPostBySlug(ctx context.Context, slug string) (res *model.PostModel, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Where("slug = ?", slug).First(&res).Error
+	return res, err
+}
+
+func (r repository) IsSlugTaken(ctx context.Context, slug string, excludePostId string) (taken bool, err error) {
+	var count int64
+
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Unscoped().Model(&model.PostModel{}).Where("slug = ? AND id <> ?", slug, excludePostId).Count(&count).Error
+	if err != nil || count > 0 {
+		return count > 0, err
+	}
+
+	err = trx.Model(&model.PostSlugHistoryModel{}).Where("slug = ? AND post_id <> ?", slug, excludePostId).Count(&count).Error
+	return count > 0, err
+}
 
+func (r repository) GetSlugHistory(ctx context.Context, slug string) (res *model.PostSlugHistoryModel, err error) {
 	trx := transaction.GetTrxContext(ctx, r.db)
-	err = trx.Limit(limit).Offset(offset).Find(&res).Error
+	err = trx.Where("slug = ?", slug).First(&res).Error
 	return res, err
 }
+
+func (r repository) InsertSlugHistory(ctx context.Context, history model.PostSlugHistoryModel) (model.PostSlugHistoryModel, error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	qres := trx.Create(&history).Error
+	if db.IsUniqueViolation(qres) {
+		return history, port.ErrSlugTaken
+	}
+
+	return history, qres
+}
+
+func (r repository) DeleteSlugHistory(ctx context.Context, postId string, slug string) (err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Where("post_id = ? AND slug = ?", postId, slug).Delete(&model.PostSlugHistoryModel{}).Error
+	return err
+}
@@ -3,15 +3,21 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"net/http"
 	"regexp"
 	"testing"
 	"time"
 
 	"simple-blog-system/config/db"
 	"simple-blog-system/internal/app/post/model"
+	"simple-blog-system/internal/app/post/payload"
+	"simple-blog-system/internal/app/post/port"
+	"simple-blog-system/pkg/apperror"
+	"simple-blog-system/pkg/pagination"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/go-openapi/strfmt"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"gorm.io/driver/postgres"
@@ -62,6 +68,7 @@ func (suite *PostRepositoryTestSuite) TestInsertPost_Success() {
 		ID:        postID,
 		Username:  "testuser",
 		Title:     "Test Post",
+		Slug:      "test-post",
 		Body:      "This is a test post body",
 		Status:    "published",
 		CreatedBy: "testuser",
@@ -74,6 +81,7 @@ func (suite *PostRepositoryTestSuite) TestInsertPost_Success() {
 		WithArgs(
 			post.Username,
 			post.Title,
+			post.Slug,
 			post.Body,
 			post.Status,
 			post.CreatedBy,
@@ -102,6 +110,7 @@ func (suite *PostRepositoryTestSuite) TestInsertPost_Error() {
 		ID:        postID,
 		Username:  "testuser",
 		Title:     "Test Post",
+		Slug:      "test-post",
 		Body:      "This is a test post body",
 		Status:    "published",
 		CreatedBy: "testuser",
@@ -114,6 +123,7 @@ func (suite *PostRepositoryTestSuite) TestInsertPost_Error() {
 		WithArgs(
 			post.Username,
 			post.Title,
+			post.Slug,
 			post.Body,
 			post.Status,
 			post.CreatedBy,
@@ -131,6 +141,29 @@ func (suite *PostRepositoryTestSuite) TestInsertPost_Error() {
 	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
 }
 
+func (suite *PostRepositoryTestSuite) TestInsertPost_SlugTaken() {
+	ctx := context.Background()
+
+	post := model.PostModel{
+		Username:  "testuser",
+		Title:     "Test Post",
+		Slug:      "test-post",
+		Body:      "This is a test post body",
+		Status:    "published",
+		CreatedBy: "testuser",
+	}
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "posts"`)).
+		WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "posts_slug_unique"})
+	suite.mock.ExpectRollback()
+
+	_, err := suite.repository.InsertPost(ctx, post)
+
+	assert.ErrorIs(suite.T(), err, port.ErrSlugTaken)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
 func (suite *PostRepositoryTestSuite) TestUpdatePost_Success() {
 	ctx := context.Background()
 	now := time.Now()
@@ -140,6 +173,7 @@ func (suite *PostRepositoryTestSuite) TestUpdatePost_Success() {
 		ID:        postID,
 		Username:  "testuser",
 		Title:     "Updated Post",
+		Slug:      "updated-post",
 		Body:      "This is an updated post body",
 		Status:    "published",
 		CreatedBy: "testuser",
@@ -153,8 +187,12 @@ func (suite *PostRepositoryTestSuite) TestUpdatePost_Success() {
 		WithArgs(
 			post.Username,
 			post.Title,
+			post.Slug,
 			post.Body,
 			post.Status,
+			post.PublishAt,
+			post.CategoryId,
+			post.CommentModeration,
 			post.CreatedBy,
 			post.UpdatedBy,
 			sqlmock.AnyArg(), // CreatedAt
@@ -181,6 +219,7 @@ func (suite *PostRepositoryTestSuite) TestUpdatePost_Error() {
 		ID:        postID,
 		Username:  "testuser",
 		Title:     "Updated Post",
+		Slug:      "updated-post",
 		Body:      "This is an updated post body",
 		Status:    "published",
 		CreatedBy: "testuser",
@@ -194,8 +233,12 @@ func (suite *PostRepositoryTestSuite) TestUpdatePost_Error() {
 		WithArgs(
 			post.Username,
 			post.Title,
+			post.Slug,
 			post.Body,
 			post.Status,
+			post.PublishAt,
+			post.CategoryId,
+			post.CommentModeration,
 			post.CreatedBy,
 			post.UpdatedBy,
 			sqlmock.AnyArg(),
@@ -222,6 +265,7 @@ func (suite *PostRepositoryTestSuite) TestGetPostById_Success() {
 		ID:        strfmt.UUID4(postID),
 		Username:  "testuser",
 		Title:     "Test Post",
+		Slug:      "test-post",
 		Body:      "This is a test post body",
 		Status:    "published",
 		CreatedBy: "testuser",
@@ -340,8 +384,7 @@ func (suite *PostRepositoryTestSuite) TestDeletePost_NotFound() {
 
 func (suite *PostRepositoryTestSuite) TestGetAllPost_Success() {
 	ctx := context.Background()
-	page := 1
-	limit := 10
+	page := pagination.Page{Limit: 10}
 	now := time.Now()
 
 	post1 := model.PostModel{
@@ -370,11 +413,11 @@ func (suite *PostRepositoryTestSuite) TestGetAllPost_Success() {
 		AddRow(post1.ID, post1.Username, post1.Title, post1.Body, post1.Status, post1.CreatedBy, nil, post1.CreatedAt, post1.UpdatedAt).
 		AddRow(post2.ID, post2.Username, post2.Title, post2.Body, post2.Status, post2.CreatedBy, nil, post2.CreatedAt, post2.UpdatedAt)
 
-	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "posts" WHERE "posts"."deleted_at" IS NULL LIMIT`)).
-		WithArgs(limit).
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "posts" WHERE "posts"."deleted_at" IS NULL ORDER BY posts.created_at DESC, posts.id DESC LIMIT $1`)).
+		WithArgs(page.Limit + 1).
 		WillReturnRows(rows)
 
-	result, err := suite.repository.GetAllPost(ctx, page, limit)
+	result, _, err := suite.repository.GetAllPost(ctx, payload.PostFilter{}, page)
 
 	assert.NoError(suite.T(), err)
 	assert.Len(suite.T(), result, 2)
@@ -385,32 +428,31 @@ func (suite *PostRepositoryTestSuite) TestGetAllPost_Success() {
 
 func (suite *PostRepositoryTestSuite) TestGetAllPost_EmptyResult() {
 	ctx := context.Background()
-	page := 1
-	limit := 10
+	page := pagination.Page{Limit: 10}
 
 	rows := sqlmock.NewRows([]string{"id", "username", "title", "body", "status", "created_by", "updated_by", "created_at", "updated_at"})
 
-	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "posts" WHERE "posts"."deleted_at" IS NULL LIMIT`)).
-		WithArgs(limit).
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "posts" WHERE "posts"."deleted_at" IS NULL ORDER BY posts.created_at DESC, posts.id DESC LIMIT $1`)).
+		WithArgs(page.Limit + 1).
 		WillReturnRows(rows)
 
-	result, err := suite.repository.GetAllPost(ctx, page, limit)
+	result, meta, err := suite.repository.GetAllPost(ctx, payload.PostFilter{}, page)
 
 	assert.NoError(suite.T(), err)
 	assert.Len(suite.T(), result, 0)
+	assert.Empty(suite.T(), meta.NextCursor)
 	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
 }
 
 func (suite *PostRepositoryTestSuite) TestGetAllPost_Error() {
 	ctx := context.Background()
-	page := 1
-	limit := 10
+	page := pagination.Page{Limit: 10}
 
-	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "posts" WHERE "posts"."deleted_at" IS NULL LIMIT`)).
-		WithArgs(limit).
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "posts" WHERE "posts"."deleted_at" IS NULL ORDER BY posts.created_at DESC, posts.id DESC LIMIT $1`)).
+		WithArgs(page.Limit + 1).
 		WillReturnError(gorm.ErrInvalidDB)
 
-	result, err := suite.repository.GetAllPost(ctx, page, limit)
+	result, _, err := suite.repository.GetAllPost(ctx, payload.PostFilter{}, page)
 
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), gorm.ErrInvalidDB, err)
@@ -420,19 +462,400 @@ func (suite *PostRepositoryTestSuite) TestGetAllPost_Error() {
 
 func (suite *PostRepositoryTestSuite) TestGetAllPost_Pagination() {
 	ctx := context.Background()
-	page := 2
-	limit := 5
-	offset := 5
+	page := pagination.Page{Limit: 5, Number: 2}
 
 	rows := sqlmock.NewRows([]string{"id", "username", "title", "body", "status", "created_by", "updated_by", "created_at", "updated_at"})
 
-	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "posts" WHERE "posts"."deleted_at" IS NULL LIMIT $1 OFFSET $2`)).
-		WithArgs(limit, offset).
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "posts" WHERE "posts"."deleted_at" IS NULL`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(12))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "posts" WHERE "posts"."deleted_at" IS NULL ORDER BY posts.created_at DESC, posts.id DESC LIMIT $1 OFFSET $2`)).
+		WithArgs(5, 5).
 		WillReturnRows(rows)
 
-	result, err := suite.repository.GetAllPost(ctx, page, limit)
+	result, meta, err := suite.repository.GetAllPost(ctx, payload.PostFilter{}, page)
 
 	assert.NoError(suite.T(), err)
 	assert.Len(suite.T(), result, 0)
+	assert.Equal(suite.T(), int64(12), *meta.Total)
+	assert.Equal(suite.T(), 3, *meta.TotalPages)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostRepositoryTestSuite) TestGetPostBySlug_Success() {
+	ctx := context.Background()
+	slug := "test-post"
+
+	rows := sqlmock.NewRows([]string{"id", "username", "title", "slug", "body", "status"}).
+		AddRow("123e4567-e89b-12d3-a456-426614174000", "testuser", "Test Post", slug, "Body", "PUBLISH")
+
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "posts" WHERE slug = $1 AND "posts"."deleted_at" IS NULL ORDER BY "posts"."id" LIMIT`)).
+		WithArgs(slug, 1).
+		WillReturnRows(rows)
+
+	result, err := suite.repository.GetPostBySlug(ctx, slug)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), slug, result.Slug)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostRepositoryTestSuite) TestIsSlugTaken_ByPost() {
+	ctx := context.Background()
+
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "posts" WHERE slug = $1 AND id <> $2`)).
+		WithArgs("test-post", "post-1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	taken, err := suite.repository.IsSlugTaken(ctx, "test-post", "post-1")
+
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), taken)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostRepositoryTestSuite) TestIsSlugTaken_ByHistory() {
+	ctx := context.Background()
+
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "posts" WHERE slug = $1 AND id <> $2`)).
+		WithArgs("old-post", "post-1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "post_slug_history" WHERE slug = $1 AND post_id <> $2`)).
+		WithArgs("old-post", "post-1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	taken, err := suite.repository.IsSlugTaken(ctx, "old-post", "post-1")
+
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), taken)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostRepositoryTestSuite) TestInsertSlugHistory_Success() {
+	ctx := context.Background()
+	history := model.PostSlugHistoryModel{
+		PostId: "post-1",
+		Slug:   "old-post",
+	}
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "post_slug_history"`)).
+		WithArgs(history.PostId, history.Slug, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("123e4567-e89b-12d3-a456-426614174000"))
+	suite.mock.ExpectCommit()
+
+	result, err := suite.repository.InsertSlugHistory(ctx, history)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), history.Slug, result.Slug)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostRepositoryTestSuite) TestGetAllPost_WithFilter() {
+	ctx := context.Background()
+	filter := payload.PostFilter{Tag: "go", Category: "programming"}
+
+	rows := sqlmock.NewRows([]string{"id", "username", "title", "body", "status", "created_by", "updated_by", "created_at", "updated_at"})
+
+	suite.mock.ExpectQuery(`SELECT \* FROM "posts" WHERE posts.id IN \(SELECT post_tags.post_id FROM post_tags JOIN tags .+ WHERE tags.slug = \$1\) AND \(posts.category_id IN \( WITH RECURSIVE tree .+ slug = \$2 .+\)\) AND "posts"."deleted_at" IS NULL ORDER BY posts.created_at DESC, posts.id DESC LIMIT`).
+		WithArgs(filter.Tag, filter.Category, 11).
+		WillReturnRows(rows)
+
+	result, _, err := suite.repository.GetAllPost(ctx, filter, pagination.Page{Limit: 10})
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 0)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostRepositoryTestSuite) TestGetAllPost_QueryLanguage() {
+	ctx := context.Background()
+	filter := payload.PostFilter{
+		Status:        "DRAFT",
+		Author:        "alice",
+		CreatedAfter:  "2026-01-01",
+		CreatedBefore: "2026-02-01",
+		Sort:          "-updated_at,title",
+	}
+	page := pagination.Page{Limit: 5, Number: 1}
+
+	suite.mock.ExpectQuery(`SELECT count\(\*\) FROM "posts" WHERE posts.status = \$1 AND posts.username = \$2 AND posts.created_at >= \$3 AND posts.created_at < \$4 AND "posts"."deleted_at" IS NULL`).
+		WithArgs("DRAFT", "alice", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	rows := sqlmock.NewRows([]string{"id", "username", "title", "body", "status", "created_by", "updated_by", "created_at", "updated_at"}).
+		AddRow("post-1", "alice", "Draft", "Body", "DRAFT", "alice", "", time.Now(), time.Now())
+
+	suite.mock.ExpectQuery(`SELECT \* FROM "posts" WHERE .+ ORDER BY "posts"."updated_at" DESC,"posts"."title",posts.created_at DESC, posts.id DESC LIMIT \$5`).
+		WithArgs("DRAFT", "alice", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), 5).
+		WillReturnRows(rows)
+
+	result, meta, err := suite.repository.GetAllPost(ctx, filter, page)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 1)
+	assert.Equal(suite.T(), int64(1), *meta.Total)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostRepositoryTestSuite) TestGetAllPost_BadDate() {
+	ctx := context.Background()
+	filter := payload.PostFilter{CreatedBefore: "2026-13-01"}
+	page := pagination.Page{Limit: 5, Number: 1}
+
+	_, _, err := suite.repository.GetAllPost(ctx, filter, page)
+
+	var appErr *apperror.Error
+	assert.ErrorAs(suite.T(), err, &appErr)
+	assert.Equal(suite.T(), http.StatusBadRequest, appErr.Code)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostRepositoryTestSuite) TestGetAllPost_PublishedOrOwn() {
+	ctx := context.Background()
+	filter := payload.PostFilter{PublishedOnly: true, Owner: "alice"}
+	page := pagination.Page{Limit: 5, Number: 1}
+
+	suite.mock.ExpectQuery(`SELECT count\(\*\) FROM "posts" WHERE \(posts.status = \$1 OR posts.username = \$2\) AND "posts"."deleted_at" IS NULL`).
+		WithArgs("PUBLISH", "alice").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	suite.mock.ExpectQuery(`SELECT \* FROM "posts" WHERE \(posts.status = \$1 OR posts.username = \$2\) AND "posts"."deleted_at" IS NULL`).
+		WithArgs("PUBLISH", "alice", 5).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, _, err := suite.repository.GetAllPost(ctx, filter, page)
+
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostRepositoryTestSuite) TestGetAllPost_PublishedOnly() {
+	ctx := context.Background()
+	filter := payload.PostFilter{Status: "DRAFT", PublishedOnly: true}
+	page := pagination.Page{Limit: 5, Number: 1}
+
+	suite.mock.ExpectQuery(`SELECT count\(\*\) FROM "posts" WHERE posts.status = \$1 AND posts.status = \$2 AND "posts"."deleted_at" IS NULL`).
+		WithArgs("DRAFT", "PUBLISH").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	suite.mock.ExpectQuery(`SELECT \* FROM "posts" WHERE posts.status = \$1 AND posts.status = \$2`).
+		WithArgs("DRAFT", "PUBLISH", 5).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	result, _, err := suite.repository.GetAllPost(ctx, filter, page)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 0)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostRepositoryTestSuite) TestGetAllPost_InvalidSort() {
+	ctx := context.Background()
+
+	suite.mock.ExpectQuery(`SELECT count\(\*\) FROM "posts"`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	result, _, err := suite.repository.GetAllPost(ctx, payload.PostFilter{Sort: "password"}, pagination.Page{Limit: 5, Number: 1})
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostRepositoryTestSuite) TestGetDueScheduledPosts() {
+	ctx := context.Background()
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "username", "title", "body", "status", "publish_at", "created_by", "created_at"}).
+		AddRow("post-1", "alice", "Later", "Body", "SCHEDULED", now.Add(-time.Minute), "alice", now.Add(-time.Hour))
+
+	suite.mock.ExpectQuery(`SELECT \* FROM "posts" WHERE \(status = \$1 AND publish_at <= \$2\) AND "posts"."deleted_at" IS NULL ORDER BY publish_at ASC LIMIT \$3 FOR UPDATE SKIP LOCKED`).
+		WithArgs(model.StatusScheduled, now, 100).
+		WillReturnRows(rows)
+
+	result, err := suite.repository.GetDueScheduledPosts(ctx, now, 100)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 1)
+	assert.NotNil(suite.T(), result[0].PublishAt)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostRepositoryTestSuite) TestGetPublishedSlugs() {
+	ctx := context.Background()
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "slug", "updated_at"}).
+		AddRow("post-1", "hello", now).
+		AddRow("post-2", "generics-in-go", now)
+
+	suite.mock.ExpectQuery(`SELECT "id","slug","updated_at" FROM "posts" WHERE status = \$1 AND "posts"."deleted_at" IS NULL ORDER BY created_at, id`).
+		WithArgs(model.StatusPublish).
+		WillReturnRows(rows)
+
+	result, err := suite.repository.GetPublishedSlugs(ctx)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 2)
+	assert.Equal(suite.T(), "generics-in-go", result[1].Slug)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostRepositoryTestSuite) TestGetAllPost_Cursor() {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	page := pagination.Page{Limit: 1}
+
+	rows := sqlmock.NewRows([]string{"id", "username", "title", "body", "status", "created_at"}).
+		AddRow("post-2", "user1", "Post 2", "Body 2", "PUBLISH", now).
+		AddRow("post-1", "user1", "Post 1", "Body 1", "PUBLISH", now.Add(-time.Minute))
+
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "posts" WHERE "posts"."deleted_at" IS NULL ORDER BY posts.created_at DESC, posts.id DESC LIMIT $1`)).
+		WithArgs(2).
+		WillReturnRows(rows)
+
+	result, meta, err := suite.repository.GetAllPost(ctx, payload.PostFilter{}, page)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 1)
+	assert.NotEmpty(suite.T(), meta.NextCursor)
+	assert.Empty(suite.T(), meta.PrevCursor)
+
+	next, err := pagination.New(meta.NextCursor, "", "1")
+	assert.NoError(suite.T(), err)
+
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "posts" WHERE (posts.created_at, posts.id) < ($1, $2) AND "posts"."deleted_at" IS NULL ORDER BY posts.created_at DESC, posts.id DESC LIMIT $3`)).
+		WithArgs(now, "post-2", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "title", "body", "status", "created_at"}).
+			AddRow("post-1", "user1", "Post 1", "Body 1", "PUBLISH", now.Add(-time.Minute)))
+
+	result, meta, err = suite.repository.GetAllPost(ctx, payload.PostFilter{}, next)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 1)
+	assert.Equal(suite.T(), strfmt.UUID4("post-1"), result[0].ID)
+	assert.Empty(suite.T(), meta.NextCursor)
+	assert.NotEmpty(suite.T(), meta.PrevCursor)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostRepositoryTestSuite) TestInsertRevision() {
+	ctx := context.Background()
+	revision := model.PostRevisionModel{PostId: "post-1", Title: "Title", Body: "Body", CreatedBy: "alice"}
+
+	suite.mock.ExpectQuery(`SELECT "id" FROM "posts" WHERE id = \$1 AND "posts"."deleted_at" IS NULL LIMIT \$2 FOR UPDATE`).
+		WithArgs("post-1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("post-1"))
+	suite.mock.ExpectQuery(`INSERT INTO post_revisions \(post_id, revision, title, body, created_by\) SELECT \$1, COALESCE\(MAX\(revision\), 0\) \+ 1, \$2, \$3, \$4 FROM post_revisions WHERE post_id = \$5 RETURNING \*`).
+		WithArgs("post-1", "Title", "Body", "alice", "post-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "post_id", "revision", "title", "body", "created_by", "created_at"}).
+			AddRow("rev-1", "post-1", 3, "Title", "Body", "alice", time.Now()))
+
+	result, err := suite.repository.InsertRevision(ctx, revision)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 3, result.Revision)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostRepositoryTestSuite) TestInsertRevision_PostNotFound() {
+	ctx := context.Background()
+	revision := model.PostRevisionModel{PostId: "post-1", Title: "Title", Body: "Body", CreatedBy: "alice"}
+
+	suite.mock.ExpectQuery(`SELECT "id" FROM "posts" WHERE id = \$1 AND "posts"."deleted_at" IS NULL LIMIT \$2 FOR UPDATE`).
+		WithArgs("post-1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := suite.repository.InsertRevision(ctx, revision)
+
+	assert.ErrorIs(suite.T(), err, gorm.ErrRecordNotFound)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostRepositoryTestSuite) TestGetRevisions() {
+	ctx := context.Background()
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "post_id", "revision", "title", "body", "created_by", "created_at"}).
+		AddRow("rev-2", "post-1", 2, "Second", "Body", "alice", now).
+		AddRow("rev-1", "post-1", 1, "First", "Body", "alice", now.Add(-time.Hour))
+
+	suite.mock.ExpectQuery(`SELECT \* FROM "post_revisions" WHERE post_id = \$1 ORDER BY post_revisions.created_at DESC, post_revisions.id DESC LIMIT \$2`).
+		WithArgs("post-1", 11).
+		WillReturnRows(rows)
+
+	result, meta, err := suite.repository.GetRevisions(ctx, "post-1", pagination.Page{Limit: 10})
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 2)
+	assert.Equal(suite.T(), 2, result[0].Revision)
+	assert.Empty(suite.T(), meta.NextCursor)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostRepositoryTestSuite) TestGetRevision_NotFound() {
+	ctx := context.Background()
+
+	suite.mock.ExpectQuery(`SELECT \* FROM "post_revisions" WHERE post_id = \$1 AND revision = \$2 ORDER BY "post_revisions"."id" LIMIT \$3`).
+		WithArgs("post-1", 4, 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	_, err := suite.repository.GetRevision(ctx, "post-1", 4)
+
+	assert.ErrorIs(suite.T(), err, gorm.ErrRecordNotFound)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostRepositoryTestSuite) TestCountComments() {
+	ctx := context.Background()
+
+	suite.mock.ExpectQuery(`SELECT post_id, COUNT\(\*\) AS total FROM "comments" WHERE post_id IN \(\$1,\$2\) AND status = \$3 AND deleted_at IS NULL GROUP BY "post_id"`).
+		WithArgs("post-1", "post-2", "approved").
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "total"}).AddRow("post-1", 4))
+
+	result, err := suite.repository.CountComments(ctx, []string{"post-1", "post-2"})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), map[string]int64{"post-1": 4}, result)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostRepositoryTestSuite) TestAnonymizeUserPosts_Success() {
+	ctx := context.Background()
+
+	rows := sqlmock.NewRows([]string{"id", "username", "title", "status"}).
+		AddRow("123e4567-e89b-12d3-a456-426614174000", "[deleted]", "Test Post", "PUBLISH")
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`UPDATE "posts" SET "username"=$1,"updated_at"=$2 WHERE username = $3 AND "posts"."deleted_at" IS NULL RETURNING *`)).
+		WithArgs("[deleted]", sqlmock.AnyArg(), "testuser").
+		WillReturnRows(rows)
+	suite.mock.ExpectCommit()
+
+	posts, err := suite.repository.AnonymizeUserPosts(ctx, "testuser", "[deleted]")
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), posts, 1)
+	assert.Equal(suite.T(), "[deleted]", posts[0].Username)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostRepositoryTestSuite) TestDeleteUserPosts_Success() {
+	ctx := context.Background()
+
+	rows := sqlmock.NewRows([]string{"id", "username", "title", "status"}).
+		AddRow("123e4567-e89b-12d3-a456-426614174000", "testuser", "Test Post", "PUBLISH")
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`UPDATE "posts" SET "deleted_at"=$1 WHERE username = $2 AND "posts"."deleted_at" IS NULL RETURNING *`)).
+		WithArgs(sqlmock.AnyArg(), "testuser").
+		WillReturnRows(rows)
+	suite.mock.ExpectCommit()
+
+	posts, err := suite.repository.DeleteUserPosts(ctx, "testuser")
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), posts, 1)
+	assert.Equal(suite.T(), strfmt.UUID4("123e4567-e89b-12d3-a456-426614174000"), posts[0].ID)
 	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
 }
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+
+	"simple-blog-system/pkg/pagination"
+	"simple-blog-system/pkg/transaction"
+
+	"simple-blog-system/internal/app/post/model"
+
+	"gorm.io/gorm/clause"
+)
+
+// InsertRevision stores the revision as the next number of its post. It locks the post row first so concurrent
+// revisions of one post wait for each other instead of taking the same number, callers run it in a transaction
+func (r repository) InsertRevision(ctx context.Context, revision model.PostRevisionModel) (res model.PostRevisionModel, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+
+	var post model.PostModel
+	err = trx.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).Select("id").Where("id = ?", revision.PostId).Take(&post).Error
+	if err != nil {
+		return res, err
+	}
+
+	err = trx.Raw(`INSERT INTO post_revisions (post_id, revision, title, body, created_by)
+		SELECT ?, COALESCE(MAX(revision), 0) + 1, ?, ?, ? FROM post_revisions WHERE post_id = ?
+		RETURNING *`,
+		revision.PostId, revision.Title, revision.Body, revision.CreatedBy, revision.PostId).
+		Scan(&res).Error
+
+	return res, err
+}
+
+func (r repository) GetRevisions(ctx context.Context, postId string, page pagination.Page) (res []model.PostRevisionModel, meta pagination.Meta, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	query := trx.Model(&model.PostRevisionModel{}).Where("post_id = ?", postId)
+
+	total, err := page.Count(query)
+	if err != nil {
+		return nil, meta, err
+	}
+
+	err = query.Scopes(page.Scope("post_revisions")).Find(&res).Error
+	if err != nil {
+		return nil, meta, err
+	}
+
+	res, meta = pagination.Paginate(page, res, total, revisionKey)
+	return res, meta, nil
+}
+
+func (r repository) GetRevision(ctx context.Context, postId string, revision int) (res *model.PostRevisionModel, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Where("post_id = ? AND revision = ?", postId, revision).First(&res).Error
+
+	return res, err
+}
+
+func revisionKey(revision model.PostRevisionModel) pagination.Key {
+	return pagination.Key{CreatedAt: revision.CreatedAt, ID: string(revision.ID)}
+}
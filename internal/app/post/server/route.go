@@ -20,4 +20,16 @@ func (r routes) New(router *gin.RouterGroup, handler port.IPostHandler) {
 	router.DELETE("/:id", handler.DeletePost)
 	router.GET("/", handler.GetAllPost)
 	router.GET("/:id", handler.GetById)
+	router.GET("/slug/:slug", handler.GetBySlug)
+	router.GET("/:id/revisions", handler.GetRevisions)
+	router.GET("/:id/revisions/diff", handler.DiffRevisions)
+	router.GET("/:id/revisions/:revision", handler.GetRevision)
+	router.POST("/:id/revisions/:revision/restore", handler.RestoreRevision)
+}
+
+// NewPublic registers the read endpoints of published posts that need no login
+func (r routes) NewPublic(router *gin.RouterGroup, handler port.IPostHandler) {
+	router.GET("/", handler.GetPublicPosts)
+	router.GET("/:id", handler.GetPublicById)
+	router.GET("/slug/:slug", handler.GetPublicBySlug)
 }
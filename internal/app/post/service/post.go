@@ -3,24 +3,67 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
+	filterModel "simple-blog-system/internal/app/filter/model"
+	filterPayload "simple-blog-system/internal/app/filter/payload"
+	filterPort "simple-blog-system/internal/app/filter/port"
 	"simple-blog-system/internal/app/post/model"
 	"simple-blog-system/internal/app/post/payload"
 	"simple-blog-system/internal/app/post/port"
+	searchModel "simple-blog-system/internal/app/search/model"
+	searchPort "simple-blog-system/internal/app/search/port"
+	sitemapPort "simple-blog-system/internal/app/sitemap/port"
+	taxonomyModel "simple-blog-system/internal/app/taxonomy/model"
+	taxonomyPort "simple-blog-system/internal/app/taxonomy/port"
+	userModel "simple-blog-system/internal/app/user/model"
 	userPort "simple-blog-system/internal/app/user/port"
+	"simple-blog-system/pkg/apperror"
+	"simple-blog-system/pkg/authorization"
+	"simple-blog-system/pkg/helper"
+	"simple-blog-system/pkg/pagination"
+	"simple-blog-system/pkg/transaction"
 
 	"github.com/go-openapi/strfmt"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	maxSlugAttempts = 50
+	// maxSlugRaces is how often a write is tried again when a concurrent post took the slug it picked
+	maxSlugRaces = 3
+	// publishBatch is how many due posts one scheduler transaction locks and publishes
+	publishBatch = 100
+	scheduledBy  = "scheduler"
 )
 
 type service struct {
-	postRepo port.IPostRepository
-	userRepo userPort.IUserRepository
+	postRepo     port.IPostRepository
+	userRepo     userPort.IUserRepository
+	taxonomyRepo taxonomyPort.ITaxonomyRepository
+	searchIndex  searchPort.ISearchIndex
+	trxHandler   transaction.ISqlTransaction
+	// sitemap is invalidated whenever published posts change
+	sitemap sitemapPort.ISitemapCache
+	// contentFilter inspects posts of users that are not moderators before they are published
+	contentFilter filterPort.IContentFilter
+	// requireVerifiedEmail keeps users without a verified email address to drafts
+	requireVerifiedEmail bool
 }
 
-func New(postRepo port.IPostRepository, userRepo userPort.IUserRepository) port.IPostService {
+func New(postRepo port.IPostRepository, userRepo userPort.IUserRepository, taxonomyRepo taxonomyPort.ITaxonomyRepository, searchIndex searchPort.ISearchIndex, sitemap sitemapPort.ISitemapCache, trxHandler transaction.ISqlTransaction, contentFilter filterPort.IContentFilter, requireVerifiedEmail bool) port.IPostService {
 	return &service{
-		postRepo: postRepo,
-		userRepo: userRepo,
+		postRepo:             postRepo,
+		userRepo:             userRepo,
+		taxonomyRepo:         taxonomyRepo,
+		searchIndex:          searchIndex,
+		sitemap:              sitemap,
+		trxHandler:           trxHandler,
+		contentFilter:        contentFilter,
+		requireVerifiedEmail: requireVerifiedEmail,
 	}
 }
 
@@ -30,18 +73,68 @@ func (s *service) AddPost(ctx context.Context, username string, param payload.Po
 		return nil, errors.New("user not found")
 	}
 
+	if !authorization.CanCreatePost(users[0].Role) {
+		return nil, authorization.ErrForbidden
+	}
+
+	if !s.canPublish(users[0], param.Status) {
+		return nil, authorization.ErrEmailNotVerified
+	}
+
 	post := model.PostModel{
-		Username:  users[0].Username,
-		Title:     param.Title,
-		Body:      param.Body,
-		Status:    param.Status,
-		CreatedBy: username,
+		Username:          users[0].Username,
+		Title:             param.Title,
+		Body:              param.Body,
+		Status:            param.Status,
+		PublishAt:         publishAt(param),
+		CommentModeration: commentModeration(param, nil),
+		CreatedBy:         username,
 	}
-	post, qerr = s.postRepo.InsertPost(ctx, post)
+	qerr = s.filterPost(ctx, users[0], post)
 	if qerr != nil {
 		return nil, qerr
 	}
 
+	qerr = s.slugTransaction(ctx, func(ctx context.Context) error {
+		slug, err := s.generateSlug(ctx, param.Title, "")
+		if err != nil {
+			return err
+		}
+		post.Slug = slug
+
+		category, err := s.resolveCategory(ctx, param.CategoryId)
+		if err != nil {
+			return err
+		}
+		if category != nil {
+			categoryId := string(category.ID)
+			post.CategoryId = &categoryId
+		}
+
+		post, err = s.postRepo.InsertPost(ctx, post)
+		if err != nil {
+			return err
+		}
+
+		err = s.addRevision(ctx, post, username)
+		if err != nil {
+			return err
+		}
+
+		post.Category = category
+		post.Tags = []taxonomyModel.TagModel{}
+		if len(param.Tags) > 0 {
+			post.Tags, err = s.setPostTags(ctx, string(post.ID), param.Tags)
+		}
+		return err
+	})
+	if qerr != nil {
+		return nil, qerr
+	}
+
+	s.indexPost(ctx, post)
+	s.postsChanged(post)
+
 	return &post, nil
 }
 
@@ -51,20 +144,91 @@ func (s *service) UpdatePost(ctx context.Context, username string, id string, pa
 		return nil, errors.New("user not found")
 	}
 
+	existing, qerr := s.postRepo.GetPostById(ctx, id)
+	if qerr != nil {
+		return nil, errors.New("post not found")
+	}
+
+	if !authorization.CanManage(users[0].Role, users[0].Username, existing.Username) {
+		return nil, authorization.ErrForbidden
+	}
+
+	if !s.canPublish(users[0], param.Status) {
+		return nil, authorization.ErrEmailNotVerified
+	}
+
 	post := model.PostModel{
-		ID:        strfmt.UUID4(id),
-		Username:  users[0].Username,
-		Title:     param.Title,
-		Body:      param.Body,
-		Status:    param.Status,
-		CreatedBy: username,
+		ID:                existing.ID,
+		Username:          existing.Username,
+		Title:             param.Title,
+		Slug:              existing.Slug,
+		Body:              param.Body,
+		Status:            param.Status,
+		PublishAt:         publishAt(param),
+		CategoryId:        existing.CategoryId,
+		CommentModeration: commentModeration(param, existing.CommentModeration),
+		CreatedBy:         existing.CreatedBy,
+		UpdatedBy:         username,
+		CreatedAt:         existing.CreatedAt,
 	}
-	post, qerr = s.postRepo.UpdatePost(ctx, post)
+	qerr = s.filterPost(ctx, users[0], post)
 	if qerr != nil {
 		return nil, qerr
 	}
 
-	return &post, nil
+	qerr = s.slugTransaction(ctx, func(ctx context.Context) error {
+		if existing.Slug == "" || helper.Slugify(param.Title) != helper.Slugify(existing.Title) {
+			slug, err := s.changeSlug(ctx, *existing, param.Title)
+			if err != nil {
+				return err
+			}
+			post.Slug = slug
+		}
+
+		if param.CategoryId != nil {
+			category, err := s.resolveCategory(ctx, param.CategoryId)
+			if err != nil {
+				return err
+			}
+			post.CategoryId = nil
+			if category != nil {
+				categoryId := string(category.ID)
+				post.CategoryId = &categoryId
+			}
+		}
+
+		var err error
+		post, err = s.postRepo.UpdatePost(ctx, post)
+		if err != nil {
+			return err
+		}
+
+		err = s.addRevision(ctx, post, username)
+		if err != nil {
+			return err
+		}
+
+		if param.Tags != nil {
+			_, err = s.setPostTags(ctx, string(post.ID), param.Tags)
+		} else if post.Status != existing.Status {
+			err = s.refreshTagCounts(ctx, string(post.ID))
+		}
+		return err
+	})
+	if qerr != nil {
+		return nil, qerr
+	}
+
+	s.indexPost(ctx, post)
+	s.postsChanged(*existing, post)
+
+	posts := []model.PostModel{post}
+	qerr = s.attachTaxonomy(ctx, posts)
+	if qerr != nil {
+		return nil, qerr
+	}
+
+	return &posts[0], nil
 }
 
 func (s *service) DeletePost(ctx context.Context, username string, id string) (res *model.PostModel, err error) {
@@ -78,26 +242,139 @@ func (s *service) DeletePost(ctx context.Context, username string, id string) (r
 		return nil, errors.New("post not found")
 	}
 
-	err = s.postRepo.DeletePost(ctx, *post)
+	if !authorization.CanManage(users[0].Role, users[0].Username, post.Username) {
+		return nil, authorization.ErrForbidden
+	}
+
+	err = s.trxHandler.Transaction(ctx, func(ctx context.Context) error {
+		postTags, err := s.taxonomyRepo.GetPostTags(ctx, []string{id})
+		if err != nil {
+			return err
+		}
+
+		err = s.postRepo.DeletePost(ctx, *post)
+		if err != nil {
+			return err
+		}
+
+		tagIds := make([]string, 0, len(postTags))
+		for _, postTag := range postTags {
+			tagIds = append(tagIds, postTag.TagId)
+		}
+		return s.taxonomyRepo.RefreshTagCounts(ctx, tagIds)
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	err = s.searchIndex.Remove(ctx, searchModel.TypePost, id)
+	if err != nil {
+		log.Error().Err(err).Str("post_id", id).Msg("failed to remove post from search index")
+	}
+	s.postsChanged(*post)
+
 	return post, nil
 }
 
-func (s *service) GetAllPost(ctx context.Context, username string, page int, limit int) (res []model.PostModel, err error) {
+// AnonymizeUserContent hands the posts of a deleted account over to placeholder
+func (s *service) AnonymizeUserContent(ctx context.Context, username string, placeholder string) (int, error) {
+	posts, err := s.postRepo.AnonymizeUserPosts(ctx, username, placeholder)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, post := range posts {
+		s.indexPost(ctx, post)
+	}
+	s.postsChanged(posts...)
+
+	return len(posts), nil
+}
+
+// DeleteUserContent deletes the posts of a deleted account like DeletePost does
+func (s *service) DeleteUserContent(ctx context.Context, username string) (int, error) {
+	var posts []model.PostModel
+	err := s.trxHandler.Transaction(ctx, func(ctx context.Context) (err error) {
+		posts, err = s.postRepo.DeleteUserPosts(ctx, username)
+		if err != nil || len(posts) == 0 {
+			return err
+		}
+
+		postIds := make([]string, 0, len(posts))
+		for _, post := range posts {
+			postIds = append(postIds, string(post.ID))
+		}
+		postTags, err := s.taxonomyRepo.GetPostTags(ctx, postIds)
+		if err != nil {
+			return err
+		}
+
+		tagIds := make([]string, 0, len(postTags))
+		for _, postTag := range postTags {
+			tagIds = append(tagIds, postTag.TagId)
+		}
+		return s.taxonomyRepo.RefreshTagCounts(ctx, tagIds)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, post := range posts {
+		err = s.searchIndex.Remove(ctx, searchModel.TypePost, string(post.ID))
+		if err != nil {
+			log.Error().Err(err).Str("post_id", string(post.ID)).Msg("failed to remove post from search index")
+		}
+	}
+	s.postsChanged(posts...)
+
+	return len(posts), nil
+}
+
+// GetAllPost lists the published posts and the drafts and scheduled posts of the user, moderators see every post
+func (s *service) GetAllPost(ctx context.Context, username string, filter payload.PostFilter, page pagination.Page) (res []model.PostModel, meta pagination.Meta, err error) {
 	users, qerr := s.userRepo.GetUserByUsername(ctx, username)
 	if len(users) == 0 || qerr != nil {
-		return nil, errors.New("user not found")
+		return nil, meta, errors.New("user not found")
+	}
+
+	return s.getAllPost(ctx, users[0], filter, page)
+}
+
+// GetPublicPosts lists the published posts for readers without a login
+func (s *service) GetPublicPosts(ctx context.Context, filter payload.PostFilter, page pagination.Page) (res []model.PostModel, meta pagination.Meta, err error) {
+	return s.getAllPost(ctx, userModel.AuthUserModel{}, filter, page)
+}
+
+func (s *service) getAllPost(ctx context.Context, viewer userModel.AuthUserModel, filter payload.PostFilter, page pagination.Page) (res []model.PostModel, meta pagination.Meta, err error) {
+	filter.Tag = helper.Slugify(filter.Tag)
+	filter.Category = helper.Slugify(filter.Category)
+	filter.PublishedOnly, filter.Owner = visibleTo(viewer)
+
+	// cursors follow created_at, any other order pages by offset
+	if filter.Sort != "" {
+		page = page.AsOffset()
 	}
 
-	post, err := s.postRepo.GetAllPost(ctx, page, limit)
+	post, meta, err := s.postRepo.GetAllPost(ctx, filter, page)
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		return nil, meta, err
+	}
 	if err != nil {
-		return nil, errors.New("post not found")
+		return nil, meta, errors.New("post not found")
 	}
 
-	return post, nil
+	err = s.attachTaxonomy(ctx, post)
+	if err != nil {
+		return nil, meta, err
+	}
+
+	err = s.attachCommentCounts(ctx, post)
+	if err != nil {
+		return nil, meta, err
+	}
+
+	return post, meta, nil
 }
 
 func (s *service) GetById(ctx context.Context, username string, id string) (res *model.PostModel, err error) {
@@ -106,10 +383,523 @@ func (s *service) GetById(ctx context.Context, username string, id string) (res
 		return nil, errors.New("user not found")
 	}
 
+	return s.getById(ctx, users[0], id)
+}
+
+// GetPublicById returns a published post, other posts answer as not found
+func (s *service) GetPublicById(ctx context.Context, id string) (res *model.PostModel, err error) {
+	return s.getById(ctx, userModel.AuthUserModel{}, id)
+}
+
+func (s *service) getById(ctx context.Context, viewer userModel.AuthUserModel, id string) (res *model.PostModel, err error) {
 	post, err := s.postRepo.GetPostById(ctx, id)
+	if err != nil || !canView(viewer, *post) {
+		return nil, errors.New("post not found")
+	}
+
+	posts := []model.PostModel{*post}
+	err = s.attachTaxonomy(ctx, posts)
 	if err != nil {
+		return nil, err
+	}
+
+	err = s.attachCommentCounts(ctx, posts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &posts[0], nil
+}
+
+func (s *service) GetBySlug(ctx context.Context, username string, slug string) (res *payload.PostSlugResponse, err error) {
+	users, qerr := s.userRepo.GetUserByUsername(ctx, username)
+	if len(users) == 0 || qerr != nil {
+		return nil, errors.New("user not found")
+	}
+
+	return s.getBySlug(ctx, users[0], slug)
+}
+
+// GetPublicBySlug resolves the slug of a published post, other posts answer as not found
+func (s *service) GetPublicBySlug(ctx context.Context, slug string) (res *payload.PostSlugResponse, err error) {
+	return s.getBySlug(ctx, userModel.AuthUserModel{}, slug)
+}
+
+func (s *service) getBySlug(ctx context.Context, viewer userModel.AuthUserModel, slug string) (res *payload.PostSlugResponse, err error) {
+	post, qerr := s.postRepo.GetPostBySlug(ctx, slug)
+	if qerr == nil {
+		if !canView(viewer, *post) {
+			return nil, errors.New("post not found")
+		}
+
+		return &payload.PostSlugResponse{
+			Post:          post,
+			CanonicalSlug: post.Slug,
+		}, nil
+	}
+
+	history, qerr := s.postRepo.GetSlugHistory(ctx, slug)
+	if qerr != nil {
+		return nil, errors.New("post not found")
+	}
+
+	post, qerr = s.postRepo.GetPostById(ctx, history.PostId)
+	if qerr != nil || !canView(viewer, *post) {
 		return nil, errors.New("post not found")
 	}
 
+	return &payload.PostSlugResponse{
+		Post:          post,
+		CanonicalSlug: post.Slug,
+		Redirect:      true,
+	}, nil
+}
+
+// PublishDuePosts flips scheduled posts whose publish_at has passed to PUBLISH, every batch in its own transaction
+func (s *service) PublishDuePosts(ctx context.Context) (published int, err error) {
+	for {
+		var posts []model.PostModel
+
+		err = s.trxHandler.Transaction(ctx, func(ctx context.Context) error {
+			due, err := s.postRepo.GetDueScheduledPosts(ctx, time.Now(), publishBatch)
+			if err != nil {
+				return err
+			}
+
+			postIds := make([]string, 0, len(due))
+			for i := range due {
+				due[i].Status = model.StatusPublish
+				due[i].UpdatedBy = scheduledBy
+				due[i], err = s.postRepo.UpdatePost(ctx, due[i])
+				if err != nil {
+					return err
+				}
+				postIds = append(postIds, string(due[i].ID))
+			}
+
+			posts = due
+			return s.refreshTagCounts(ctx, postIds...)
+		})
+		if err != nil {
+			return published, err
+		}
+
+		for _, post := range posts {
+			s.indexPost(ctx, post)
+		}
+		s.postsChanged(posts...)
+
+		published += len(posts)
+		if len(posts) < publishBatch {
+			return published, nil
+		}
+	}
+}
+
+func (s *service) GetRevisions(ctx context.Context, username string, id string, page pagination.Page) (res []model.PostRevisionModel, meta pagination.Meta, err error) {
+	_, err = s.managedPost(ctx, username, id)
+	if err != nil {
+		return nil, meta, err
+	}
+
+	return s.postRepo.GetRevisions(ctx, id, page)
+}
+
+func (s *service) GetRevision(ctx context.Context, username string, id string, revision int) (res *model.PostRevisionModel, err error) {
+	_, err = s.managedPost(ctx, username, id)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err = s.postRepo.GetRevision(ctx, id, revision)
+	if err != nil {
+		return nil, errors.New("revision not found")
+	}
+
+	return res, nil
+}
+
+func (s *service) DiffRevisions(ctx context.Context, username string, id string, from int, to int) (res *payload.RevisionDiffResponse, err error) {
+	_, err = s.managedPost(ctx, username, id)
+	if err != nil {
+		return nil, err
+	}
+
+	fromRevision, err := s.postRepo.GetRevision(ctx, id, from)
+	if err != nil {
+		return nil, fmt.Errorf("revision %d not found", from)
+	}
+
+	toRevision, err := s.postRepo.GetRevision(ctx, id, to)
+	if err != nil {
+		return nil, fmt.Errorf("revision %d not found", to)
+	}
+
+	return &payload.RevisionDiffResponse{
+		From:  fromRevision,
+		To:    toRevision,
+		Title: helper.DiffLines(fromRevision.Title, toRevision.Title),
+		Body:  helper.DiffLines(fromRevision.Body, toRevision.Body),
+	}, nil
+}
+
+// RestoreRevision updates the post with the title and body of an older revision, which is recorded as a new revision
+func (s *service) RestoreRevision(ctx context.Context, username string, id string, revision int) (res *model.PostModel, err error) {
+	post, err := s.managedPost(ctx, username, id)
+	if err != nil {
+		return nil, err
+	}
+
+	old, err := s.postRepo.GetRevision(ctx, id, revision)
+	if err != nil {
+		return nil, errors.New("revision not found")
+	}
+
+	param := payload.PostRequest{
+		Title:  old.Title,
+		Body:   old.Body,
+		Status: post.Status,
+	}
+	if post.PublishAt != nil {
+		publishAt := strfmt.DateTime(*post.PublishAt)
+		param.PublishAt = &publishAt
+	}
+
+	return s.UpdatePost(ctx, username, id, param)
+}
+
+// managedPost returns the post when the user may manage it
+func (s *service) managedPost(ctx context.Context, username string, id string) (*model.PostModel, error) {
+	users, err := s.userRepo.GetUserByUsername(ctx, username)
+	if len(users) == 0 || err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	post, err := s.postRepo.GetPostById(ctx, id)
+	if err != nil {
+		return nil, errors.New("post not found")
+	}
+
+	if !authorization.CanManage(users[0].Role, users[0].Username, post.Username) {
+		return nil, authorization.ErrForbidden
+	}
+
 	return post, nil
 }
+
+func (s *service) addRevision(ctx context.Context, post model.PostModel, username string) error {
+	_, err := s.postRepo.InsertRevision(ctx, model.PostRevisionModel{
+		PostId:    string(post.ID),
+		Title:     post.Title,
+		Body:      post.Body,
+		CreatedBy: username,
+	})
+
+	return err
+}
+
+// slugTransaction runs fn in a transaction and runs it again when a concurrent post took the slug fn picked,
+// the next run sees that post and picks the slug with the next suffix
+func (s *service) slugTransaction(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	for range maxSlugRaces {
+		err = s.trxHandler.Transaction(ctx, fn)
+		if !errors.Is(err, port.ErrSlugTaken) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// generateSlug builds a slug from title, adding a numeric suffix while it collides with another post
+func (s *service) generateSlug(ctx context.Context, title string, postId string) (string, error) {
+	base := helper.Slugify(title)
+	if base == "" {
+		base = "post"
+	}
+
+	slug := base
+	for i := 2; i <= maxSlugAttempts; i++ {
+		taken, err := s.postRepo.IsSlugTaken(ctx, slug, postId)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return slug, nil
+		}
+		slug = fmt.Sprintf("%s-%d", base, i)
+	}
+
+	return fmt.Sprintf("%s-%s", base, uuid.New().String()[:8]), nil
+}
+
+// changeSlug gives post a slug for newTitle and keeps the current one in the history so it still resolves
+func (s *service) changeSlug(ctx context.Context, post model.PostModel, newTitle string) (string, error) {
+	slug, err := s.generateSlug(ctx, newTitle, string(post.ID))
+	if err != nil || slug == post.Slug {
+		return slug, err
+	}
+
+	// the post may get back one of its own previous slugs
+	err = s.postRepo.DeleteSlugHistory(ctx, string(post.ID), slug)
+	if err != nil {
+		return "", err
+	}
+
+	if post.Slug != "" {
+		_, err = s.postRepo.InsertSlugHistory(ctx, model.PostSlugHistoryModel{
+			PostId: string(post.ID),
+			Slug:   post.Slug,
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return slug, nil
+}
+
+// resolveCategory returns the category for id, nil when no category is requested
+func (s *service) resolveCategory(ctx context.Context, id *string) (*taxonomyModel.CategoryModel, error) {
+	if id == nil || *id == "" {
+		return nil, nil
+	}
+
+	category, err := s.taxonomyRepo.GetCategoryById(ctx, *id)
+	if err != nil {
+		return nil, errors.New("category not found")
+	}
+
+	return category, nil
+}
+
+// setPostTags replaces the tags of a post, creating unknown tags and keeping the tag counts in sync
+func (s *service) setPostTags(ctx context.Context, postId string, names []string) ([]taxonomyModel.TagModel, error) {
+	oldTags, err := s.taxonomyRepo.GetPostTags(ctx, []string{postId})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	wanted := make([]taxonomyModel.TagModel, 0, len(names))
+	for _, name := range names {
+		slug := helper.Slugify(name)
+		if slug == "" || seen[slug] {
+			continue
+		}
+		seen[slug] = true
+		wanted = append(wanted, taxonomyModel.TagModel{Name: strings.TrimSpace(name), Slug: slug})
+	}
+
+	tags, err := s.taxonomyRepo.FindOrCreateTags(ctx, wanted)
+	if err != nil {
+		return nil, err
+	}
+
+	tagIds := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tagIds = append(tagIds, string(tag.ID))
+	}
+
+	err = s.taxonomyRepo.ReplacePostTags(ctx, postId, tagIds)
+	if err != nil {
+		return nil, err
+	}
+
+	touched := tagIds
+	for _, postTag := range oldTags {
+		if !seen[postTag.Tag.Slug] {
+			touched = append(touched, postTag.TagId)
+		}
+	}
+
+	err = s.taxonomyRepo.RefreshTagCounts(ctx, touched)
+	if err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// refreshTagCounts recounts the tags of posts whose status changed, only published posts count
+func (s *service) refreshTagCounts(ctx context.Context, postIds ...string) error {
+	if len(postIds) == 0 {
+		return nil
+	}
+
+	postTags, err := s.taxonomyRepo.GetPostTags(ctx, postIds)
+	if err != nil {
+		return err
+	}
+
+	tagIds := make([]string, 0, len(postTags))
+	for _, postTag := range postTags {
+		tagIds = append(tagIds, postTag.TagId)
+	}
+
+	return s.taxonomyRepo.RefreshTagCounts(ctx, tagIds)
+}
+
+// attachCommentCounts fills the comment count of the given posts
+func (s *service) attachCommentCounts(ctx context.Context, posts []model.PostModel) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	postIds := make([]string, 0, len(posts))
+	for _, post := range posts {
+		postIds = append(postIds, string(post.ID))
+	}
+
+	counts, err := s.postRepo.CountComments(ctx, postIds)
+	if err != nil {
+		return err
+	}
+
+	for i := range posts {
+		posts[i].CommentCount = counts[string(posts[i].ID)]
+	}
+
+	return nil
+}
+
+// attachTaxonomy fills the tags and category of the given posts
+func (s *service) attachTaxonomy(ctx context.Context, posts []model.PostModel) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	postIds := make([]string, 0, len(posts))
+	categoryIds := []string{}
+	for _, post := range posts {
+		postIds = append(postIds, string(post.ID))
+		if post.CategoryId != nil {
+			categoryIds = append(categoryIds, *post.CategoryId)
+		}
+	}
+
+	postTags, err := s.taxonomyRepo.GetPostTags(ctx, postIds)
+	if err != nil {
+		return err
+	}
+
+	categories, err := s.taxonomyRepo.GetCategoriesByIds(ctx, categoryIds)
+	if err != nil {
+		return err
+	}
+
+	tagsByPost := map[string][]taxonomyModel.TagModel{}
+	for _, postTag := range postTags {
+		tagsByPost[postTag.PostId] = append(tagsByPost[postTag.PostId], postTag.Tag)
+	}
+
+	categoryById := map[string]*taxonomyModel.CategoryModel{}
+	for i := range categories {
+		categoryById[string(categories[i].ID)] = &categories[i]
+	}
+
+	for i := range posts {
+		posts[i].Tags = tagsByPost[string(posts[i].ID)]
+		if posts[i].Tags == nil {
+			posts[i].Tags = []taxonomyModel.TagModel{}
+		}
+		if posts[i].CategoryId != nil {
+			posts[i].Category = categoryById[*posts[i].CategoryId]
+		}
+	}
+
+	return nil
+}
+
+// indexPost updates the search index, the post is already saved so a failure is only logged
+func (s *service) indexPost(ctx context.Context, post model.PostModel) {
+	err := s.searchIndex.Index(ctx, searchModel.NewPostDocument(post))
+	if err != nil {
+		log.Error().Err(err).Str("post_id", string(post.ID)).Msg("failed to index post")
+	}
+}
+
+// postsChanged invalidates the sitemap when one of posts is or was published
+func (s *service) postsChanged(posts ...model.PostModel) {
+	for _, post := range posts {
+		if post.Status == model.StatusPublish {
+			s.sitemap.Invalidate()
+			return
+		}
+	}
+}
+
+// canPublish tells whether user may save a post with status, when verification is required users without
+// a verified email address only keep drafts, moderators are exempt
+func (s *service) canPublish(user userModel.AuthUserModel, status string) bool {
+	return !s.requireVerifiedEmail || status == model.StatusDraft || authorization.IsModerator(user.Role) || user.EmailVerified()
+}
+
+// filterPost runs the content filters on posts of users that are not moderators, a post the filters do not allow
+// is answered with the reason instead of being published or scheduled, drafts are not checked
+func (s *service) filterPost(ctx context.Context, user userModel.AuthUserModel, post model.PostModel) error {
+	if authorization.IsModerator(user.Role) || post.Status == model.StatusDraft {
+		return nil
+	}
+
+	verdict, err := s.contentFilter.Check(ctx, filterPayload.Content{
+		ID:       string(post.ID),
+		Type:     filterPayload.TypePost,
+		Username: post.Username,
+		Title:    post.Title,
+		Text:     post.Body,
+	})
+	if err != nil {
+		log.Error().Err(err).Str("username", post.Username).Msg("content filter failed on post")
+		return errors.New("post could not be checked by the content filters")
+	}
+	if verdict.Action == filterModel.ActionAllow {
+		return nil
+	}
+
+	log.Info().Str("username", post.Username).Str("filter", verdict.Filter).Str("reason", verdict.Reason).Str("action", verdict.Action).Msg("post held back by content filter")
+	message := "post can not be published, the content filters do not allow it"
+	if verdict.Reason != "" {
+		message += ": " + verdict.Reason
+	}
+	return apperror.UnprocessableEntity(message)
+}
+
+// visibleTo tells whether viewer is kept to published posts and whose posts it sees in every state besides,
+// the zero user is a reader without a login
+func visibleTo(viewer userModel.AuthUserModel) (publishedOnly bool, owner string) {
+	if authorization.IsModerator(viewer.Role) {
+		return false, ""
+	}
+
+	return true, viewer.Username
+}
+
+// canView tells whether viewer may read post, drafts and scheduled posts are left to their author and moderators
+func canView(viewer userModel.AuthUserModel, post model.PostModel) bool {
+	return post.Status == model.StatusPublish || authorization.CanManage(viewer.Role, viewer.Username, post.Username)
+}
+
+// publishAt keeps the publish time only for scheduled posts
+func publishAt(param payload.PostRequest) *time.Time {
+	if param.Status != model.StatusScheduled || param.PublishAt == nil {
+		return nil
+	}
+
+	at := time.Time(*param.PublishAt)
+	return &at
+}
+
+// commentModeration resolves the moderation mode of a post, an empty mode clears it so the global mode applies
+func commentModeration(param payload.PostRequest, current *string) *string {
+	if param.CommentModeration == nil {
+		return current
+	}
+
+	if *param.CommentModeration == "" {
+		return nil
+	}
+
+	mode := *param.CommentModeration
+	return &mode
+}
@@ -2,13 +2,26 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"net/http"
 	"testing"
 	"time"
 
+	filterModel "simple-blog-system/internal/app/filter/model"
+	filterPayload "simple-blog-system/internal/app/filter/payload"
+	filterService "simple-blog-system/internal/app/filter/service"
 	"simple-blog-system/internal/app/post/model"
 	"simple-blog-system/internal/app/post/payload"
+	"simple-blog-system/internal/app/post/port"
+	searchPayload "simple-blog-system/internal/app/search/payload"
+	searchRepository "simple-blog-system/internal/app/search/repository"
+	taxonomyModel "simple-blog-system/internal/app/taxonomy/model"
 	userModel "simple-blog-system/internal/app/user/model"
+	"simple-blog-system/pkg/apperror"
+	"simple-blog-system/pkg/authorization"
+	"simple-blog-system/pkg/helper"
+	"simple-blog-system/pkg/pagination"
 
 	"github.com/go-openapi/strfmt"
 	"github.com/stretchr/testify/assert"
@@ -45,8 +58,100 @@ func (m *MockPostRepository) DeletePost(ctx context.Context, post model.PostMode
 	return args.Error(0)
 }
 
-func (m *MockPostRepository) GetAllPost(ctx context.Context, page int, limit int) ([]model.PostModel, error) {
-	args := m.Called(ctx, page, limit)
+func (m *MockPostRepository) GetAllPost(ctx context.Context, filter payload.PostFilter, page pagination.Page) ([]model.PostModel, pagination.Meta, error) {
+	args := m.Called(ctx, filter, page)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(pagination.Meta), args.Error(2)
+	}
+	return args.Get(0).([]model.PostModel), args.Get(1).(pagination.Meta), args.Error(2)
+}
+
+func (m *MockPostRepository) GetPublishedSlugs(ctx context.Context) ([]model.PostModel, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.PostModel), args.Error(1)
+}
+
+func (m *MockPostRepository) GetDueScheduledPosts(ctx context.Context, now time.Time, limit int) ([]model.PostModel, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.PostModel), args.Error(1)
+}
+
+func (m *MockPostRepository) InsertRevision(ctx context.Context, revision model.PostRevisionModel) (model.PostRevisionModel, error) {
+	args := m.Called(ctx, revision)
+	return args.Get(0).(model.PostRevisionModel), args.Error(1)
+}
+
+func (m *MockPostRepository) GetRevisions(ctx context.Context, postId string, page pagination.Page) ([]model.PostRevisionModel, pagination.Meta, error) {
+	args := m.Called(ctx, postId, page)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(pagination.Meta), args.Error(2)
+	}
+	return args.Get(0).([]model.PostRevisionModel), args.Get(1).(pagination.Meta), args.Error(2)
+}
+
+func (m *MockPostRepository) GetRevision(ctx context.Context, postId string, revision int) (*model.PostRevisionModel, error) {
+	args := m.Called(ctx, postId, revision)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PostRevisionModel), args.Error(1)
+}
+
+func (m *MockPostRepository) CountComments(ctx context.Context, postIds []string) (map[string]int64, error) {
+	args := m.Called(ctx, postIds)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+
+func (m *MockPostRepository) GetPostBySlug(ctx context.Context, slug string) (*model.PostModel, error) {
+	args := m.Called(ctx, slug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PostModel), args.Error(1)
+}
+
+func (m *MockPostRepository) IsSlugTaken(ctx context.Context, slug string, excludePostId string) (bool, error) {
+	args := m.Called(ctx, slug, excludePostId)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPostRepository) GetSlugHistory(ctx context.Context, slug string) (*model.PostSlugHistoryModel, error) {
+	args := m.Called(ctx, slug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PostSlugHistoryModel), args.Error(1)
+}
+
+func (m *MockPostRepository) InsertSlugHistory(ctx context.Context, history model.PostSlugHistoryModel) (model.PostSlugHistoryModel, error) {
+	args := m.Called(ctx, history)
+	return args.Get(0).(model.PostSlugHistoryModel), args.Error(1)
+}
+
+func (m *MockPostRepository) DeleteSlugHistory(ctx context.Context, postId string, slug string) error {
+	args := m.Called(ctx, postId, slug)
+	return args.Error(0)
+}
+
+func (m *MockPostRepository) AnonymizeUserPosts(ctx context.Context, username string, placeholder string) ([]model.PostModel, error) {
+	args := m.Called(ctx, username, placeholder)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.PostModel), args.Error(1)
+}
+
+func (m *MockPostRepository) DeleteUserPosts(ctx context.Context, username string) ([]model.PostModel, error) {
+	args := m.Called(ctx, username)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -84,21 +189,183 @@ func (m *MockUserRepository) UpdateLastLogin(ctx context.Context, user userModel
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) UpdateRole(ctx context.Context, username string, role string) error {
+	args := m.Called(ctx, username, role)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdatePassword(ctx context.Context, username string, password string) error {
+	args := m.Called(ctx, username, password)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateEmail(ctx context.Context, username string, email string) error {
+	args := m.Called(ctx, username, email)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) VerifyEmail(ctx context.Context, username string, email string) (bool, error) {
+	args := m.Called(ctx, username, email)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) IsUsernameTaken(ctx context.Context, username string) (bool, error) {
+	args := m.Called(ctx, username)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) SuspendUser(ctx context.Context, username string, reason string, until *time.Time) error {
+	args := m.Called(ctx, username, reason, until)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ReactivateUser(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ScheduleDeletion(ctx context.Context, username string, deleteAfter *time.Time) error {
+	args := m.Called(ctx, username, deleteAfter)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetUsersDueForDeletion(ctx context.Context, now time.Time, limit int) ([]userModel.AuthUserModel, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]userModel.AuthUserModel), args.Error(1)
+}
+
+func (m *MockUserRepository) DeleteUser(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetUserByEmail(ctx context.Context, email string) ([]userModel.AuthUserModel, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]userModel.AuthUserModel), args.Error(1)
+}
+
+// Mock for ITaxonomyRepository
+type MockTaxonomyRepository struct {
+	mock.Mock
+}
+
+func (m *MockTaxonomyRepository) GetAllTag(ctx context.Context) ([]taxonomyModel.TagModel, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]taxonomyModel.TagModel), args.Error(1)
+}
+
+func (m *MockTaxonomyRepository) FindOrCreateTags(ctx context.Context, tags []taxonomyModel.TagModel) ([]taxonomyModel.TagModel, error) {
+	args := m.Called(ctx, tags)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]taxonomyModel.TagModel), args.Error(1)
+}
+
+func (m *MockTaxonomyRepository) GetPostTags(ctx context.Context, postIds []string) ([]taxonomyModel.PostTagModel, error) {
+	args := m.Called(ctx, postIds)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]taxonomyModel.PostTagModel), args.Error(1)
+}
+
+func (m *MockTaxonomyRepository) ReplacePostTags(ctx context.Context, postId string, tagIds []string) error {
+	args := m.Called(ctx, postId, tagIds)
+	return args.Error(0)
+}
+
+func (m *MockTaxonomyRepository) RefreshTagCounts(ctx context.Context, tagIds []string) error {
+	args := m.Called(ctx, tagIds)
+	return args.Error(0)
+}
+
+func (m *MockTaxonomyRepository) GetAllCategory(ctx context.Context) ([]taxonomyModel.CategoryModel, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]taxonomyModel.CategoryModel), args.Error(1)
+}
+
+func (m *MockTaxonomyRepository) GetCategoryById(ctx context.Context, id string) (*taxonomyModel.CategoryModel, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*taxonomyModel.CategoryModel), args.Error(1)
+}
+
+func (m *MockTaxonomyRepository) GetCategoriesByIds(ctx context.Context, ids []string) ([]taxonomyModel.CategoryModel, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]taxonomyModel.CategoryModel), args.Error(1)
+}
+
+func (m *MockTaxonomyRepository) InsertCategory(ctx context.Context, category taxonomyModel.CategoryModel) (taxonomyModel.CategoryModel, error) {
+	args := m.Called(ctx, category)
+	return args.Get(0).(taxonomyModel.CategoryModel), args.Error(1)
+}
+
+func (m *MockTaxonomyRepository) CountPostsByCategory(ctx context.Context) (map[string]int64, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+
+// Mock for ISqlTransaction, runs the callback without a real transaction
+type MockTransaction struct{}
+
+func (m MockTransaction) Transaction(c context.Context, fn func(wrappedCtx context.Context) error, opts ...*sql.TxOptions) error {
+	return fn(c)
+}
+
+// Fake for ISitemapCache, counts the invalidations
+type FakeSitemapCache struct {
+	invalidated int
+}
+
+func (f *FakeSitemapCache) Invalidate() {
+	f.invalidated++
+}
+
 // Test Suite
 type PostServiceTestSuite struct {
 	suite.Suite
-	service  *service
-	postRepo *MockPostRepository
-	userRepo *MockUserRepository
-	ctx      context.Context
+	service      *service
+	postRepo     *MockPostRepository
+	userRepo     *MockUserRepository
+	taxonomyRepo *MockTaxonomyRepository
+	sitemap      *FakeSitemapCache
+	ctx          context.Context
 }
 
 func (suite *PostServiceTestSuite) SetupTest() {
 	suite.postRepo = new(MockPostRepository)
 	suite.userRepo = new(MockUserRepository)
+	suite.taxonomyRepo = new(MockTaxonomyRepository)
+	suite.sitemap = new(FakeSitemapCache)
 	suite.service = &service{
-		postRepo: suite.postRepo,
-		userRepo: suite.userRepo,
+		postRepo:      suite.postRepo,
+		userRepo:      suite.userRepo,
+		taxonomyRepo:  suite.taxonomyRepo,
+		searchIndex:   searchRepository.NewMemoryIndex(),
+		sitemap:       suite.sitemap,
+		trxHandler:    MockTransaction{},
+		contentFilter: filterService.New(),
 	}
 	suite.ctx = context.Background()
 }
@@ -120,6 +387,7 @@ func (suite *PostServiceTestSuite) TestAddPost_Success() {
 	user := userModel.AuthUserModel{
 		ID:       strfmt.UUID4("user-123"),
 		Username: username,
+		Role:     authorization.RoleAuthor,
 	}
 
 	post := model.PostModel{
@@ -133,9 +401,11 @@ func (suite *PostServiceTestSuite) TestAddPost_Success() {
 	}
 
 	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("IsSlugTaken", suite.ctx, "test-post", "").Return(false, nil)
 	suite.postRepo.On("InsertPost", suite.ctx, mock.MatchedBy(func(p model.PostModel) bool {
-		return p.Username == username && p.Title == param.Title && p.Body == param.Body && p.Status == param.Status
+		return p.Username == username && p.Title == param.Title && p.Slug == "test-post" && p.Body == param.Body && p.Status == param.Status
 	})).Return(post, nil)
+	suite.postRepo.On("InsertRevision", suite.ctx, mock.AnythingOfType("model.PostRevisionModel")).Return(model.PostRevisionModel{Revision: 1}, nil)
 
 	result, err := suite.service.AddPost(suite.ctx, username, param)
 
@@ -143,6 +413,11 @@ func (suite *PostServiceTestSuite) TestAddPost_Success() {
 	assert.NotNil(suite.T(), result)
 	assert.Equal(suite.T(), post.Title, result.Title)
 	assert.Equal(suite.T(), post.Body, result.Body)
+
+	found, _, err := suite.service.searchIndex.Search(suite.ctx, searchPayload.SearchRequest{Query: "test post"}, pagination.Page{Limit: 10})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), found, 1)
+	assert.Equal(suite.T(), 1, suite.sitemap.invalidated)
 	suite.postRepo.AssertExpectations(suite.T())
 	suite.userRepo.AssertExpectations(suite.T())
 }
@@ -194,9 +469,11 @@ func (suite *PostServiceTestSuite) TestAddPost_InsertPostError() {
 	user := userModel.AuthUserModel{
 		ID:       strfmt.UUID4("user-123"),
 		Username: username,
+		Role:     authorization.RoleAuthor,
 	}
 
 	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("IsSlugTaken", suite.ctx, "test-post", "").Return(false, nil)
 	suite.postRepo.On("InsertPost", suite.ctx, mock.Anything).Return(model.PostModel{}, errors.New("insert error"))
 
 	result, err := suite.service.AddPost(suite.ctx, username, param)
@@ -208,6 +485,30 @@ func (suite *PostServiceTestSuite) TestAddPost_InsertPostError() {
 	suite.postRepo.AssertExpectations(suite.T())
 }
 
+func (suite *PostServiceTestSuite) TestAddPost_ReaderForbidden() {
+	username := "reader"
+	param := payload.PostRequest{
+		Title:  "Test Post",
+		Body:   "This is a test post body",
+		Status: "PUBLISH",
+	}
+
+	user := userModel.AuthUserModel{
+		ID:       strfmt.UUID4("user-123"),
+		Username: username,
+		Role:     authorization.RoleReader,
+	}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+
+	result, err := suite.service.AddPost(suite.ctx, username, param)
+
+	assert.ErrorIs(suite.T(), err, authorization.ErrForbidden)
+	assert.Nil(suite.T(), result)
+	suite.userRepo.AssertExpectations(suite.T())
+	suite.postRepo.AssertNotCalled(suite.T(), "InsertPost", mock.Anything, mock.Anything)
+}
+
 func (suite *PostServiceTestSuite) TestUpdatePost_Success() {
 	username := "testuser"
 	postID := "post-123"
@@ -222,6 +523,17 @@ func (suite *PostServiceTestSuite) TestUpdatePost_Success() {
 	user := userModel.AuthUserModel{
 		ID:       strfmt.UUID4("user-123"),
 		Username: username,
+		Role:     authorization.RoleAuthor,
+	}
+
+	existing := model.PostModel{
+		ID:        strfmt.UUID4(postID),
+		Username:  username,
+		Title:     "Test Post",
+		Slug:      "test-post",
+		Body:      "Test Body",
+		Status:    "PUBLISH",
+		CreatedBy: username,
 	}
 
 	post := model.PostModel{
@@ -235,9 +547,18 @@ func (suite *PostServiceTestSuite) TestUpdatePost_Success() {
 	}
 
 	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, postID).Return(&existing, nil)
+	suite.postRepo.On("IsSlugTaken", suite.ctx, "updated-post", postID).Return(false, nil)
+	suite.postRepo.On("DeleteSlugHistory", suite.ctx, postID, "updated-post").Return(nil)
+	suite.postRepo.On("InsertSlugHistory", suite.ctx, model.PostSlugHistoryModel{PostId: postID, Slug: "test-post"}).Return(model.PostSlugHistoryModel{}, nil)
 	suite.postRepo.On("UpdatePost", suite.ctx, mock.MatchedBy(func(p model.PostModel) bool {
-		return p.Username == username && p.Title == param.Title && p.Body == param.Body && p.Status == param.Status
+		return p.Username == username && p.Title == param.Title && p.Slug == "updated-post" && p.Body == param.Body && p.Status == param.Status && p.UpdatedBy == username
 	})).Return(post, nil)
+	suite.taxonomyRepo.On("GetPostTags", suite.ctx, []string{postID}).Return([]taxonomyModel.PostTagModel{}, nil)
+	suite.taxonomyRepo.On("GetCategoriesByIds", suite.ctx, []string{}).Return([]taxonomyModel.CategoryModel{}, nil)
+	// the post goes from draft to published, so its tags are recounted
+	suite.taxonomyRepo.On("RefreshTagCounts", suite.ctx, []string{}).Return(nil)
+	suite.postRepo.On("InsertRevision", suite.ctx, model.PostRevisionModel{PostId: postID, Title: param.Title, Body: param.Body, CreatedBy: username}).Return(model.PostRevisionModel{Revision: 2}, nil)
 
 	result, err := suite.service.UpdatePost(suite.ctx, username, postID, param)
 
@@ -245,10 +566,86 @@ func (suite *PostServiceTestSuite) TestUpdatePost_Success() {
 	assert.NotNil(suite.T(), result)
 	assert.Equal(suite.T(), post.Title, result.Title)
 	assert.Equal(suite.T(), post.Body, result.Body)
+	// the post went back to a draft, so it leaves the sitemap
+	assert.Equal(suite.T(), 1, suite.sitemap.invalidated)
+	suite.postRepo.AssertExpectations(suite.T())
+	suite.userRepo.AssertExpectations(suite.T())
+}
+
+func (suite *PostServiceTestSuite) TestUpdatePost_EditorKeepsOwner() {
+	username := "editor"
+	postID := "post-123"
+
+	param := payload.PostRequest{
+		Title:  "Test Post!",
+		Body:   "Edited body",
+		Status: "PUBLISH",
+	}
+
+	user := userModel.AuthUserModel{
+		ID:       strfmt.UUID4("user-456"),
+		Username: username,
+		Role:     authorization.RoleEditor,
+	}
+
+	existing := model.PostModel{
+		ID:        strfmt.UUID4(postID),
+		Username:  "owner",
+		Title:     "Test Post",
+		Slug:      "test-post",
+		Body:      "Test Body",
+		Status:    "DRAFT",
+		CreatedBy: "owner",
+	}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, postID).Return(&existing, nil)
+	suite.postRepo.On("UpdatePost", suite.ctx, mock.MatchedBy(func(p model.PostModel) bool {
+		return p.Username == "owner" && p.CreatedBy == "owner" && p.UpdatedBy == username && p.Slug == "test-post"
+	})).Return(existing, nil)
+	suite.taxonomyRepo.On("GetPostTags", suite.ctx, []string{postID}).Return([]taxonomyModel.PostTagModel{}, nil)
+	suite.taxonomyRepo.On("GetCategoriesByIds", suite.ctx, []string{}).Return([]taxonomyModel.CategoryModel{}, nil)
+	suite.postRepo.On("InsertRevision", suite.ctx, mock.AnythingOfType("model.PostRevisionModel")).Return(model.PostRevisionModel{Revision: 1}, nil)
+
+	result, err := suite.service.UpdatePost(suite.ctx, username, postID, param)
+
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), result)
 	suite.postRepo.AssertExpectations(suite.T())
 	suite.userRepo.AssertExpectations(suite.T())
 }
 
+func (suite *PostServiceTestSuite) TestUpdatePost_NotOwnerForbidden() {
+	username := "testuser"
+	postID := "post-123"
+
+	param := payload.PostRequest{
+		Title:  "Updated Post",
+		Body:   "This is an updated post body",
+		Status: "DRAFT",
+	}
+
+	user := userModel.AuthUserModel{
+		ID:       strfmt.UUID4("user-123"),
+		Username: username,
+		Role:     authorization.RoleAuthor,
+	}
+
+	existing := model.PostModel{
+		ID:       strfmt.UUID4(postID),
+		Username: "someoneelse",
+	}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, postID).Return(&existing, nil)
+
+	result, err := suite.service.UpdatePost(suite.ctx, username, postID, param)
+
+	assert.ErrorIs(suite.T(), err, authorization.ErrForbidden)
+	assert.Nil(suite.T(), result)
+	suite.postRepo.AssertNotCalled(suite.T(), "UpdatePost", mock.Anything, mock.Anything)
+}
+
 func (suite *PostServiceTestSuite) TestUpdatePost_UserNotFound() {
 	username := "nonexistent"
 	postID := "post-123"
@@ -268,9 +665,9 @@ func (suite *PostServiceTestSuite) TestUpdatePost_UserNotFound() {
 	suite.userRepo.AssertExpectations(suite.T())
 }
 
-func (suite *PostServiceTestSuite) TestUpdatePost_UpdateError() {
+func (suite *PostServiceTestSuite) TestUpdatePost_PostNotFound() {
 	username := "testuser"
-	postID := "post-123"
+	postID := "nonexistent"
 	param := payload.PostRequest{
 		Title:  "Updated Post",
 		Body:   "This is an updated post body",
@@ -280,51 +677,144 @@ func (suite *PostServiceTestSuite) TestUpdatePost_UpdateError() {
 	user := userModel.AuthUserModel{
 		ID:       strfmt.UUID4("user-123"),
 		Username: username,
+		Role:     authorization.RoleAuthor,
 	}
 
 	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
-	suite.postRepo.On("UpdatePost", suite.ctx, mock.Anything).Return(model.PostModel{}, errors.New("update error"))
+	suite.postRepo.On("GetPostById", suite.ctx, postID).Return(nil, gorm.ErrRecordNotFound)
 
 	result, err := suite.service.UpdatePost(suite.ctx, username, postID, param)
 
 	assert.Error(suite.T(), err)
 	assert.Nil(suite.T(), result)
-	assert.Equal(suite.T(), "update error", err.Error())
+	assert.Equal(suite.T(), "post not found", err.Error())
 	suite.userRepo.AssertExpectations(suite.T())
 	suite.postRepo.AssertExpectations(suite.T())
 }
 
-func (suite *PostServiceTestSuite) TestDeletePost_Success() {
+func (suite *PostServiceTestSuite) TestUpdatePost_UpdateError() {
 	username := "testuser"
 	postID := "post-123"
+	param := payload.PostRequest{
+		Title:  "Updated Post",
+		Body:   "This is an updated post body",
+		Status: "DRAFT",
+	}
 
 	user := userModel.AuthUserModel{
 		ID:       strfmt.UUID4("user-123"),
 		Username: username,
+		Role:     authorization.RoleAuthor,
 	}
 
-	post := model.PostModel{
-		ID:        strfmt.UUID4(postID),
-		Username:  username,
-		Title:     "Test Post",
-		Body:      "Test Body",
-		Status:    "PUBLISH",
-		CreatedBy: username,
+	existing := model.PostModel{
+		ID:       strfmt.UUID4(postID),
+		Username: username,
+		Title:    param.Title,
+		Slug:     "updated-post",
 	}
 
 	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
-	suite.postRepo.On("GetPostById", suite.ctx, postID).Return(&post, nil)
-	suite.postRepo.On("DeletePost", suite.ctx, post).Return(nil)
+	suite.postRepo.On("GetPostById", suite.ctx, postID).Return(&existing, nil)
+	suite.postRepo.On("UpdatePost", suite.ctx, mock.Anything).Return(model.PostModel{}, errors.New("update error"))
 
-	result, err := suite.service.DeletePost(suite.ctx, username, postID)
+	result, err := suite.service.UpdatePost(suite.ctx, username, postID, param)
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), "update error", err.Error())
+	suite.userRepo.AssertExpectations(suite.T())
+	suite.postRepo.AssertExpectations(suite.T())
+}
+
+func (suite *PostServiceTestSuite) TestDeletePost_Success() {
+	username := "testuser"
+	postID := "post-123"
+
+	user := userModel.AuthUserModel{
+		ID:       strfmt.UUID4("user-123"),
+		Username: username,
+		Role:     authorization.RoleAuthor,
+	}
+
+	post := model.PostModel{
+		ID:        strfmt.UUID4(postID),
+		Username:  username,
+		Title:     "Test Post",
+		Body:      "Test Body",
+		Status:    "PUBLISH",
+		CreatedBy: username,
+	}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, postID).Return(&post, nil)
+	suite.postRepo.On("DeletePost", suite.ctx, post).Return(nil)
+	suite.taxonomyRepo.On("GetPostTags", suite.ctx, []string{postID}).Return([]taxonomyModel.PostTagModel{}, nil)
+	suite.taxonomyRepo.On("RefreshTagCounts", suite.ctx, []string{}).Return(nil)
+
+	result, err := suite.service.DeletePost(suite.ctx, username, postID)
 
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), result)
 	assert.Equal(suite.T(), post.ID, result.ID)
+	assert.Equal(suite.T(), 1, suite.sitemap.invalidated)
 	suite.userRepo.AssertExpectations(suite.T())
 	suite.postRepo.AssertExpectations(suite.T())
 }
 
+func (suite *PostServiceTestSuite) TestDeletePost_AdminCanDeleteAnyPost() {
+	username := "admin"
+	postID := "post-123"
+
+	user := userModel.AuthUserModel{
+		ID:       strfmt.UUID4("user-1"),
+		Username: username,
+		Role:     authorization.RoleAdmin,
+	}
+
+	post := model.PostModel{
+		ID:       strfmt.UUID4(postID),
+		Username: "owner",
+	}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, postID).Return(&post, nil)
+	suite.postRepo.On("DeletePost", suite.ctx, post).Return(nil)
+	suite.taxonomyRepo.On("GetPostTags", suite.ctx, []string{postID}).Return([]taxonomyModel.PostTagModel{}, nil)
+	suite.taxonomyRepo.On("RefreshTagCounts", suite.ctx, []string{}).Return(nil)
+
+	result, err := suite.service.DeletePost(suite.ctx, username, postID)
+
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), result)
+	suite.postRepo.AssertExpectations(suite.T())
+}
+
+func (suite *PostServiceTestSuite) TestDeletePost_NotOwnerForbidden() {
+	username := "testuser"
+	postID := "post-123"
+
+	user := userModel.AuthUserModel{
+		ID:       strfmt.UUID4("user-123"),
+		Username: username,
+		Role:     authorization.RoleAuthor,
+	}
+
+	post := model.PostModel{
+		ID:       strfmt.UUID4(postID),
+		Username: "owner",
+	}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, postID).Return(&post, nil)
+
+	result, err := suite.service.DeletePost(suite.ctx, username, postID)
+
+	assert.ErrorIs(suite.T(), err, authorization.ErrForbidden)
+	assert.Nil(suite.T(), result)
+	suite.postRepo.AssertNotCalled(suite.T(), "DeletePost", mock.Anything, mock.Anything)
+}
+
 func (suite *PostServiceTestSuite) TestDeletePost_UserNotFound() {
 	username := "nonexistent"
 	postID := "post-123"
@@ -346,6 +836,7 @@ func (suite *PostServiceTestSuite) TestDeletePost_PostNotFound() {
 	user := userModel.AuthUserModel{
 		ID:       strfmt.UUID4("user-123"),
 		Username: username,
+		Role:     authorization.RoleAuthor,
 	}
 
 	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
@@ -367,6 +858,7 @@ func (suite *PostServiceTestSuite) TestDeletePost_DeleteError() {
 	user := userModel.AuthUserModel{
 		ID:       strfmt.UUID4("user-123"),
 		Username: username,
+		Role:     authorization.RoleAuthor,
 	}
 
 	post := model.PostModel{
@@ -381,6 +873,7 @@ func (suite *PostServiceTestSuite) TestDeletePost_DeleteError() {
 	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
 	suite.postRepo.On("GetPostById", suite.ctx, postID).Return(&post, nil)
 	suite.postRepo.On("DeletePost", suite.ctx, post).Return(errors.New("delete error"))
+	suite.taxonomyRepo.On("GetPostTags", suite.ctx, []string{postID}).Return([]taxonomyModel.PostTagModel{}, nil)
 
 	result, err := suite.service.DeletePost(suite.ctx, username, postID)
 
@@ -393,12 +886,12 @@ func (suite *PostServiceTestSuite) TestDeletePost_DeleteError() {
 
 func (suite *PostServiceTestSuite) TestGetAllPost_Success() {
 	username := "testuser"
-	page := 1
-	limit := 10
+	page := pagination.Page{Limit: 10}
 
 	user := userModel.AuthUserModel{
 		ID:       strfmt.UUID4("user-123"),
 		Username: username,
+		Role:     authorization.RoleAuthor,
 	}
 
 	posts := []model.PostModel{
@@ -419,26 +912,34 @@ func (suite *PostServiceTestSuite) TestGetAllPost_Success() {
 	}
 
 	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
-	suite.postRepo.On("GetAllPost", suite.ctx, page, limit).Return(posts, nil)
+	suite.postRepo.On("GetAllPost", suite.ctx, payload.PostFilter{PublishedOnly: true, Owner: username}, page).Return(posts, pagination.Meta{}, nil)
+	suite.taxonomyRepo.On("GetPostTags", suite.ctx, []string{"post-1", "post-2"}).Return([]taxonomyModel.PostTagModel{
+		{PostId: "post-1", TagId: "tag-1", Tag: taxonomyModel.TagModel{ID: strfmt.UUID4("tag-1"), Name: "Go", Slug: "go"}},
+	}, nil)
+	suite.taxonomyRepo.On("GetCategoriesByIds", suite.ctx, []string{}).Return([]taxonomyModel.CategoryModel{}, nil)
+	suite.postRepo.On("CountComments", suite.ctx, []string{"post-1", "post-2"}).Return(map[string]int64{"post-1": 2}, nil)
 
-	result, err := suite.service.GetAllPost(suite.ctx, username, page, limit)
+	result, _, err := suite.service.GetAllPost(suite.ctx, username, payload.PostFilter{}, page)
 
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), result)
 	assert.Len(suite.T(), result, 2)
 	assert.Equal(suite.T(), posts[0].Title, result[0].Title)
+	assert.Equal(suite.T(), "go", result[0].Tags[0].Slug)
+	assert.Empty(suite.T(), result[1].Tags)
+	assert.Equal(suite.T(), int64(2), result[0].CommentCount)
+	assert.Equal(suite.T(), int64(0), result[1].CommentCount)
 	suite.userRepo.AssertExpectations(suite.T())
 	suite.postRepo.AssertExpectations(suite.T())
 }
 
 func (suite *PostServiceTestSuite) TestGetAllPost_UserNotFound() {
 	username := "nonexistent"
-	page := 1
-	limit := 10
+	page := pagination.Page{Limit: 10}
 
 	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{}, nil)
 
-	result, err := suite.service.GetAllPost(suite.ctx, username, page, limit)
+	result, _, err := suite.service.GetAllPost(suite.ctx, username, payload.PostFilter{}, page)
 
 	assert.Error(suite.T(), err)
 	assert.Nil(suite.T(), result)
@@ -448,18 +949,18 @@ func (suite *PostServiceTestSuite) TestGetAllPost_UserNotFound() {
 
 func (suite *PostServiceTestSuite) TestGetAllPost_Error() {
 	username := "testuser"
-	page := 1
-	limit := 10
+	page := pagination.Page{Limit: 10}
 
 	user := userModel.AuthUserModel{
 		ID:       strfmt.UUID4("user-123"),
 		Username: username,
+		Role:     authorization.RoleAuthor,
 	}
 
 	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
-	suite.postRepo.On("GetAllPost", suite.ctx, page, limit).Return(nil, errors.New("database error"))
+	suite.postRepo.On("GetAllPost", suite.ctx, payload.PostFilter{PublishedOnly: true, Owner: username}, page).Return(nil, pagination.Meta{}, errors.New("database error"))
 
-	result, err := suite.service.GetAllPost(suite.ctx, username, page, limit)
+	result, _, err := suite.service.GetAllPost(suite.ctx, username, payload.PostFilter{}, page)
 
 	assert.Error(suite.T(), err)
 	assert.Nil(suite.T(), result)
@@ -468,6 +969,30 @@ func (suite *PostServiceTestSuite) TestGetAllPost_Error() {
 	suite.postRepo.AssertExpectations(suite.T())
 }
 
+func (suite *PostServiceTestSuite) TestGetAllPost_BadFilter() {
+	username := "testuser"
+	page := pagination.Page{Limit: 10}
+	filter := payload.PostFilter{CreatedAfter: "yesterday"}
+
+	user := userModel.AuthUserModel{
+		ID:       strfmt.UUID4("user-123"),
+		Username: username,
+		Role:     authorization.RoleAuthor,
+	}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetAllPost", suite.ctx, payload.PostFilter{CreatedAfter: "yesterday", PublishedOnly: true, Owner: username}, page).
+		Return(nil, pagination.Meta{}, apperror.BadRequest("created_after must be a date like 2006-01-02"))
+
+	result, _, err := suite.service.GetAllPost(suite.ctx, username, filter, page)
+
+	var appErr *apperror.Error
+	assert.ErrorAs(suite.T(), err, &appErr)
+	assert.Equal(suite.T(), http.StatusBadRequest, appErr.Code)
+	assert.Nil(suite.T(), result)
+	suite.postRepo.AssertExpectations(suite.T())
+}
+
 func (suite *PostServiceTestSuite) TestGetById_Success() {
 	username := "testuser"
 	postID := "post-123"
@@ -475,6 +1000,7 @@ func (suite *PostServiceTestSuite) TestGetById_Success() {
 	user := userModel.AuthUserModel{
 		ID:       strfmt.UUID4("user-123"),
 		Username: username,
+		Role:     authorization.RoleAuthor,
 	}
 
 	post := model.PostModel{
@@ -488,12 +1014,16 @@ func (suite *PostServiceTestSuite) TestGetById_Success() {
 
 	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
 	suite.postRepo.On("GetPostById", suite.ctx, postID).Return(&post, nil)
+	suite.taxonomyRepo.On("GetPostTags", suite.ctx, []string{postID}).Return([]taxonomyModel.PostTagModel{}, nil)
+	suite.taxonomyRepo.On("GetCategoriesByIds", suite.ctx, []string{}).Return([]taxonomyModel.CategoryModel{}, nil)
+	suite.postRepo.On("CountComments", suite.ctx, []string{postID}).Return(map[string]int64{postID: 3}, nil)
 
 	result, err := suite.service.GetById(suite.ctx, username, postID)
 
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), result)
 	assert.Equal(suite.T(), post.Title, result.Title)
+	assert.Equal(suite.T(), int64(3), result.CommentCount)
 	suite.userRepo.AssertExpectations(suite.T())
 	suite.postRepo.AssertExpectations(suite.T())
 }
@@ -519,6 +1049,7 @@ func (suite *PostServiceTestSuite) TestGetById_PostNotFound() {
 	user := userModel.AuthUserModel{
 		ID:       strfmt.UUID4("user-123"),
 		Username: username,
+		Role:     authorization.RoleAuthor,
 	}
 
 	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
@@ -532,3 +1063,804 @@ func (suite *PostServiceTestSuite) TestGetById_PostNotFound() {
 	suite.userRepo.AssertExpectations(suite.T())
 	suite.postRepo.AssertExpectations(suite.T())
 }
+
+func (suite *PostServiceTestSuite) TestAddPost_SlugCollision() {
+	username := "testuser"
+	param := payload.PostRequest{
+		Title:  "Héllo, World!",
+		Body:   "Body",
+		Status: "PUBLISH",
+	}
+
+	user := userModel.AuthUserModel{
+		ID:       strfmt.UUID4("user-123"),
+		Username: username,
+		Role:     authorization.RoleAuthor,
+	}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("IsSlugTaken", suite.ctx, "hello-world", "").Return(true, nil)
+	suite.postRepo.On("IsSlugTaken", suite.ctx, "hello-world-2", "").Return(true, nil)
+	suite.postRepo.On("IsSlugTaken", suite.ctx, "hello-world-3", "").Return(false, nil)
+	suite.postRepo.On("InsertPost", suite.ctx, mock.MatchedBy(func(p model.PostModel) bool {
+		return p.Slug == "hello-world-3"
+	})).Return(model.PostModel{Slug: "hello-world-3"}, nil)
+	suite.postRepo.On("InsertRevision", suite.ctx, mock.AnythingOfType("model.PostRevisionModel")).Return(model.PostRevisionModel{Revision: 1}, nil)
+
+	result, err := suite.service.AddPost(suite.ctx, username, param)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "hello-world-3", result.Slug)
+	suite.postRepo.AssertExpectations(suite.T())
+}
+
+func (suite *PostServiceTestSuite) TestAddPost_SlugRace() {
+	username := "testuser"
+	param := payload.PostRequest{
+		Title:  "Hello World",
+		Body:   "Body",
+		Status: "PUBLISH",
+	}
+
+	user := userModel.AuthUserModel{
+		ID:       strfmt.UUID4("user-123"),
+		Username: username,
+		Role:     authorization.RoleAuthor,
+	}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	// another post takes the slug between the check and the insert
+	suite.postRepo.On("IsSlugTaken", suite.ctx, "hello-world", "").Return(false, nil).Once()
+	suite.postRepo.On("InsertPost", suite.ctx, mock.MatchedBy(func(p model.PostModel) bool {
+		return p.Slug == "hello-world"
+	})).Return(model.PostModel{}, port.ErrSlugTaken).Once()
+	suite.postRepo.On("IsSlugTaken", suite.ctx, "hello-world", "").Return(true, nil).Once()
+	suite.postRepo.On("IsSlugTaken", suite.ctx, "hello-world-2", "").Return(false, nil).Once()
+	suite.postRepo.On("InsertPost", suite.ctx, mock.MatchedBy(func(p model.PostModel) bool {
+		return p.Slug == "hello-world-2"
+	})).Return(model.PostModel{Slug: "hello-world-2"}, nil).Once()
+	suite.postRepo.On("InsertRevision", suite.ctx, mock.AnythingOfType("model.PostRevisionModel")).Return(model.PostRevisionModel{Revision: 1}, nil)
+
+	result, err := suite.service.AddPost(suite.ctx, username, param)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "hello-world-2", result.Slug)
+	suite.postRepo.AssertExpectations(suite.T())
+}
+
+func (suite *PostServiceTestSuite) TestGetBySlug_Current() {
+	username := "testuser"
+	post := model.PostModel{
+		ID:     strfmt.UUID4("post-123"),
+		Title:  "Test Post",
+		Slug:   "test-post",
+		Status: model.StatusPublish,
+	}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{{Username: username}}, nil)
+	suite.postRepo.On("GetPostBySlug", suite.ctx, "test-post").Return(&post, nil)
+
+	result, err := suite.service.GetBySlug(suite.ctx, username, "test-post")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "test-post", result.CanonicalSlug)
+	assert.False(suite.T(), result.Redirect)
+	suite.postRepo.AssertExpectations(suite.T())
+}
+
+func (suite *PostServiceTestSuite) TestGetBySlug_History() {
+	username := "testuser"
+	post := model.PostModel{
+		ID:     strfmt.UUID4("post-123"),
+		Title:  "New Title",
+		Slug:   "new-title",
+		Status: model.StatusPublish,
+	}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{{Username: username}}, nil)
+	suite.postRepo.On("GetPostBySlug", suite.ctx, "old-title").Return(nil, gorm.ErrRecordNotFound)
+	suite.postRepo.On("GetSlugHistory", suite.ctx, "old-title").Return(&model.PostSlugHistoryModel{PostId: "post-123", Slug: "old-title"}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, "post-123").Return(&post, nil)
+
+	result, err := suite.service.GetBySlug(suite.ctx, username, "old-title")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "new-title", result.CanonicalSlug)
+	assert.True(suite.T(), result.Redirect)
+	suite.postRepo.AssertExpectations(suite.T())
+}
+
+func (suite *PostServiceTestSuite) TestGetBySlug_NotFound() {
+	username := "testuser"
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{{Username: username}}, nil)
+	suite.postRepo.On("GetPostBySlug", suite.ctx, "missing").Return(nil, gorm.ErrRecordNotFound)
+	suite.postRepo.On("GetSlugHistory", suite.ctx, "missing").Return(nil, gorm.ErrRecordNotFound)
+
+	result, err := suite.service.GetBySlug(suite.ctx, username, "missing")
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), "post not found", err.Error())
+}
+
+func (suite *PostServiceTestSuite) TestAddPost_WithTagsAndCategory() {
+	username := "testuser"
+	categoryID := "category-1"
+
+	param := payload.PostRequest{
+		Title:      "Test Post",
+		Body:       "This is a test post body",
+		Status:     "PUBLISH",
+		Tags:       []string{"Go", "golang ", "go", "Web Dev"},
+		CategoryId: &categoryID,
+	}
+
+	user := userModel.AuthUserModel{
+		ID:       strfmt.UUID4("user-123"),
+		Username: username,
+		Role:     authorization.RoleAuthor,
+	}
+
+	category := taxonomyModel.CategoryModel{ID: strfmt.UUID4(categoryID), Name: "Programming", Slug: "programming"}
+	tags := []taxonomyModel.TagModel{
+		{ID: strfmt.UUID4("tag-1"), Name: "Go", Slug: "go"},
+		{ID: strfmt.UUID4("tag-2"), Name: "golang", Slug: "golang"},
+		{ID: strfmt.UUID4("tag-3"), Name: "Web Dev", Slug: "web-dev"},
+	}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("IsSlugTaken", suite.ctx, "test-post", "").Return(false, nil)
+	suite.taxonomyRepo.On("GetCategoryById", suite.ctx, categoryID).Return(&category, nil)
+	suite.postRepo.On("InsertPost", suite.ctx, mock.MatchedBy(func(p model.PostModel) bool {
+		return p.CategoryId != nil && *p.CategoryId == categoryID
+	})).Return(model.PostModel{ID: strfmt.UUID4("post-123"), Title: param.Title, CategoryId: &categoryID}, nil)
+	suite.taxonomyRepo.On("GetPostTags", suite.ctx, []string{"post-123"}).Return([]taxonomyModel.PostTagModel{}, nil)
+	suite.taxonomyRepo.On("FindOrCreateTags", suite.ctx, []taxonomyModel.TagModel{
+		{Name: "Go", Slug: "go"},
+		{Name: "golang", Slug: "golang"},
+		{Name: "Web Dev", Slug: "web-dev"},
+	}).Return(tags, nil)
+	suite.taxonomyRepo.On("ReplacePostTags", suite.ctx, "post-123", []string{"tag-1", "tag-2", "tag-3"}).Return(nil)
+	suite.taxonomyRepo.On("RefreshTagCounts", suite.ctx, []string{"tag-1", "tag-2", "tag-3"}).Return(nil)
+	suite.postRepo.On("InsertRevision", suite.ctx, mock.AnythingOfType("model.PostRevisionModel")).Return(model.PostRevisionModel{Revision: 1}, nil)
+
+	result, err := suite.service.AddPost(suite.ctx, username, param)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result.Tags, 3)
+	assert.Equal(suite.T(), "programming", result.Category.Slug)
+	suite.postRepo.AssertExpectations(suite.T())
+	suite.taxonomyRepo.AssertExpectations(suite.T())
+}
+
+func (suite *PostServiceTestSuite) TestAddPost_CategoryNotFound() {
+	username := "testuser"
+	categoryID := "missing"
+
+	param := payload.PostRequest{
+		Title:      "Test Post",
+		Body:       "This is a test post body",
+		Status:     "PUBLISH",
+		CategoryId: &categoryID,
+	}
+
+	user := userModel.AuthUserModel{
+		ID:       strfmt.UUID4("user-123"),
+		Username: username,
+		Role:     authorization.RoleAuthor,
+	}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("IsSlugTaken", suite.ctx, "test-post", "").Return(false, nil)
+	suite.taxonomyRepo.On("GetCategoryById", suite.ctx, categoryID).Return(nil, gorm.ErrRecordNotFound)
+
+	result, err := suite.service.AddPost(suite.ctx, username, param)
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), "category not found", err.Error())
+	suite.postRepo.AssertNotCalled(suite.T(), "InsertPost", mock.Anything, mock.Anything)
+}
+
+func (suite *PostServiceTestSuite) TestUpdatePost_ReplacesTags() {
+	username := "testuser"
+	postID := "post-123"
+
+	param := payload.PostRequest{
+		Title:  "Test Post",
+		Body:   "Test Body",
+		Status: "PUBLISH",
+		Tags:   []string{"Go"},
+	}
+
+	user := userModel.AuthUserModel{
+		ID:       strfmt.UUID4("user-123"),
+		Username: username,
+		Role:     authorization.RoleAuthor,
+	}
+
+	existing := model.PostModel{
+		ID:        strfmt.UUID4(postID),
+		Username:  username,
+		Title:     "Test Post",
+		Slug:      "test-post",
+		Body:      "Test Body",
+		Status:    "PUBLISH",
+		CreatedBy: username,
+	}
+
+	goTag := taxonomyModel.TagModel{ID: strfmt.UUID4("tag-1"), Name: "Go", Slug: "go"}
+	oldTags := []taxonomyModel.PostTagModel{
+		{PostId: postID, TagId: "tag-1", Tag: goTag},
+		{PostId: postID, TagId: "tag-9", Tag: taxonomyModel.TagModel{ID: strfmt.UUID4("tag-9"), Name: "Old", Slug: "old"}},
+	}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, postID).Return(&existing, nil)
+	suite.postRepo.On("UpdatePost", suite.ctx, mock.Anything).Return(existing, nil)
+	suite.taxonomyRepo.On("GetPostTags", suite.ctx, []string{postID}).Return(oldTags, nil).Once()
+	suite.taxonomyRepo.On("FindOrCreateTags", suite.ctx, []taxonomyModel.TagModel{{Name: "Go", Slug: "go"}}).Return([]taxonomyModel.TagModel{goTag}, nil)
+	suite.taxonomyRepo.On("ReplacePostTags", suite.ctx, postID, []string{"tag-1"}).Return(nil)
+	suite.taxonomyRepo.On("RefreshTagCounts", suite.ctx, []string{"tag-1", "tag-9"}).Return(nil)
+	suite.taxonomyRepo.On("GetPostTags", suite.ctx, []string{postID}).Return(oldTags[:1], nil).Once()
+	suite.taxonomyRepo.On("GetCategoriesByIds", suite.ctx, []string{}).Return([]taxonomyModel.CategoryModel{}, nil)
+	suite.postRepo.On("InsertRevision", suite.ctx, mock.AnythingOfType("model.PostRevisionModel")).Return(model.PostRevisionModel{Revision: 1}, nil)
+
+	result, err := suite.service.UpdatePost(suite.ctx, username, postID, param)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result.Tags, 1)
+	suite.taxonomyRepo.AssertExpectations(suite.T())
+}
+
+func (suite *PostServiceTestSuite) TestGetAllPost_NormalizesFilter() {
+	username := "testuser"
+
+	user := userModel.AuthUserModel{
+		ID:       strfmt.UUID4("user-123"),
+		Username: username,
+		Role:     authorization.RoleReader,
+	}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetAllPost", suite.ctx, payload.PostFilter{Tag: "web-dev", Category: "programming", PublishedOnly: true, Owner: username}, pagination.Page{Limit: 10}).Return([]model.PostModel{}, pagination.Meta{}, nil)
+
+	result, _, err := suite.service.GetAllPost(suite.ctx, username, payload.PostFilter{Tag: "Web Dev", Category: "Programming"}, pagination.Page{Limit: 10})
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 0)
+	suite.postRepo.AssertExpectations(suite.T())
+}
+
+func (suite *PostServiceTestSuite) TestGetAllPost_SortPagesByOffset() {
+	username := "testuser"
+	filter := payload.PostFilter{Sort: "title"}
+
+	user := userModel.AuthUserModel{
+		ID:       strfmt.UUID4("user-123"),
+		Username: username,
+		Role:     authorization.RoleEditor,
+	}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetAllPost", suite.ctx, filter, pagination.Page{Limit: 10, Number: 1}).Return([]model.PostModel{}, pagination.Meta{}, nil)
+
+	_, _, err := suite.service.GetAllPost(suite.ctx, username, filter, pagination.Page{Limit: 10})
+
+	assert.NoError(suite.T(), err)
+	suite.postRepo.AssertExpectations(suite.T())
+}
+
+func (suite *PostServiceTestSuite) TestAddPost_Scheduled() {
+	username := "testuser"
+	publishAt := strfmt.DateTime(time.Now().Add(time.Hour))
+
+	param := payload.PostRequest{
+		Title:     "Later Post",
+		Body:      "Body",
+		Status:    model.StatusScheduled,
+		PublishAt: &publishAt,
+	}
+
+	user := userModel.AuthUserModel{
+		ID:       strfmt.UUID4("user-123"),
+		Username: username,
+		Role:     authorization.RoleAuthor,
+	}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("IsSlugTaken", suite.ctx, "later-post", "").Return(false, nil)
+	suite.postRepo.On("InsertPost", suite.ctx, mock.MatchedBy(func(p model.PostModel) bool {
+		return p.Status == model.StatusScheduled && p.PublishAt != nil && p.PublishAt.Equal(time.Time(publishAt))
+	})).Return(model.PostModel{ID: strfmt.UUID4("post-123"), Title: param.Title, Status: model.StatusScheduled}, nil)
+	suite.postRepo.On("InsertRevision", suite.ctx, mock.AnythingOfType("model.PostRevisionModel")).Return(model.PostRevisionModel{Revision: 1}, nil)
+
+	result, err := suite.service.AddPost(suite.ctx, username, param)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.StatusScheduled, result.Status)
+	suite.postRepo.AssertExpectations(suite.T())
+}
+
+func (suite *PostServiceTestSuite) TestAddPost_PublishAtIgnoredWhenNotScheduled() {
+	username := "testuser"
+	publishAt := strfmt.DateTime(time.Now().Add(time.Hour))
+
+	param := payload.PostRequest{
+		Title:     "Now Post",
+		Body:      "Body",
+		Status:    model.StatusDraft,
+		PublishAt: &publishAt,
+	}
+
+	user := userModel.AuthUserModel{
+		ID:       strfmt.UUID4("user-123"),
+		Username: username,
+		Role:     authorization.RoleAuthor,
+	}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("IsSlugTaken", suite.ctx, "now-post", "").Return(false, nil)
+	suite.postRepo.On("InsertPost", suite.ctx, mock.MatchedBy(func(p model.PostModel) bool {
+		return p.Status == model.StatusDraft && p.PublishAt == nil
+	})).Return(model.PostModel{ID: strfmt.UUID4("post-123"), Title: param.Title, Status: model.StatusDraft}, nil)
+	suite.postRepo.On("InsertRevision", suite.ctx, mock.AnythingOfType("model.PostRevisionModel")).Return(model.PostRevisionModel{Revision: 1}, nil)
+
+	_, err := suite.service.AddPost(suite.ctx, username, param)
+
+	assert.NoError(suite.T(), err)
+	suite.postRepo.AssertExpectations(suite.T())
+}
+
+func (suite *PostServiceTestSuite) TestPublishDuePosts_Success() {
+	publishAt := time.Now().Add(-time.Minute)
+	due := []model.PostModel{
+		{ID: strfmt.UUID4("post-1"), Title: "First", Status: model.StatusScheduled, PublishAt: &publishAt},
+		{ID: strfmt.UUID4("post-2"), Title: "Second", Status: model.StatusScheduled, PublishAt: &publishAt},
+	}
+
+	suite.postRepo.On("GetDueScheduledPosts", suite.ctx, mock.AnythingOfType("time.Time"), publishBatch).Return(due, nil)
+	for _, post := range due {
+		published := post
+		published.Status = model.StatusPublish
+		published.UpdatedBy = scheduledBy
+		suite.postRepo.On("UpdatePost", suite.ctx, published).Return(published, nil).Once()
+	}
+	suite.taxonomyRepo.On("GetPostTags", suite.ctx, []string{"post-1", "post-2"}).Return([]taxonomyModel.PostTagModel{{PostId: "post-1", TagId: "tag-1"}}, nil)
+	suite.taxonomyRepo.On("RefreshTagCounts", suite.ctx, []string{"tag-1"}).Return(nil)
+
+	published, err := suite.service.PublishDuePosts(suite.ctx)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, published)
+	suite.taxonomyRepo.AssertExpectations(suite.T())
+	assert.Equal(suite.T(), 1, suite.sitemap.invalidated)
+
+	found, _, err := suite.service.searchIndex.Search(suite.ctx, searchPayload.SearchRequest{Query: "first", Status: model.StatusPublish}, pagination.Page{Limit: 10})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), found, 1)
+	suite.postRepo.AssertExpectations(suite.T())
+}
+
+func (suite *PostServiceTestSuite) TestPublishDuePosts_NothingDue() {
+	suite.postRepo.On("GetDueScheduledPosts", suite.ctx, mock.AnythingOfType("time.Time"), publishBatch).Return([]model.PostModel{}, nil)
+
+	published, err := suite.service.PublishDuePosts(suite.ctx)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, published)
+	assert.Equal(suite.T(), 0, suite.sitemap.invalidated)
+	suite.postRepo.AssertNotCalled(suite.T(), "UpdatePost", mock.Anything, mock.Anything)
+}
+
+func (suite *PostServiceTestSuite) TestPublishDuePosts_Error() {
+	suite.postRepo.On("GetDueScheduledPosts", suite.ctx, mock.AnythingOfType("time.Time"), publishBatch).Return(nil, errors.New("database error"))
+
+	published, err := suite.service.PublishDuePosts(suite.ctx)
+
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), 0, published)
+}
+
+func (suite *PostServiceTestSuite) TestGetRevisions_Success() {
+	username := "testuser"
+	postID := "post-123"
+	page := pagination.Page{Limit: 10}
+
+	user := userModel.AuthUserModel{ID: strfmt.UUID4("user-123"), Username: username, Role: authorization.RoleAuthor}
+	existing := model.PostModel{ID: strfmt.UUID4(postID), Username: username}
+	revisions := []model.PostRevisionModel{
+		{PostId: postID, Revision: 2, Title: "Second"},
+		{PostId: postID, Revision: 1, Title: "First"},
+	}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, postID).Return(&existing, nil)
+	suite.postRepo.On("GetRevisions", suite.ctx, postID, page).Return(revisions, pagination.Meta{Limit: 10}, nil)
+
+	result, meta, err := suite.service.GetRevisions(suite.ctx, username, postID, page)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 2)
+	assert.Equal(suite.T(), 10, meta.Limit)
+	suite.postRepo.AssertExpectations(suite.T())
+}
+
+func (suite *PostServiceTestSuite) TestGetRevisions_NotOwnerForbidden() {
+	username := "testuser"
+	postID := "post-123"
+
+	user := userModel.AuthUserModel{ID: strfmt.UUID4("user-123"), Username: username, Role: authorization.RoleAuthor}
+	existing := model.PostModel{ID: strfmt.UUID4(postID), Username: "someoneelse"}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, postID).Return(&existing, nil)
+
+	result, _, err := suite.service.GetRevisions(suite.ctx, username, postID, pagination.Page{Limit: 10})
+
+	assert.ErrorIs(suite.T(), err, authorization.ErrForbidden)
+	assert.Nil(suite.T(), result)
+	suite.postRepo.AssertNotCalled(suite.T(), "GetRevisions", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *PostServiceTestSuite) TestGetRevision_NotFound() {
+	username := "testuser"
+	postID := "post-123"
+
+	user := userModel.AuthUserModel{ID: strfmt.UUID4("user-123"), Username: username, Role: authorization.RoleEditor}
+	existing := model.PostModel{ID: strfmt.UUID4(postID), Username: "someoneelse"}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, postID).Return(&existing, nil)
+	suite.postRepo.On("GetRevision", suite.ctx, postID, 9).Return(nil, errors.New("record not found"))
+
+	result, err := suite.service.GetRevision(suite.ctx, username, postID, 9)
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), "revision not found", err.Error())
+}
+
+func (suite *PostServiceTestSuite) TestDiffRevisions_Success() {
+	username := "testuser"
+	postID := "post-123"
+
+	user := userModel.AuthUserModel{ID: strfmt.UUID4("user-123"), Username: username, Role: authorization.RoleAuthor}
+	existing := model.PostModel{ID: strfmt.UUID4(postID), Username: username}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, postID).Return(&existing, nil)
+	suite.postRepo.On("GetRevision", suite.ctx, postID, 1).Return(&model.PostRevisionModel{Revision: 1, Title: "Title", Body: "one\ntwo\nthree"}, nil)
+	suite.postRepo.On("GetRevision", suite.ctx, postID, 2).Return(&model.PostRevisionModel{Revision: 2, Title: "Title", Body: "one\n2\nthree\nfour"}, nil)
+
+	result, err := suite.service.DiffRevisions(suite.ctx, username, postID, 1, 2)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []helper.DiffLine{{Op: helper.DiffEqual, Text: "Title"}}, result.Title)
+	assert.Equal(suite.T(), []helper.DiffLine{
+		{Op: helper.DiffEqual, Text: "one"},
+		{Op: helper.DiffDelete, Text: "two"},
+		{Op: helper.DiffInsert, Text: "2"},
+		{Op: helper.DiffEqual, Text: "three"},
+		{Op: helper.DiffInsert, Text: "four"},
+	}, result.Body)
+}
+
+func (suite *PostServiceTestSuite) TestDiffRevisions_RevisionNotFound() {
+	username := "testuser"
+	postID := "post-123"
+
+	user := userModel.AuthUserModel{ID: strfmt.UUID4("user-123"), Username: username, Role: authorization.RoleAuthor}
+	existing := model.PostModel{ID: strfmt.UUID4(postID), Username: username}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, postID).Return(&existing, nil)
+	suite.postRepo.On("GetRevision", suite.ctx, postID, 1).Return(&model.PostRevisionModel{Revision: 1}, nil)
+	suite.postRepo.On("GetRevision", suite.ctx, postID, 5).Return(nil, errors.New("record not found"))
+
+	result, err := suite.service.DiffRevisions(suite.ctx, username, postID, 1, 5)
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), "revision 5 not found", err.Error())
+}
+
+func (suite *PostServiceTestSuite) TestRestoreRevision_Success() {
+	username := "testuser"
+	postID := "post-123"
+
+	user := userModel.AuthUserModel{ID: strfmt.UUID4("user-123"), Username: username, Role: authorization.RoleAuthor}
+	existing := model.PostModel{
+		ID:        strfmt.UUID4(postID),
+		Username:  username,
+		Title:     "Old Title",
+		Slug:      "old-title",
+		Body:      "Current body",
+		Status:    model.StatusPublish,
+		CreatedBy: username,
+	}
+	revision := model.PostRevisionModel{PostId: postID, Revision: 1, Title: "Old Title", Body: "Original body"}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, postID).Return(&existing, nil)
+	suite.postRepo.On("GetRevision", suite.ctx, postID, 1).Return(&revision, nil)
+	suite.postRepo.On("UpdatePost", suite.ctx, mock.MatchedBy(func(p model.PostModel) bool {
+		return p.Title == revision.Title && p.Body == revision.Body && p.Status == model.StatusPublish && p.Slug == "old-title"
+	})).Return(model.PostModel{ID: strfmt.UUID4(postID), Username: username, Title: revision.Title, Body: revision.Body, Status: model.StatusPublish}, nil)
+	suite.postRepo.On("InsertRevision", suite.ctx, model.PostRevisionModel{PostId: postID, Title: revision.Title, Body: revision.Body, CreatedBy: username}).Return(model.PostRevisionModel{Revision: 3}, nil)
+	suite.taxonomyRepo.On("GetPostTags", suite.ctx, []string{postID}).Return([]taxonomyModel.PostTagModel{}, nil)
+	suite.taxonomyRepo.On("GetCategoriesByIds", suite.ctx, []string{}).Return([]taxonomyModel.CategoryModel{}, nil)
+
+	result, err := suite.service.RestoreRevision(suite.ctx, username, postID, 1)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Original body", result.Body)
+	suite.postRepo.AssertExpectations(suite.T())
+}
+
+func (suite *PostServiceTestSuite) TestCommentModeration() {
+	trusted := model.CommentModerationTrusted
+	approval := model.CommentModerationApproval
+	empty := ""
+
+	assert.Equal(suite.T(), &trusted, commentModeration(payload.PostRequest{}, &trusted))
+	assert.Nil(suite.T(), commentModeration(payload.PostRequest{CommentModeration: &empty}, &trusted))
+	assert.Equal(suite.T(), &approval, commentModeration(payload.PostRequest{CommentModeration: &approval}, &trusted))
+}
+
+// rejectFilter rejects every content it checks
+type rejectFilter struct{}
+
+func (f rejectFilter) Check(ctx context.Context, content filterPayload.Content) (filterModel.Verdict, error) {
+	return filterModel.Verdict{Action: filterModel.ActionReject, Filter: "banned_words", Reason: `contains the banned word "casino"`}, nil
+}
+
+// failingFilter fails on every content it checks
+type failingFilter struct{}
+
+func (f failingFilter) Check(ctx context.Context, content filterPayload.Content) (filterModel.Verdict, error) {
+	return filterModel.Verdict{}, errors.New("database error")
+}
+
+func (suite *PostServiceTestSuite) TestAddPost_FilterRejects() {
+	username := "testuser"
+	suite.service.contentFilter = rejectFilter{}
+
+	param := payload.PostRequest{Title: "Casino", Body: "Body", Status: model.StatusPublish}
+	user := userModel.AuthUserModel{ID: strfmt.UUID4("user-123"), Username: username, Role: authorization.RoleAuthor}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("IsSlugTaken", suite.ctx, "casino", "").Return(false, nil)
+
+	result, err := suite.service.AddPost(suite.ctx, username, param)
+
+	var appErr *apperror.Error
+	assert.ErrorAs(suite.T(), err, &appErr)
+	assert.Equal(suite.T(), http.StatusUnprocessableEntity, appErr.Code)
+	assert.Contains(suite.T(), err.Error(), "casino")
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), 0, suite.sitemap.invalidated)
+	suite.postRepo.AssertNotCalled(suite.T(), "InsertPost", mock.Anything, mock.Anything)
+}
+
+func (suite *PostServiceTestSuite) TestAddPost_FilterSkipsDrafts() {
+	username := "testuser"
+	suite.service.contentFilter = rejectFilter{}
+
+	param := payload.PostRequest{Title: "Casino", Body: "Body", Status: model.StatusDraft}
+	user := userModel.AuthUserModel{ID: strfmt.UUID4("user-123"), Username: username, Role: authorization.RoleAuthor}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("IsSlugTaken", suite.ctx, "casino", "").Return(false, nil)
+	suite.postRepo.On("InsertPost", suite.ctx, mock.Anything).Return(model.PostModel{ID: strfmt.UUID4("post-1"), Title: param.Title, Status: model.StatusDraft}, nil)
+	suite.postRepo.On("InsertRevision", suite.ctx, mock.Anything).Return(model.PostRevisionModel{Revision: 1}, nil)
+
+	result, err := suite.service.AddPost(suite.ctx, username, param)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.StatusDraft, result.Status)
+	// drafts are not in the sitemap
+	assert.Equal(suite.T(), 0, suite.sitemap.invalidated)
+}
+
+func (suite *PostServiceTestSuite) TestUpdatePost_FilterFails() {
+	username := "testuser"
+	postID := "post-123"
+	suite.service.contentFilter = failingFilter{}
+
+	param := payload.PostRequest{Title: "Test Post", Body: "Body", Status: model.StatusPublish}
+	user := userModel.AuthUserModel{ID: strfmt.UUID4("user-123"), Username: username, Role: authorization.RoleAuthor}
+	existing := model.PostModel{ID: strfmt.UUID4(postID), Username: username, Title: "Test Post", Slug: "test-post", Status: model.StatusDraft}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, postID).Return(&existing, nil)
+
+	result, err := suite.service.UpdatePost(suite.ctx, username, postID, param)
+
+	assert.EqualError(suite.T(), err, "post could not be checked by the content filters")
+	assert.Nil(suite.T(), result)
+	suite.postRepo.AssertNotCalled(suite.T(), "UpdatePost", mock.Anything, mock.Anything)
+}
+
+func (suite *PostServiceTestSuite) TestAddPost_UnverifiedEmailCannotPublish() {
+	username := "testuser"
+	suite.service.requireVerifiedEmail = true
+
+	param := payload.PostRequest{Title: "Test Post", Body: "Body", Status: model.StatusPublish}
+	user := userModel.AuthUserModel{ID: strfmt.UUID4("user-123"), Username: username, Email: "test@example.com", Role: authorization.RoleAuthor}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+
+	result, err := suite.service.AddPost(suite.ctx, username, param)
+
+	assert.ErrorIs(suite.T(), err, authorization.ErrEmailNotVerified)
+	assert.Nil(suite.T(), result)
+	suite.postRepo.AssertNotCalled(suite.T(), "InsertPost", mock.Anything, mock.Anything)
+}
+
+func (suite *PostServiceTestSuite) TestAddPost_UnverifiedEmailKeepsDrafts() {
+	username := "testuser"
+	suite.service.requireVerifiedEmail = true
+
+	param := payload.PostRequest{Title: "Test Post", Body: "Body", Status: model.StatusDraft}
+	user := userModel.AuthUserModel{ID: strfmt.UUID4("user-123"), Username: username, Role: authorization.RoleAuthor}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{user}, nil)
+	suite.postRepo.On("IsSlugTaken", suite.ctx, "test-post", "").Return(false, nil)
+	suite.postRepo.On("InsertPost", suite.ctx, mock.Anything).Return(model.PostModel{ID: strfmt.UUID4("post-1"), Title: param.Title, Status: model.StatusDraft}, nil)
+	suite.postRepo.On("InsertRevision", suite.ctx, mock.Anything).Return(model.PostRevisionModel{Revision: 1}, nil)
+	suite.taxonomyRepo.On("GetPostTags", suite.ctx, []string{"post-1"}).Return([]taxonomyModel.PostTagModel{}, nil)
+	suite.taxonomyRepo.On("GetCategoriesByIds", suite.ctx, []string{}).Return([]taxonomyModel.CategoryModel{}, nil)
+
+	result, err := suite.service.AddPost(suite.ctx, username, param)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.StatusDraft, result.Status)
+}
+
+func (suite *PostServiceTestSuite) TestCanPublish() {
+	suite.service.requireVerifiedEmail = true
+	verifiedAt := time.Now()
+
+	assert.True(suite.T(), suite.service.canPublish(userModel.AuthUserModel{Role: authorization.RoleAuthor, Email: "a@example.com", EmailVerifiedAt: &verifiedAt}, model.StatusPublish))
+	assert.True(suite.T(), suite.service.canPublish(userModel.AuthUserModel{Role: authorization.RoleEditor}, model.StatusPublish))
+	assert.False(suite.T(), suite.service.canPublish(userModel.AuthUserModel{Role: authorization.RoleAuthor}, model.StatusPublish))
+
+	suite.service.requireVerifiedEmail = false
+	assert.True(suite.T(), suite.service.canPublish(userModel.AuthUserModel{Role: authorization.RoleAuthor}, model.StatusPublish))
+}
+
+func (suite *PostServiceTestSuite) TestAnonymizeUserContent_Reindexes() {
+	anonymized := []model.PostModel{
+		{ID: strfmt.UUID4("post-1"), Username: "[deleted]", Title: "First", Status: model.StatusPublish},
+	}
+	suite.postRepo.On("AnonymizeUserPosts", suite.ctx, "testuser", "[deleted]").Return(anonymized, nil)
+
+	count, err := suite.service.AnonymizeUserContent(suite.ctx, "testuser", "[deleted]")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, count)
+	found, _, err := suite.service.searchIndex.Search(suite.ctx, searchPayload.SearchRequest{Query: "first", Author: "[deleted]"}, pagination.Page{Limit: 10})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), found, 1)
+}
+
+func (suite *PostServiceTestSuite) TestDeleteUserContent_RefreshesTagsAndIndex() {
+	deleted := []model.PostModel{
+		{ID: strfmt.UUID4("post-1"), Username: "testuser", Title: "First", Status: model.StatusPublish},
+		{ID: strfmt.UUID4("post-2"), Username: "testuser", Title: "Second", Status: model.StatusDraft},
+	}
+	for _, post := range deleted {
+		suite.service.indexPost(suite.ctx, post)
+	}
+	suite.postRepo.On("DeleteUserPosts", suite.ctx, "testuser").Return(deleted, nil)
+	suite.taxonomyRepo.On("GetPostTags", suite.ctx, []string{"post-1", "post-2"}).Return([]taxonomyModel.PostTagModel{{PostId: "post-1", TagId: "tag-1"}}, nil)
+	suite.taxonomyRepo.On("RefreshTagCounts", suite.ctx, []string{"tag-1"}).Return(nil)
+
+	count, err := suite.service.DeleteUserContent(suite.ctx, "testuser")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, count)
+	found, _, err := suite.service.searchIndex.Search(suite.ctx, searchPayload.SearchRequest{Query: "first"}, pagination.Page{Limit: 10})
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), found)
+	suite.taxonomyRepo.AssertExpectations(suite.T())
+}
+
+func (suite *PostServiceTestSuite) TestDeleteUserContent_NoPosts() {
+	suite.postRepo.On("DeleteUserPosts", suite.ctx, "testuser").Return([]model.PostModel{}, nil)
+
+	count, err := suite.service.DeleteUserContent(suite.ctx, "testuser")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, count)
+	suite.taxonomyRepo.AssertNotCalled(suite.T(), "RefreshTagCounts", mock.Anything, mock.Anything)
+}
+
+func (suite *PostServiceTestSuite) TestGetById_DraftOfOtherUser() {
+	username := "testuser"
+	post := model.PostModel{ID: strfmt.UUID4("post-123"), Username: "otheruser", Status: model.StatusDraft}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{{Username: username, Role: authorization.RoleAuthor}}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, "post-123").Return(&post, nil)
+
+	result, err := suite.service.GetById(suite.ctx, username, "post-123")
+
+	assert.Nil(suite.T(), result)
+	assert.EqualError(suite.T(), err, "post not found")
+	suite.taxonomyRepo.AssertNotCalled(suite.T(), "GetPostTags", mock.Anything, mock.Anything)
+}
+
+func (suite *PostServiceTestSuite) TestGetById_DraftForEditor() {
+	username := "editor"
+	post := model.PostModel{ID: strfmt.UUID4("post-123"), Username: "otheruser", Status: model.StatusScheduled}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{{Username: username, Role: authorization.RoleEditor}}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, "post-123").Return(&post, nil)
+	suite.taxonomyRepo.On("GetPostTags", suite.ctx, []string{"post-123"}).Return([]taxonomyModel.PostTagModel{}, nil)
+	suite.taxonomyRepo.On("GetCategoriesByIds", suite.ctx, []string{}).Return([]taxonomyModel.CategoryModel{}, nil)
+	suite.postRepo.On("CountComments", suite.ctx, []string{"post-123"}).Return(map[string]int64{}, nil)
+
+	result, err := suite.service.GetById(suite.ctx, username, "post-123")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.StatusScheduled, result.Status)
+}
+
+func (suite *PostServiceTestSuite) TestGetPublicPosts_PublishedOnly() {
+	page := pagination.Page{Limit: 10}
+	posts := []model.PostModel{{ID: strfmt.UUID4("post-1"), Status: model.StatusPublish}}
+
+	suite.postRepo.On("GetAllPost", suite.ctx, payload.PostFilter{Tag: "go", PublishedOnly: true}, page).Return(posts, pagination.Meta{}, nil)
+	suite.taxonomyRepo.On("GetPostTags", suite.ctx, []string{"post-1"}).Return([]taxonomyModel.PostTagModel{}, nil)
+	suite.taxonomyRepo.On("GetCategoriesByIds", suite.ctx, []string{}).Return([]taxonomyModel.CategoryModel{}, nil)
+	suite.postRepo.On("CountComments", suite.ctx, []string{"post-1"}).Return(map[string]int64{"post-1": 1}, nil)
+
+	// a request can not widen the listing by setting the fields itself
+	result, _, err := suite.service.GetPublicPosts(suite.ctx, payload.PostFilter{Tag: "Go", Owner: "admin"}, page)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 1)
+	assert.Equal(suite.T(), int64(1), result[0].CommentCount)
+	suite.userRepo.AssertNotCalled(suite.T(), "GetUserByUsername", mock.Anything, mock.Anything)
+	suite.postRepo.AssertExpectations(suite.T())
+}
+
+func (suite *PostServiceTestSuite) TestGetPublicById_Published() {
+	post := model.PostModel{ID: strfmt.UUID4("post-123"), Username: "testuser", Status: model.StatusPublish}
+
+	suite.postRepo.On("GetPostById", suite.ctx, "post-123").Return(&post, nil)
+	suite.taxonomyRepo.On("GetPostTags", suite.ctx, []string{"post-123"}).Return([]taxonomyModel.PostTagModel{}, nil)
+	suite.taxonomyRepo.On("GetCategoriesByIds", suite.ctx, []string{}).Return([]taxonomyModel.CategoryModel{}, nil)
+	suite.postRepo.On("CountComments", suite.ctx, []string{"post-123"}).Return(map[string]int64{}, nil)
+
+	result, err := suite.service.GetPublicById(suite.ctx, "post-123")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), strfmt.UUID4("post-123"), result.ID)
+}
+
+func (suite *PostServiceTestSuite) TestGetPublicById_Draft() {
+	post := model.PostModel{ID: strfmt.UUID4("post-123"), Username: "testuser", Status: model.StatusDraft}
+
+	suite.postRepo.On("GetPostById", suite.ctx, "post-123").Return(&post, nil)
+
+	result, err := suite.service.GetPublicById(suite.ctx, "post-123")
+
+	assert.Nil(suite.T(), result)
+	assert.EqualError(suite.T(), err, "post not found")
+}
+
+func (suite *PostServiceTestSuite) TestGetPublicBySlug_OldSlugOfDraft() {
+	post := model.PostModel{ID: strfmt.UUID4("post-123"), Slug: "new-title", Status: model.StatusDraft}
+
+	suite.postRepo.On("GetPostBySlug", suite.ctx, "old-title").Return(nil, gorm.ErrRecordNotFound)
+	suite.postRepo.On("GetSlugHistory", suite.ctx, "old-title").Return(&model.PostSlugHistoryModel{PostId: "post-123", Slug: "old-title"}, nil)
+	suite.postRepo.On("GetPostById", suite.ctx, "post-123").Return(&post, nil)
+
+	result, err := suite.service.GetPublicBySlug(suite.ctx, "old-title")
+
+	assert.Nil(suite.T(), result)
+	assert.EqualError(suite.T(), err, "post not found")
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"simple-blog-system/internal/app/search/payload"
+	"simple-blog-system/internal/app/search/port"
+	"simple-blog-system/pkg/helper"
+	"simple-blog-system/pkg/pagination"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type handler struct {
+	searchService port.ISearchService
+}
+
+func New(searchService port.ISearchService) port.ISearchHandler {
+	return &handler{
+		searchService: searchService,
+	}
+}
+
+// @BasePath /v1
+
+// @Summary Search
+// @Description Full-text search over posts and comments, ranked with the title above the body
+// @Tags search
+// @Accept json
+// @Produce json
+// @Param q query string true "Search query"
+// @Param type query string false "post or comment"
+// @Param status query string false "PUBLISH, DRAFT or SCHEDULED, other than PUBLISH only with author set to yourself unless moderator"
+// @Param author query string false "Username of the author"
+// @Param page query int false "Page"
+// @Param limit query int false "Limit, default 10 and at most 100"
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Failure 403 {object} helper.Response
+// @Router /api/search [get]
+func (h *handler) Search(c *gin.Context) {
+	username := c.GetString("username")
+	var (
+		searchRequest payload.SearchRequest
+	)
+
+	if err := c.ShouldBindQuery(&searchRequest); err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	validate := validator.New()
+	err := validate.Struct(searchRequest)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	page, err := pagination.FromContext(c)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	res, meta, err := h.searchService.Search(c.Request.Context(), username, searchRequest, page)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "get successfully",
+		Data:    res,
+		Meta:    meta,
+	})
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	commentModel "simple-blog-system/internal/app/comment/model"
+	postModel "simple-blog-system/internal/app/post/model"
+)
+
+func NewPostDocument(post postModel.PostModel) DocumentModel {
+	return DocumentModel{
+		ID:        string(post.ID),
+		Type:      TypePost,
+		PostId:    string(post.ID),
+		Title:     post.Title,
+		Body:      post.Body,
+		Status:    post.Status,
+		Username:  post.Username,
+		PostOwner: post.Username,
+		CreatedAt: post.CreatedAt,
+	}
+}
+
+// NewCommentDocument takes title, status and author from comment.Post so comments can be filtered like their post
+func NewCommentDocument(comment commentModel.CommentModel) DocumentModel {
+	return DocumentModel{
+		ID:        string(comment.ID),
+		Type:      TypeComment,
+		PostId:    comment.PostId,
+		Title:     comment.Post.Title,
+		Body:      comment.Comment,
+		Status:    comment.Post.Status,
+		Username:  comment.Username,
+		PostOwner: comment.Post.Username,
+		CreatedAt: comment.CreatedAt,
+	}
+}
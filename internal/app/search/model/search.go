@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+const (
+	TypePost    = "post"
+	TypeComment = "comment"
+)
+
+// DocumentModel is the searchable content of a post or a comment, PostOwner is the author of the post
+// or of the commented post
+type DocumentModel struct {
+	ID        string
+	Type      string
+	PostId    string
+	Title     string
+	Body      string
+	Status    string
+	Username  string
+	PostOwner string
+	CreatedAt time.Time
+}
+
+type SearchResultModel struct {
+	Type      string    `json:"type"`
+	ID        string    `json:"id"`
+	PostId    string    `json:"post_id"`
+	Title     string    `json:"title"`
+	Snippet   string    `json:"snippet"`
+	Rank      float64   `json:"rank"`
+	Status    string    `json:"status"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,12 @@
+package payload
+
+type SearchRequest struct {
+	Query  string `form:"q" validate:"required,max=200"`
+	Type   string `form:"type" validate:"omitempty,oneof=post comment"`
+	Status string `form:"status" validate:"omitempty,oneof=PUBLISH DRAFT SCHEDULED"`
+	Author string `form:"author" validate:"omitempty,max=50"`
+	// PublishedOnly and Owner are set by the service and not the request, they keep the results to published posts
+	// and the posts of Owner in every state, comments follow their post
+	PublishedOnly bool   `form:"-"`
+	Owner         string `form:"-"`
+}
@@ -0,0 +1,11 @@
+package port
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type ISearchHandler interface {
+
+	// (GET /search)
+	Search(ctx *gin.Context)
+}
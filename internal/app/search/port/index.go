@@ -0,0 +1,16 @@
+package port
+
+import (
+	"context"
+
+	"simple-blog-system/internal/app/search/model"
+	"simple-blog-system/internal/app/search/payload"
+	"simple-blog-system/pkg/pagination"
+)
+
+// ISearchIndex stores searchable documents and answers ranked queries over them
+type ISearchIndex interface {
+	Index(ctx context.Context, doc model.DocumentModel) (err error)
+	Remove(ctx context.Context, docType string, id string) (err error)
+	Search(ctx context.Context, param payload.SearchRequest, page pagination.Page) (res []model.SearchResultModel, meta pagination.Meta, err error)
+}
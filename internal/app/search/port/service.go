@@ -0,0 +1,14 @@
+package port
+
+import (
+	"context"
+
+	"simple-blog-system/internal/app/search/model"
+	"simple-blog-system/internal/app/search/payload"
+	"simple-blog-system/pkg/pagination"
+)
+
+type ISearchService interface {
+	Search(ctx context.Context, username string, param payload.SearchRequest, page pagination.Page) (res []model.SearchResultModel, meta pagination.Meta, err error)
+	Reindex(ctx context.Context) (err error)
+}
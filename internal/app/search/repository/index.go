@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"simple-blog-system/config/db"
+
+	"simple-blog-system/internal/app/search/port"
+)
+
+const (
+	DriverPostgres = "postgres"
+	DriverMemory   = "memory"
+)
+
+// NewIndex returns the search index for driver, postgres unless memory is asked for
+func NewIndex(driver string, db *db.GormDB) port.ISearchIndex {
+	if driver == DriverMemory {
+		return NewMemoryIndex()
+	}
+
+	return NewPostgresIndex(db)
+}
@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	postModel "simple-blog-system/internal/app/post/model"
+	"simple-blog-system/internal/app/search/model"
+	"simple-blog-system/internal/app/search/payload"
+	"simple-blog-system/internal/app/search/port"
+	"simple-blog-system/pkg/pagination"
+)
+
+const (
+	// titleWeight and bodyWeight follow the default weights of postgres for A and B
+	titleWeight = 1.0
+	bodyWeight  = 0.4
+
+	snippetWords = 35
+)
+
+type memoryIndex struct {
+	mu   sync.RWMutex
+	docs map[string]memoryDocument
+}
+
+type memoryDocument struct {
+	model.DocumentModel
+	titleTerms map[string]int
+	bodyTerms  map[string]int
+	length     int
+}
+
+// NewMemoryIndex keeps the documents in memory, for tests and single node deployments.
+// Words are matched as a whole without stemming, every query word has to match.
+func NewMemoryIndex() port.ISearchIndex {
+	return &memoryIndex{docs: make(map[string]memoryDocument)}
+}
+
+func (r *memoryIndex) Index(ctx context.Context, doc model.DocumentModel) (err error) {
+	// a comment shows the title of its post but is only searched by its own text
+	titleTerms := map[string]int{}
+	if doc.Type == model.TypePost {
+		titleTerms = countTerms(doc.Title)
+	}
+	bodyTerms := countTerms(doc.Body)
+
+	length := 0
+	for _, count := range titleTerms {
+		length += count
+	}
+	for _, count := range bodyTerms {
+		length += count
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.docs[documentKey(doc.Type, doc.ID)] = memoryDocument{
+		DocumentModel: doc,
+		titleTerms:    titleTerms,
+		bodyTerms:     bodyTerms,
+		length:        length,
+	}
+
+	// comments carry the title, status and author of their post
+	if doc.Type == model.TypePost {
+		for key, comment := range r.docs {
+			if comment.Type == model.TypeComment && comment.PostId == doc.ID {
+				comment.Title = doc.Title
+				comment.Status = doc.Status
+				comment.PostOwner = doc.PostOwner
+				r.docs[key] = comment
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *memoryIndex) Remove(ctx context.Context, docType string, id string) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.docs, documentKey(docType, id))
+
+	if docType == model.TypePost {
+		for key, comment := range r.docs {
+			if comment.Type == model.TypeComment && comment.PostId == id {
+				delete(r.docs, key)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *memoryIndex) Search(ctx context.Context, param payload.SearchRequest, page pagination.Page) (res []model.SearchResultModel, meta pagination.Meta, err error) {
+	page = page.AsOffset()
+
+	terms := tokenize(param.Query)
+	if len(terms) == 0 {
+		res, meta = pagination.Paginate(page, []model.SearchResultModel{}, 0, resultKey)
+		return res, meta, nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matches := []model.SearchResultModel{}
+	for _, doc := range r.docs {
+		if param.Type != "" && doc.Type != param.Type {
+			continue
+		}
+		if param.Status != "" && doc.Status != param.Status {
+			continue
+		}
+		if param.Author != "" && doc.Username != param.Author {
+			continue
+		}
+		if param.PublishedOnly && doc.Status != postModel.StatusPublish && doc.PostOwner != param.Owner {
+			continue
+		}
+
+		rank, ok := doc.rank(terms)
+		if !ok {
+			continue
+		}
+
+		matches = append(matches, model.SearchResultModel{
+			Type:      doc.Type,
+			ID:        doc.ID,
+			PostId:    doc.PostId,
+			Title:     doc.Title,
+			Snippet:   snippet(doc.Body, terms),
+			Rank:      rank,
+			Status:    doc.Status,
+			Username:  doc.Username,
+			CreatedAt: doc.CreatedAt,
+		})
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Rank != matches[j].Rank {
+			return matches[i].Rank > matches[j].Rank
+		}
+		return matches[i].CreatedAt.After(matches[j].CreatedAt)
+	})
+
+	offset := min(page.Offset(), len(matches))
+	end := min(offset+page.Limit, len(matches))
+
+	res, meta = pagination.Paginate(page, matches[offset:end], int64(len(matches)), resultKey)
+	return res, meta, nil
+}
+
+func resultKey(result model.SearchResultModel) pagination.Key {
+	return pagination.Key{CreatedAt: result.CreatedAt, ID: result.ID}
+}
+
+// rank scores a document the way ts_rank does with the title weighted above the body,
+// it reports false when one of the terms is missing
+func (d memoryDocument) rank(terms []string) (float64, bool) {
+	rank := 0.0
+	for _, term := range terms {
+		title, body := d.titleTerms[term], d.bodyTerms[term]
+		if title == 0 && body == 0 {
+			return 0, false
+		}
+		rank += titleWeight*float64(title) + bodyWeight*float64(body)
+	}
+
+	return rank / (1 + math.Log(float64(1+d.length))), true
+}
+
+// snippet returns a window of the text around the first match with matched words wrapped in <b></b>
+func snippet(text string, terms []string) string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return ""
+	}
+
+	wanted := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		wanted[term] = true
+	}
+
+	start := 0
+	for i, word := range words {
+		if matchesAny(word, wanted) {
+			start = i - snippetWords/4
+			break
+		}
+	}
+	if start < 0 {
+		start = 0
+	}
+
+	end := start + snippetWords
+	if end > len(words) {
+		end = len(words)
+	}
+
+	parts := make([]string, 0, end-start)
+	for _, word := range words[start:end] {
+		if matchesAny(word, wanted) {
+			word = "<b>" + word + "</b>"
+		}
+		parts = append(parts, word)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func matchesAny(word string, wanted map[string]bool) bool {
+	for _, term := range tokenize(word) {
+		if wanted[term] {
+			return true
+		}
+	}
+	return false
+}
+
+func countTerms(text string) map[string]int {
+	terms := make(map[string]int)
+	for _, term := range tokenize(text) {
+		terms[term]++
+	}
+	return terms
+}
+
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func documentKey(docType string, id string) string {
+	return docType + ":" + id
+}
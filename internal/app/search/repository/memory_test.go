@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"simple-blog-system/internal/app/search/model"
+	"simple-blog-system/internal/app/search/payload"
+	"simple-blog-system/pkg/pagination"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type MemoryIndexTestSuite struct {
+	suite.Suite
+	index *memoryIndex
+	ctx   context.Context
+	now   time.Time
+}
+
+func (suite *MemoryIndexTestSuite) SetupTest() {
+	suite.index = NewMemoryIndex().(*memoryIndex)
+	suite.ctx = context.Background()
+	suite.now = time.Now()
+
+	docs := []model.DocumentModel{
+		{ID: "post-1", Type: model.TypePost, PostId: "post-1", Title: "Learning Golang", Body: "A short tour of the language.", Status: "PUBLISH", Username: "alice", PostOwner: "alice", CreatedAt: suite.now.Add(-time.Hour)},
+		{ID: "post-2", Type: model.TypePost, PostId: "post-2", Title: "Weekend trip", Body: "We wrote some golang on the train, golang is fun.", Status: "DRAFT", Username: "bob", PostOwner: "bob", CreatedAt: suite.now},
+		{ID: "comment-1", Type: model.TypeComment, PostId: "post-1", Title: "Learning Golang", Body: "Great golang tour!", Status: "PUBLISH", Username: "bob", PostOwner: "alice", CreatedAt: suite.now},
+	}
+	for _, doc := range docs {
+		assert.NoError(suite.T(), suite.index.Index(suite.ctx, doc))
+	}
+}
+
+func TestMemoryIndexTestSuite(t *testing.T) {
+	suite.Run(t, new(MemoryIndexTestSuite))
+}
+
+func (suite *MemoryIndexTestSuite) search(param payload.SearchRequest) []model.SearchResultModel {
+	res, _, err := suite.index.Search(suite.ctx, param, pagination.Page{Limit: 10})
+	assert.NoError(suite.T(), err)
+	return res
+}
+
+func (suite *MemoryIndexTestSuite) TestSearch_TitleRanksAboveBody() {
+	res := suite.search(payload.SearchRequest{Query: "golang", Type: model.TypePost})
+
+	assert.Len(suite.T(), res, 2)
+	assert.Equal(suite.T(), "post-1", res[0].ID)
+	assert.Equal(suite.T(), "post-2", res[1].ID)
+	assert.Greater(suite.T(), res[0].Rank, res[1].Rank)
+}
+
+func (suite *MemoryIndexTestSuite) TestSearch_Snippet() {
+	res := suite.search(payload.SearchRequest{Query: "GoLang train", Type: model.TypePost})
+
+	assert.Len(suite.T(), res, 1)
+	assert.Equal(suite.T(), "We wrote some <b>golang</b> on the <b>train,</b> <b>golang</b> is fun.", res[0].Snippet)
+}
+
+func (suite *MemoryIndexTestSuite) TestSearch_AllTermsMustMatch() {
+	res := suite.search(payload.SearchRequest{Query: "golang weekend"})
+
+	assert.Len(suite.T(), res, 1)
+	assert.Equal(suite.T(), "post-2", res[0].ID)
+}
+
+func (suite *MemoryIndexTestSuite) TestSearch_Filters() {
+	res := suite.search(payload.SearchRequest{Query: "golang", Status: "PUBLISH"})
+	assert.Len(suite.T(), res, 2)
+
+	res = suite.search(payload.SearchRequest{Query: "golang", Author: "bob"})
+	assert.Len(suite.T(), res, 2)
+
+	res = suite.search(payload.SearchRequest{Query: "golang", Type: model.TypeComment})
+	assert.Len(suite.T(), res, 1)
+	assert.Equal(suite.T(), "post-1", res[0].PostId)
+}
+
+func (suite *MemoryIndexTestSuite) TestSearch_PublishedOnly() {
+	res := suite.search(payload.SearchRequest{Query: "golang", PublishedOnly: true, Owner: "alice"})
+	assert.Len(suite.T(), res, 2)
+	for _, result := range res {
+		assert.Equal(suite.T(), "PUBLISH", result.Status)
+	}
+
+	res = suite.search(payload.SearchRequest{Query: "golang", PublishedOnly: true, Owner: "bob"})
+	assert.Len(suite.T(), res, 3)
+}
+
+func (suite *MemoryIndexTestSuite) TestSearch_PublishedOnlyHidesCommentsOfDrafts() {
+	err := suite.index.Index(suite.ctx, model.DocumentModel{ID: "post-1", Type: model.TypePost, PostId: "post-1", Title: "Go basics", Body: "Tour", Status: "DRAFT", Username: "alice", PostOwner: "alice"})
+	assert.NoError(suite.T(), err)
+
+	// bob wrote the comment but the post is a draft of alice
+	res := suite.search(payload.SearchRequest{Query: "golang", Type: model.TypeComment, PublishedOnly: true, Owner: "bob"})
+	assert.Len(suite.T(), res, 0)
+
+	res = suite.search(payload.SearchRequest{Query: "golang", Type: model.TypeComment, PublishedOnly: true, Owner: "alice"})
+	assert.Len(suite.T(), res, 1)
+}
+
+func (suite *MemoryIndexTestSuite) TestSearch_Pagination() {
+	param := payload.SearchRequest{Query: "golang"}
+
+	res, meta, err := suite.index.Search(suite.ctx, param, pagination.Page{Limit: 2, Number: 2})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), res, 1)
+	assert.Equal(suite.T(), int64(3), *meta.Total)
+	assert.Equal(suite.T(), 2, *meta.TotalPages)
+
+	res, _, err = suite.index.Search(suite.ctx, param, pagination.Page{Limit: 2, Number: 3})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), res, 0)
+}
+
+func (suite *MemoryIndexTestSuite) TestIndex_PostUpdatesItsComments() {
+	err := suite.index.Index(suite.ctx, model.DocumentModel{ID: "post-1", Type: model.TypePost, PostId: "post-1", Title: "Go basics", Body: "Tour", Status: "DRAFT", Username: "alice"})
+	assert.NoError(suite.T(), err)
+
+	res := suite.search(payload.SearchRequest{Query: "golang", Type: model.TypeComment})
+	assert.Len(suite.T(), res, 1)
+	assert.Equal(suite.T(), "Go basics", res[0].Title)
+	assert.Equal(suite.T(), "DRAFT", res[0].Status)
+}
+
+func (suite *MemoryIndexTestSuite) TestRemove_PostRemovesItsComments() {
+	assert.NoError(suite.T(), suite.index.Remove(suite.ctx, model.TypePost, "post-1"))
+
+	res := suite.search(payload.SearchRequest{Query: "golang"})
+	assert.Len(suite.T(), res, 1)
+	assert.Equal(suite.T(), "post-2", res[0].ID)
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+
+	"simple-blog-system/config/db"
+	"simple-blog-system/pkg/pagination"
+	"simple-blog-system/pkg/transaction"
+
+	"simple-blog-system/internal/app/search/model"
+	"simple-blog-system/internal/app/search/payload"
+	"simple-blog-system/internal/app/search/port"
+)
+
+const (
+	// headlineOptions shapes the ts_headline snippets, matches are wrapped in <b></b>
+	headlineOptions = "MaxWords=35, MinWords=15, MaxFragments=2"
+
+	searchPostsQuery = `
+		SELECT 'post' AS type, posts.id, posts.id AS post_id, posts.title, posts.status, posts.username, posts.created_at,
+			ts_rank_cd(posts.search_vector, query) AS rank,
+			ts_headline('english', posts.body, query, @options) AS snippet
+		FROM posts, websearch_to_tsquery('english', @query) query
+		WHERE posts.deleted_at IS NULL AND posts.search_vector @@ query`
+
+	searchCommentsQuery = `
+		SELECT 'comment' AS type, comments.id, comments.post_id, posts.title, posts.status, comments.username, comments.created_at,
+			ts_rank_cd(comments.search_vector, query) AS rank,
+			ts_headline('english', comments.comment, query, @options) AS snippet
+		FROM comments
+		JOIN posts ON posts.id = comments.post_id AND posts.deleted_at IS NULL,
+			websearch_to_tsquery('english', @query) query
+		WHERE comments.deleted_at IS NULL AND comments.status = 'approved' AND comments.search_vector @@ query`
+
+	// visibleQuery keeps both branches to published posts and the posts of @owner, comments follow their post
+	visibleQuery = `
+			AND (posts.status = 'PUBLISH' OR posts.username = @owner)`
+)
+
+type postgresIndex struct {
+	db *db.GormDB
+}
+
+// NewPostgresIndex searches the search_vector columns of posts and comments,
+// the columns are generated by the database so Index and Remove have nothing to do
+func NewPostgresIndex(db *db.GormDB) port.ISearchIndex {
+	return postgresIndex{db: db}
+}
+
+func (r postgresIndex) Index(ctx context.Context, doc model.DocumentModel) (err error) {
+	return nil
+}
+
+func (r postgresIndex) Remove(ctx context.Context, docType string, id string) (err error) {
+	return nil
+}
+
+func (r postgresIndex) Search(ctx context.Context, param payload.SearchRequest, page pagination.Page) (res []model.SearchResultModel, meta pagination.Meta, err error) {
+	page = page.AsOffset()
+
+	posts, comments := searchPostsQuery, searchCommentsQuery
+	if param.PublishedOnly {
+		posts += visibleQuery
+		comments += visibleQuery
+	}
+
+	trx := transaction.GetTrxContext(ctx, r.db)
+	results := trx.Raw(posts+"\n\t\tUNION ALL"+comments, map[string]interface{}{
+		"query":   param.Query,
+		"options": headlineOptions,
+		"owner":   param.Owner,
+	})
+
+	query := trx.Table("(?) AS results", results)
+	if param.Type != "" {
+		query = query.Where("type = ?", param.Type)
+	}
+	if param.Status != "" {
+		query = query.Where("status = ?", param.Status)
+	}
+	if param.Author != "" {
+		query = query.Where("username = ?", param.Author)
+	}
+
+	total, err := page.Count(query)
+	if err != nil {
+		return nil, meta, err
+	}
+
+	err = query.Order("rank DESC, created_at DESC").Limit(page.Limit).Offset(page.Offset()).Scan(&res).Error
+	if err != nil {
+		return nil, meta, err
+	}
+
+	res, meta = pagination.Paginate(page, res, total, resultKey)
+	return res, meta, nil
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"simple-blog-system/config/db"
+	"simple-blog-system/internal/app/search/payload"
+	"simple-blog-system/pkg/pagination"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type PostgresIndexTestSuite struct {
+	suite.Suite
+	db    *gorm.DB
+	mock  sqlmock.Sqlmock
+	index postgresIndex
+}
+
+func (suite *PostgresIndexTestSuite) SetupTest() {
+	var (
+		sqlDB *sql.DB
+		err   error
+	)
+
+	sqlDB, suite.mock, err = sqlmock.New()
+	assert.NoError(suite.T(), err)
+
+	suite.db, err = gorm.Open(postgres.New(postgres.Config{
+		Conn: sqlDB,
+	}), &gorm.Config{})
+	assert.NoError(suite.T(), err)
+
+	suite.index = postgresIndex{db: &db.GormDB{DB: suite.db}}
+}
+
+func (suite *PostgresIndexTestSuite) TearDownTest() {
+	sqlDB, err := suite.db.DB()
+	assert.NoError(suite.T(), err)
+	sqlDB.Close()
+}
+
+func TestPostgresIndexTestSuite(t *testing.T) {
+	suite.Run(t, new(PostgresIndexTestSuite))
+}
+
+func (suite *PostgresIndexTestSuite) TestSearch_Success() {
+	ctx := context.Background()
+	now := time.Now()
+
+	param := payload.SearchRequest{Query: "golang", Type: "post", Status: "PUBLISH", Author: "alice"}
+	page := pagination.Page{Limit: 5, Number: 2}
+
+	rows := sqlmock.NewRows([]string{"type", "id", "post_id", "title", "status", "username", "created_at", "rank", "snippet"}).
+		AddRow("post", "post-1", "post-1", "Learning Golang", "PUBLISH", "alice", now, 0.6, "A tour of <b>golang</b>")
+
+	suite.mock.ExpectQuery(`SELECT count\(\*\) FROM \(.+UNION ALL.+\) AS results WHERE type = \$5 AND status = \$6 AND username = \$7`).
+		WithArgs(headlineOptions, param.Query, headlineOptions, param.Query, param.Type, param.Status, param.Author).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(6))
+	suite.mock.ExpectQuery(`SELECT \* FROM \(.+ts_rank_cd\(posts.search_vector, query\).+websearch_to_tsquery\('english', \$2\).+UNION ALL.+websearch_to_tsquery\('english', \$4\).+\) AS results WHERE type = \$5 AND status = \$6 AND username = \$7 ORDER BY rank DESC, created_at DESC LIMIT \$8 OFFSET \$9`).
+		WithArgs(headlineOptions, param.Query, headlineOptions, param.Query, param.Type, param.Status, param.Author, page.Limit, 5).
+		WillReturnRows(rows)
+
+	res, meta, err := suite.index.Search(ctx, param, page)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), res, 1)
+	assert.Equal(suite.T(), "A tour of <b>golang</b>", res[0].Snippet)
+	assert.Equal(suite.T(), 0.6, res[0].Rank)
+	assert.Equal(suite.T(), int64(6), *meta.Total)
+	assert.Equal(suite.T(), 2, *meta.TotalPages)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostgresIndexTestSuite) TestSearch_PublishedOnly() {
+	ctx := context.Background()
+
+	param := payload.SearchRequest{Query: "golang", PublishedOnly: true, Owner: "alice"}
+	page := pagination.Page{Limit: 10}
+
+	suite.mock.ExpectQuery(`SELECT count\(\*\) FROM \(.+posts.search_vector @@ query\s+AND \(posts.status = 'PUBLISH' OR posts.username = \$3\)\s+UNION ALL.+comments.search_vector @@ query\s+AND \(posts.status = 'PUBLISH' OR posts.username = \$6\)\) AS results`).
+		WithArgs(headlineOptions, param.Query, param.Owner, headlineOptions, param.Query, param.Owner).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	suite.mock.ExpectQuery(`SELECT \* FROM \(.+\) AS results ORDER BY rank DESC, created_at DESC LIMIT \$7`).
+		WithArgs(headlineOptions, param.Query, param.Owner, headlineOptions, param.Query, param.Owner, page.Limit).
+		WillReturnRows(sqlmock.NewRows([]string{"type", "id", "post_id", "title", "status", "username", "created_at", "rank", "snippet"}))
+
+	res, _, err := suite.index.Search(ctx, param, page)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), res, 0)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
@@ -0,0 +1,19 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"simple-blog-system/internal/app/search/port"
+)
+
+type (
+	routes struct{}
+)
+
+var (
+	Routes routes
+)
+
+func (r routes) New(router *gin.RouterGroup, handler port.ISearchHandler) {
+	router.GET("/", handler.Search)
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	commentModel "simple-blog-system/internal/app/comment/model"
+	commentPayload "simple-blog-system/internal/app/comment/payload"
+	commentPort "simple-blog-system/internal/app/comment/port"
+	postModel "simple-blog-system/internal/app/post/model"
+	postPayload "simple-blog-system/internal/app/post/payload"
+	postPort "simple-blog-system/internal/app/post/port"
+	"simple-blog-system/internal/app/search/model"
+	"simple-blog-system/internal/app/search/payload"
+	"simple-blog-system/internal/app/search/port"
+	userPort "simple-blog-system/internal/app/user/port"
+	"simple-blog-system/pkg/authorization"
+	"simple-blog-system/pkg/pagination"
+)
+
+const reindexBatch = 500
+
+type service struct {
+	searchIndex port.ISearchIndex
+	userRepo    userPort.IUserRepository
+	postRepo    postPort.IPostRepository
+	commentRepo commentPort.ICommentRepository
+}
+
+func New(searchIndex port.ISearchIndex, userRepo userPort.IUserRepository, postRepo postPort.IPostRepository, commentRepo commentPort.ICommentRepository) port.ISearchService {
+	return &service{
+		searchIndex: searchIndex,
+		userRepo:    userRepo,
+		postRepo:    postRepo,
+		commentRepo: commentRepo,
+	}
+}
+
+// Search pages by page number since results are ordered by rank, users that are not moderators find published
+// posts and their own, and filter by a status other than PUBLISH only on their own posts
+func (s *service) Search(ctx context.Context, username string, param payload.SearchRequest, page pagination.Page) (res []model.SearchResultModel, meta pagination.Meta, err error) {
+	users, qerr := s.userRepo.GetUserByUsername(ctx, username)
+	if len(users) == 0 || qerr != nil {
+		return nil, meta, errors.New("user not found")
+	}
+
+	if !authorization.IsModerator(users[0].Role) {
+		if param.Status != "" && param.Status != postModel.StatusPublish && param.Author != users[0].Username {
+			return nil, meta, authorization.ErrForbidden
+		}
+		param.PublishedOnly, param.Owner = true, users[0].Username
+	}
+
+	return s.searchIndex.Search(ctx, param, page.AsOffset())
+}
+
+// Reindex feeds every post and comment to the index, needed on start for indexes that are not backed by the database
+func (s *service) Reindex(ctx context.Context) (err error) {
+	page := pagination.Page{Limit: reindexBatch}
+	for {
+		posts, meta, err := s.postRepo.GetAllPost(ctx, postPayload.PostFilter{}, page)
+		if err != nil {
+			return err
+		}
+
+		for _, post := range posts {
+			err = s.searchIndex.Index(ctx, model.NewPostDocument(post))
+			if err != nil {
+				return err
+			}
+		}
+
+		var ok bool
+		if page, ok = page.Next(meta); !ok {
+			break
+		}
+	}
+
+	page = pagination.Page{Limit: reindexBatch}
+	for {
+		comments, meta, err := s.commentRepo.GetAllComment(ctx, commentPayload.CommentFilter{Status: []string{commentModel.StatusApproved}}, page)
+		if err != nil {
+			return err
+		}
+
+		for _, comment := range comments {
+			// comments of deleted posts are not searchable
+			if comment.Post.ID == "" {
+				continue
+			}
+
+			err = s.searchIndex.Index(ctx, model.NewCommentDocument(comment))
+			if err != nil {
+				return err
+			}
+		}
+
+		var ok bool
+		if page, ok = page.Next(meta); !ok {
+			break
+		}
+	}
+
+	return nil
+}
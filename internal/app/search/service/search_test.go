@@ -0,0 +1,455 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"simple-blog-system/internal/app/comment/model"
+	commentPayload "simple-blog-system/internal/app/comment/payload"
+	postModel "simple-blog-system/internal/app/post/model"
+	postPayload "simple-blog-system/internal/app/post/payload"
+	searchModel "simple-blog-system/internal/app/search/model"
+	"simple-blog-system/internal/app/search/payload"
+	searchRepository "simple-blog-system/internal/app/search/repository"
+	userModel "simple-blog-system/internal/app/user/model"
+	"simple-blog-system/pkg/authorization"
+	"simple-blog-system/pkg/pagination"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// Mock for ICommentRepository
+type MockCommentRepository struct {
+	mock.Mock
+}
+
+func (m *MockCommentRepository) InsertComment(ctx context.Context, comment model.CommentModel) (model.CommentModel, error) {
+	args := m.Called(ctx, comment)
+	return args.Get(0).(model.CommentModel), args.Error(1)
+}
+
+func (m *MockCommentRepository) UpdateComment(ctx context.Context, comment model.CommentModel) (model.CommentModel, error) {
+	args := m.Called(ctx, comment)
+	return args.Get(0).(model.CommentModel), args.Error(1)
+}
+
+func (m *MockCommentRepository) GetCommentById(ctx context.Context, id string) (*model.CommentModel, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CommentModel), args.Error(1)
+}
+
+func (m *MockCommentRepository) DeleteComment(ctx context.Context, comment model.CommentModel) error {
+	args := m.Called(ctx, comment)
+	return args.Error(0)
+}
+
+func (m *MockCommentRepository) GetAllComment(ctx context.Context, filter commentPayload.CommentFilter, page pagination.Page) ([]model.CommentModel, pagination.Meta, error) {
+	args := m.Called(ctx, filter, page)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(pagination.Meta), args.Error(2)
+	}
+	return args.Get(0).([]model.CommentModel), args.Get(1).(pagination.Meta), args.Error(2)
+}
+
+func (m *MockCommentRepository) GetCommentsByPostId(ctx context.Context, postId string, filter commentPayload.CommentFilter, page pagination.Page) ([]model.CommentModel, pagination.Meta, error) {
+	args := m.Called(ctx, postId, filter, page)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(pagination.Meta), args.Error(2)
+	}
+	return args.Get(0).([]model.CommentModel), args.Get(1).(pagination.Meta), args.Error(2)
+}
+
+func (m *MockCommentRepository) GetCommentThread(ctx context.Context, postId string) ([]model.CommentModel, error) {
+	args := m.Called(ctx, postId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.CommentModel), args.Error(1)
+}
+
+func (m *MockCommentRepository) GetCommentsByIds(ctx context.Context, ids []string) ([]model.CommentModel, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.CommentModel), args.Error(1)
+}
+
+func (m *MockCommentRepository) UpdateCommentStatus(ctx context.Context, ids []string, status string, updatedBy string) error {
+	args := m.Called(ctx, ids, status, updatedBy)
+	return args.Error(0)
+}
+
+func (m *MockCommentRepository) CountUserComments(ctx context.Context, username string, status string) (int64, error) {
+	args := m.Called(ctx, username, status)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockCommentRepository) AnonymizeUserComments(ctx context.Context, username string, placeholder string) ([]model.CommentModel, error) {
+	args := m.Called(ctx, username, placeholder)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.CommentModel), args.Error(1)
+}
+
+func (m *MockCommentRepository) DeleteUserComments(ctx context.Context, username string) ([]model.CommentModel, error) {
+	args := m.Called(ctx, username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.CommentModel), args.Error(1)
+}
+
+// Mock for IUserRepository
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) InsertUser(ctx context.Context, user userModel.AuthUserModel) (userModel.AuthUserModel, error) {
+	args := m.Called(ctx, user)
+	return args.Get(0).(userModel.AuthUserModel), args.Error(1)
+}
+
+func (m *MockUserRepository) GetUserByUsername(ctx context.Context, username string) ([]userModel.AuthUserModel, error) {
+	args := m.Called(ctx, username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]userModel.AuthUserModel), args.Error(1)
+}
+
+func (m *MockUserRepository) GetPasswordByUsername(ctx context.Context, username string) ([]userModel.AuthUserModel, error) {
+	args := m.Called(ctx, username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]userModel.AuthUserModel), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdateLastLogin(ctx context.Context, user userModel.AuthUserModel) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateRole(ctx context.Context, username string, role string) error {
+	args := m.Called(ctx, username, role)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdatePassword(ctx context.Context, username string, password string) error {
+	args := m.Called(ctx, username, password)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateEmail(ctx context.Context, username string, email string) error {
+	args := m.Called(ctx, username, email)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) VerifyEmail(ctx context.Context, username string, email string) (bool, error) {
+	args := m.Called(ctx, username, email)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) IsUsernameTaken(ctx context.Context, username string) (bool, error) {
+	args := m.Called(ctx, username)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) SuspendUser(ctx context.Context, username string, reason string, until *time.Time) error {
+	args := m.Called(ctx, username, reason, until)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ReactivateUser(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ScheduleDeletion(ctx context.Context, username string, deleteAfter *time.Time) error {
+	args := m.Called(ctx, username, deleteAfter)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetUsersDueForDeletion(ctx context.Context, now time.Time, limit int) ([]userModel.AuthUserModel, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]userModel.AuthUserModel), args.Error(1)
+}
+
+func (m *MockUserRepository) DeleteUser(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetUserByEmail(ctx context.Context, email string) ([]userModel.AuthUserModel, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]userModel.AuthUserModel), args.Error(1)
+}
+
+// Mock for IPostRepository
+type MockPostRepository struct {
+	mock.Mock
+}
+
+func (m *MockPostRepository) InsertPost(ctx context.Context, post postModel.PostModel) (postModel.PostModel, error) {
+	args := m.Called(ctx, post)
+	return args.Get(0).(postModel.PostModel), args.Error(1)
+}
+
+func (m *MockPostRepository) UpdatePost(ctx context.Context, post postModel.PostModel) (postModel.PostModel, error) {
+	args := m.Called(ctx, post)
+	return args.Get(0).(postModel.PostModel), args.Error(1)
+}
+
+func (m *MockPostRepository) GetPostById(ctx context.Context, id string) (*postModel.PostModel, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*postModel.PostModel), args.Error(1)
+}
+
+func (m *MockPostRepository) DeletePost(ctx context.Context, post postModel.PostModel) error {
+	args := m.Called(ctx, post)
+	return args.Error(0)
+}
+
+func (m *MockPostRepository) GetAllPost(ctx context.Context, filter postPayload.PostFilter, page pagination.Page) ([]postModel.PostModel, pagination.Meta, error) {
+	args := m.Called(ctx, filter, page)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(pagination.Meta), args.Error(2)
+	}
+	return args.Get(0).([]postModel.PostModel), args.Get(1).(pagination.Meta), args.Error(2)
+}
+
+func (m *MockPostRepository) GetPublishedSlugs(ctx context.Context) ([]postModel.PostModel, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]postModel.PostModel), args.Error(1)
+}
+
+func (m *MockPostRepository) GetDueScheduledPosts(ctx context.Context, now time.Time, limit int) ([]postModel.PostModel, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]postModel.PostModel), args.Error(1)
+}
+
+func (m *MockPostRepository) InsertRevision(ctx context.Context, revision postModel.PostRevisionModel) (postModel.PostRevisionModel, error) {
+	args := m.Called(ctx, revision)
+	return args.Get(0).(postModel.PostRevisionModel), args.Error(1)
+}
+
+func (m *MockPostRepository) GetRevisions(ctx context.Context, postId string, page pagination.Page) ([]postModel.PostRevisionModel, pagination.Meta, error) {
+	args := m.Called(ctx, postId, page)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(pagination.Meta), args.Error(2)
+	}
+	return args.Get(0).([]postModel.PostRevisionModel), args.Get(1).(pagination.Meta), args.Error(2)
+}
+
+func (m *MockPostRepository) GetRevision(ctx context.Context, postId string, revision int) (*postModel.PostRevisionModel, error) {
+	args := m.Called(ctx, postId, revision)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*postModel.PostRevisionModel), args.Error(1)
+}
+
+func (m *MockPostRepository) CountComments(ctx context.Context, postIds []string) (map[string]int64, error) {
+	args := m.Called(ctx, postIds)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+
+func (m *MockPostRepository) GetPostBySlug(ctx context.Context, slug string) (*postModel.PostModel, error) {
+	args := m.Called(ctx, slug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*postModel.PostModel), args.Error(1)
+}
+
+func (m *MockPostRepository) IsSlugTaken(ctx context.Context, slug string, excludePostId string) (bool, error) {
+	args := m.Called(ctx, slug, excludePostId)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPostRepository) GetSlugHistory(ctx context.Context, slug string) (*postModel.PostSlugHistoryModel, error) {
+	args := m.Called(ctx, slug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*postModel.PostSlugHistoryModel), args.Error(1)
+}
+
+func (m *MockPostRepository) InsertSlugHistory(ctx context.Context, history postModel.PostSlugHistoryModel) (postModel.PostSlugHistoryModel, error) {
+	args := m.Called(ctx, history)
+	return args.Get(0).(postModel.PostSlugHistoryModel), args.Error(1)
+}
+
+func (m *MockPostRepository) DeleteSlugHistory(ctx context.Context, postId string, slug string) error {
+	args := m.Called(ctx, postId, slug)
+	return args.Error(0)
+}
+
+func (m *MockPostRepository) AnonymizeUserPosts(ctx context.Context, username string, placeholder string) ([]postModel.PostModel, error) {
+	args := m.Called(ctx, username, placeholder)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]postModel.PostModel), args.Error(1)
+}
+
+func (m *MockPostRepository) DeleteUserPosts(ctx context.Context, username string) ([]postModel.PostModel, error) {
+	args := m.Called(ctx, username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]postModel.PostModel), args.Error(1)
+}
+
+// Test Suite
+type SearchServiceTestSuite struct {
+	suite.Suite
+	service     *service
+	commentRepo *MockCommentRepository
+	userRepo    *MockUserRepository
+	postRepo    *MockPostRepository
+	ctx         context.Context
+}
+
+func (suite *SearchServiceTestSuite) SetupTest() {
+	suite.commentRepo = new(MockCommentRepository)
+	suite.userRepo = new(MockUserRepository)
+	suite.postRepo = new(MockPostRepository)
+	suite.service = &service{
+		searchIndex: searchRepository.NewMemoryIndex(),
+		userRepo:    suite.userRepo,
+		postRepo:    suite.postRepo,
+		commentRepo: suite.commentRepo,
+	}
+	suite.ctx = context.Background()
+}
+
+func TestSearchServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(SearchServiceTestSuite))
+}
+
+func (suite *SearchServiceTestSuite) TestSearch_UserNotFound() {
+	username := "nonexistent"
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{}, nil)
+
+	result, _, err := suite.service.Search(suite.ctx, username, payload.SearchRequest{Query: "golang"}, pagination.Page{Limit: 10})
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), "user not found", err.Error())
+}
+
+func (suite *SearchServiceTestSuite) TestReindex_ThenSearch() {
+	username := "testuser"
+	now := time.Now()
+
+	post := postModel.PostModel{
+		ID:        strfmt.UUID4("post-1"),
+		Username:  "alice",
+		Title:     "Learning Golang",
+		Body:      "A short tour",
+		Status:    "PUBLISH",
+		CreatedAt: now,
+	}
+
+	comments := []model.CommentModel{
+		{ID: strfmt.UUID4("comment-1"), Username: "bob", Comment: "Nice golang tour", PostId: "post-1", Post: post, CreatedAt: now},
+		// the post of this comment is deleted so it is not preloaded
+		{ID: strfmt.UUID4("comment-2"), Username: "bob", Comment: "Old golang comment", PostId: "post-9", CreatedAt: now},
+	}
+
+	suite.postRepo.On("GetAllPost", suite.ctx, postPayload.PostFilter{}, pagination.Page{Limit: reindexBatch}).Return([]postModel.PostModel{post}, pagination.Meta{}, nil)
+	suite.commentRepo.On("GetAllComment", suite.ctx, commentPayload.CommentFilter{Status: []string{model.StatusApproved}}, pagination.Page{Limit: reindexBatch}).Return(comments, pagination.Meta{}, nil)
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{{Username: username}}, nil)
+
+	err := suite.service.Reindex(suite.ctx)
+	assert.NoError(suite.T(), err)
+
+	result, _, err := suite.service.Search(suite.ctx, username, payload.SearchRequest{Query: "golang"}, pagination.Page{Limit: 10})
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 2)
+	assert.Equal(suite.T(), searchModel.TypePost, result[0].Type)
+	assert.Equal(suite.T(), searchModel.TypeComment, result[1].Type)
+	assert.Equal(suite.T(), "Learning Golang", result[1].Title)
+	suite.postRepo.AssertExpectations(suite.T())
+	suite.commentRepo.AssertExpectations(suite.T())
+}
+
+func (suite *SearchServiceTestSuite) TestSearch_HidesDraftsOfOthers() {
+	now := time.Now()
+	posts := []postModel.PostModel{
+		{ID: strfmt.UUID4("post-1"), Username: "alice", Title: "Golang draft", Status: "DRAFT", CreatedAt: now},
+		{ID: strfmt.UUID4("post-2"), Username: "bob", Title: "Golang notes", Status: "SCHEDULED", CreatedAt: now},
+		{ID: strfmt.UUID4("post-3"), Username: "alice", Title: "Golang tour", Status: "PUBLISH", CreatedAt: now},
+	}
+	comments := []model.CommentModel{
+		{ID: strfmt.UUID4("comment-1"), Username: "bob", Comment: "Golang typo", PostId: "post-1", Post: posts[0], CreatedAt: now},
+	}
+	for _, post := range posts {
+		assert.NoError(suite.T(), suite.service.searchIndex.Index(suite.ctx, searchModel.NewPostDocument(post)))
+	}
+	assert.NoError(suite.T(), suite.service.searchIndex.Index(suite.ctx, searchModel.NewCommentDocument(comments[0])))
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "bob").Return([]userModel.AuthUserModel{{Username: "bob", Role: "author"}}, nil)
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "editor").Return([]userModel.AuthUserModel{{Username: "editor", Role: "editor"}}, nil)
+
+	result, _, err := suite.service.Search(suite.ctx, "bob", payload.SearchRequest{Query: "golang"}, pagination.Page{Limit: 10})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 2)
+	for _, res := range result {
+		assert.NotEqual(suite.T(), "post-1", res.PostId)
+	}
+
+	result, _, err = suite.service.Search(suite.ctx, "editor", payload.SearchRequest{Query: "golang"}, pagination.Page{Limit: 10})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 4)
+}
+
+func (suite *SearchServiceTestSuite) TestSearch_StatusOfOthersForbidden() {
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "bob").Return([]userModel.AuthUserModel{{Username: "bob", Role: "author"}}, nil)
+
+	_, _, err := suite.service.Search(suite.ctx, "bob", payload.SearchRequest{Query: "golang", Status: "DRAFT"}, pagination.Page{Limit: 10})
+	assert.Equal(suite.T(), authorization.ErrForbidden, err)
+
+	_, _, err = suite.service.Search(suite.ctx, "bob", payload.SearchRequest{Query: "golang", Status: "DRAFT", Author: "bob"}, pagination.Page{Limit: 10})
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *SearchServiceTestSuite) TestReindex_Error() {
+	suite.postRepo.On("GetAllPost", suite.ctx, postPayload.PostFilter{}, pagination.Page{Limit: reindexBatch}).Return(nil, pagination.Meta{}, errors.New("database error"))
+
+	err := suite.service.Reindex(suite.ctx)
+
+	assert.Error(suite.T(), err)
+	suite.commentRepo.AssertNotCalled(suite.T(), "GetAllComment", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"simple-blog-system/internal/app/sitemap/port"
+	"simple-blog-system/pkg/helper"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxAge is how long crawlers and proxies may keep a sitemap or robots.txt before asking again with its ETag
+const maxAge = 5 * time.Minute
+
+type handler struct {
+	sitemapService port.ISitemapService
+}
+
+func New(sitemapService port.ISitemapService) port.ISitemapHandler {
+	return &handler{
+		sitemapService: sitemapService,
+	}
+}
+
+// @Summary Sitemap
+// @Description Sitemap of the published posts, a sitemap index of /sitemaps/{n}.xml once there are more than 50,000. Answers 304 for a matching If-None-Match or If-Modified-Since
+// @Tags sitemap
+// @Produce xml
+// @Success 200 {string} string
+// @Success 304 {string} string
+// @Router /sitemap.xml [get]
+func (h *handler) Sitemap(c *gin.Context) {
+	h.sitemap(c, 0)
+}
+
+// @Summary Sitemap Page
+// @Description One sitemap of the sitemap index, numbered from 1
+// @Tags sitemap
+// @Produce xml
+// @Param file path string true "Sitemap number with .xml, e.g. 1.xml"
+// @Success 200 {string} string
+// @Success 304 {string} string
+// @Failure 404 {object} helper.Response
+// @Router /sitemaps/{file} [get]
+func (h *handler) SitemapPage(c *gin.Context) {
+	number, ok := strings.CutSuffix(c.Param("file"), ".xml")
+	page, err := strconv.Atoi(number)
+	if !ok || err != nil || page < 1 {
+		helper.ResponseError(c, errors.New("sitemap not found"))
+		return
+	}
+
+	h.sitemap(c, page)
+}
+
+// @Summary Robots
+// @Description robots.txt with the paths crawlers should skip and the sitemap
+// @Tags sitemap
+// @Produce plain
+// @Success 200 {string} string
+// @Router /robots.txt [get]
+func (h *handler) Robots(c *gin.Context) {
+	res, err := h.sitemapService.Robots(c.Request.Context())
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseConditional(c, res.ContentType, res.Body, res.LastModified, maxAge)
+}
+
+func (h *handler) sitemap(c *gin.Context, page int) {
+	res, err := h.sitemapService.Sitemap(c.Request.Context(), page)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseConditional(c, res.ContentType, res.Body, res.LastModified, maxAge)
+}
@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+// DocumentModel is a rendered sitemap or robots.txt, LastModified is zero when it is not known
+type DocumentModel struct {
+	ContentType  string
+	Body         []byte
+	LastModified time.Time
+}
@@ -0,0 +1,17 @@
+package port
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type ISitemapHandler interface {
+
+	// (GET /sitemap.xml)
+	Sitemap(ctx *gin.Context)
+
+	// (GET /sitemaps/:file)
+	SitemapPage(ctx *gin.Context)
+
+	// (GET /robots.txt)
+	Robots(ctx *gin.Context)
+}
@@ -0,0 +1,21 @@
+package port
+
+import (
+	"context"
+
+	"simple-blog-system/internal/app/sitemap/model"
+)
+
+// ISitemapCache forgets the cached sitemap, the post service calls it whenever published posts change
+type ISitemapCache interface {
+	Invalidate()
+}
+
+type ISitemapService interface {
+	ISitemapCache
+
+	// Sitemap is the sitemap of page 0, or the sitemap index once there are more posts than one sitemap takes,
+	// pages from 1 are the sitemaps of the index
+	Sitemap(ctx context.Context, page int) (res *model.DocumentModel, err error)
+	Robots(ctx context.Context) (res *model.DocumentModel, err error)
+}
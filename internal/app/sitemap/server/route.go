@@ -0,0 +1,21 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"simple-blog-system/internal/app/sitemap/port"
+)
+
+type (
+	routes struct{}
+)
+
+var (
+	Routes routes
+)
+
+func (r routes) New(router *gin.RouterGroup, handler port.ISitemapHandler) {
+	router.GET("/sitemap.xml", handler.Sitemap)
+	router.GET("/sitemaps/:file", handler.SitemapPage)
+	router.GET("/robots.txt", handler.Robots)
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	postPort "simple-blog-system/internal/app/post/port"
+	"simple-blog-system/internal/app/sitemap/model"
+	"simple-blog-system/internal/app/sitemap/port"
+	"simple-blog-system/pkg/sitemap"
+)
+
+type Options struct {
+	// BaseURL is where the blog is served, posts are listed as BaseURL/post/{slug}
+	BaseURL string
+	// CacheTTL is how long a rendered sitemap is served before the posts are read again, changes made on this
+	// replica invalidate it at once, 0 reads the posts on every request
+	CacheTTL time.Duration
+	// Disallow are the path prefixes robots.txt keeps crawlers away from
+	Disallow []string
+}
+
+type service struct {
+	postRepo postPort.IPostRepository
+	options  Options
+	// perPage is the most URLs in one sitemap before it is split over a sitemap index
+	perPage int
+
+	// building lets one request at a time read the posts, the others wait and take its result
+	building sync.Mutex
+
+	mu         sync.Mutex
+	pages      []*model.DocumentModel
+	until      time.Time
+	generation int
+}
+
+func New(postRepo postPort.IPostRepository, options Options) port.ISitemapService {
+	options.BaseURL = strings.TrimSuffix(options.BaseURL, "/")
+
+	return &service{
+		postRepo: postRepo,
+		options:  options,
+		perPage:  sitemap.MaxURLs,
+	}
+}
+
+func (s *service) Sitemap(ctx context.Context, page int) (res *model.DocumentModel, err error) {
+	pages, err := s.cached(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if page < 0 || page >= len(pages) {
+		return nil, errors.New("sitemap not found")
+	}
+
+	return pages[page], nil
+}
+
+// Invalidate drops the rendered sitemap, a render that started before does not get cached
+func (s *service) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pages = nil
+	s.generation++
+}
+
+func (s *service) Robots(ctx context.Context) (res *model.DocumentModel, err error) {
+	var body strings.Builder
+	body.WriteString("User-agent: *\n")
+	if len(s.options.Disallow) == 0 {
+		body.WriteString("Disallow:\n")
+	}
+	for _, path := range s.options.Disallow {
+		body.WriteString("Disallow: " + path + "\n")
+	}
+	body.WriteString("\nSitemap: " + s.options.BaseURL + "/sitemap.xml\n")
+
+	return &model.DocumentModel{
+		ContentType: "text/plain; charset=utf-8",
+		Body:        []byte(body.String()),
+	}, nil
+}
+
+func (s *service) cached(ctx context.Context) ([]*model.DocumentModel, error) {
+	if pages, _, ok := s.load(); ok {
+		return pages, nil
+	}
+
+	s.building.Lock()
+	defer s.building.Unlock()
+
+	pages, generation, ok := s.load()
+	if ok {
+		return pages, nil
+	}
+
+	pages, err := s.render(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if s.generation == generation && s.options.CacheTTL > 0 {
+		s.pages = pages
+		s.until = time.Now().Add(s.options.CacheTTL)
+	}
+	s.mu.Unlock()
+
+	return pages, nil
+}
+
+func (s *service) load() (pages []*model.DocumentModel, generation int, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.pages, s.generation, s.pages != nil && time.Now().Before(s.until)
+}
+
+// render lists every published post in one sitemap, or over sitemaps of perPage posts behind a sitemap index
+func (s *service) render(ctx context.Context) ([]*model.DocumentModel, error) {
+	posts, err := s.postRepo.GetPublishedSlugs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make([]sitemap.URL, 0, len(posts))
+	for _, post := range posts {
+		urls = append(urls, sitemap.URL{
+			Loc:     s.options.BaseURL + "/post/" + url.PathEscape(post.Slug),
+			LastMod: post.UpdatedAt,
+		})
+	}
+
+	if len(urls) <= s.perPage {
+		doc, err := urlSet(urls)
+		if err != nil {
+			return nil, err
+		}
+		return []*model.DocumentModel{doc}, nil
+	}
+
+	pages := []*model.DocumentModel{nil}
+	sitemaps := []sitemap.URL{}
+	for start := 0; start < len(urls); start += s.perPage {
+		doc, err := urlSet(urls[start:min(start+s.perPage, len(urls))])
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, doc)
+		sitemaps = append(sitemaps, sitemap.URL{
+			Loc:     s.options.BaseURL + "/sitemaps/" + strconv.Itoa(len(pages)-1) + ".xml",
+			LastMod: doc.LastModified,
+		})
+	}
+
+	body, err := sitemap.Index(sitemaps)
+	if err != nil {
+		return nil, err
+	}
+	pages[0] = &model.DocumentModel{ContentType: sitemap.ContentType, Body: body, LastModified: lastModified(sitemaps)}
+
+	return pages, nil
+}
+
+func urlSet(urls []sitemap.URL) (*model.DocumentModel, error) {
+	body, err := sitemap.URLSet(urls)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.DocumentModel{ContentType: sitemap.ContentType, Body: body, LastModified: lastModified(urls)}, nil
+}
+
+func lastModified(urls []sitemap.URL) time.Time {
+	var res time.Time
+	for _, url := range urls {
+		if url.LastMod.After(res) {
+			res = url.LastMod
+		}
+	}
+
+	return res
+}
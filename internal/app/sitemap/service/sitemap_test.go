@@ -0,0 +1,329 @@
+package service
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"testing"
+	"time"
+
+	postModel "simple-blog-system/internal/app/post/model"
+	postPayload "simple-blog-system/internal/app/post/payload"
+	"simple-blog-system/pkg/pagination"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// Mock for IPostRepository
+type MockPostRepository struct {
+	mock.Mock
+}
+
+func (m *MockPostRepository) InsertPost(ctx context.Context, post postModel.PostModel) (postModel.PostModel, error) {
+	args := m.Called(ctx, post)
+	return args.Get(0).(postModel.PostModel), args.Error(1)
+}
+
+func (m *MockPostRepository) UpdatePost(ctx context.Context, post postModel.PostModel) (postModel.PostModel, error) {
+	args := m.Called(ctx, post)
+	return args.Get(0).(postModel.PostModel), args.Error(1)
+}
+
+func (m *MockPostRepository) GetPostById(ctx context.Context, id string) (*postModel.PostModel, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*postModel.PostModel), args.Error(1)
+}
+
+func (m *MockPostRepository) DeletePost(ctx context.Context, post postModel.PostModel) error {
+	args := m.Called(ctx, post)
+	return args.Error(0)
+}
+
+func (m *MockPostRepository) GetAllPost(ctx context.Context, filter postPayload.PostFilter, page pagination.Page) ([]postModel.PostModel, pagination.Meta, error) {
+	args := m.Called(ctx, filter, page)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(pagination.Meta), args.Error(2)
+	}
+	return args.Get(0).([]postModel.PostModel), args.Get(1).(pagination.Meta), args.Error(2)
+}
+
+func (m *MockPostRepository) GetPublishedSlugs(ctx context.Context) ([]postModel.PostModel, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]postModel.PostModel), args.Error(1)
+}
+
+func (m *MockPostRepository) GetDueScheduledPosts(ctx context.Context, now time.Time, limit int) ([]postModel.PostModel, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]postModel.PostModel), args.Error(1)
+}
+
+func (m *MockPostRepository) InsertRevision(ctx context.Context, revision postModel.PostRevisionModel) (postModel.PostRevisionModel, error) {
+	args := m.Called(ctx, revision)
+	return args.Get(0).(postModel.PostRevisionModel), args.Error(1)
+}
+
+func (m *MockPostRepository) GetRevisions(ctx context.Context, postId string, page pagination.Page) ([]postModel.PostRevisionModel, pagination.Meta, error) {
+	args := m.Called(ctx, postId, page)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(pagination.Meta), args.Error(2)
+	}
+	return args.Get(0).([]postModel.PostRevisionModel), args.Get(1).(pagination.Meta), args.Error(2)
+}
+
+func (m *MockPostRepository) GetRevision(ctx context.Context, postId string, revision int) (*postModel.PostRevisionModel, error) {
+	args := m.Called(ctx, postId, revision)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*postModel.PostRevisionModel), args.Error(1)
+}
+
+func (m *MockPostRepository) CountComments(ctx context.Context, postIds []string) (map[string]int64, error) {
+	args := m.Called(ctx, postIds)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+
+func (m *MockPostRepository) GetPostBySlug(ctx context.Context, slug string) (*postModel.PostModel, error) {
+	args := m.Called(ctx, slug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*postModel.PostModel), args.Error(1)
+}
+
+func (m *MockPostRepository) IsSlugTaken(ctx context.Context, slug string, excludePostId string) (bool, error) {
+	args := m.Called(ctx, slug, excludePostId)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPostRepository) GetSlugHistory(ctx context.Context, slug string) (*postModel.PostSlugHistoryModel, error) {
+	args := m.Called(ctx, slug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*postModel.PostSlugHistoryModel), args.Error(1)
+}
+
+func (m *MockPostRepository) InsertSlugHistory(ctx context.Context, history postModel.PostSlugHistoryModel) (postModel.PostSlugHistoryModel, error) {
+	args := m.Called(ctx, history)
+	return args.Get(0).(postModel.PostSlugHistoryModel), args.Error(1)
+}
+
+func (m *MockPostRepository) DeleteSlugHistory(ctx context.Context, postId string, slug string) error {
+	args := m.Called(ctx, postId, slug)
+	return args.Error(0)
+}
+
+func (m *MockPostRepository) AnonymizeUserPosts(ctx context.Context, username string, placeholder string) ([]postModel.PostModel, error) {
+	args := m.Called(ctx, username, placeholder)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]postModel.PostModel), args.Error(1)
+}
+
+func (m *MockPostRepository) DeleteUserPosts(ctx context.Context, username string) ([]postModel.PostModel, error) {
+	args := m.Called(ctx, username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]postModel.PostModel), args.Error(1)
+}
+
+type SitemapServiceTestSuite struct {
+	suite.Suite
+	postRepo *MockPostRepository
+	service  *service
+	ctx      context.Context
+}
+
+func (suite *SitemapServiceTestSuite) SetupTest() {
+	suite.postRepo = new(MockPostRepository)
+	suite.service = New(suite.postRepo, Options{
+		BaseURL:  "https://blog.example.com/",
+		CacheTTL: time.Hour,
+		Disallow: []string{"/v1/api/", "/swagger/"},
+	}).(*service)
+	suite.ctx = context.Background()
+}
+
+func TestSitemapServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(SitemapServiceTestSuite))
+}
+
+// posts are n published posts, the day of the month of UpdatedAt is their number
+func posts(n int) []postModel.PostModel {
+	res := []postModel.PostModel{}
+	for i := 1; i <= n; i++ {
+		res = append(res, postModel.PostModel{
+			ID:        strfmt.UUID4("post-" + string(rune('0'+i))),
+			Slug:      "post-" + string(rune('0'+i)),
+			UpdatedAt: time.Date(2026, 3, i, 9, 0, 0, 0, time.UTC),
+		})
+	}
+
+	return res
+}
+
+type urlSetDoc struct {
+	XMLName xml.Name `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []struct {
+		Loc     string `xml:"loc"`
+		LastMod string `xml:"lastmod"`
+	} `xml:"url"`
+}
+
+type sitemapIndexDoc struct {
+	XMLName  xml.Name `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 sitemapindex"`
+	Sitemaps []struct {
+		Loc     string `xml:"loc"`
+		LastMod string `xml:"lastmod"`
+	} `xml:"sitemap"`
+}
+
+func (suite *SitemapServiceTestSuite) TestSitemap_URLSet() {
+	suite.postRepo.On("GetPublishedSlugs", suite.ctx).Return(posts(2), nil)
+
+	res, err := suite.service.Sitemap(suite.ctx, 0)
+
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "application/xml; charset=utf-8", res.ContentType)
+	assert.Equal(suite.T(), time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC), res.LastModified)
+
+	var doc urlSetDoc
+	suite.Require().NoError(xml.Unmarshal(res.Body, &doc))
+	suite.Require().Len(doc.URLs, 2)
+	assert.Equal(suite.T(), "https://blog.example.com/post/post-1", doc.URLs[0].Loc)
+	assert.Equal(suite.T(), "2026-03-01T09:00:00Z", doc.URLs[0].LastMod)
+
+	_, err = suite.service.Sitemap(suite.ctx, 1)
+	assert.EqualError(suite.T(), err, "sitemap not found")
+}
+
+func (suite *SitemapServiceTestSuite) TestSitemap_IndexAboveLimit() {
+	suite.service.perPage = 2
+	suite.postRepo.On("GetPublishedSlugs", suite.ctx).Return(posts(5), nil).Once()
+
+	res, err := suite.service.Sitemap(suite.ctx, 0)
+
+	suite.Require().NoError(err)
+	var index sitemapIndexDoc
+	suite.Require().NoError(xml.Unmarshal(res.Body, &index))
+	suite.Require().Len(index.Sitemaps, 3)
+	assert.Equal(suite.T(), "https://blog.example.com/sitemaps/1.xml", index.Sitemaps[0].Loc)
+	assert.Equal(suite.T(), "2026-03-02T09:00:00Z", index.Sitemaps[0].LastMod)
+	assert.Equal(suite.T(), "https://blog.example.com/sitemaps/3.xml", index.Sitemaps[2].Loc)
+	assert.Equal(suite.T(), "2026-03-05T09:00:00Z", index.Sitemaps[2].LastMod)
+	assert.Equal(suite.T(), time.Date(2026, 3, 5, 9, 0, 0, 0, time.UTC), res.LastModified)
+
+	last, err := suite.service.Sitemap(suite.ctx, 3)
+	suite.Require().NoError(err)
+	var doc urlSetDoc
+	suite.Require().NoError(xml.Unmarshal(last.Body, &doc))
+	suite.Require().Len(doc.URLs, 1)
+	assert.Equal(suite.T(), "https://blog.example.com/post/post-5", doc.URLs[0].Loc)
+
+	_, err = suite.service.Sitemap(suite.ctx, 4)
+	assert.EqualError(suite.T(), err, "sitemap not found")
+	suite.postRepo.AssertExpectations(suite.T())
+}
+
+func (suite *SitemapServiceTestSuite) TestSitemap_CachedUntilInvalidated() {
+	suite.postRepo.On("GetPublishedSlugs", suite.ctx).Return(posts(1), nil).Once()
+
+	first, err := suite.service.Sitemap(suite.ctx, 0)
+	suite.Require().NoError(err)
+	second, err := suite.service.Sitemap(suite.ctx, 0)
+	suite.Require().NoError(err)
+	assert.Same(suite.T(), first, second)
+	suite.postRepo.AssertNumberOfCalls(suite.T(), "GetPublishedSlugs", 1)
+
+	suite.service.Invalidate()
+	suite.postRepo.On("GetPublishedSlugs", suite.ctx).Return(posts(2), nil).Once()
+
+	third, err := suite.service.Sitemap(suite.ctx, 0)
+	suite.Require().NoError(err)
+	assert.Contains(suite.T(), string(third.Body), "post-2")
+	suite.postRepo.AssertNumberOfCalls(suite.T(), "GetPublishedSlugs", 2)
+}
+
+func (suite *SitemapServiceTestSuite) TestSitemap_InvalidatedWhileRendering() {
+	// a post published while the sitemap is read may be missing from it, so that render is not kept
+	suite.postRepo.On("GetPublishedSlugs", suite.ctx).Run(func(args mock.Arguments) {
+		suite.service.Invalidate()
+	}).Return(posts(1), nil).Once()
+	suite.postRepo.On("GetPublishedSlugs", suite.ctx).Return(posts(2), nil).Once()
+
+	_, err := suite.service.Sitemap(suite.ctx, 0)
+	suite.Require().NoError(err)
+	res, err := suite.service.Sitemap(suite.ctx, 0)
+	suite.Require().NoError(err)
+
+	assert.Contains(suite.T(), string(res.Body), "post-2")
+	suite.postRepo.AssertExpectations(suite.T())
+}
+
+func (suite *SitemapServiceTestSuite) TestSitemap_NoCacheWithoutTTL() {
+	suite.service.options.CacheTTL = 0
+	suite.postRepo.On("GetPublishedSlugs", suite.ctx).Return(posts(1), nil)
+
+	_, err := suite.service.Sitemap(suite.ctx, 0)
+	suite.Require().NoError(err)
+	_, err = suite.service.Sitemap(suite.ctx, 0)
+	suite.Require().NoError(err)
+
+	suite.postRepo.AssertNumberOfCalls(suite.T(), "GetPublishedSlugs", 2)
+}
+
+func (suite *SitemapServiceTestSuite) TestSitemap_RepositoryError() {
+	suite.postRepo.On("GetPublishedSlugs", suite.ctx).Return(nil, errors.New("database error"))
+
+	res, err := suite.service.Sitemap(suite.ctx, 0)
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "database error")
+}
+
+func (suite *SitemapServiceTestSuite) TestSitemap_Empty() {
+	suite.postRepo.On("GetPublishedSlugs", suite.ctx).Return([]postModel.PostModel{}, nil)
+
+	res, err := suite.service.Sitemap(suite.ctx, 0)
+
+	suite.Require().NoError(err)
+	assert.True(suite.T(), res.LastModified.IsZero())
+	var doc urlSetDoc
+	suite.Require().NoError(xml.Unmarshal(res.Body, &doc))
+	assert.Empty(suite.T(), doc.URLs)
+}
+
+func (suite *SitemapServiceTestSuite) TestRobots() {
+	res, err := suite.service.Robots(suite.ctx)
+
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "text/plain; charset=utf-8", res.ContentType)
+	assert.Equal(suite.T(), "User-agent: *\nDisallow: /v1/api/\nDisallow: /swagger/\n\nSitemap: https://blog.example.com/sitemap.xml\n", string(res.Body))
+}
+
+func (suite *SitemapServiceTestSuite) TestRobots_AllowEverything() {
+	suite.service.options.Disallow = []string{}
+
+	res, err := suite.service.Robots(suite.ctx)
+
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "User-agent: *\nDisallow:\n\nSitemap: https://blog.example.com/sitemap.xml\n", string(res.Body))
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"simple-blog-system/internal/app/taxonomy/payload"
+	"simple-blog-system/internal/app/taxonomy/port"
+	"simple-blog-system/pkg/helper"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type handler struct {
+	taxonomyService port.ITaxonomyService
+}
+
+func New(taxonomyService port.ITaxonomyService) port.ITaxonomyHandler {
+	return &handler{
+		taxonomyService: taxonomyService,
+	}
+}
+
+// @BasePath /v1
+
+// @Summary Get All Tag
+// @Description Get All Tag with post count
+// @Tags taxonomy
+// @Accept json
+// @Produce json
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Router /api/tag [get]
+func (h *handler) GetAllTag(c *gin.Context) {
+	res, err := h.taxonomyService.GetAllTag(c.Request.Context())
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "get successfully",
+		Data:    res,
+	})
+}
+
+// @Summary Get All Category
+// @Description Get category tree with post count
+// @Tags taxonomy
+// @Accept json
+// @Produce json
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Router /api/category [get]
+func (h *handler) GetAllCategory(c *gin.Context) {
+	res, err := h.taxonomyService.GetAllCategory(c.Request.Context())
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "get successfully",
+		Data:    res,
+	})
+}
+
+// @Summary Add Category
+// @Description Add Category, admin and editor only
+// @Tags taxonomy
+// @Accept json
+// @Produce json
+// @Param category body payload.CategoryRequest true "Param Category"
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Failure 403 {object} helper.Response
+// @Failure 409 {object} helper.Response
+// @Router /api/category [post]
+func (h *handler) AddCategory(c *gin.Context) {
+	username := c.GetString("username")
+	var (
+		categoryRequest payload.CategoryRequest
+	)
+
+	if err := c.ShouldBind(&categoryRequest); err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	validate := validator.New()
+	err := validate.Struct(categoryRequest)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	res, err := h.taxonomyService.AddCategory(c.Request.Context(), username, categoryRequest)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "insert successfully",
+		Data:    res,
+	})
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"gorm.io/gorm"
+)
+
+type CategoryModel struct {
+	ID        strfmt.UUID4    `json:"id" gorm:"type:uuid;default:uuid_generate_v4()"`
+	Name      string          `json:"name" validate:"required"`
+	Slug      string          `json:"slug"`
+	ParentId  *string         `json:"parent_id" gorm:"default:null"`
+	PostCount int64           `json:"post_count" gorm:"-"`
+	Children  []CategoryModel `json:"children,omitempty" gorm:"-"`
+	CreatedBy string          `json:"created_by"`
+	UpdatedBy string          `json:"updated_by" gorm:"default:null"`
+	CreatedAt time.Time       `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt  `json:"deleted_at" gorm:"default:null"`
+}
+
+func (u CategoryModel) TableName() string {
+	return "categories"
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+type TagModel struct {
+	ID        strfmt.UUID4 `json:"id" gorm:"type:uuid;default:uuid_generate_v4()"`
+	Name      string       `json:"name" validate:"required"`
+	Slug      string       `json:"slug"`
+	PostCount int64        `json:"post_count" gorm:"default:0"`
+	CreatedAt time.Time    `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (u TagModel) TableName() string {
+	return "tags"
+}
+
+type PostTagModel struct {
+	PostId string   `json:"post_id" gorm:"primaryKey"`
+	TagId  string   `json:"tag_id" gorm:"primaryKey"`
+	Tag    TagModel `json:"tag" gorm:"foreignKey:TagId"`
+}
+
+func (u PostTagModel) TableName() string {
+	return "post_tags"
+}
@@ -0,0 +1,6 @@
+package payload
+
+type CategoryRequest struct {
+	Name     string `json:"name" validate:"required,max=100"`
+	ParentId string `json:"parent_id"`
+}
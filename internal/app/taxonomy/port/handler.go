@@ -0,0 +1,17 @@
+package port
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type ITaxonomyHandler interface {
+
+	// (GET /tag)
+	GetAllTag(ctx *gin.Context)
+
+	// (GET /category)
+	GetAllCategory(ctx *gin.Context)
+
+	// (POST /category)
+	AddCategory(ctx *gin.Context)
+}
@@ -0,0 +1,20 @@
+package port
+
+import (
+	"context"
+	"simple-blog-system/internal/app/taxonomy/model"
+)
+
+type ITaxonomyRepository interface {
+	GetAllTag(ctx context.Context) (res []model.TagModel, err error)
+	FindOrCreateTags(ctx context.Context, tags []model.TagModel) (res []model.TagModel, err error)
+	GetPostTags(ctx context.Context, postIds []string) (res []model.PostTagModel, err error)
+	ReplacePostTags(ctx context.Context, postId string, tagIds []string) (err error)
+	RefreshTagCounts(ctx context.Context, tagIds []string) (err error)
+
+	GetAllCategory(ctx context.Context) (res []model.CategoryModel, err error)
+	GetCategoryById(ctx context.Context, id string) (res *model.CategoryModel, err error)
+	GetCategoriesByIds(ctx context.Context, ids []string) (res []model.CategoryModel, err error)
+	InsertCategory(ctx context.Context, category model.CategoryModel) (model.CategoryModel, error)
+	CountPostsByCategory(ctx context.Context) (res map[string]int64, err error)
+}
@@ -0,0 +1,13 @@
+package port
+
+import (
+	"context"
+	"simple-blog-system/internal/app/taxonomy/model"
+	"simple-blog-system/internal/app/taxonomy/payload"
+)
+
+type ITaxonomyService interface {
+	GetAllTag(ctx context.Context) (res []model.TagModel, err error)
+	GetAllCategory(ctx context.Context) (res []model.CategoryModel, err error)
+	AddCategory(ctx context.Context, username string, param payload.CategoryRequest) (res *model.CategoryModel, err error)
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm/clause"
+
+	"simple-blog-system/config/db"
+	"simple-blog-system/pkg/apperror"
+	"simple-blog-system/pkg/cache"
+	"simple-blog-system/pkg/transaction"
+
+	postModel "simple-blog-system/internal/app/post/model"
+	"simple-blog-system/internal/app/taxonomy/model"
+	"simple-blog-system/internal/app/taxonomy/port"
+)
+
+type repository struct {
+	db    *db.GormDB
+	cache cache.ICache
+}
+
+func NewRepository(db *db.GormDB) port.ITaxonomyRepository {
+	return repository{db: db}
+}
+
+func (r repository) GetAllTag(ctx context.Context) (res []model.TagModel, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Order("post_count DESC, name").Find(&res).Error
+	return res, err
+}
+
+func (r repository) FindOrCreateTags(ctx context.Context, tags []model.TagModel) (res []model.TagModel, err error) {
+	if len(tags) == 0 {
+		return res, nil
+	}
+
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+
+	slugs := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		slugs = append(slugs, tag.Slug)
+	}
+
+	err = trx.Where("slug IN ?", slugs).Find(&res).Error
+	return res, err
+}
+
+func (r repository) GetPostTags(ctx context.Context, postIds []string) (res []model.PostTagModel, err error) {
+	if len(postIds) == 0 {
+		return res, nil
+	}
+
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Preload("Tag").Where("post_id IN ?", postIds).Find(&res).Error
+	return res, err
+}
+
+func (r repository) ReplacePostTags(ctx context.Context, postId string, tagIds []string) (err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Where("post_id = ?", postId).Delete(&model.PostTagModel{}).Error
+	if err != nil || len(tagIds) == 0 {
+		return err
+	}
+
+	postTags := make([]model.PostTagModel, 0, len(tagIds))
+	for _, tagId := range tagIds {
+		postTags = append(postTags, model.PostTagModel{PostId: postId, TagId: tagId})
+	}
+
+	err = trx.Omit("Tag").Create(&postTags).Error
+	return err
+}
+
+// RefreshTagCounts recounts the published posts of each tag, drafts and scheduled posts are not counted
+func (r repository) RefreshTagCounts(ctx context.Context, tagIds []string) (err error) {
+	if len(tagIds) == 0 {
+		return nil
+	}
+
+	count := r.db.Table("post_tags").
+		Select("COUNT(*)").
+		Joins("JOIN posts ON posts.id = post_tags.post_id AND posts.deleted_at IS NULL AND posts.status = ?", postModel.StatusPublish).
+		Where("post_tags.tag_id = tags.id")
+
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Model(&model.TagModel{}).Where("id IN ?", tagIds).Update("post_count", count).Error
+	return err
+}
+
+func (r repository) GetAllCategory(ctx context.Context) (res []model.CategoryModel, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Order("name").Find(&res).Error
+	return res, err
+}
+
+func (r repository) GetCategoryById(ctx context.Context, id string) (res *model.CategoryModel, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Where("id = ?", id).First(&res).Error
+	return res, err
+}
+
+func (r repository) GetCategoriesByIds(ctx context.Context, ids []string) (res []model.CategoryModel, err error) {
+	if len(ids) == 0 {
+		return res, nil
+	}
+
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Where("id IN ?", ids).Find(&res).Error
+	return res, err
+}
+
+// InsertCategory answers a conflict when a category with the same slug already exists
+func (r repository) InsertCategory(ctx context.Context, category model.CategoryModel) (model.CategoryModel, error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	qres := trx.Create(&category).Error
+	if db.IsUniqueViolation(qres) {
+		return category, apperror.Conflict("category already exists")
+	}
+
+	return category, qres
+}
+
+// CountPostsByCategory counts the published posts of each category
+func (r repository) CountPostsByCategory(ctx context.Context) (res map[string]int64, err error) {
+	var rows []struct {
+		CategoryId string
+		Total      int64
+	}
+
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Table("posts").
+		Select("category_id, COUNT(*) AS total").
+		Where("category_id IS NOT NULL AND deleted_at IS NULL AND status = ?", postModel.StatusPublish).
+		Group("category_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	res = make(map[string]int64, len(rows))
+	for _, row := range rows {
+		res[row.CategoryId] = row.Total
+	}
+
+	return res, nil
+}
@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"simple-blog-system/config/db"
+	"simple-blog-system/internal/app/taxonomy/model"
+	"simple-blog-system/pkg/apperror"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-openapi/strfmt"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type TaxonomyRepositoryTestSuite struct {
+	suite.Suite
+	db         *gorm.DB
+	mock       sqlmock.Sqlmock
+	repository repository
+}
+
+func (suite *TaxonomyRepositoryTestSuite) SetupTest() {
+	var (
+		sqlDB *sql.DB
+		err   error
+	)
+
+	sqlDB, suite.mock, err = sqlmock.New()
+	assert.NoError(suite.T(), err)
+
+	suite.db, err = gorm.Open(postgres.New(postgres.Config{
+		Conn: sqlDB,
+	}), &gorm.Config{})
+	assert.NoError(suite.T(), err)
+
+	gormDB := &db.GormDB{DB: suite.db}
+	suite.repository = repository{db: gormDB}
+}
+
+func (suite *TaxonomyRepositoryTestSuite) TearDownTest() {
+	sqlDB, err := suite.db.DB()
+	assert.NoError(suite.T(), err)
+	sqlDB.Close()
+}
+
+func TestTaxonomyRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(TaxonomyRepositoryTestSuite))
+}
+
+func (suite *TaxonomyRepositoryTestSuite) TestGetAllTag_Success() {
+	ctx := context.Background()
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "name", "slug", "post_count", "created_at"}).
+		AddRow("tag-1", "Go", "go", 3, now).
+		AddRow("tag-2", "Web", "web", 1, now)
+
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tags" ORDER BY post_count DESC, name`)).
+		WillReturnRows(rows)
+
+	result, err := suite.repository.GetAllTag(ctx)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 2)
+	assert.Equal(suite.T(), int64(3), result[0].PostCount)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *TaxonomyRepositoryTestSuite) TestFindOrCreateTags_Success() {
+	ctx := context.Background()
+	now := time.Now()
+
+	tags := []model.TagModel{
+		{Name: "Go", Slug: "go"},
+		{Name: "Web", Slug: "web"},
+	}
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "tags" ("name","slug","post_count","created_at") VALUES ($1,$2,$3,$4),($5,$6,$7,$8) ON CONFLICT DO NOTHING RETURNING "id"`)).
+		WithArgs("Go", "go", 0, sqlmock.AnyArg(), "Web", "web", 0, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("tag-2"))
+	suite.mock.ExpectCommit()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tags" WHERE slug IN ($1,$2)`)).
+		WithArgs("go", "web").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "slug", "post_count", "created_at"}).
+			AddRow("tag-1", "Go", "go", 3, now).
+			AddRow("tag-2", "Web", "web", 0, now))
+
+	result, err := suite.repository.FindOrCreateTags(ctx, tags)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 2)
+	assert.Equal(suite.T(), strfmt.UUID4("tag-1"), result[0].ID)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *TaxonomyRepositoryTestSuite) TestFindOrCreateTags_Empty() {
+	result, err := suite.repository.FindOrCreateTags(context.Background(), nil)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 0)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *TaxonomyRepositoryTestSuite) TestReplacePostTags_Success() {
+	ctx := context.Background()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "post_tags" WHERE post_id = $1`)).
+		WithArgs("post-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "post_tags" ("post_id","tag_id") VALUES ($1,$2),($3,$4)`)).
+		WithArgs("post-1", "tag-1", "post-1", "tag-2").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.ReplacePostTags(ctx, "post-1", []string{"tag-1", "tag-2"})
+
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *TaxonomyRepositoryTestSuite) TestRefreshTagCounts_Success() {
+	ctx := context.Background()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "tags" SET "post_count"=(SELECT COUNT(*) FROM "post_tags" JOIN posts ON posts.id = post_tags.post_id AND posts.deleted_at IS NULL AND posts.status = $1 WHERE post_tags.tag_id = tags.id) WHERE id IN ($2)`)).
+		WithArgs("PUBLISH", "tag-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.RefreshTagCounts(ctx, []string{"tag-1"})
+
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *TaxonomyRepositoryTestSuite) TestInsertCategory_Success() {
+	ctx := context.Background()
+	parentID := "category-1"
+
+	category := model.CategoryModel{
+		Name:      "Golang",
+		Slug:      "golang",
+		ParentId:  &parentID,
+		CreatedBy: "admin",
+	}
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "categories"`)).
+		WithArgs(category.Name, category.Slug, category.CreatedBy, sqlmock.AnyArg(), sqlmock.AnyArg(), parentID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "parent_id", "updated_by", "deleted_at"}).AddRow("category-2", parentID, nil, nil))
+	suite.mock.ExpectCommit()
+
+	result, err := suite.repository.InsertCategory(ctx, category)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), strfmt.UUID4("category-2"), result.ID)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *TaxonomyRepositoryTestSuite) TestInsertCategory_SlugTaken() {
+	ctx := context.Background()
+
+	category := model.CategoryModel{
+		Name:      "Golang",
+		Slug:      "golang",
+		CreatedBy: "admin",
+	}
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "categories"`)).
+		WillReturnError(&pgconn.PgError{Code: "23505"})
+	suite.mock.ExpectRollback()
+
+	_, err := suite.repository.InsertCategory(ctx, category)
+
+	var appErr *apperror.Error
+	assert.ErrorAs(suite.T(), err, &appErr)
+	assert.Equal(suite.T(), http.StatusConflict, appErr.Code)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *TaxonomyRepositoryTestSuite) TestCountPostsByCategory_Success() {
+	ctx := context.Background()
+
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT category_id, COUNT(*) AS total FROM "posts" WHERE category_id IS NOT NULL AND deleted_at IS NULL AND status = $1 GROUP BY "category_id"`)).
+		WithArgs("PUBLISH").
+		WillReturnRows(sqlmock.NewRows([]string{"category_id", "total"}).
+			AddRow("category-1", 2).
+			AddRow("category-2", 5))
+
+	result, err := suite.repository.CountPostsByCategory(ctx)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), map[string]int64{"category-1": 2, "category-2": 5}, result)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *TaxonomyRepositoryTestSuite) TestGetCategoryById_NotFound() {
+	ctx := context.Background()
+
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "categories" WHERE id = $1 AND "categories"."deleted_at" IS NULL ORDER BY "categories"."id" LIMIT $2`)).
+		WithArgs("missing", 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	_, err := suite.repository.GetCategoryById(ctx, "missing")
+
+	assert.Error(suite.T(), err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
@@ -0,0 +1,24 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"simple-blog-system/internal/app/taxonomy/port"
+)
+
+type (
+	routes struct{}
+)
+
+var (
+	Routes routes
+)
+
+func (r routes) NewTag(router *gin.RouterGroup, handler port.ITaxonomyHandler) {
+	router.GET("/", handler.GetAllTag)
+}
+
+func (r routes) NewCategory(router *gin.RouterGroup, handler port.ITaxonomyHandler) {
+	router.GET("/", handler.GetAllCategory)
+	router.POST("/", handler.AddCategory)
+}
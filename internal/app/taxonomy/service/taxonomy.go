@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"simple-blog-system/internal/app/taxonomy/model"
+	"simple-blog-system/internal/app/taxonomy/payload"
+	"simple-blog-system/internal/app/taxonomy/port"
+	userPort "simple-blog-system/internal/app/user/port"
+	"simple-blog-system/pkg/apperror"
+	"simple-blog-system/pkg/authorization"
+	"simple-blog-system/pkg/helper"
+)
+
+type service struct {
+	taxonomyRepo port.ITaxonomyRepository
+	userRepo     userPort.IUserRepository
+}
+
+func New(taxonomyRepo port.ITaxonomyRepository, userRepo userPort.IUserRepository) port.ITaxonomyService {
+	return &service{
+		taxonomyRepo: taxonomyRepo,
+		userRepo:     userRepo,
+	}
+}
+
+func (s *service) GetAllTag(ctx context.Context) (res []model.TagModel, err error) {
+	tags, err := s.taxonomyRepo.GetAllTag(ctx)
+	if err != nil {
+		return nil, errors.New("tag not found")
+	}
+
+	return tags, nil
+}
+
+// GetAllCategory returns the category tree, post_count of a category includes the posts of its children
+func (s *service) GetAllCategory(ctx context.Context) (res []model.CategoryModel, err error) {
+	categories, err := s.taxonomyRepo.GetAllCategory(ctx)
+	if err != nil {
+		return nil, errors.New("category not found")
+	}
+
+	counts, err := s.taxonomyRepo.CountPostsByCategory(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildCategoryTree(categories, counts), nil
+}
+
+func (s *service) AddCategory(ctx context.Context, username string, param payload.CategoryRequest) (res *model.CategoryModel, err error) {
+	users, qerr := s.userRepo.GetUserByUsername(ctx, username)
+	if len(users) == 0 || qerr != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if !authorization.IsModerator(users[0].Role) {
+		return nil, authorization.ErrForbidden
+	}
+
+	slug := helper.Slugify(param.Name)
+	if slug == "" {
+		return nil, apperror.BadRequest("category name must contain a letter or a digit")
+	}
+
+	category := model.CategoryModel{
+		Name:      param.Name,
+		Slug:      slug,
+		CreatedBy: username,
+	}
+
+	if param.ParentId != "" {
+		parent, qerr := s.taxonomyRepo.GetCategoryById(ctx, param.ParentId)
+		if qerr != nil {
+			return nil, errors.New("parent category not found")
+		}
+		parentId := string(parent.ID)
+		category.ParentId = &parentId
+	}
+
+	category, qerr = s.taxonomyRepo.InsertCategory(ctx, category)
+	if qerr != nil {
+		return nil, qerr
+	}
+
+	return &category, nil
+}
+
+func buildCategoryTree(categories []model.CategoryModel, counts map[string]int64) []model.CategoryModel {
+	children := make(map[string][]model.CategoryModel)
+	roots := []model.CategoryModel{}
+
+	for _, category := range categories {
+		category.PostCount = counts[string(category.ID)]
+		if category.ParentId == nil {
+			roots = append(roots, category)
+			continue
+		}
+		children[*category.ParentId] = append(children[*category.ParentId], category)
+	}
+
+	var attach func(category *model.CategoryModel)
+	attach = func(category *model.CategoryModel) {
+		category.Children = children[string(category.ID)]
+		for i := range category.Children {
+			attach(&category.Children[i])
+			category.PostCount += category.Children[i].PostCount
+		}
+	}
+
+	for i := range roots {
+		attach(&roots[i])
+	}
+
+	return roots
+}
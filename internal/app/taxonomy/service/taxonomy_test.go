@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"simple-blog-system/internal/app/taxonomy/model"
+	"simple-blog-system/internal/app/taxonomy/payload"
+	userModel "simple-blog-system/internal/app/user/model"
+	"simple-blog-system/pkg/authorization"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+// Mock for ITaxonomyRepository
+type MockTaxonomyRepository struct {
+	mock.Mock
+}
+
+func (m *MockTaxonomyRepository) GetAllTag(ctx context.Context) ([]model.TagModel, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.TagModel), args.Error(1)
+}
+
+func (m *MockTaxonomyRepository) FindOrCreateTags(ctx context.Context, tags []model.TagModel) ([]model.TagModel, error) {
+	args := m.Called(ctx, tags)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.TagModel), args.Error(1)
+}
+
+func (m *MockTaxonomyRepository) GetPostTags(ctx context.Context, postIds []string) ([]model.PostTagModel, error) {
+	args := m.Called(ctx, postIds)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.PostTagModel), args.Error(1)
+}
+
+func (m *MockTaxonomyRepository) ReplacePostTags(ctx context.Context, postId string, tagIds []string) error {
+	args := m.Called(ctx, postId, tagIds)
+	return args.Error(0)
+}
+
+func (m *MockTaxonomyRepository) RefreshTagCounts(ctx context.Context, tagIds []string) error {
+	args := m.Called(ctx, tagIds)
+	return args.Error(0)
+}
+
+func (m *MockTaxonomyRepository) GetAllCategory(ctx context.Context) ([]model.CategoryModel, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.CategoryModel), args.Error(1)
+}
+
+func (m *MockTaxonomyRepository) GetCategoryById(ctx context.Context, id string) (*model.CategoryModel, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CategoryModel), args.Error(1)
+}
+
+func (m *MockTaxonomyRepository) GetCategoriesByIds(ctx context.Context, ids []string) ([]model.CategoryModel, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.CategoryModel), args.Error(1)
+}
+
+func (m *MockTaxonomyRepository) InsertCategory(ctx context.Context, category model.CategoryModel) (model.CategoryModel, error) {
+	args := m.Called(ctx, category)
+	return args.Get(0).(model.CategoryModel), args.Error(1)
+}
+
+func (m *MockTaxonomyRepository) CountPostsByCategory(ctx context.Context) (map[string]int64, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+
+// Mock for IUserRepository
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) InsertUser(ctx context.Context, user userModel.AuthUserModel) (userModel.AuthUserModel, error) {
+	args := m.Called(ctx, user)
+	return args.Get(0).(userModel.AuthUserModel), args.Error(1)
+}
+
+func (m *MockUserRepository) GetUserByUsername(ctx context.Context, username string) ([]userModel.AuthUserModel, error) {
+	args := m.Called(ctx, username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]userModel.AuthUserModel), args.Error(1)
+}
+
+func (m *MockUserRepository) GetPasswordByUsername(ctx context.Context, username string) ([]userModel.AuthUserModel, error) {
+	args := m.Called(ctx, username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]userModel.AuthUserModel), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdateLastLogin(ctx context.Context, user userModel.AuthUserModel) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateRole(ctx context.Context, username string, role string) error {
+	args := m.Called(ctx, username, role)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdatePassword(ctx context.Context, username string, password string) error {
+	args := m.Called(ctx, username, password)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateEmail(ctx context.Context, username string, email string) error {
+	args := m.Called(ctx, username, email)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) VerifyEmail(ctx context.Context, username string, email string) (bool, error) {
+	args := m.Called(ctx, username, email)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) IsUsernameTaken(ctx context.Context, username string) (bool, error) {
+	args := m.Called(ctx, username)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) SuspendUser(ctx context.Context, username string, reason string, until *time.Time) error {
+	args := m.Called(ctx, username, reason, until)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ReactivateUser(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ScheduleDeletion(ctx context.Context, username string, deleteAfter *time.Time) error {
+	args := m.Called(ctx, username, deleteAfter)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetUsersDueForDeletion(ctx context.Context, now time.Time, limit int) ([]userModel.AuthUserModel, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]userModel.AuthUserModel), args.Error(1)
+}
+
+func (m *MockUserRepository) DeleteUser(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetUserByEmail(ctx context.Context, email string) ([]userModel.AuthUserModel, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]userModel.AuthUserModel), args.Error(1)
+}
+
+// Test Suite
+type TaxonomyServiceTestSuite struct {
+	suite.Suite
+	service      *service
+	taxonomyRepo *MockTaxonomyRepository
+	userRepo     *MockUserRepository
+	ctx          context.Context
+}
+
+func (suite *TaxonomyServiceTestSuite) SetupTest() {
+	suite.taxonomyRepo = new(MockTaxonomyRepository)
+	suite.userRepo = new(MockUserRepository)
+	suite.service = &service{
+		taxonomyRepo: suite.taxonomyRepo,
+		userRepo:     suite.userRepo,
+	}
+	suite.ctx = context.Background()
+}
+
+func TestTaxonomyServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(TaxonomyServiceTestSuite))
+}
+
+func (suite *TaxonomyServiceTestSuite) TestGetAllTag_Success() {
+	tags := []model.TagModel{{ID: strfmt.UUID4("tag-1"), Name: "Go", Slug: "go", PostCount: 2}}
+
+	suite.taxonomyRepo.On("GetAllTag", suite.ctx).Return(tags, nil)
+
+	result, err := suite.service.GetAllTag(suite.ctx)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), tags, result)
+	suite.taxonomyRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TaxonomyServiceTestSuite) TestGetAllTag_Error() {
+	suite.taxonomyRepo.On("GetAllTag", suite.ctx).Return(nil, errors.New("database error"))
+
+	result, err := suite.service.GetAllTag(suite.ctx)
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), "tag not found", err.Error())
+}
+
+func (suite *TaxonomyServiceTestSuite) TestGetAllCategory_BuildsTree() {
+	rootID := "category-1"
+	childID := "category-2"
+
+	categories := []model.CategoryModel{
+		{ID: strfmt.UUID4(rootID), Name: "Programming", Slug: "programming"},
+		{ID: strfmt.UUID4(childID), Name: "Golang", Slug: "golang", ParentId: &rootID},
+		{ID: strfmt.UUID4("category-3"), Name: "Travel", Slug: "travel"},
+	}
+	counts := map[string]int64{rootID: 1, childID: 4}
+
+	suite.taxonomyRepo.On("GetAllCategory", suite.ctx).Return(categories, nil)
+	suite.taxonomyRepo.On("CountPostsByCategory", suite.ctx).Return(counts, nil)
+
+	result, err := suite.service.GetAllCategory(suite.ctx)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 2)
+	assert.Equal(suite.T(), "programming", result[0].Slug)
+	assert.Equal(suite.T(), int64(5), result[0].PostCount)
+	assert.Len(suite.T(), result[0].Children, 1)
+	assert.Equal(suite.T(), int64(4), result[0].Children[0].PostCount)
+	assert.Equal(suite.T(), int64(0), result[1].PostCount)
+	suite.taxonomyRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TaxonomyServiceTestSuite) TestAddCategory_Success() {
+	username := "editor"
+	parentID := "category-1"
+
+	param := payload.CategoryRequest{Name: "Go Lang", ParentId: parentID}
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{{Username: username, Role: authorization.RoleEditor}}, nil)
+	suite.taxonomyRepo.On("GetCategoryById", suite.ctx, parentID).Return(&model.CategoryModel{ID: strfmt.UUID4(parentID)}, nil)
+	suite.taxonomyRepo.On("InsertCategory", suite.ctx, mock.MatchedBy(func(c model.CategoryModel) bool {
+		return c.Name == param.Name && c.Slug == "go-lang" && c.ParentId != nil && *c.ParentId == parentID && c.CreatedBy == username
+	})).Return(model.CategoryModel{ID: strfmt.UUID4("category-2"), Name: param.Name, Slug: "go-lang"}, nil)
+
+	result, err := suite.service.AddCategory(suite.ctx, username, param)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "go-lang", result.Slug)
+	suite.taxonomyRepo.AssertExpectations(suite.T())
+	suite.userRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TaxonomyServiceTestSuite) TestAddCategory_AuthorForbidden() {
+	username := "author"
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{{Username: username, Role: authorization.RoleAuthor}}, nil)
+
+	result, err := suite.service.AddCategory(suite.ctx, username, payload.CategoryRequest{Name: "Go"})
+
+	assert.ErrorIs(suite.T(), err, authorization.ErrForbidden)
+	assert.Nil(suite.T(), result)
+	suite.taxonomyRepo.AssertNotCalled(suite.T(), "InsertCategory", mock.Anything, mock.Anything)
+}
+
+func (suite *TaxonomyServiceTestSuite) TestAddCategory_ParentNotFound() {
+	username := "admin"
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{{Username: username, Role: authorization.RoleAdmin}}, nil)
+	suite.taxonomyRepo.On("GetCategoryById", suite.ctx, "missing").Return(nil, gorm.ErrRecordNotFound)
+
+	result, err := suite.service.AddCategory(suite.ctx, username, payload.CategoryRequest{Name: "Go", ParentId: "missing"})
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), "parent category not found", err.Error())
+}
+
+func (suite *TaxonomyServiceTestSuite) TestAddCategory_UserNotFound() {
+	username := "nonexistent"
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]userModel.AuthUserModel{}, nil)
+
+	result, err := suite.service.AddCategory(suite.ctx, username, payload.CategoryRequest{Name: "Go"})
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), "user not found", err.Error())
+}
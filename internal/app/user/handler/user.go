@@ -1,6 +1,9 @@
 package handler
 
 import (
+	"net/http"
+	"strings"
+
 	"simple-blog-system/internal/app/user/model"
 	"simple-blog-system/internal/app/user/payload"
 	"simple-blog-system/internal/app/user/port"
@@ -20,6 +23,24 @@ func New(userService port.IUserService) port.IUserHandler {
 	}
 }
 
+const (
+	// maxIpAddress and maxUserAgent are the column sizes in user_sessions
+	maxIpAddress = 64
+	maxUserAgent = 512
+)
+
+// client describes the device of the request, behind proxies only the first forwarded address is kept
+func client(c *gin.Context) payload.Client {
+	ip, _, _ := strings.Cut(helper.GetIpAddress(c), ",")
+	ip = strings.TrimSpace(ip)
+	userAgent := c.Request.UserAgent()
+
+	return payload.Client{
+		IpAddress: ip[:min(len(ip), maxIpAddress)],
+		UserAgent: userAgent[:min(len(userAgent), maxUserAgent)],
+	}
+}
+
 // @BasePath /v1
 
 // @Summary Register User
@@ -47,7 +68,7 @@ func (h *handler) Register(c *gin.Context) {
 		return
 	}
 
-	res, err := h.userService.Register(c.Request.Context(), dataUser.User)
+	res, err := h.userService.Register(c.Request.Context(), dataUser.User, client(c))
 	if err != nil {
 		helper.ResponseError(c, err)
 		return
@@ -67,6 +88,8 @@ func (h *handler) Register(c *gin.Context) {
 // @Param user body model.AuthUserModel true "Param Login"
 // @Success 200 {object} helper.Response
 // @Failure 400 {object} helper.Response
+// @Failure 403 {object} helper.Response
+// @Failure 429 {object} helper.Response
 // @Router /public-api/user/login [post]
 func (h *handler) Login(c *gin.Context) {
 	var (
@@ -85,7 +108,7 @@ func (h *handler) Login(c *gin.Context) {
 		return
 	}
 
-	res, err := h.userService.Login(c.Request.Context(), dataUser)
+	res, err := h.userService.Login(c.Request.Context(), dataUser, client(c))
 	if err != nil {
 		helper.ResponseError(c, err)
 		return
@@ -97,24 +120,891 @@ func (h *handler) Login(c *gin.Context) {
 	})
 }
 
-// @Summary Get User
-// @Description Get User
+// @Summary Verify MFA
+// @Description Swap the mfa token of a login and a code of the authenticator app or a recovery code for an access and refresh token
 // @Tags user
 // @Accept json
 // @Produce json
+// @Param mfa body payload.MfaLoginRequest true "Param MFA"
 // @Success 200 {object} helper.Response
 // @Failure 400 {object} helper.Response
-// @Router /api/profile [get]
-func (h *handler) GetUser(c *gin.Context) {
-	username := c.GetString("username")
-	res, err := h.userService.GetUser(c.Request.Context(), username)
+// @Failure 401 {object} helper.Response
+// @Failure 429 {object} helper.Response
+// @Router /public-api/user/login/mfa [post]
+func (h *handler) VerifyMfa(c *gin.Context) {
+	var (
+		mfaRequest payload.MfaLoginRequest
+	)
+
+	if err := c.ShouldBind(&mfaRequest); err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	validate := validator.New()
+	err := validate.Struct(mfaRequest)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	res, err := h.userService.VerifyMfa(c.Request.Context(), mfaRequest, client(c))
 	if err != nil {
 		helper.ResponseError(c, err)
 		return
 	}
 
 	helper.ResponseData(c, &helper.Response{
-		Message: "get user successfully",
+		Message: "login successfully",
+		Data:    res,
+	})
+}
+
+// @Summary Start Single Sign-On
+// @Description Start a sign in with the identity provider, the user is sent to authorization_url and comes back to the redirect url with a code and state
+// @Tags user
+// @Accept json
+// @Produce json
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Router /public-api/user/oidc/login [get]
+func (h *handler) StartOidc(c *gin.Context) {
+	res, err := h.userService.StartOidc(c.Request.Context())
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "start single sign-on successfully",
+		Data:    res,
+	})
+}
+
+// @Summary Complete Single Sign-On
+// @Description Swap the code and state the identity provider sent back for an access and refresh token, the account is linked or created on the first sign in
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param code query string false "Authorization code"
+// @Param state query string true "State of the sign in"
+// @Param error query string false "Error of the identity provider"
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Failure 401 {object} helper.Response
+// @Failure 403 {object} helper.Response
+// @Router /public-api/user/oidc/callback [get]
+func (h *handler) CompleteOidc(c *gin.Context) {
+	var (
+		request payload.OidcCallbackRequest
+	)
+
+	if err := c.ShouldBindQuery(&request); err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	validate := validator.New()
+	err := validate.Struct(request)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	res, err := h.userService.CompleteOidc(c.Request.Context(), request, client(c))
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "login successfully",
+		Data:    res,
+	})
+}
+
+// @Summary Refresh Token
+// @Description Swap a refresh token for a new access and refresh token, the old refresh token can not be used again
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param token body payload.RefreshRequest true "Param Refresh"
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Failure 401 {object} helper.Response
+// @Router /public-api/user/refresh [post]
+func (h *handler) Refresh(c *gin.Context) {
+	var (
+		refreshRequest payload.RefreshRequest
+	)
+
+	if err := c.ShouldBind(&refreshRequest); err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	validate := validator.New()
+	err := validate.Struct(refreshRequest)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	res, err := h.userService.Refresh(c.Request.Context(), refreshRequest.RefreshToken, client(c))
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "refresh token successfully",
+		Data:    res,
+	})
+}
+
+// @Summary Logout User
+// @Description Revoke the session of a refresh token and its access token
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param token body payload.RefreshRequest true "Param Logout"
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Failure 401 {object} helper.Response
+// @Router /public-api/user/logout [post]
+func (h *handler) Logout(c *gin.Context) {
+	var (
+		refreshRequest payload.RefreshRequest
+	)
+
+	if err := c.ShouldBind(&refreshRequest); err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	validate := validator.New()
+	err := validate.Struct(refreshRequest)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	err = h.userService.Logout(c.Request.Context(), refreshRequest.RefreshToken)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "logout successfully",
+	})
+}
+
+// @Summary Forgot Password
+// @Description Mail a password reset token to the email address of a user, answers the same whether the user exists or not
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param user body payload.ForgotPasswordRequest true "Param Forgot Password"
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Router /public-api/user/password/forgot [post]
+func (h *handler) ForgotPassword(c *gin.Context) {
+	var (
+		forgotRequest payload.ForgotPasswordRequest
+	)
+
+	if err := c.ShouldBind(&forgotRequest); err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	validate := validator.New()
+	err := validate.Struct(forgotRequest)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	err = h.userService.ForgotPassword(c.Request.Context(), forgotRequest.Username)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "if the user has an email address, a reset token has been sent to it",
+	})
+}
+
+// @Summary Reset Password
+// @Description Set a new password with a reset token, signs the user out everywhere
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param user body payload.ResetPasswordRequest true "Param Reset Password"
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Router /public-api/user/password/reset [post]
+func (h *handler) ResetPassword(c *gin.Context) {
+	var (
+		resetRequest payload.ResetPasswordRequest
+	)
+
+	if err := c.ShouldBind(&resetRequest); err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	validate := validator.New()
+	err := validate.Struct(resetRequest)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	err = h.userService.ResetPassword(c.Request.Context(), resetRequest)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "reset password successfully",
+	})
+}
+
+// @Summary Verify Email
+// @Description Confirm an email address with the token mailed to it
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param token query string true "Verification token"
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Router /public-api/user/verify [get]
+func (h *handler) VerifyEmail(c *gin.Context) {
+	var (
+		request payload.VerifyEmailRequest
+	)
+
+	if err := c.ShouldBindQuery(&request); err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	validate := validator.New()
+	err := validate.Struct(request)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	err = h.userService.VerifyEmail(c.Request.Context(), request.Token)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "verify email successfully",
+	})
+}
+
+// @Summary Change Email
+// @Description Set the email address of the user and mail a verification token to it, sending the same address again mails a new token
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param email body payload.EmailRequest true "Param Email"
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Router /api/profile/email [put]
+func (h *handler) ChangeEmail(c *gin.Context) {
+	var (
+		emailRequest payload.EmailRequest
+	)
+
+	if err := c.ShouldBind(&emailRequest); err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	validate := validator.New()
+	err := validate.Struct(emailRequest)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	res, err := h.userService.ChangeEmail(c.Request.Context(), c.GetString("username"), emailRequest.Email)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "change email successfully",
+		Data:    res,
+	})
+}
+
+// @Summary Change Password
+// @Description Change the password with the current one, signs the user out of every other session
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param password body payload.PasswordRequest true "Param Password"
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Router /api/profile/password [put]
+func (h *handler) ChangePassword(c *gin.Context) {
+	var (
+		passwordRequest payload.PasswordRequest
+	)
+
+	if err := c.ShouldBind(&passwordRequest); err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	validate := validator.New()
+	err := validate.Struct(passwordRequest)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	err = h.userService.ChangePassword(c.Request.Context(), c.GetString("username"), c.GetString("sid"), passwordRequest)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "change password successfully",
+	})
+}
+
+// @Summary Delete Account
+// @Description Delete the account of the user after a grace period, logging in before then keeps the account. Signs the user out everywhere.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param password body payload.DeleteAccountRequest true "Param Password"
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Router /api/profile [delete]
+func (h *handler) DeleteAccount(c *gin.Context) {
+	var (
+		deleteRequest payload.DeleteAccountRequest
+	)
+
+	if err := c.ShouldBind(&deleteRequest); err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	validate := validator.New()
+	err := validate.Struct(deleteRequest)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	res, err := h.userService.DeleteAccount(c.Request.Context(), c.GetString("username"), deleteRequest)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "delete account successfully",
+		Data:    res,
+	})
+}
+
+// @Summary JSON Web Key Set
+// @Description Public keys the access tokens are verified with, as a plain JWKS (RFC 7517) without the usual response envelope
+// @Tags user
+// @Produce json
+// @Success 200 {object} signing.JWKS
+// @Router /.well-known/jwks.json [get]
+func (h *handler) Jwks(c *gin.Context) {
+	// verifiers cache the keys, a new key is published well before it signs
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.userService.Jwks(c.Request.Context()))
+}
+
+// @Summary Get User
+// @Description Get User
+// @Tags user
+// @Accept json
+// @Produce json
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Router /api/profile [get]
+func (h *handler) GetUser(c *gin.Context) {
+	username := c.GetString("username")
+	res, err := h.userService.GetUser(c.Request.Context(), username)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "get user successfully",
+		Data:    res,
+	})
+}
+
+// @Summary Get Sessions
+// @Description List the devices the user is logged in on, the session of the request is marked as current
+// @Tags user
+// @Accept json
+// @Produce json
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Router /api/profile/sessions [get]
+func (h *handler) GetSessions(c *gin.Context) {
+	res, err := h.userService.GetSessions(c.Request.Context(), c.GetString("username"), c.GetString("sid"))
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "get sessions successfully",
+		Data:    res,
+	})
+}
+
+// @Summary Revoke Session
+// @Description Sign out of one session of the user, its tokens stop working at once
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} helper.Response
+// @Failure 404 {object} helper.Response
+// @Router /api/profile/sessions/{id} [delete]
+func (h *handler) RevokeSession(c *gin.Context) {
+	err := h.userService.RevokeSession(c.Request.Context(), c.GetString("username"), c.Param("id"))
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "revoke session successfully",
+	})
+}
+
+// @Summary Update User Role
+// @Description Update User Role, admin only
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param username path string true "Username"
+// @Param role body payload.RoleRequest true "Param Role"
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Failure 403 {object} helper.Response
+// @Router /api/admin/user/{username}/role [put]
+func (h *handler) UpdateRole(c *gin.Context) {
+	actor := c.GetString("username")
+	var (
+		roleRequest payload.RoleRequest
+	)
+
+	if err := c.ShouldBind(&roleRequest); err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	validate := validator.New()
+	err := validate.Struct(roleRequest)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	res, err := h.userService.UpdateRole(c.Request.Context(), actor, c.Param("username"), roleRequest.Role)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "update role successfully",
+		Data:    res,
+	})
+}
+
+// @Summary Revoke User Sessions
+// @Description Sign a user out of every session, admin only
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param username path string true "Username"
+// @Success 200 {object} helper.Response
+// @Failure 403 {object} helper.Response
+// @Failure 404 {object} helper.Response
+// @Router /api/admin/user/{username}/sessions [delete]
+func (h *handler) RevokeUserSessions(c *gin.Context) {
+	res, err := h.userService.RevokeUserSessions(c.Request.Context(), c.GetString("username"), c.Param("username"))
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "revoke sessions successfully",
+		Data:    res,
+	})
+}
+
+// @Summary Unlock User
+// @Description Forget the failed logins of a user so the user can log in again right away, admin only
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param username path string true "Username"
+// @Success 200 {object} helper.Response
+// @Failure 403 {object} helper.Response
+// @Failure 404 {object} helper.Response
+// @Router /api/admin/user/{username}/unlock [post]
+func (h *handler) UnlockUser(c *gin.Context) {
+	err := h.userService.UnlockUser(c.Request.Context(), c.GetString("username"), c.Param("username"))
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "unlock user successfully",
+	})
+}
+
+// @Summary Suspend User
+// @Description Keep a user from logging in and sign the user out everywhere, for good or until the given time, admin only
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param username path string true "Username"
+// @Param suspension body payload.SuspendRequest true "Param Suspension"
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Failure 403 {object} helper.Response
+// @Failure 404 {object} helper.Response
+// @Router /api/admin/user/{username}/suspend [post]
+func (h *handler) SuspendUser(c *gin.Context) {
+	var (
+		suspendRequest payload.SuspendRequest
+	)
+
+	if err := c.ShouldBind(&suspendRequest); err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	validate := validator.New()
+	err := validate.Struct(suspendRequest)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	res, err := h.userService.SuspendUser(c.Request.Context(), c.GetString("username"), c.Param("username"), suspendRequest)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "suspend user successfully",
+		Data:    res,
+	})
+}
+
+// @Summary Reactivate User
+// @Description End the suspension of a user, admin only
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param username path string true "Username"
+// @Success 200 {object} helper.Response
+// @Failure 403 {object} helper.Response
+// @Failure 404 {object} helper.Response
+// @Router /api/admin/user/{username}/reactivate [post]
+func (h *handler) ReactivateUser(c *gin.Context) {
+	res, err := h.userService.ReactivateUser(c.Request.Context(), c.GetString("username"), c.Param("username"))
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "reactivate user successfully",
+		Data:    res,
+	})
+}
+
+// @Summary Create API Token
+// @Description Create a named api token with scopes for automation, the token is only shown in this answer. Send it as Authorization: Bearer
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param token body payload.ApiTokenRequest true "Param Token"
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Router /api/profile/tokens [post]
+func (h *handler) CreateApiToken(c *gin.Context) {
+	var (
+		tokenRequest payload.ApiTokenRequest
+	)
+
+	if err := c.ShouldBind(&tokenRequest); err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	validate := validator.New()
+	err := validate.Struct(tokenRequest)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	res, err := h.userService.CreateApiToken(c.Request.Context(), c.GetString("username"), tokenRequest)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "create api token successfully",
+		Data:    res,
+	})
+}
+
+// @Summary Get API Tokens
+// @Description List the api tokens of the user that are neither revoked nor expired, with when and from where they were last used
+// @Tags user
+// @Accept json
+// @Produce json
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Router /api/profile/tokens [get]
+func (h *handler) GetApiTokens(c *gin.Context) {
+	res, err := h.userService.GetApiTokens(c.Request.Context(), c.GetString("username"))
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "get api tokens successfully",
+		Data:    res,
+	})
+}
+
+// @Summary Revoke API Token
+// @Description Revoke one api token of the user, it stops working at once
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param id path string true "API Token ID"
+// @Success 200 {object} helper.Response
+// @Failure 404 {object} helper.Response
+// @Router /api/profile/tokens/{id} [delete]
+func (h *handler) RevokeApiToken(c *gin.Context) {
+	err := h.userService.RevokeApiToken(c.Request.Context(), c.GetString("username"), c.Param("id"))
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "revoke api token successfully",
+	})
+}
+
+// @Summary Enroll MFA
+// @Description Create a TOTP secret for an authenticator app, two-factor authentication is on once a code of it is confirmed
+// @Tags user
+// @Accept json
+// @Produce json
+// @Success 200 {object} helper.Response
+// @Failure 409 {object} helper.Response
+// @Router /api/profile/mfa [post]
+func (h *handler) EnrollMfa(c *gin.Context) {
+	res, err := h.userService.EnrollMfa(c.Request.Context(), c.GetString("username"))
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "enroll mfa successfully",
+		Data:    res,
+	})
+}
+
+// @Summary Confirm MFA
+// @Description Turn two-factor authentication on with the first code of the authenticator app, answers with the recovery codes
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param code body payload.MfaCodeRequest true "Param Code"
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Router /api/profile/mfa/confirm [post]
+func (h *handler) ConfirmMfa(c *gin.Context) {
+	var (
+		codeRequest payload.MfaCodeRequest
+	)
+
+	if err := c.ShouldBind(&codeRequest); err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	validate := validator.New()
+	err := validate.Struct(codeRequest)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	res, err := h.userService.ConfirmMfa(c.Request.Context(), c.GetString("username"), codeRequest)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "confirm mfa successfully",
+		Data:    res,
+	})
+}
+
+// @Summary Regenerate Recovery Codes
+// @Description Replace the recovery codes with new ones, takes a code
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param code body payload.MfaCodeRequest true "Param Code"
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Router /api/profile/mfa/recovery-codes [post]
+func (h *handler) RegenerateRecoveryCodes(c *gin.Context) {
+	var (
+		codeRequest payload.MfaCodeRequest
+	)
+
+	if err := c.ShouldBind(&codeRequest); err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	validate := validator.New()
+	err := validate.Struct(codeRequest)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	res, err := h.userService.RegenerateRecoveryCodes(c.Request.Context(), c.GetString("username"), codeRequest)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "regenerate recovery codes successfully",
+		Data:    res,
+	})
+}
+
+// @Summary Disable MFA
+// @Description Turn two-factor authentication off with the password and a code
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param mfa body payload.DisableMfaRequest true "Param MFA"
+// @Success 200 {object} helper.Response
+// @Failure 400 {object} helper.Response
+// @Router /api/profile/mfa [delete]
+func (h *handler) DisableMfa(c *gin.Context) {
+	var (
+		disableRequest payload.DisableMfaRequest
+	)
+
+	if err := c.ShouldBind(&disableRequest); err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	validate := validator.New()
+	err := validate.Struct(disableRequest)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	err = h.userService.DisableMfa(c.Request.Context(), c.GetString("username"), disableRequest)
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "disable mfa successfully",
+	})
+}
+
+// @Summary Reset MFA
+// @Description Turn two-factor authentication off for a user who lost the authenticator app and the recovery codes, admin only
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param username path string true "Username"
+// @Success 200 {object} helper.Response
+// @Failure 403 {object} helper.Response
+// @Failure 404 {object} helper.Response
+// @Router /api/admin/user/{username}/mfa [delete]
+func (h *handler) ResetMfa(c *gin.Context) {
+	err := h.userService.ResetMfa(c.Request.Context(), c.GetString("username"), c.Param("username"))
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "reset mfa successfully",
+	})
+}
+
+// @Summary Revoke User API Tokens
+// @Description Revoke every api token of a user, admin only
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param username path string true "Username"
+// @Success 200 {object} helper.Response
+// @Failure 403 {object} helper.Response
+// @Failure 404 {object} helper.Response
+// @Router /api/admin/user/{username}/tokens [delete]
+func (h *handler) RevokeUserApiTokens(c *gin.Context) {
+	res, err := h.userService.RevokeUserApiTokens(c.Request.Context(), c.GetString("username"), c.Param("username"))
+	if err != nil {
+		helper.ResponseError(c, err)
+		return
+	}
+
+	helper.ResponseData(c, &helper.Response{
+		Message: "revoke api tokens successfully",
 		Data:    res,
 	})
 }
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+// ApiTokenPrefix starts every api token, it tells them apart from JWTs and makes leaked ones easy to search for
+const ApiTokenPrefix = "sbs_"
+
+// ApiTokenModel is a personal access token a user created for automation, it acts as the user within its scopes
+type ApiTokenModel struct {
+	ID         strfmt.UUID4 `json:"id" gorm:"type:uuid;default:uuid_generate_v4()"`
+	Username   string       `json:"username"`
+	Name       string       `json:"name"`
+	Scopes     []string     `json:"scopes" gorm:"serializer:json"`
+	TokenHash  string       `json:"-"`
+	TokenHint  string       `json:"token_hint"`
+	LastUsedAt *time.Time   `json:"last_used_at"`
+	LastUsedIp string       `json:"last_used_ip" gorm:"default:null"`
+	ExpiresAt  time.Time    `json:"expires_at"`
+	RevokedAt  *time.Time   `json:"revoked_at"`
+	CreatedAt  time.Time    `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (t ApiTokenModel) TableName() string {
+	return "api_tokens"
+}
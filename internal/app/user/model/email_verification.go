@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+type EmailVerificationModel struct {
+	ID        strfmt.UUID4 `json:"id" gorm:"type:uuid;default:uuid_generate_v4()"`
+	Username  string       `json:"username"`
+	Email     string       `json:"email"`
+	TokenHash string       `json:"-"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	UsedAt    *time.Time   `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (e EmailVerificationModel) TableName() string {
+	return "email_verifications"
+}
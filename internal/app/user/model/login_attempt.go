@@ -0,0 +1,16 @@
+package model
+
+import (
+	"time"
+)
+
+// LoginAttemptModel counts the failed logins of a username or an address
+type LoginAttemptModel struct {
+	Key          string    `json:"key" gorm:"primaryKey"`
+	Failures     int       `json:"failures"`
+	LastFailedAt time.Time `json:"last_failed_at"`
+}
+
+func (l LoginAttemptModel) TableName() string {
+	return "login_attempts"
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+// UserMfaModel is the TOTP secret of a user, two-factor authentication is on once ConfirmedAt is set
+type UserMfaModel struct {
+	Username    string     `json:"username" gorm:"primaryKey"`
+	Secret      string     `json:"-"`
+	ConfirmedAt *time.Time `json:"confirmed_at"`
+	LastStep    int64      `json:"-"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (m UserMfaModel) TableName() string {
+	return "user_mfa"
+}
+
+// Enabled tells whether logins of the user need a code
+func (m UserMfaModel) Enabled() bool {
+	return m.ConfirmedAt != nil
+}
+
+type RecoveryCodeModel struct {
+	ID        strfmt.UUID4 `json:"id" gorm:"type:uuid;default:uuid_generate_v4()"`
+	Username  string       `json:"username"`
+	CodeHash  string       `json:"-"`
+	UsedAt    *time.Time   `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (r RecoveryCodeModel) TableName() string {
+	return "mfa_recovery_codes"
+}
+
+// MfaChallengeModel is a login that passed the password and waits for the second factor
+type MfaChallengeModel struct {
+	ID        strfmt.UUID4 `json:"id" gorm:"type:uuid;default:uuid_generate_v4()"`
+	Username  string       `json:"username"`
+	TokenHash string       `json:"-"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	UsedAt    *time.Time   `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (c MfaChallengeModel) TableName() string {
+	return "mfa_challenges"
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+// OidcStateModel is a sign in with the identity provider that waits for the callback
+type OidcStateModel struct {
+	ID           strfmt.UUID4 `json:"id" gorm:"type:uuid;default:uuid_generate_v4()"`
+	StateHash    string       `json:"-"`
+	CodeVerifier string       `json:"-"`
+	Nonce        string       `json:"-"`
+	ExpiresAt    time.Time    `json:"expires_at"`
+	UsedAt       *time.Time   `json:"used_at"`
+	CreatedAt    time.Time    `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (s OidcStateModel) TableName() string {
+	return "oidc_states"
+}
+
+// UserIdentityModel links the subject of an identity provider to a local user
+type UserIdentityModel struct {
+	ID        strfmt.UUID4 `json:"id" gorm:"type:uuid;default:uuid_generate_v4()"`
+	Issuer    string       `json:"issuer"`
+	Subject   string       `json:"subject"`
+	Username  string       `json:"username"`
+	CreatedAt time.Time    `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (i UserIdentityModel) TableName() string {
+	return "user_identities"
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+type UserSessionModel struct {
+	ID               strfmt.UUID4 `json:"id" gorm:"type:uuid;default:uuid_generate_v4()"`
+	Username         string       `json:"username"`
+	RefreshTokenHash string       `json:"-"`
+	AccessJti        string       `json:"-"`
+	AccessExpiresAt  time.Time    `json:"-"`
+	IpAddress        string       `json:"ip_address" gorm:"default:null"`
+	UserAgent        string       `json:"user_agent" gorm:"default:null"`
+	LastSeenAt       time.Time    `json:"last_seen_at"`
+	ExpiresAt        time.Time    `json:"expires_at"`
+	RevokedAt        *time.Time   `json:"revoked_at"`
+	CreatedAt        time.Time    `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt        time.Time    `json:"updated_at" gorm:"autoUpdateTime"`
+	Current          bool         `json:"current" gorm:"-"`
+}
+
+func (s UserSessionModel) TableName() string {
+	return "user_sessions"
+}
+
+type RevokedTokenModel struct {
+	Jti       string    `json:"jti" gorm:"primaryKey"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (t RevokedTokenModel) TableName() string {
+	return "revoked_tokens"
+}
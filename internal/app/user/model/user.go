@@ -4,21 +4,56 @@ import (
 	"time"
 
 	"github.com/go-openapi/strfmt"
+	"gorm.io/gorm"
 )
 
+// DeletedUsername takes over the content of deleted accounts when it is anonymized, nobody can register it
+const DeletedUsername = "[deleted]"
+
 type AuthUserModel struct {
-	ID        strfmt.UUID4 `json:"id" gorm:"type:uuid;default:uuid_generate_v4()"`
-	Username  string       `json:"username" validate:"required"`
-	Password  string       `json:"password" validate:"required"`
-	IsActive  bool         `json:"is_active"`
-	LastLogin time.Time    `json:"last_login"`
-	CreatedBy string       `json:"created_by"`
-	UpdatedBy string       `json:"updated_by" gorm:"default:null"`
-	CreatedAt time.Time    `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt time.Time    `json:"updated_at" gorm:"autoUpdateTime"`
-	DeletedAt time.Time    `json:"deleted_at" gorm:"default:null"`
+	ID              strfmt.UUID4 `json:"id" gorm:"type:uuid;default:uuid_generate_v4()"`
+	Username        string       `json:"username" validate:"required"`
+	Password        string       `json:"password" validate:"required"`
+	Email           string       `json:"email" validate:"omitempty,email,max=254" gorm:"default:null"`
+	EmailVerifiedAt *time.Time   `json:"email_verified_at" gorm:"default:null"`
+	Role            string       `json:"role" gorm:"default:author"`
+	// IsActive is false while the user is suspended, a suspension with SuspendedUntil ends by itself
+	IsActive        bool           `json:"is_active" gorm:"default:true"`
+	SuspendedReason string         `json:"suspended_reason,omitempty" gorm:"default:null"`
+	SuspendedUntil  *time.Time     `json:"suspended_until,omitempty" gorm:"default:null"`
+	DeleteAfter     *time.Time     `json:"delete_after,omitempty" gorm:"default:null"`
+	LastLogin       time.Time      `json:"last_login"`
+	CreatedBy       string         `json:"created_by"`
+	UpdatedBy       string         `json:"updated_by" gorm:"default:null"`
+	CreatedAt       time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt       gorm.DeletedAt `json:"deleted_at" gorm:"default:null"`
 }
 
 func (u AuthUserModel) TableName() string {
 	return "auth_user"
 }
+
+// EmailVerified tells whether the user confirmed their current email address
+func (u AuthUserModel) EmailVerified() bool {
+	return u.Email != "" && u.EmailVerifiedAt != nil
+}
+
+// Active tells whether the user may log in at now, accounts waiting for their deletion are not active
+func (u AuthUserModel) Active(now time.Time) bool {
+	if u.DeleteAfter != nil {
+		return false
+	}
+
+	return !u.Suspended(now)
+}
+
+// Suspended tells whether the user is suspended at now
+func (u AuthUserModel) Suspended(now time.Time) bool {
+	return !u.IsActive && !u.SuspensionExpired(now)
+}
+
+// SuspensionExpired tells whether the user is suspended until a time that passed
+func (u AuthUserModel) SuspensionExpired(now time.Time) bool {
+	return !u.IsActive && u.SuspendedUntil != nil && !now.Before(*u.SuspendedUntil)
+}
@@ -1,9 +1,154 @@
 package payload
 
 import (
+	"time"
+
 	"simple-blog-system/internal/app/user/model"
 )
 
 type User struct {
 	User model.AuthUserModel `json:"auth_user"`
 }
+
+type RoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=admin editor author reader"`
+}
+
+type Token struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	// ExpiresIn is the lifetime of the access token in seconds, or of the mfa token when a code is required
+	ExpiresIn int64 `json:"expires_in"`
+	// MfaToken is all a user with two-factor authentication gets from the password, it is swapped for a token pair
+	// together with a code
+	MfaRequired bool   `json:"mfa_required,omitempty"`
+	MfaToken    string `json:"mfa_token,omitempty"`
+}
+
+type MfaLoginRequest struct {
+	MfaToken string `json:"mfa_token" validate:"required"`
+	// Code is the code of the authenticator app or a recovery code
+	Code string `json:"code" validate:"required,max=20"`
+}
+
+// OidcLogin is where to send the user to sign in with the identity provider, the client keeps State to check it against
+// the one the provider sends back
+type OidcLogin struct {
+	AuthorizationURL string `json:"authorization_url"`
+	State            string `json:"state"`
+	ExpiresIn        int64  `json:"expires_in"`
+}
+
+// OidcCallbackRequest is what the identity provider sends back to the redirect url, Error instead of Code when the sign in was refused
+type OidcCallbackRequest struct {
+	Code  string `form:"code" validate:"required_without=Error"`
+	State string `form:"state" validate:"required"`
+	Error string `form:"error"`
+}
+
+type MfaCodeRequest struct {
+	Code string `json:"code" validate:"required,max=20"`
+}
+
+type DisableMfaRequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required,max=20"`
+}
+
+// MfaEnrollment is the secret to add to an authenticator app, OtpauthURI is meant for a QR code
+type MfaEnrollment struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauth_uri"`
+}
+
+// RecoveryCodes are shown once, each of them stands in for a code of the authenticator app one time
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
+
+type ApiTokenRequest struct {
+	Name   string   `json:"name" validate:"required,max=100"`
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,required"`
+	// ExpiresAt defaults to the configured lifetime of api tokens
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// ApiToken is shown once on creation, only the hash of Token is kept
+type ApiToken struct {
+	Token    string              `json:"token"`
+	ApiToken model.ApiTokenModel `json:"api_token"`
+}
+
+type RevokedApiTokens struct {
+	Revoked int64 `json:"revoked"`
+}
+
+// ApiTokenIdentity is the user a request with an api token acts as, limited to Scopes
+type ApiTokenIdentity struct {
+	TokenId  string
+	UserId   string
+	Username string
+	Role     string
+	Scopes   []string
+}
+
+// Client is the device a session is started or refreshed from
+type Client struct {
+	IpAddress string
+	UserAgent string
+}
+
+type RevokedSessions struct {
+	Revoked int `json:"revoked"`
+}
+
+// Lockout is a username or an address that is kept from logging in after too many failures, only one of them is set
+type Lockout struct {
+	Username  string
+	IpAddress string
+	Failures  int
+	Until     time.Time
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type EmailRequest struct {
+	Email string `json:"email" validate:"required,email,max=254"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `form:"token" validate:"required"`
+}
+
+type PasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	// NewPassword is capped at 72 bytes, bcrypt ignores anything longer
+	NewPassword string `json:"new_password" validate:"required,min=8,max=72"`
+}
+
+type ForgotPasswordRequest struct {
+	Username string `json:"username" validate:"required"`
+}
+
+// SuspendRequest suspends a user for good, unless Until is set
+type SuspendRequest struct {
+	Reason string     `json:"reason" validate:"required,max=500"`
+	Until  *time.Time `json:"until"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+// AccountDeletion is when a deleted account is gone for good, logging in before then keeps it
+type AccountDeletion struct {
+	DeleteAfter time.Time `json:"delete_after"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=8,max=72"`
+}
@@ -12,6 +12,90 @@ type IUserHandler interface {
 	// (POST /user/login)
 	Login(ctx *gin.Context)
 
+	// (POST /user/login/mfa)
+	VerifyMfa(ctx *gin.Context)
+
+	// (GET /user/oidc/login)
+	StartOidc(ctx *gin.Context)
+
+	// (GET /user/oidc/callback)
+	CompleteOidc(ctx *gin.Context)
+
+	// (POST /user/refresh)
+	Refresh(ctx *gin.Context)
+
+	// (POST /user/logout)
+	Logout(ctx *gin.Context)
+
+	// (POST /user/password/forgot)
+	ForgotPassword(ctx *gin.Context)
+
+	// (POST /user/password/reset)
+	ResetPassword(ctx *gin.Context)
+
+	// (GET /user/verify)
+	VerifyEmail(ctx *gin.Context)
+
+	// (PUT /profile/email)
+	ChangeEmail(ctx *gin.Context)
+
+	// (PUT /profile/password)
+	ChangePassword(ctx *gin.Context)
+
+	// (DELETE /profile/)
+	DeleteAccount(ctx *gin.Context)
+
+	// (POST /profile/tokens)
+	CreateApiToken(ctx *gin.Context)
+
+	// (GET /profile/tokens)
+	GetApiTokens(ctx *gin.Context)
+
+	// (DELETE /profile/tokens/:id)
+	RevokeApiToken(ctx *gin.Context)
+
+	// (POST /profile/mfa)
+	EnrollMfa(ctx *gin.Context)
+
+	// (POST /profile/mfa/confirm)
+	ConfirmMfa(ctx *gin.Context)
+
+	// (POST /profile/mfa/recovery-codes)
+	RegenerateRecoveryCodes(ctx *gin.Context)
+
+	// (DELETE /profile/mfa)
+	DisableMfa(ctx *gin.Context)
+
+	// (GET /.well-known/jwks.json)
+	Jwks(ctx *gin.Context)
+
 	// (GET /user/)
 	GetUser(ctx *gin.Context)
+
+	// (GET /profile/sessions)
+	GetSessions(ctx *gin.Context)
+
+	// (DELETE /profile/sessions/:id)
+	RevokeSession(ctx *gin.Context)
+
+	// (PUT /admin/user/:username/role)
+	UpdateRole(ctx *gin.Context)
+
+	// (DELETE /admin/user/:username/sessions)
+	RevokeUserSessions(ctx *gin.Context)
+
+	// (POST /admin/user/:username/unlock)
+	UnlockUser(ctx *gin.Context)
+
+	// (POST /admin/user/:username/suspend)
+	SuspendUser(ctx *gin.Context)
+
+	// (POST /admin/user/:username/reactivate)
+	ReactivateUser(ctx *gin.Context)
+
+	// (DELETE /admin/user/:username/mfa)
+	ResetMfa(ctx *gin.Context)
+
+	// (DELETE /admin/user/:username/tokens)
+	RevokeUserApiTokens(ctx *gin.Context)
 }
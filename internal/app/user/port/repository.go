@@ -2,6 +2,8 @@ package port
 
 import (
 	"context"
+	"time"
+
 	"simple-blog-system/internal/app/user/model"
 )
 
@@ -10,7 +12,184 @@ type IUserRepository interface {
 
 	GetUserByUsername(ctx context.Context, username string) (user []model.AuthUserModel, err error)
 
+	// GetUserByEmail lists the users of an email address regardless of case, the address is not unique among users
+	GetUserByEmail(ctx context.Context, email string) (user []model.AuthUserModel, err error)
+
+	// IsUsernameTaken also counts deleted users, their names are not handed out again
+	IsUsernameTaken(ctx context.Context, username string) (taken bool, err error)
+
 	GetPasswordByUsername(ctx context.Context, username string) (user []model.AuthUserModel, err error)
 
 	UpdateLastLogin(ctx context.Context, user model.AuthUserModel) error
+
+	UpdateRole(ctx context.Context, username string, role string) error
+
+	UpdatePassword(ctx context.Context, username string, password string) error
+
+	// UpdateEmail sets a new email address, which has to be verified again
+	UpdateEmail(ctx context.Context, username string, email string) error
+
+	// VerifyEmail marks the email address of a user as verified as long as it is still email, verified is false otherwise
+	VerifyEmail(ctx context.Context, username string, email string) (verified bool, err error)
+
+	// SuspendUser marks the user as inactive, until is when the suspension ends by itself, nil suspends until reactivated
+	SuspendUser(ctx context.Context, username string, reason string, until *time.Time) error
+
+	ReactivateUser(ctx context.Context, username string) error
+
+	// ScheduleDeletion sets when the account of username is deleted, nil cancels the deletion
+	ScheduleDeletion(ctx context.Context, username string, deleteAfter *time.Time) error
+
+	GetUsersDueForDeletion(ctx context.Context, now time.Time, limit int) (user []model.AuthUserModel, err error)
+
+	// DeleteUser soft deletes the user and forgets the email address
+	DeleteUser(ctx context.Context, username string) error
+}
+
+// IAccountStatus tells whether the user of a token may still use the API and with which role
+type IAccountStatus interface {
+	Lookup(ctx context.Context, username string) (active bool, role string, err error)
+}
+
+// IUserContent is the content of another domain that goes along with a deleted account
+type IUserContent interface {
+	// AnonymizeUserContent hands the content of username over to placeholder
+	AnonymizeUserContent(ctx context.Context, username string, placeholder string) (int, error)
+
+	DeleteUserContent(ctx context.Context, username string) (int, error)
+}
+
+type ISessionRepository interface {
+	InsertSession(ctx context.Context, session model.UserSessionModel) (model.UserSessionModel, error)
+
+	GetSessionById(ctx context.Context, id string) (session []model.UserSessionModel, err error)
+
+	// GetSessionsByUsername lists the sessions of a user that are neither revoked nor expired, last seen first
+	GetSessionsByUsername(ctx context.Context, username string) (sessions []model.UserSessionModel, err error)
+
+	// RotateSession swaps the refresh token of a live session, rotated is false when the token was already swapped
+	RotateSession(ctx context.Context, session model.UserSessionModel, previousHash string) (rotated bool, err error)
+
+	RevokeSession(ctx context.Context, id string) error
+
+	// RevokeUserSessions revokes every session of a user but the one with the id except
+	RevokeUserSessions(ctx context.Context, username string, except string) error
+
+	DeleteExpiredSessions(ctx context.Context, before time.Time) (int64, error)
+}
+
+// IRevocationStore keeps the jti of access tokens that were revoked before they expired
+type IRevocationStore interface {
+	Revoke(ctx context.Context, jti string, expiresAt time.Time) error
+
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+type IPasswordResetRepository interface {
+	InsertPasswordReset(ctx context.Context, reset model.PasswordResetModel) (model.PasswordResetModel, error)
+
+	// UsePasswordReset marks the reset of a token hash as used and returns it, nothing is returned for a used or expired token
+	UsePasswordReset(ctx context.Context, tokenHash string) (reset []model.PasswordResetModel, err error)
+
+	// InvalidatePasswordResets marks every unused reset of a user as used
+	InvalidatePasswordResets(ctx context.Context, username string) error
+
+	DeleteExpiredPasswordResets(ctx context.Context, before time.Time) (int64, error)
+}
+
+type IEmailVerificationRepository interface {
+	InsertEmailVerification(ctx context.Context, verification model.EmailVerificationModel) (model.EmailVerificationModel, error)
+
+	// UseEmailVerification marks the verification of a token hash as used and returns it, nothing is returned for a used or expired token
+	UseEmailVerification(ctx context.Context, tokenHash string) (verification []model.EmailVerificationModel, err error)
+
+	// InvalidateEmailVerifications marks every unused verification of a user as used
+	InvalidateEmailVerifications(ctx context.Context, username string) error
+
+	DeleteExpiredEmailVerifications(ctx context.Context, before time.Time) (int64, error)
+}
+
+type IApiTokenRepository interface {
+	InsertApiToken(ctx context.Context, token model.ApiTokenModel) (model.ApiTokenModel, error)
+
+	// GetApiTokensByUsername lists the tokens of a user that are neither revoked nor expired, newest first
+	GetApiTokensByUsername(ctx context.Context, username string) (tokens []model.ApiTokenModel, err error)
+
+	// GetApiTokenByHash returns the token of a hash unless it is revoked or expired
+	GetApiTokenByHash(ctx context.Context, tokenHash string) (tokens []model.ApiTokenModel, err error)
+
+	// TouchApiToken records the last use of a token, uses shortly after the recorded one are skipped
+	TouchApiToken(ctx context.Context, id string, ipAddress string, usedAt time.Time) error
+
+	// RevokeApiToken revokes a live token of a user, revoked is false when the user has no such token
+	RevokeApiToken(ctx context.Context, username string, id string) (revoked bool, err error)
+
+	RevokeUserApiTokens(ctx context.Context, username string) (int64, error)
+
+	// DeleteExpiredApiTokens deletes the tokens that expired or were revoked before before
+	DeleteExpiredApiTokens(ctx context.Context, before time.Time) (int64, error)
+}
+
+type IMfaRepository interface {
+	GetMfa(ctx context.Context, username string) (mfa []model.UserMfaModel, err error)
+
+	// SaveMfa stores a new secret for the user, replacing the one before together with its confirmation
+	SaveMfa(ctx context.Context, mfa model.UserMfaModel) error
+
+	// ConfirmMfa turns on the secret of a user with the step of its first code, confirmed is false when it was on already
+	ConfirmMfa(ctx context.Context, username string, step int64) (confirmed bool, err error)
+
+	// UseMfaStep accepts a code of step when no code of that step or a later one was accepted before
+	UseMfaStep(ctx context.Context, username string, step int64) (used bool, err error)
+
+	// DeleteMfa turns two-factor authentication off for the user and drops their recovery codes
+	DeleteMfa(ctx context.Context, username string) error
+
+	// ReplaceRecoveryCodes drops the recovery codes of a user for codes
+	ReplaceRecoveryCodes(ctx context.Context, username string, codes []model.RecoveryCodeModel) error
+
+	// UseRecoveryCode marks the unused recovery code of a code hash as used, used is false when there is none
+	UseRecoveryCode(ctx context.Context, username string, codeHash string) (used bool, err error)
+
+	InsertMfaChallenge(ctx context.Context, challenge model.MfaChallengeModel) (model.MfaChallengeModel, error)
+
+	// GetMfaChallenge returns the challenge of a token hash unless it is used or expired
+	GetMfaChallenge(ctx context.Context, tokenHash string) (challenge []model.MfaChallengeModel, err error)
+
+	// UseMfaChallenge marks the challenge of a token hash as used, used is false when it was used or expired in the meantime
+	UseMfaChallenge(ctx context.Context, tokenHash string) (used bool, err error)
+
+	DeleteExpiredMfaChallenges(ctx context.Context, before time.Time) (int64, error)
+}
+
+type IOidcRepository interface {
+	InsertOidcState(ctx context.Context, state model.OidcStateModel) (model.OidcStateModel, error)
+
+	// UseOidcState marks the state of a state hash as used and returns it, nothing is returned for a used or expired state
+	UseOidcState(ctx context.Context, stateHash string) (state []model.OidcStateModel, err error)
+
+	DeleteExpiredOidcStates(ctx context.Context, before time.Time) (int64, error)
+
+	// GetIdentity returns the link of the subject of an issuer to a local user
+	GetIdentity(ctx context.Context, issuer string, subject string) (identity []model.UserIdentityModel, err error)
+
+	InsertIdentity(ctx context.Context, identity model.UserIdentityModel) (model.UserIdentityModel, error)
+
+	DeleteUserIdentities(ctx context.Context, username string) error
+}
+
+// ILoginAttemptStore counts failed logins per key, a key stands for a username or an address
+type ILoginAttemptStore interface {
+	// GetLoginAttempts returns the counters of the keys that failed before, keys without failures are left out
+	GetLoginAttempts(ctx context.Context, keys []string) ([]model.LoginAttemptModel, error)
+
+	// AddLoginFailure counts a failure of key at failedAt and returns the counter, a counter whose last failure
+	// is before since starts over
+	AddLoginFailure(ctx context.Context, key string, failedAt time.Time, since time.Time) (model.LoginAttemptModel, error)
+
+	ClearLoginAttempts(ctx context.Context, key string) error
+
+	DeleteExpiredLoginAttempts(ctx context.Context, before time.Time) (int64, error)
 }
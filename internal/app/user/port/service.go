@@ -4,12 +4,100 @@ import (
 	"context"
 	"simple-blog-system/internal/app/user/model"
 	"simple-blog-system/internal/app/user/payload"
+	"simple-blog-system/pkg/signing"
 )
 
 type IUserService interface {
-	Register(ctx context.Context, user model.AuthUserModel) (token string, err error)
+	Register(ctx context.Context, user model.AuthUserModel, client payload.Client) (token *payload.Token, err error)
 
-	Login(ctx context.Context, user model.AuthUserModel) (token string, err error)
+	// Login answers users with two-factor authentication with an mfa token only, VerifyMfa swaps it for a token pair
+	Login(ctx context.Context, user model.AuthUserModel, client payload.Client) (token *payload.Token, err error)
+
+	VerifyMfa(ctx context.Context, request payload.MfaLoginRequest, client payload.Client) (token *payload.Token, err error)
+
+	// StartOidc hands out the page of the identity provider to sign in on, CompleteOidc swaps the code it sends back for a token pair
+	StartOidc(ctx context.Context) (res *payload.OidcLogin, err error)
+
+	CompleteOidc(ctx context.Context, request payload.OidcCallbackRequest, client payload.Client) (token *payload.Token, err error)
+
+	Refresh(ctx context.Context, refreshToken string, client payload.Client) (token *payload.Token, err error)
+
+	Logout(ctx context.Context, refreshToken string) error
+
+	GetSessions(ctx context.Context, username string, currentSession string) (res []model.UserSessionModel, err error)
+
+	RevokeSession(ctx context.Context, username string, id string) error
+
+	RevokeUserSessions(ctx context.Context, actor string, username string) (res *payload.RevokedSessions, err error)
+
+	// ChangePassword signs the user out of every session but the current one
+	ChangePassword(ctx context.Context, username string, currentSession string, request payload.PasswordRequest) error
+
+	// ForgotPassword mails a reset token to the user, it answers the same whether the user exists or not
+	ForgotPassword(ctx context.Context, username string) error
+
+	ResetPassword(ctx context.Context, request payload.ResetPasswordRequest) error
+
+	VerifyEmail(ctx context.Context, token string) error
+
+	ChangeEmail(ctx context.Context, username string, email string) (res *payload.User, err error)
+
+	// EnrollMfa hands out a new TOTP secret, logins ask for codes once ConfirmMfa took the first one
+	EnrollMfa(ctx context.Context, username string) (res *payload.MfaEnrollment, err error)
+
+	ConfirmMfa(ctx context.Context, username string, request payload.MfaCodeRequest) (res *payload.RecoveryCodes, err error)
+
+	RegenerateRecoveryCodes(ctx context.Context, username string, request payload.MfaCodeRequest) (res *payload.RecoveryCodes, err error)
+
+	DisableMfa(ctx context.Context, username string, request payload.DisableMfaRequest) error
+
+	// ResetMfa turns two-factor authentication off for a user, admin only
+	ResetMfa(ctx context.Context, actor string, username string) error
+
+	// CreateApiToken hands out a new api token, the token itself is only returned here
+	CreateApiToken(ctx context.Context, username string, request payload.ApiTokenRequest) (res *payload.ApiToken, err error)
+
+	GetApiTokens(ctx context.Context, username string) (res []model.ApiTokenModel, err error)
+
+	RevokeApiToken(ctx context.Context, username string, id string) error
+
+	// RevokeUserApiTokens revokes every api token of a user, admin only
+	RevokeUserApiTokens(ctx context.Context, actor string, username string) (res *payload.RevokedApiTokens, err error)
+
+	IApiTokenAuthenticator
+
+	// Jwks are the public keys access tokens are verified with, other services verify the tokens with them
+	Jwks(ctx context.Context) signing.JWKS
 
 	GetUser(ctx context.Context, username string) (res *payload.User, err error)
+
+	UpdateRole(ctx context.Context, actor string, username string, role string) (res *payload.User, err error)
+
+	// UnlockUser forgets the failed logins of a user, admin only
+	UnlockUser(ctx context.Context, actor string, username string) error
+
+	// SuspendUser keeps a user from logging in until ReactivateUser or until request.Until, admin only
+	SuspendUser(ctx context.Context, actor string, username string, request payload.SuspendRequest) (res *payload.User, err error)
+
+	// ReactivateUser ends the suspension of a user, admin only
+	ReactivateUser(ctx context.Context, actor string, username string) (res *payload.User, err error)
+
+	// DeleteAccount schedules the deletion of the account of the user, logging in before it is due cancels it
+	DeleteAccount(ctx context.Context, username string, request payload.DeleteAccountRequest) (res *payload.AccountDeletion, err error)
+
+	// DeleteDueAccounts deletes the accounts whose deletion is due together with their content
+	DeleteDueAccounts(ctx context.Context) (int, error)
+
+	PurgeExpiredSessions(ctx context.Context) (int64, error)
+}
+
+// IApiTokenAuthenticator tells who a request with an api token acts as
+type IApiTokenAuthenticator interface {
+	// AuthenticateApiToken records the use of token from ipAddress, identity is nil when token is unknown, revoked or expired
+	AuthenticateApiToken(ctx context.Context, token string, ipAddress string) (identity *payload.ApiTokenIdentity, err error)
+}
+
+// ILockoutNotifier is told whenever a username or an address gets locked out after too many failed logins
+type ILockoutNotifier interface {
+	NotifyLockout(ctx context.Context, lockout payload.Lockout)
 }
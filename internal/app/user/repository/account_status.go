@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"simple-blog-system/internal/app/user/port"
+)
+
+type accountStatus struct {
+	users port.IUserRepository
+	ttl   time.Duration
+
+	mu      sync.Mutex
+	entries map[string]statusEntry
+	sweepAt int
+}
+
+type statusEntry struct {
+	active bool
+	role   string
+	until  time.Time
+}
+
+// NewAccountStatus looks up whether a user is active and their role and keeps the answer for ttl, so a user suspended,
+// deleted or given another role on any replica is treated so at the latest ttl later. A ttl of 0 asks the database every time.
+func NewAccountStatus(users port.IUserRepository, ttl time.Duration) port.IAccountStatus {
+	return &accountStatus{
+		users:   users,
+		ttl:     ttl,
+		entries: make(map[string]statusEntry),
+		sweepAt: minSweep,
+	}
+}
+
+func (r *accountStatus) Lookup(ctx context.Context, username string) (bool, string, error) {
+	now := time.Now()
+
+	r.mu.Lock()
+	entry, ok := r.entries[username]
+	r.mu.Unlock()
+	if ok && now.Before(entry.until) {
+		return entry.active, entry.role, nil
+	}
+
+	users, err := r.users.GetUserByUsername(ctx, username)
+	if err != nil {
+		return false, "", err
+	}
+
+	// deleted users are not found
+	entry = statusEntry{}
+	if len(users) > 0 {
+		entry = statusEntry{active: users[0].Active(now), role: users[0].Role}
+	}
+	if r.ttl > 0 {
+		entry.until = now.Add(r.ttl)
+		r.set(username, entry)
+	}
+
+	return entry.active, entry.role, nil
+}
+
+// set stores an entry, expired entries are swept once the map doubled since the last sweep
+func (r *accountStatus) set(username string, entry statusEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries[username] = entry
+	if len(r.entries) < r.sweepAt {
+		return
+	}
+
+	now := time.Now()
+	for key, e := range r.entries {
+		if !now.Before(e.until) {
+			delete(r.entries, key)
+		}
+	}
+	r.sweepAt = max(2*len(r.entries), minSweep)
+}
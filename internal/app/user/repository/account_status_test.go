@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"simple-blog-system/internal/app/user/model"
+	"simple-blog-system/internal/app/user/port"
+	"simple-blog-system/pkg/authorization"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// countingUsers answers GetUserByUsername from a map and counts the lookups, the other methods are not used
+type countingUsers struct {
+	port.IUserRepository
+	users   map[string]model.AuthUserModel
+	lookups int
+}
+
+func (r *countingUsers) GetUserByUsername(ctx context.Context, username string) ([]model.AuthUserModel, error) {
+	r.lookups++
+	user, ok := r.users[username]
+	if !ok {
+		return []model.AuthUserModel{}, nil
+	}
+
+	return []model.AuthUserModel{user}, nil
+}
+
+type AccountStatusTestSuite struct {
+	suite.Suite
+	users *countingUsers
+	ctx   context.Context
+}
+
+func (suite *AccountStatusTestSuite) SetupTest() {
+	suite.users = &countingUsers{users: map[string]model.AuthUserModel{
+		"active": {Username: "active", IsActive: true},
+	}}
+	suite.ctx = context.Background()
+}
+
+func TestAccountStatusTestSuite(t *testing.T) {
+	suite.Run(t, new(AccountStatusTestSuite))
+}
+
+func (suite *AccountStatusTestSuite) TestLookup_AnswersFromCache() {
+	accounts := NewAccountStatus(suite.users, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		active, _, err := accounts.Lookup(suite.ctx, "active")
+		assert.NoError(suite.T(), err)
+		assert.True(suite.T(), active)
+	}
+
+	assert.Equal(suite.T(), 1, suite.users.lookups)
+}
+
+func (suite *AccountStatusTestSuite) TestLookup_InactiveAccounts() {
+	until := time.Now().Add(-time.Minute)
+	deleteAfter := time.Now().Add(time.Hour)
+	suite.users.users["suspended"] = model.AuthUserModel{Username: "suspended", SuspendedReason: "spam"}
+	suite.users.users["expired"] = model.AuthUserModel{Username: "expired", SuspendedUntil: &until}
+	suite.users.users["leaving"] = model.AuthUserModel{Username: "leaving", IsActive: true, DeleteAfter: &deleteAfter}
+	accounts := NewAccountStatus(suite.users, time.Minute)
+
+	for username, want := range map[string]bool{"suspended": false, "expired": true, "leaving": false, "deleted": false} {
+		active, _, err := accounts.Lookup(suite.ctx, username)
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), want, active, username)
+	}
+}
+
+func (suite *AccountStatusTestSuite) TestLookup_AsksAgainAfterTTL() {
+	accounts := NewAccountStatus(suite.users, time.Millisecond)
+
+	active, _, _ := accounts.Lookup(suite.ctx, "active")
+	assert.True(suite.T(), active)
+
+	// suspended on another replica
+	suite.users.users["active"] = model.AuthUserModel{Username: "active"}
+	time.Sleep(2 * time.Millisecond)
+
+	active, _, _ = accounts.Lookup(suite.ctx, "active")
+	assert.False(suite.T(), active)
+	assert.Equal(suite.T(), 2, suite.users.lookups)
+}
+
+func (suite *AccountStatusTestSuite) TestLookup_Role() {
+	suite.users.users["demoted"] = model.AuthUserModel{Username: "demoted", Role: authorization.RoleEditor, IsActive: true}
+	accounts := NewAccountStatus(suite.users, time.Millisecond)
+
+	_, role, _ := accounts.Lookup(suite.ctx, "demoted")
+	assert.Equal(suite.T(), authorization.RoleEditor, role)
+
+	// demoted on another replica
+	suite.users.users["demoted"] = model.AuthUserModel{Username: "demoted", Role: authorization.RoleAuthor, IsActive: true}
+	time.Sleep(2 * time.Millisecond)
+
+	_, role, _ = accounts.Lookup(suite.ctx, "demoted")
+	assert.Equal(suite.T(), authorization.RoleAuthor, role)
+
+	_, role, _ = accounts.Lookup(suite.ctx, "deleted")
+	assert.Empty(suite.T(), role)
+}
+
+func (suite *AccountStatusTestSuite) TestLookup_WithoutCache() {
+	accounts := NewAccountStatus(suite.users, 0)
+
+	accounts.Lookup(suite.ctx, "active")
+	accounts.Lookup(suite.ctx, "active")
+
+	assert.Equal(suite.T(), 2, suite.users.lookups)
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"simple-blog-system/config/db"
+	"simple-blog-system/pkg/transaction"
+
+	"simple-blog-system/internal/app/user/model"
+	"simple-blog-system/internal/app/user/port"
+)
+
+// lastUsedInterval is how often the last use of a token is written at most, a busy token would write on every request otherwise
+const lastUsedInterval = time.Minute
+
+type apiTokenRepository struct {
+	db *db.GormDB
+}
+
+func NewApiTokenRepository(db *db.GormDB) port.IApiTokenRepository {
+	return apiTokenRepository{db: db}
+}
+
+func (r apiTokenRepository) InsertApiToken(ctx context.Context, token model.ApiTokenModel) (model.ApiTokenModel, error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err := trx.Create(&token).Error
+
+	return token, err
+}
+
+func (r apiTokenRepository) GetApiTokensByUsername(ctx context.Context, username string) (tokens []model.ApiTokenModel, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Where("username = ? AND revoked_at IS NULL AND expires_at > ?", username, time.Now()).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+func (r apiTokenRepository) GetApiTokenByHash(ctx context.Context, tokenHash string) (tokens []model.ApiTokenModel, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Where("token_hash = ? AND revoked_at IS NULL AND expires_at > ?", tokenHash, time.Now()).Find(&tokens).Error
+	return tokens, err
+}
+
+func (r apiTokenRepository) TouchApiToken(ctx context.Context, id string, ipAddress string, usedAt time.Time) error {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err := trx.Model(&model.ApiTokenModel{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, usedAt.Add(-lastUsedInterval)).
+		Updates(map[string]interface{}{
+			"last_used_at": usedAt,
+			"last_used_ip": ipAddress,
+		}).Error
+	return err
+}
+
+func (r apiTokenRepository) RevokeApiToken(ctx context.Context, username string, id string) (revoked bool, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	res := trx.Model(&model.ApiTokenModel{}).
+		Where("id = ? AND username = ? AND revoked_at IS NULL AND expires_at > ?", id, username, time.Now()).
+		Update("revoked_at", time.Now())
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r apiTokenRepository) RevokeUserApiTokens(ctx context.Context, username string) (int64, error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	res := trx.Model(&model.ApiTokenModel{}).
+		Where("username = ? AND revoked_at IS NULL AND expires_at > ?", username, time.Now()).
+		Update("revoked_at", time.Now())
+	return res.RowsAffected, res.Error
+}
+
+func (r apiTokenRepository) DeleteExpiredApiTokens(ctx context.Context, before time.Time) (int64, error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	res := trx.Where("expires_at < ? OR revoked_at < ?", before, before).Delete(&model.ApiTokenModel{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"simple-blog-system/config/db"
+	"simple-blog-system/internal/app/user/model"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type ApiTokenRepositoryTestSuite struct {
+	suite.Suite
+	db         *gorm.DB
+	mock       sqlmock.Sqlmock
+	repository apiTokenRepository
+}
+
+func (suite *ApiTokenRepositoryTestSuite) SetupTest() {
+	var (
+		sqlDB *sql.DB
+		err   error
+	)
+
+	sqlDB, suite.mock, err = sqlmock.New()
+	assert.NoError(suite.T(), err)
+
+	suite.db, err = gorm.Open(postgres.New(postgres.Config{
+		Conn: sqlDB,
+	}), &gorm.Config{})
+	assert.NoError(suite.T(), err)
+
+	suite.repository = apiTokenRepository{db: &db.GormDB{DB: suite.db}}
+}
+
+func (suite *ApiTokenRepositoryTestSuite) TearDownTest() {
+	sqlDB, err := suite.db.DB()
+	assert.NoError(suite.T(), err)
+	sqlDB.Close()
+}
+
+func TestApiTokenRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(ApiTokenRepositoryTestSuite))
+}
+
+func (suite *ApiTokenRepositoryTestSuite) TestInsertApiToken_Success() {
+	ctx := context.Background()
+	token := model.ApiTokenModel{
+		Username:  "testuser",
+		Name:      "ci",
+		Scopes:    []string{"posts:read", "posts:write"},
+		TokenHash: "hash",
+		TokenHint: "abcd",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "api_tokens" ("username","name","scopes","token_hash","token_hint","last_used_at","expires_at","revoked_at","created_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9) RETURNING "id","last_used_ip"`)).
+		WithArgs("testuser", "ci", `["posts:read","posts:write"]`, "hash", "abcd", nil, token.ExpiresAt, nil, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "last_used_ip"}).AddRow("123e4567-e89b-12d3-a456-426614174000", nil))
+	suite.mock.ExpectCommit()
+
+	res, err := suite.repository.InsertApiToken(ctx, token)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "123e4567-e89b-12d3-a456-426614174000", res.ID.String())
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *ApiTokenRepositoryTestSuite) TestGetApiTokenByHash_Success() {
+	ctx := context.Background()
+
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "api_tokens" WHERE token_hash = $1 AND revoked_at IS NULL AND expires_at > $2`)).
+		WithArgs("hash", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "name", "scopes", "token_hash"}).
+			AddRow("123e4567-e89b-12d3-a456-426614174000", "testuser", "ci", `["posts:write"]`, "hash"))
+
+	tokens, err := suite.repository.GetApiTokenByHash(ctx, "hash")
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), tokens, 1)
+	assert.Equal(suite.T(), []string{"posts:write"}, tokens[0].Scopes)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *ApiTokenRepositoryTestSuite) TestTouchApiToken_SkipsRecentUse() {
+	ctx := context.Background()
+	usedAt := time.Now()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "api_tokens" SET "last_used_at"=$1,"last_used_ip"=$2 WHERE id = $3 AND (last_used_at IS NULL OR last_used_at < $4)`)).
+		WithArgs(usedAt, "10.0.0.1", "token-1", usedAt.Add(-time.Minute)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.TouchApiToken(ctx, "token-1", "10.0.0.1", usedAt)
+
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *ApiTokenRepositoryTestSuite) TestRevokeApiToken_OtherUser() {
+	ctx := context.Background()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "api_tokens" SET "revoked_at"=$1 WHERE id = $2 AND username = $3 AND revoked_at IS NULL AND expires_at > $4`)).
+		WithArgs(sqlmock.AnyArg(), "token-1", "testuser", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectCommit()
+
+	revoked, err := suite.repository.RevokeApiToken(ctx, "testuser", "token-1")
+
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), revoked)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *ApiTokenRepositoryTestSuite) TestRevokeUserApiTokens_Success() {
+	ctx := context.Background()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "api_tokens" SET "revoked_at"=$1 WHERE username = $2 AND revoked_at IS NULL AND expires_at > $3`)).
+		WithArgs(sqlmock.AnyArg(), "testuser", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	suite.mock.ExpectCommit()
+
+	revoked, err := suite.repository.RevokeUserApiTokens(ctx, "testuser")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(2), revoked)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *ApiTokenRepositoryTestSuite) TestDeleteExpiredApiTokens_Success() {
+	ctx := context.Background()
+	before := time.Now()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "api_tokens" WHERE expires_at < $1 OR revoked_at < $2`)).
+		WithArgs(before, before).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	suite.mock.ExpectCommit()
+
+	deleted, err := suite.repository.DeleteExpiredApiTokens(ctx, before)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(4), deleted)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
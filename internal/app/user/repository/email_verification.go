@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"simple-blog-system/config/db"
+	"simple-blog-system/pkg/transaction"
+
+	"simple-blog-system/internal/app/user/model"
+	"simple-blog-system/internal/app/user/port"
+
+	"gorm.io/gorm/clause"
+)
+
+type emailVerificationRepository struct {
+	db *db.GormDB
+}
+
+func NewEmailVerificationRepository(db *db.GormDB) port.IEmailVerificationRepository {
+	return emailVerificationRepository{db: db}
+}
+
+func (r emailVerificationRepository) InsertEmailVerification(ctx context.Context, verification model.EmailVerificationModel) (model.EmailVerificationModel, error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err := trx.Create(&verification).Error
+
+	return verification, err
+}
+
+func (r emailVerificationRepository) UseEmailVerification(ctx context.Context, tokenHash string) (verification []model.EmailVerificationModel, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Model(&verification).
+		Clauses(clause.Returning{}).
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, time.Now()).
+		Update("used_at", time.Now()).Error
+	return verification, err
+}
+
+func (r emailVerificationRepository) InvalidateEmailVerifications(ctx context.Context, username string) error {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err := trx.Model(&model.EmailVerificationModel{}).Where("username = ? AND used_at IS NULL", username).Update("used_at", time.Now()).Error
+	return err
+}
+
+func (r emailVerificationRepository) DeleteExpiredEmailVerifications(ctx context.Context, before time.Time) (int64, error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	res := trx.Where("expires_at < ?", before).Delete(&model.EmailVerificationModel{})
+	return res.RowsAffected, res.Error
+}
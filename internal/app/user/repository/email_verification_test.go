@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"simple-blog-system/config/db"
+	"simple-blog-system/internal/app/user/model"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type EmailVerificationRepositoryTestSuite struct {
+	suite.Suite
+	db         *gorm.DB
+	mock       sqlmock.Sqlmock
+	repository emailVerificationRepository
+}
+
+func (suite *EmailVerificationRepositoryTestSuite) SetupTest() {
+	var (
+		sqlDB *sql.DB
+		err   error
+	)
+
+	sqlDB, suite.mock, err = sqlmock.New()
+	assert.NoError(suite.T(), err)
+
+	suite.db, err = gorm.Open(postgres.New(postgres.Config{
+		Conn: sqlDB,
+	}), &gorm.Config{})
+	assert.NoError(suite.T(), err)
+
+	suite.repository = emailVerificationRepository{db: &db.GormDB{DB: suite.db}}
+}
+
+func (suite *EmailVerificationRepositoryTestSuite) TearDownTest() {
+	sqlDB, err := suite.db.DB()
+	assert.NoError(suite.T(), err)
+	sqlDB.Close()
+}
+
+func TestEmailVerificationRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(EmailVerificationRepositoryTestSuite))
+}
+
+func (suite *EmailVerificationRepositoryTestSuite) TestInsertEmailVerification_Success() {
+	ctx := context.Background()
+	verification := model.EmailVerificationModel{
+		Username:  "testuser",
+		Email:     "test@example.com",
+		TokenHash: "hash",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "email_verifications" ("username","email","token_hash","expires_at","used_at","created_at") VALUES ($1,$2,$3,$4,$5,$6) RETURNING "id"`)).
+		WithArgs("testuser", "test@example.com", "hash", verification.ExpiresAt, nil, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("123e4567-e89b-12d3-a456-426614174000"))
+	suite.mock.ExpectCommit()
+
+	res, err := suite.repository.InsertEmailVerification(ctx, verification)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "123e4567-e89b-12d3-a456-426614174000", res.ID.String())
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *EmailVerificationRepositoryTestSuite) TestUseEmailVerification_Success() {
+	ctx := context.Background()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`UPDATE "email_verifications" SET "used_at"=$1 WHERE token_hash = $2 AND used_at IS NULL AND expires_at > $3 RETURNING *`)).
+		WithArgs(sqlmock.AnyArg(), "hash", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "token_hash"}).AddRow("verification-1", "testuser", "hash"))
+	suite.mock.ExpectCommit()
+
+	res, err := suite.repository.UseEmailVerification(ctx, "hash")
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), res, 1)
+	assert.Equal(suite.T(), "testuser", res[0].Username)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *EmailVerificationRepositoryTestSuite) TestUseEmailVerification_UsedToken() {
+	ctx := context.Background()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`UPDATE "email_verifications" SET "used_at"=$1 WHERE token_hash = $2`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "token_hash"}))
+	suite.mock.ExpectCommit()
+
+	res, err := suite.repository.UseEmailVerification(ctx, "hash")
+
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), res)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *EmailVerificationRepositoryTestSuite) TestInvalidateEmailVerifications_Success() {
+	ctx := context.Background()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "email_verifications" SET "used_at"=$1 WHERE username = $2 AND used_at IS NULL`)).
+		WithArgs(sqlmock.AnyArg(), "testuser").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.InvalidateEmailVerifications(ctx, "testuser")
+
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *EmailVerificationRepositoryTestSuite) TestDeleteExpiredEmailVerifications_Success() {
+	ctx := context.Background()
+	before := time.Now()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "email_verifications" WHERE expires_at < $1`)).
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	suite.mock.ExpectCommit()
+
+	deleted, err := suite.repository.DeleteExpiredEmailVerifications(ctx, before)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(2), deleted)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
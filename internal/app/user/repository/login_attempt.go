@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"simple-blog-system/config/db"
+	"simple-blog-system/pkg/transaction"
+
+	"simple-blog-system/internal/app/user/model"
+	"simple-blog-system/internal/app/user/port"
+)
+
+const (
+	AttemptDriverPostgres = "postgres"
+	AttemptDriverMemory   = "memory"
+
+	// addFailureQuery counts a failure in one statement so concurrent logins do not lose any
+	addFailureQuery = `
+		INSERT INTO login_attempts (key, failures, last_failed_at) VALUES (@key, 1, @failed_at)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN login_attempts.last_failed_at < @since THEN 1 ELSE login_attempts.failures + 1 END,
+			last_failed_at = EXCLUDED.last_failed_at
+		RETURNING key, failures, last_failed_at`
+)
+
+type loginAttemptStore struct {
+	db *db.GormDB
+}
+
+// NewAttemptStore returns the store of failed logins for driver, postgres unless memory is asked for
+func NewAttemptStore(driver string, db *db.GormDB) port.ILoginAttemptStore {
+	if driver == AttemptDriverMemory {
+		return NewMemoryAttemptStore()
+	}
+
+	return NewPostgresAttemptStore(db)
+}
+
+// NewPostgresAttemptStore keeps the failed logins in postgres so every replica counts them
+func NewPostgresAttemptStore(db *db.GormDB) port.ILoginAttemptStore {
+	return loginAttemptStore{db: db}
+}
+
+func (r loginAttemptStore) GetLoginAttempts(ctx context.Context, keys []string) (attempts []model.LoginAttemptModel, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Where("key IN ?", keys).Find(&attempts).Error
+	return attempts, err
+}
+
+func (r loginAttemptStore) AddLoginFailure(ctx context.Context, key string, failedAt time.Time, since time.Time) (attempt model.LoginAttemptModel, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Raw(addFailureQuery, map[string]interface{}{
+		"key":       key,
+		"failed_at": failedAt,
+		"since":     since,
+	}).Scan(&attempt).Error
+	return attempt, err
+}
+
+func (r loginAttemptStore) ClearLoginAttempts(ctx context.Context, key string) error {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err := trx.Where("key = ?", key).Delete(&model.LoginAttemptModel{}).Error
+	return err
+}
+
+func (r loginAttemptStore) DeleteExpiredLoginAttempts(ctx context.Context, before time.Time) (int64, error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	res := trx.Where("last_failed_at < ?", before).Delete(&model.LoginAttemptModel{})
+	return res.RowsAffected, res.Error
+}
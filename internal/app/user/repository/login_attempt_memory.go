@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"simple-blog-system/internal/app/user/model"
+	"simple-blog-system/internal/app/user/port"
+)
+
+type memoryAttemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]model.LoginAttemptModel
+}
+
+// NewMemoryAttemptStore counts the failed logins in memory, for tests and single node deployments,
+// every replica counts on its own and the counters are lost on restart
+func NewMemoryAttemptStore() port.ILoginAttemptStore {
+	return &memoryAttemptStore{attempts: make(map[string]model.LoginAttemptModel)}
+}
+
+func (r *memoryAttemptStore) GetLoginAttempts(ctx context.Context, keys []string) ([]model.LoginAttemptModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempts := []model.LoginAttemptModel{}
+	for _, key := range keys {
+		if attempt, ok := r.attempts[key]; ok {
+			attempts = append(attempts, attempt)
+		}
+	}
+
+	return attempts, nil
+}
+
+func (r *memoryAttemptStore) AddLoginFailure(ctx context.Context, key string, failedAt time.Time, since time.Time) (model.LoginAttemptModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempt, ok := r.attempts[key]
+	if !ok || attempt.LastFailedAt.Before(since) {
+		attempt = model.LoginAttemptModel{Key: key}
+	}
+	attempt.Failures++
+	attempt.LastFailedAt = failedAt
+	r.attempts[key] = attempt
+
+	return attempt, nil
+}
+
+func (r *memoryAttemptStore) ClearLoginAttempts(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.attempts, key)
+	return nil
+}
+
+func (r *memoryAttemptStore) DeleteExpiredLoginAttempts(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for key, attempt := range r.attempts {
+		if attempt.LastFailedAt.Before(before) {
+			delete(r.attempts, key)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type MemoryAttemptStoreTestSuite struct {
+	suite.Suite
+	store *memoryAttemptStore
+	ctx   context.Context
+	now   time.Time
+}
+
+func (suite *MemoryAttemptStoreTestSuite) SetupTest() {
+	suite.store = NewMemoryAttemptStore().(*memoryAttemptStore)
+	suite.ctx = context.Background()
+	suite.now = time.Now()
+}
+
+func TestMemoryAttemptStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(MemoryAttemptStoreTestSuite))
+}
+
+func (suite *MemoryAttemptStoreTestSuite) TestAddLoginFailure_Counts() {
+	for i := 1; i <= 3; i++ {
+		attempt, err := suite.store.AddLoginFailure(suite.ctx, "user:testuser", suite.now, suite.now.Add(-time.Hour))
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), i, attempt.Failures)
+	}
+
+	attempts, err := suite.store.GetLoginAttempts(suite.ctx, []string{"user:testuser", "ip:10.0.0.1"})
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), attempts, 1)
+	assert.Equal(suite.T(), 3, attempts[0].Failures)
+	assert.Equal(suite.T(), suite.now, attempts[0].LastFailedAt)
+}
+
+func (suite *MemoryAttemptStoreTestSuite) TestAddLoginFailure_StartsOverAfterWindow() {
+	_, _ = suite.store.AddLoginFailure(suite.ctx, "user:testuser", suite.now.Add(-2*time.Hour), suite.now.Add(-3*time.Hour))
+	_, _ = suite.store.AddLoginFailure(suite.ctx, "user:testuser", suite.now.Add(-2*time.Hour), suite.now.Add(-3*time.Hour))
+
+	attempt, err := suite.store.AddLoginFailure(suite.ctx, "user:testuser", suite.now, suite.now.Add(-time.Hour))
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, attempt.Failures)
+}
+
+func (suite *MemoryAttemptStoreTestSuite) TestClearLoginAttempts() {
+	_, _ = suite.store.AddLoginFailure(suite.ctx, "user:testuser", suite.now, suite.now)
+	_, _ = suite.store.AddLoginFailure(suite.ctx, "ip:10.0.0.1", suite.now, suite.now)
+
+	err := suite.store.ClearLoginAttempts(suite.ctx, "user:testuser")
+
+	assert.NoError(suite.T(), err)
+	attempts, _ := suite.store.GetLoginAttempts(suite.ctx, []string{"user:testuser", "ip:10.0.0.1"})
+	assert.Len(suite.T(), attempts, 1)
+	assert.Equal(suite.T(), "ip:10.0.0.1", attempts[0].Key)
+}
+
+func (suite *MemoryAttemptStoreTestSuite) TestDeleteExpiredLoginAttempts() {
+	_, _ = suite.store.AddLoginFailure(suite.ctx, "user:old", suite.now.Add(-48*time.Hour), suite.now)
+	_, _ = suite.store.AddLoginFailure(suite.ctx, "user:new", suite.now, suite.now)
+
+	deleted, err := suite.store.DeleteExpiredLoginAttempts(suite.ctx, suite.now.Add(-24*time.Hour))
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(1), deleted)
+	attempts, _ := suite.store.GetLoginAttempts(suite.ctx, []string{"user:old", "user:new"})
+	assert.Len(suite.T(), attempts, 1)
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"simple-blog-system/config/db"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type LoginAttemptStoreTestSuite struct {
+	suite.Suite
+	db    *gorm.DB
+	mock  sqlmock.Sqlmock
+	store loginAttemptStore
+}
+
+func (suite *LoginAttemptStoreTestSuite) SetupTest() {
+	var (
+		sqlDB *sql.DB
+		err   error
+	)
+
+	sqlDB, suite.mock, err = sqlmock.New()
+	assert.NoError(suite.T(), err)
+
+	suite.db, err = gorm.Open(postgres.New(postgres.Config{
+		Conn: sqlDB,
+	}), &gorm.Config{})
+	assert.NoError(suite.T(), err)
+
+	suite.store = loginAttemptStore{db: &db.GormDB{DB: suite.db}}
+}
+
+func (suite *LoginAttemptStoreTestSuite) TearDownTest() {
+	sqlDB, err := suite.db.DB()
+	assert.NoError(suite.T(), err)
+	sqlDB.Close()
+}
+
+func TestLoginAttemptStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(LoginAttemptStoreTestSuite))
+}
+
+func (suite *LoginAttemptStoreTestSuite) TestGetLoginAttempts_Success() {
+	ctx := context.Background()
+	now := time.Now()
+
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "login_attempts" WHERE key IN ($1,$2)`)).
+		WithArgs("user:testuser", "ip:10.0.0.1").
+		WillReturnRows(sqlmock.NewRows([]string{"key", "failures", "last_failed_at"}).AddRow("user:testuser", 3, now))
+
+	res, err := suite.store.GetLoginAttempts(ctx, []string{"user:testuser", "ip:10.0.0.1"})
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), res, 1)
+	assert.Equal(suite.T(), 3, res[0].Failures)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *LoginAttemptStoreTestSuite) TestAddLoginFailure_Success() {
+	ctx := context.Background()
+	now := time.Now()
+	since := now.Add(-24 * time.Hour)
+
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO login_attempts (key, failures, last_failed_at) VALUES ($1, 1, $2)`)).
+		WithArgs("user:testuser", now, since).
+		WillReturnRows(sqlmock.NewRows([]string{"key", "failures", "last_failed_at"}).AddRow("user:testuser", 4, now))
+
+	res, err := suite.store.AddLoginFailure(ctx, "user:testuser", now, since)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "user:testuser", res.Key)
+	assert.Equal(suite.T(), 4, res.Failures)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *LoginAttemptStoreTestSuite) TestClearLoginAttempts_Success() {
+	ctx := context.Background()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "login_attempts" WHERE key = $1`)).
+		WithArgs("user:testuser").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.store.ClearLoginAttempts(ctx, "user:testuser")
+
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *LoginAttemptStoreTestSuite) TestDeleteExpiredLoginAttempts_Success() {
+	ctx := context.Background()
+	before := time.Now()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "login_attempts" WHERE last_failed_at < $1`)).
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	suite.mock.ExpectCommit()
+
+	deleted, err := suite.store.DeleteExpiredLoginAttempts(ctx, before)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(2), deleted)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"simple-blog-system/config/db"
+	"simple-blog-system/pkg/transaction"
+
+	"simple-blog-system/internal/app/user/model"
+	"simple-blog-system/internal/app/user/port"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type mfaRepository struct {
+	db *db.GormDB
+}
+
+func NewMfaRepository(db *db.GormDB) port.IMfaRepository {
+	return mfaRepository{db: db}
+}
+
+func (r mfaRepository) GetMfa(ctx context.Context, username string) (mfa []model.UserMfaModel, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Where("username = ?", username).Find(&mfa).Error
+	return mfa, err
+}
+
+func (r mfaRepository) SaveMfa(ctx context.Context, mfa model.UserMfaModel) error {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err := trx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "username"}},
+		DoUpdates: clause.AssignmentColumns([]string{"secret", "confirmed_at", "last_step", "updated_at"}),
+	}).Create(&mfa).Error
+	return err
+}
+
+func (r mfaRepository) ConfirmMfa(ctx context.Context, username string, step int64) (confirmed bool, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	res := trx.Model(&model.UserMfaModel{}).
+		Where("username = ? AND confirmed_at IS NULL", username).
+		Updates(map[string]interface{}{
+			"confirmed_at": time.Now(),
+			"last_step":    step,
+		})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r mfaRepository) UseMfaStep(ctx context.Context, username string, step int64) (used bool, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	// the step moves forward in the update, so two requests with the same code can not both go through
+	res := trx.Model(&model.UserMfaModel{}).
+		Where("username = ? AND confirmed_at IS NOT NULL AND last_step < ?", username, step).
+		Update("last_step", step)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r mfaRepository) DeleteMfa(ctx context.Context, username string) error {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	return trx.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("username = ?", username).Delete(&model.RecoveryCodeModel{}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Where("username = ?", username).Delete(&model.UserMfaModel{}).Error
+	})
+}
+
+func (r mfaRepository) ReplaceRecoveryCodes(ctx context.Context, username string, codes []model.RecoveryCodeModel) error {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	return trx.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("username = ?", username).Delete(&model.RecoveryCodeModel{}).Error
+		if err != nil || len(codes) == 0 {
+			return err
+		}
+
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r mfaRepository) UseRecoveryCode(ctx context.Context, username string, codeHash string) (used bool, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	res := trx.Model(&model.RecoveryCodeModel{}).
+		Where("username = ? AND code_hash = ? AND used_at IS NULL", username, codeHash).
+		Update("used_at", time.Now())
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r mfaRepository) InsertMfaChallenge(ctx context.Context, challenge model.MfaChallengeModel) (model.MfaChallengeModel, error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err := trx.Create(&challenge).Error
+
+	return challenge, err
+}
+
+func (r mfaRepository) GetMfaChallenge(ctx context.Context, tokenHash string) (challenge []model.MfaChallengeModel, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, time.Now()).Find(&challenge).Error
+	return challenge, err
+}
+
+func (r mfaRepository) UseMfaChallenge(ctx context.Context, tokenHash string) (used bool, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	res := trx.Model(&model.MfaChallengeModel{}).
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, time.Now()).
+		Update("used_at", time.Now())
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r mfaRepository) DeleteExpiredMfaChallenges(ctx context.Context, before time.Time) (int64, error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	res := trx.Where("expires_at < ?", before).Delete(&model.MfaChallengeModel{})
+	return res.RowsAffected, res.Error
+}
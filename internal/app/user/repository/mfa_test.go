@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"simple-blog-system/config/db"
+	"simple-blog-system/internal/app/user/model"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type MfaRepositoryTestSuite struct {
+	suite.Suite
+	db         *gorm.DB
+	mock       sqlmock.Sqlmock
+	repository mfaRepository
+}
+
+func (suite *MfaRepositoryTestSuite) SetupTest() {
+	var (
+		sqlDB *sql.DB
+		err   error
+	)
+
+	sqlDB, suite.mock, err = sqlmock.New()
+	assert.NoError(suite.T(), err)
+
+	suite.db, err = gorm.Open(postgres.New(postgres.Config{
+		Conn: sqlDB,
+	}), &gorm.Config{})
+	assert.NoError(suite.T(), err)
+
+	suite.repository = mfaRepository{db: &db.GormDB{DB: suite.db}}
+}
+
+func (suite *MfaRepositoryTestSuite) TearDownTest() {
+	sqlDB, err := suite.db.DB()
+	assert.NoError(suite.T(), err)
+	sqlDB.Close()
+}
+
+func TestMfaRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(MfaRepositoryTestSuite))
+}
+
+func (suite *MfaRepositoryTestSuite) TestSaveMfa_ReplacesEnrollment() {
+	ctx := context.Background()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "user_mfa" ("username","secret","confirmed_at","last_step","created_at","updated_at") VALUES ($1,$2,$3,$4,$5,$6) ON CONFLICT ("username") DO UPDATE SET "secret"="excluded"."secret","confirmed_at"="excluded"."confirmed_at","last_step"="excluded"."last_step","updated_at"="excluded"."updated_at"`)).
+		WithArgs("testuser", "SECRET", nil, 0, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.SaveMfa(ctx, model.UserMfaModel{Username: "testuser", Secret: "SECRET"})
+
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *MfaRepositoryTestSuite) TestConfirmMfa_Success() {
+	ctx := context.Background()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "user_mfa" SET "confirmed_at"=$1,"last_step"=$2,"updated_at"=$3 WHERE username = $4 AND confirmed_at IS NULL`)).
+		WithArgs(sqlmock.AnyArg(), 1000, sqlmock.AnyArg(), "testuser").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	confirmed, err := suite.repository.ConfirmMfa(ctx, "testuser", 1000)
+
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), confirmed)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *MfaRepositoryTestSuite) TestUseMfaStep_StepUsedBefore() {
+	ctx := context.Background()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "user_mfa" SET "last_step"=$1,"updated_at"=$2 WHERE username = $3 AND confirmed_at IS NOT NULL AND last_step < $4`)).
+		WithArgs(1000, sqlmock.AnyArg(), "testuser", 1000).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectCommit()
+
+	used, err := suite.repository.UseMfaStep(ctx, "testuser", 1000)
+
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), used)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *MfaRepositoryTestSuite) TestDeleteMfa_DropsRecoveryCodes() {
+	ctx := context.Background()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "mfa_recovery_codes" WHERE username = $1`)).
+		WithArgs("testuser").
+		WillReturnResult(sqlmock.NewResult(0, 10))
+	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "user_mfa" WHERE username = $1`)).
+		WithArgs("testuser").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.DeleteMfa(ctx, "testuser")
+
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *MfaRepositoryTestSuite) TestReplaceRecoveryCodes_Success() {
+	ctx := context.Background()
+	codes := []model.RecoveryCodeModel{
+		{Username: "testuser", CodeHash: "hash-1"},
+		{Username: "testuser", CodeHash: "hash-2"},
+	}
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "mfa_recovery_codes" WHERE username = $1`)).
+		WithArgs("testuser").
+		WillReturnResult(sqlmock.NewResult(0, 10))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "mfa_recovery_codes" ("username","code_hash","used_at","created_at") VALUES ($1,$2,$3,$4),($5,$6,$7,$8) RETURNING "id"`)).
+		WithArgs("testuser", "hash-1", nil, sqlmock.AnyArg(), "testuser", "hash-2", nil, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("123e4567-e89b-12d3-a456-426614174000").AddRow("123e4567-e89b-12d3-a456-426614174001"))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.ReplaceRecoveryCodes(ctx, "testuser", codes)
+
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *MfaRepositoryTestSuite) TestUseRecoveryCode_Success() {
+	ctx := context.Background()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "mfa_recovery_codes" SET "used_at"=$1 WHERE username = $2 AND code_hash = $3 AND used_at IS NULL`)).
+		WithArgs(sqlmock.AnyArg(), "testuser", "hash-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	used, err := suite.repository.UseRecoveryCode(ctx, "testuser", "hash-1")
+
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), used)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *MfaRepositoryTestSuite) TestGetMfaChallenge_Success() {
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Minute)
+
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "mfa_challenges" WHERE token_hash = $1 AND used_at IS NULL AND expires_at > $2`)).
+		WithArgs("hash", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "token_hash", "expires_at", "used_at", "created_at"}).
+			AddRow("123e4567-e89b-12d3-a456-426614174000", "testuser", "hash", expiresAt, nil, time.Now()))
+
+	challenges, err := suite.repository.GetMfaChallenge(ctx, "hash")
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), challenges, 1)
+	assert.Equal(suite.T(), "testuser", challenges[0].Username)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *MfaRepositoryTestSuite) TestDeleteExpiredMfaChallenges_Success() {
+	ctx := context.Background()
+	before := time.Now()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "mfa_challenges" WHERE expires_at < $1`)).
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	suite.mock.ExpectCommit()
+
+	deleted, err := suite.repository.DeleteExpiredMfaChallenges(ctx, before)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(3), deleted)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"simple-blog-system/config/db"
+	"simple-blog-system/pkg/transaction"
+
+	"simple-blog-system/internal/app/user/model"
+	"simple-blog-system/internal/app/user/port"
+
+	"gorm.io/gorm/clause"
+)
+
+type oidcRepository struct {
+	db *db.GormDB
+}
+
+func NewOidcRepository(db *db.GormDB) port.IOidcRepository {
+	return oidcRepository{db: db}
+}
+
+func (r oidcRepository) InsertOidcState(ctx context.Context, state model.OidcStateModel) (model.OidcStateModel, error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err := trx.Create(&state).Error
+
+	return state, err
+}
+
+func (r oidcRepository) UseOidcState(ctx context.Context, stateHash string) (state []model.OidcStateModel, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	// claiming the state in the update keeps a replayed callback from going through
+	err = trx.Model(&state).
+		Clauses(clause.Returning{}).
+		Where("state_hash = ? AND used_at IS NULL AND expires_at > ?", stateHash, time.Now()).
+		Update("used_at", time.Now()).Error
+	return state, err
+}
+
+func (r oidcRepository) DeleteExpiredOidcStates(ctx context.Context, before time.Time) (int64, error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	res := trx.Where("expires_at < ?", before).Delete(&model.OidcStateModel{})
+	return res.RowsAffected, res.Error
+}
+
+func (r oidcRepository) GetIdentity(ctx context.Context, issuer string, subject string) (identity []model.UserIdentityModel, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Where("issuer = ? AND subject = ?", issuer, subject).Find(&identity).Error
+	return identity, err
+}
+
+func (r oidcRepository) InsertIdentity(ctx context.Context, identity model.UserIdentityModel) (model.UserIdentityModel, error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err := trx.Create(&identity).Error
+
+	return identity, err
+}
+
+func (r oidcRepository) DeleteUserIdentities(ctx context.Context, username string) error {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err := trx.Where("username = ?", username).Delete(&model.UserIdentityModel{}).Error
+	return err
+}
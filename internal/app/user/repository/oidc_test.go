@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"simple-blog-system/config/db"
+	"simple-blog-system/internal/app/user/model"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type OidcRepositoryTestSuite struct {
+	suite.Suite
+	db         *gorm.DB
+	mock       sqlmock.Sqlmock
+	repository oidcRepository
+}
+
+func (suite *OidcRepositoryTestSuite) SetupTest() {
+	var (
+		sqlDB *sql.DB
+		err   error
+	)
+
+	sqlDB, suite.mock, err = sqlmock.New()
+	assert.NoError(suite.T(), err)
+
+	suite.db, err = gorm.Open(postgres.New(postgres.Config{
+		Conn: sqlDB,
+	}), &gorm.Config{})
+	assert.NoError(suite.T(), err)
+
+	suite.repository = oidcRepository{db: &db.GormDB{DB: suite.db}}
+}
+
+func (suite *OidcRepositoryTestSuite) TearDownTest() {
+	sqlDB, err := suite.db.DB()
+	assert.NoError(suite.T(), err)
+	sqlDB.Close()
+}
+
+func TestOidcRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(OidcRepositoryTestSuite))
+}
+
+func (suite *OidcRepositoryTestSuite) TestInsertOidcState_Success() {
+	ctx := context.Background()
+	state := model.OidcStateModel{
+		StateHash:    "hash",
+		CodeVerifier: "verifier",
+		Nonce:        "nonce",
+		ExpiresAt:    time.Now().Add(10 * time.Minute),
+	}
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "oidc_states" ("state_hash","code_verifier","nonce","expires_at","used_at","created_at") VALUES ($1,$2,$3,$4,$5,$6) RETURNING "id"`)).
+		WithArgs("hash", "verifier", "nonce", state.ExpiresAt, nil, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("123e4567-e89b-12d3-a456-426614174000"))
+	suite.mock.ExpectCommit()
+
+	res, err := suite.repository.InsertOidcState(ctx, state)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "123e4567-e89b-12d3-a456-426614174000", res.ID.String())
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *OidcRepositoryTestSuite) TestUseOidcState_Success() {
+	ctx := context.Background()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`UPDATE "oidc_states" SET "used_at"=$1 WHERE state_hash = $2 AND used_at IS NULL AND expires_at > $3 RETURNING *`)).
+		WithArgs(sqlmock.AnyArg(), "hash", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "state_hash", "code_verifier", "nonce"}).AddRow("state-1", "hash", "verifier", "nonce"))
+	suite.mock.ExpectCommit()
+
+	res, err := suite.repository.UseOidcState(ctx, "hash")
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), res, 1)
+	assert.Equal(suite.T(), "verifier", res[0].CodeVerifier)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *OidcRepositoryTestSuite) TestUseOidcState_UsedState() {
+	ctx := context.Background()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`UPDATE "oidc_states" SET "used_at"=$1 WHERE state_hash = $2`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "state_hash"}))
+	suite.mock.ExpectCommit()
+
+	res, err := suite.repository.UseOidcState(ctx, "hash")
+
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), res)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *OidcRepositoryTestSuite) TestGetIdentity_Success() {
+	ctx := context.Background()
+
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "user_identities" WHERE issuer = $1 AND subject = $2`)).
+		WithArgs("https://idp.example.com", "sub-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "issuer", "subject", "username"}).AddRow("identity-1", "https://idp.example.com", "sub-1", "testuser"))
+
+	res, err := suite.repository.GetIdentity(ctx, "https://idp.example.com", "sub-1")
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), res, 1)
+	assert.Equal(suite.T(), "testuser", res[0].Username)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *OidcRepositoryTestSuite) TestInsertIdentity_Success() {
+	ctx := context.Background()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "user_identities" ("issuer","subject","username","created_at") VALUES ($1,$2,$3,$4) RETURNING "id"`)).
+		WithArgs("https://idp.example.com", "sub-1", "testuser", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("123e4567-e89b-12d3-a456-426614174000"))
+	suite.mock.ExpectCommit()
+
+	res, err := suite.repository.InsertIdentity(ctx, model.UserIdentityModel{
+		Issuer:   "https://idp.example.com",
+		Subject:  "sub-1",
+		Username: "testuser",
+	})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "123e4567-e89b-12d3-a456-426614174000", res.ID.String())
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *OidcRepositoryTestSuite) TestDeleteUserIdentities_Success() {
+	ctx := context.Background()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "user_identities" WHERE username = $1`)).
+		WithArgs("testuser").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.DeleteUserIdentities(ctx, "testuser")
+
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *OidcRepositoryTestSuite) TestDeleteExpiredOidcStates_Success() {
+	ctx := context.Background()
+	before := time.Now()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "oidc_states" WHERE expires_at < $1`)).
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	suite.mock.ExpectCommit()
+
+	deleted, err := suite.repository.DeleteExpiredOidcStates(ctx, before)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(2), deleted)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"simple-blog-system/config/db"
+	"simple-blog-system/pkg/transaction"
+
+	"simple-blog-system/internal/app/user/model"
+	"simple-blog-system/internal/app/user/port"
+
+	"gorm.io/gorm/clause"
+)
+
+type passwordResetRepository struct {
+	db *db.GormDB
+}
+
+func NewPasswordResetRepository(db *db.GormDB) port.IPasswordResetRepository {
+	return passwordResetRepository{db: db}
+}
+
+func (r passwordResetRepository) InsertPasswordReset(ctx context.Context, reset model.PasswordResetModel) (model.PasswordResetModel, error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err := trx.Create(&reset).Error
+
+	return reset, err
+}
+
+func (r passwordResetRepository) UsePasswordReset(ctx context.Context, tokenHash string) (reset []model.PasswordResetModel, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	// claiming the token in the update keeps two requests with the same token from both going through
+	err = trx.Model(&reset).
+		Clauses(clause.Returning{}).
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, time.Now()).
+		Update("used_at", time.Now()).Error
+	return reset, err
+}
+
+func (r passwordResetRepository) InvalidatePasswordResets(ctx context.Context, username string) error {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err := trx.Model(&model.PasswordResetModel{}).Where("username = ? AND used_at IS NULL", username).Update("used_at", time.Now()).Error
+	return err
+}
+
+func (r passwordResetRepository) DeleteExpiredPasswordResets(ctx context.Context, before time.Time) (int64, error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	res := trx.Where("expires_at < ?", before).Delete(&model.PasswordResetModel{})
+	return res.RowsAffected, res.Error
+}
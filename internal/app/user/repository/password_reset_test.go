@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"simple-blog-system/config/db"
+	"simple-blog-system/internal/app/user/model"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type PasswordResetRepositoryTestSuite struct {
+	suite.Suite
+	db         *gorm.DB
+	mock       sqlmock.Sqlmock
+	repository passwordResetRepository
+}
+
+func (suite *PasswordResetRepositoryTestSuite) SetupTest() {
+	var (
+		sqlDB *sql.DB
+		err   error
+	)
+
+	sqlDB, suite.mock, err = sqlmock.New()
+	assert.NoError(suite.T(), err)
+
+	suite.db, err = gorm.Open(postgres.New(postgres.Config{
+		Conn: sqlDB,
+	}), &gorm.Config{})
+	assert.NoError(suite.T(), err)
+
+	suite.repository = passwordResetRepository{db: &db.GormDB{DB: suite.db}}
+}
+
+func (suite *PasswordResetRepositoryTestSuite) TearDownTest() {
+	sqlDB, err := suite.db.DB()
+	assert.NoError(suite.T(), err)
+	sqlDB.Close()
+}
+
+func TestPasswordResetRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(PasswordResetRepositoryTestSuite))
+}
+
+func (suite *PasswordResetRepositoryTestSuite) TestInsertPasswordReset_Success() {
+	ctx := context.Background()
+	reset := model.PasswordResetModel{
+		Username:  "testuser",
+		TokenHash: "hash",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "password_resets" ("username","token_hash","expires_at","used_at","created_at") VALUES ($1,$2,$3,$4,$5) RETURNING "id"`)).
+		WithArgs("testuser", "hash", reset.ExpiresAt, nil, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("123e4567-e89b-12d3-a456-426614174000"))
+	suite.mock.ExpectCommit()
+
+	res, err := suite.repository.InsertPasswordReset(ctx, reset)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "123e4567-e89b-12d3-a456-426614174000", res.ID.String())
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PasswordResetRepositoryTestSuite) TestUsePasswordReset_Success() {
+	ctx := context.Background()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`UPDATE "password_resets" SET "used_at"=$1 WHERE token_hash = $2 AND used_at IS NULL AND expires_at > $3 RETURNING *`)).
+		WithArgs(sqlmock.AnyArg(), "hash", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "token_hash"}).AddRow("reset-1", "testuser", "hash"))
+	suite.mock.ExpectCommit()
+
+	res, err := suite.repository.UsePasswordReset(ctx, "hash")
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), res, 1)
+	assert.Equal(suite.T(), "testuser", res[0].Username)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PasswordResetRepositoryTestSuite) TestUsePasswordReset_UsedToken() {
+	ctx := context.Background()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`UPDATE "password_resets" SET "used_at"=$1 WHERE token_hash = $2`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "token_hash"}))
+	suite.mock.ExpectCommit()
+
+	res, err := suite.repository.UsePasswordReset(ctx, "hash")
+
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), res)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PasswordResetRepositoryTestSuite) TestInvalidatePasswordResets_Success() {
+	ctx := context.Background()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "password_resets" SET "used_at"=$1 WHERE username = $2 AND used_at IS NULL`)).
+		WithArgs(sqlmock.AnyArg(), "testuser").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.InvalidatePasswordResets(ctx, "testuser")
+
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"simple-blog-system/config/db"
+	"simple-blog-system/pkg/transaction"
+
+	"simple-blog-system/internal/app/user/model"
+	"simple-blog-system/internal/app/user/port"
+
+	"gorm.io/gorm/clause"
+)
+
+type revocationStore struct {
+	db *db.GormDB
+}
+
+// NewRevocationStore keeps revoked tokens in postgres so every replica sees them
+func NewRevocationStore(db *db.GormDB) port.IRevocationStore {
+	return revocationStore{db: db}
+}
+
+func (r revocationStore) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err := trx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.RevokedTokenModel{
+		Jti:       jti,
+		ExpiresAt: expiresAt,
+	}).Error
+	return err
+}
+
+func (r revocationStore) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	var count int64
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err := trx.Model(&model.RevokedTokenModel{}).Where("jti = ?", jti).Count(&count).Error
+	return count > 0, err
+}
+
+func (r revocationStore) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	res := trx.Where("expires_at < ?", before).Delete(&model.RevokedTokenModel{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"simple-blog-system/internal/app/user/port"
+)
+
+// minSweep is the number of cached entries below which expired ones are left alone
+const minSweep = 1024
+
+type cachedRevocationStore struct {
+	store port.IRevocationStore
+	ttl   time.Duration
+
+	mu      sync.Mutex
+	entries map[string]revocationEntry
+	sweepAt int
+}
+
+type revocationEntry struct {
+	revoked bool
+	until   time.Time
+}
+
+// NewCachedRevocationStore answers lookups from memory in front of store. Answers of the store are kept for ttl,
+// so a token revoked on another replica is rejected here at the latest ttl later, revocations made through
+// this store are seen at once. A ttl of 0 turns the cache off.
+func NewCachedRevocationStore(store port.IRevocationStore, ttl time.Duration) port.IRevocationStore {
+	if ttl <= 0 {
+		return store
+	}
+
+	return &cachedRevocationStore{
+		store:   store,
+		ttl:     ttl,
+		entries: make(map[string]revocationEntry),
+		sweepAt: minSweep,
+	}
+}
+
+func (r *cachedRevocationStore) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	err := r.store.Revoke(ctx, jti, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	r.set(jti, revocationEntry{revoked: true, until: expiresAt})
+	return nil
+}
+
+func (r *cachedRevocationStore) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	r.mu.Lock()
+	entry, ok := r.entries[jti]
+	r.mu.Unlock()
+	if ok && time.Now().Before(entry.until) {
+		return entry.revoked, nil
+	}
+
+	revoked, err := r.store.IsRevoked(ctx, jti)
+	if err != nil {
+		return false, err
+	}
+
+	r.set(jti, revocationEntry{revoked: revoked, until: time.Now().Add(r.ttl)})
+	return revoked, nil
+}
+
+func (r *cachedRevocationStore) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	r.sweep(before)
+	r.mu.Unlock()
+
+	return r.store.DeleteExpired(ctx, before)
+}
+
+func (r *cachedRevocationStore) set(jti string, entry revocationEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries[jti] = entry
+	if len(r.entries) >= r.sweepAt {
+		r.sweep(time.Now())
+	}
+}
+
+// sweep drops the entries that ran out before now and lets the map grow to twice what is left, must hold mu
+func (r *cachedRevocationStore) sweep(now time.Time) {
+	for jti, entry := range r.entries {
+		if !now.Before(entry.until) {
+			delete(r.entries, jti)
+		}
+	}
+
+	r.sweepAt = max(2*len(r.entries), minSweep)
+}
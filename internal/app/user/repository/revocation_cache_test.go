@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// countingStore is an in-memory revocation store that counts the lookups reaching it
+type countingStore struct {
+	revoked map[string]time.Time
+	lookups int
+}
+
+func (s *countingStore) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	s.revoked[jti] = expiresAt
+	return nil
+}
+
+func (s *countingStore) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	s.lookups++
+	_, ok := s.revoked[jti]
+	return ok, nil
+}
+
+func (s *countingStore) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+type RevocationCacheTestSuite struct {
+	suite.Suite
+	store *countingStore
+	ctx   context.Context
+}
+
+func (suite *RevocationCacheTestSuite) SetupTest() {
+	suite.store = &countingStore{revoked: map[string]time.Time{}}
+	suite.ctx = context.Background()
+}
+
+func TestRevocationCacheTestSuite(t *testing.T) {
+	suite.Run(t, new(RevocationCacheTestSuite))
+}
+
+func (suite *RevocationCacheTestSuite) TestIsRevoked_AnswersFromCache() {
+	cache := NewCachedRevocationStore(suite.store, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		revoked, err := cache.IsRevoked(suite.ctx, "jti-1")
+		assert.NoError(suite.T(), err)
+		assert.False(suite.T(), revoked)
+	}
+
+	assert.Equal(suite.T(), 1, suite.store.lookups)
+}
+
+func (suite *RevocationCacheTestSuite) TestRevoke_SeenAtOnce() {
+	cache := NewCachedRevocationStore(suite.store, time.Minute)
+
+	revoked, _ := cache.IsRevoked(suite.ctx, "jti-1")
+	assert.False(suite.T(), revoked)
+
+	err := cache.Revoke(suite.ctx, "jti-1", time.Now().Add(time.Hour))
+	assert.NoError(suite.T(), err)
+
+	revoked, _ = cache.IsRevoked(suite.ctx, "jti-1")
+	assert.True(suite.T(), revoked)
+	assert.Equal(suite.T(), 1, suite.store.lookups)
+}
+
+func (suite *RevocationCacheTestSuite) TestIsRevoked_AsksStoreAfterTTL() {
+	cache := NewCachedRevocationStore(suite.store, time.Millisecond)
+
+	revoked, _ := cache.IsRevoked(suite.ctx, "jti-1")
+	assert.False(suite.T(), revoked)
+
+	// revoked on another replica
+	suite.store.revoked["jti-1"] = time.Now().Add(time.Hour)
+	time.Sleep(2 * time.Millisecond)
+
+	revoked, _ = cache.IsRevoked(suite.ctx, "jti-1")
+	assert.True(suite.T(), revoked)
+	assert.Equal(suite.T(), 2, suite.store.lookups)
+}
+
+func (suite *RevocationCacheTestSuite) TestNoTTL_ReturnsStore() {
+	cache := NewCachedRevocationStore(suite.store, 0)
+
+	assert.Same(suite.T(), suite.store, cache)
+}
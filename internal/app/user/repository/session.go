@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"simple-blog-system/config/db"
+	"simple-blog-system/pkg/transaction"
+
+	"simple-blog-system/internal/app/user/model"
+	"simple-blog-system/internal/app/user/port"
+)
+
+type sessionRepository struct {
+	db *db.GormDB
+}
+
+func NewSessionRepository(db *db.GormDB) port.ISessionRepository {
+	return sessionRepository{db: db}
+}
+
+func (r sessionRepository) InsertSession(ctx context.Context, session model.UserSessionModel) (model.UserSessionModel, error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err := trx.Create(&session).Error
+
+	return session, err
+}
+
+func (r sessionRepository) GetSessionById(ctx context.Context, id string) (session []model.UserSessionModel, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Where("id = ?", id).Find(&session).Error
+	return session, err
+}
+
+func (r sessionRepository) GetSessionsByUsername(ctx context.Context, username string) (sessions []model.UserSessionModel, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Where("username = ? AND revoked_at IS NULL AND expires_at > ?", username, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+func (r sessionRepository) RotateSession(ctx context.Context, session model.UserSessionModel, previousHash string) (rotated bool, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	// matching the previous hash makes two refreshes with the same token race for a single winner
+	res := trx.Model(&model.UserSessionModel{}).
+		Where("id = ? AND refresh_token_hash = ? AND revoked_at IS NULL", session.ID, previousHash).
+		Updates(map[string]interface{}{
+			"refresh_token_hash": session.RefreshTokenHash,
+			"access_jti":         session.AccessJti,
+			"access_expires_at":  session.AccessExpiresAt,
+			"ip_address":         session.IpAddress,
+			"user_agent":         session.UserAgent,
+			"last_seen_at":       session.LastSeenAt,
+			"updated_at":         time.Now(),
+		})
+
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r sessionRepository) RevokeSession(ctx context.Context, id string) error {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err := trx.Model(&model.UserSessionModel{}).Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", time.Now()).Error
+	return err
+}
+
+func (r sessionRepository) RevokeUserSessions(ctx context.Context, username string, except string) error {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err := trx.Model(&model.UserSessionModel{}).Where("username = ? AND revoked_at IS NULL AND id <> ?", username, except).Update("revoked_at", time.Now()).Error
+	return err
+}
+
+func (r sessionRepository) DeleteExpiredSessions(ctx context.Context, before time.Time) (int64, error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	res := trx.Where("expires_at < ?", before).Delete(&model.UserSessionModel{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"simple-blog-system/config/db"
+	"simple-blog-system/internal/app/user/model"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-openapi/strfmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type SessionRepositoryTestSuite struct {
+	suite.Suite
+	db          *gorm.DB
+	mock        sqlmock.Sqlmock
+	repository  sessionRepository
+	revocations revocationStore
+}
+
+func (suite *SessionRepositoryTestSuite) SetupTest() {
+	var (
+		sqlDB *sql.DB
+		err   error
+	)
+
+	sqlDB, suite.mock, err = sqlmock.New()
+	assert.NoError(suite.T(), err)
+
+	suite.db, err = gorm.Open(postgres.New(postgres.Config{
+		Conn: sqlDB,
+	}), &gorm.Config{})
+	assert.NoError(suite.T(), err)
+
+	gormDB := &db.GormDB{DB: suite.db}
+	suite.repository = sessionRepository{db: gormDB}
+	suite.revocations = revocationStore{db: gormDB}
+}
+
+func (suite *SessionRepositoryTestSuite) TearDownTest() {
+	sqlDB, err := suite.db.DB()
+	assert.NoError(suite.T(), err)
+	sqlDB.Close()
+}
+
+func TestSessionRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(SessionRepositoryTestSuite))
+}
+
+func (suite *SessionRepositoryTestSuite) TestInsertSession_Success() {
+	ctx := context.Background()
+	session := model.UserSessionModel{
+		ID:               strfmt.UUID4("123e4567-e89b-12d3-a456-426614174000"),
+		Username:         "testuser",
+		RefreshTokenHash: "hash",
+		AccessJti:        "jti-1",
+		AccessExpiresAt:  time.Now().Add(time.Hour),
+		IpAddress:        "10.0.0.1",
+		UserAgent:        "curl/8.0",
+		LastSeenAt:       time.Now(),
+		ExpiresAt:        time.Now().Add(24 * time.Hour),
+	}
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "user_sessions" ("username","refresh_token_hash","access_jti","access_expires_at","last_seen_at","expires_at","revoked_at","created_at","updated_at","id","ip_address","user_agent") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12) RETURNING "id","ip_address","user_agent"`)).
+		WithArgs("testuser", "hash", "jti-1", session.AccessExpiresAt, session.LastSeenAt, session.ExpiresAt, nil, sqlmock.AnyArg(), sqlmock.AnyArg(), session.ID, "10.0.0.1", "curl/8.0").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "ip_address", "user_agent"}).AddRow(session.ID, "10.0.0.1", "curl/8.0"))
+	suite.mock.ExpectCommit()
+
+	res, err := suite.repository.InsertSession(ctx, session)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), session.ID, res.ID)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *SessionRepositoryTestSuite) TestGetSessionById_Success() {
+	ctx := context.Background()
+	id := "123e4567-e89b-12d3-a456-426614174000"
+
+	rows := sqlmock.NewRows([]string{"id", "username", "refresh_token_hash", "access_jti"}).
+		AddRow(id, "testuser", "hash", "jti-1")
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "user_sessions" WHERE id = $1`)).
+		WithArgs(id).
+		WillReturnRows(rows)
+
+	res, err := suite.repository.GetSessionById(ctx, id)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), res, 1)
+	assert.Equal(suite.T(), "hash", res[0].RefreshTokenHash)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *SessionRepositoryTestSuite) TestGetSessionsByUsername_Success() {
+	ctx := context.Background()
+
+	rows := sqlmock.NewRows([]string{"id", "username", "ip_address", "user_agent"}).
+		AddRow("session-1", "testuser", "10.0.0.1", "curl/8.0")
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "user_sessions" WHERE username = $1 AND revoked_at IS NULL AND expires_at > $2 ORDER BY last_seen_at DESC`)).
+		WithArgs("testuser", sqlmock.AnyArg()).
+		WillReturnRows(rows)
+
+	res, err := suite.repository.GetSessionsByUsername(ctx, "testuser")
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), res, 1)
+	assert.Equal(suite.T(), "10.0.0.1", res[0].IpAddress)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *SessionRepositoryTestSuite) TestRotateSession_Success() {
+	ctx := context.Background()
+	session := model.UserSessionModel{
+		ID:               strfmt.UUID4("123e4567-e89b-12d3-a456-426614174000"),
+		RefreshTokenHash: "new-hash",
+		AccessJti:        "jti-2",
+		AccessExpiresAt:  time.Now().Add(time.Hour),
+		IpAddress:        "10.0.0.1",
+		UserAgent:        "curl/8.0",
+		LastSeenAt:       time.Now(),
+	}
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "user_sessions" SET "access_expires_at"=$1,"access_jti"=$2,"ip_address"=$3,"last_seen_at"=$4,"refresh_token_hash"=$5,"updated_at"=$6,"user_agent"=$7 WHERE id = $8 AND refresh_token_hash = $9 AND revoked_at IS NULL`)).
+		WithArgs(session.AccessExpiresAt, "jti-2", "10.0.0.1", session.LastSeenAt, "new-hash", sqlmock.AnyArg(), "curl/8.0", session.ID, "old-hash").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	rotated, err := suite.repository.RotateSession(ctx, session, "old-hash")
+
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), rotated)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *SessionRepositoryTestSuite) TestRotateSession_AlreadyRotated() {
+	ctx := context.Background()
+	session := model.UserSessionModel{
+		ID:               strfmt.UUID4("123e4567-e89b-12d3-a456-426614174000"),
+		RefreshTokenHash: "new-hash",
+	}
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "user_sessions" SET`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectCommit()
+
+	rotated, err := suite.repository.RotateSession(ctx, session, "old-hash")
+
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), rotated)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *SessionRepositoryTestSuite) TestRevokeSession_Success() {
+	ctx := context.Background()
+	id := "123e4567-e89b-12d3-a456-426614174000"
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "user_sessions" SET "revoked_at"=$1,"updated_at"=$2 WHERE id = $3 AND revoked_at IS NULL`)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.RevokeSession(ctx, id)
+
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *SessionRepositoryTestSuite) TestRevokeUserSessions_Success() {
+	ctx := context.Background()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "user_sessions" SET "revoked_at"=$1,"updated_at"=$2 WHERE username = $3 AND revoked_at IS NULL AND id <> $4`)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "testuser", "session-1").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.RevokeUserSessions(ctx, "testuser", "session-1")
+
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *SessionRepositoryTestSuite) TestDeleteExpiredSessions_Success() {
+	ctx := context.Background()
+	now := time.Now()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "user_sessions" WHERE expires_at < $1`)).
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	suite.mock.ExpectCommit()
+
+	deleted, err := suite.repository.DeleteExpiredSessions(ctx, now)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(4), deleted)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *SessionRepositoryTestSuite) TestRevoke_Success() {
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Hour)
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "revoked_tokens" ("jti","expires_at") VALUES ($1,$2) ON CONFLICT DO NOTHING`)).
+		WithArgs("jti-1", expiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.revocations.Revoke(ctx, "jti-1", expiresAt)
+
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *SessionRepositoryTestSuite) TestIsRevoked_Success() {
+	ctx := context.Background()
+
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "revoked_tokens" WHERE jti = $1`)).
+		WithArgs("jti-1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	revoked, err := suite.revocations.IsRevoked(ctx, "jti-1")
+
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), revoked)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"simple-blog-system/config/db"
 	"simple-blog-system/pkg/cache"
@@ -29,13 +30,26 @@ func (r repository) InsertUser(ctx context.Context, user model.AuthUserModel) (m
 
 func (r repository) GetUserByUsername(ctx context.Context, username string) (user []model.AuthUserModel, err error) {
 	trx := transaction.GetTrxContext(ctx, r.db)
-	err = trx.Select("id, username, created_at, updated_at").Where("username = ?", username).Find(&user).Error
+	err = trx.Select("id, username, email, email_verified_at, role, is_active, suspended_reason, suspended_until, delete_after, created_at, updated_at").Where("username = ?", username).Find(&user).Error
 	return user, err
 }
 
+func (r repository) GetUserByEmail(ctx context.Context, email string) (user []model.AuthUserModel, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Select("id, username, email, email_verified_at, role, is_active, suspended_reason, suspended_until, delete_after, created_at, updated_at").Where("LOWER(email) = LOWER(?)", email).Find(&user).Error
+	return user, err
+}
+
+func (r repository) IsUsernameTaken(ctx context.Context, username string) (taken bool, err error) {
+	var count int64
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Unscoped().Model(&model.AuthUserModel{}).Where("username = ?", username).Count(&count).Error
+	return count > 0, err
+}
+
 func (r repository) GetPasswordByUsername(ctx context.Context, username string) (user []model.AuthUserModel, err error) {
 	trx := transaction.GetTrxContext(ctx, r.db)
-	err = trx.Select("id, password, username, created_at, updated_at").Where("username = ?", username).Find(&user).Error
+	err = trx.Select("id, password, username, email, email_verified_at, role, is_active, suspended_reason, suspended_until, delete_after, created_at, updated_at").Where("username = ?", username).Find(&user).Error
 	return user, err
 }
 
@@ -44,3 +58,72 @@ func (r repository) UpdateLastLogin(ctx context.Context, user model.AuthUserMode
 	err := trx.Model(&model.AuthUserModel{}).Where("username = ?", user.Username).Update("last_login", user.LastLogin).Error
 	return err
 }
+
+func (r repository) UpdateRole(ctx context.Context, username string, role string) error {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err := trx.Model(&model.AuthUserModel{}).Where("username = ?", username).Update("role", role).Error
+	return err
+}
+
+func (r repository) UpdatePassword(ctx context.Context, username string, password string) error {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err := trx.Model(&model.AuthUserModel{}).Where("username = ?", username).Update("password", password).Error
+	return err
+}
+
+func (r repository) UpdateEmail(ctx context.Context, username string, email string) error {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err := trx.Model(&model.AuthUserModel{}).Where("username = ?", username).Updates(map[string]interface{}{
+		"email":             email,
+		"email_verified_at": nil,
+	}).Error
+	return err
+}
+
+func (r repository) VerifyEmail(ctx context.Context, username string, email string) (verified bool, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	res := trx.Model(&model.AuthUserModel{}).Where("username = ? AND email = ?", username, email).Update("email_verified_at", time.Now())
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r repository) SuspendUser(ctx context.Context, username string, reason string, until *time.Time) error {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err := trx.Model(&model.AuthUserModel{}).Where("username = ?", username).Updates(map[string]interface{}{
+		"is_active":        false,
+		"suspended_reason": reason,
+		"suspended_until":  until,
+	}).Error
+	return err
+}
+
+func (r repository) ReactivateUser(ctx context.Context, username string) error {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err := trx.Model(&model.AuthUserModel{}).Where("username = ?", username).Updates(map[string]interface{}{
+		"is_active":        true,
+		"suspended_reason": nil,
+		"suspended_until":  nil,
+	}).Error
+	return err
+}
+
+func (r repository) ScheduleDeletion(ctx context.Context, username string, deleteAfter *time.Time) error {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err := trx.Model(&model.AuthUserModel{}).Where("username = ?", username).Update("delete_after", deleteAfter).Error
+	return err
+}
+
+func (r repository) GetUsersDueForDeletion(ctx context.Context, now time.Time, limit int) (user []model.AuthUserModel, err error) {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err = trx.Select("id, username, email, role, delete_after").Where("delete_after <= ?", now).Order("delete_after").Limit(limit).Find(&user).Error
+	return user, err
+}
+
+func (r repository) DeleteUser(ctx context.Context, username string) error {
+	trx := transaction.GetTrxContext(ctx, r.db)
+	err := trx.Model(&model.AuthUserModel{}).Where("username = ?", username).Updates(map[string]interface{}{
+		"email":             nil,
+		"email_verified_at": nil,
+		"deleted_at":        time.Now(),
+	}).Error
+	return err
+}
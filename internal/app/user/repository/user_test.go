@@ -73,6 +73,7 @@ func (suite *UserRepositoryTestSuite) TestInsertUser_Success() {
 		WithArgs(
 			user.Username,
 			user.Password,
+			"author", // Role default
 			user.IsActive,
 			sqlmock.AnyArg(), // LastLogin
 			user.CreatedBy,
@@ -111,6 +112,7 @@ func (suite *UserRepositoryTestSuite) TestInsertUser_Error() {
 		WithArgs(
 			user.Username,
 			user.Password,
+			"author", // Role default
 			user.IsActive,
 			sqlmock.AnyArg(),
 			user.CreatedBy,
@@ -141,10 +143,10 @@ func (suite *UserRepositoryTestSuite) TestGetUserByUsername_Success() {
 		UpdatedAt: now,
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "username", "created_at", "updated_at"}).
-		AddRow(expectedUser.ID, expectedUser.Username, expectedUser.CreatedAt, expectedUser.UpdatedAt)
+	rows := sqlmock.NewRows([]string{"id", "username", "email", "created_at", "updated_at"}).
+		AddRow(expectedUser.ID, expectedUser.Username, nil, expectedUser.CreatedAt, expectedUser.UpdatedAt)
 
-	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, username, created_at, updated_at FROM "auth_user" WHERE username = $1`)).
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, username, email, email_verified_at, role, is_active, suspended_reason, suspended_until, delete_after, created_at, updated_at FROM "auth_user" WHERE username = $1`)).
 		WithArgs(username).
 		WillReturnRows(rows)
 
@@ -153,6 +155,25 @@ func (suite *UserRepositoryTestSuite) TestGetUserByUsername_Success() {
 	assert.NoError(suite.T(), err)
 	assert.Len(suite.T(), result, 1)
 	assert.Equal(suite.T(), username, result[0].Username)
+	assert.Empty(suite.T(), result[0].Email)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *UserRepositoryTestSuite) TestGetUserByEmail_Success() {
+	ctx := context.Background()
+
+	rows := sqlmock.NewRows([]string{"id", "username", "email"}).
+		AddRow("123e4567-e89b-12d3-a456-426614174000", "testuser", "test@example.com")
+
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, username, email, email_verified_at, role, is_active, suspended_reason, suspended_until, delete_after, created_at, updated_at FROM "auth_user" WHERE LOWER(email) = LOWER($1)`)).
+		WithArgs("Test@Example.com").
+		WillReturnRows(rows)
+
+	result, err := suite.repository.GetUserByEmail(ctx, "Test@Example.com")
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 1)
+	assert.Equal(suite.T(), "testuser", result[0].Username)
 	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
 }
 
@@ -162,7 +183,7 @@ func (suite *UserRepositoryTestSuite) TestGetUserByUsername_NotFound() {
 
 	rows := sqlmock.NewRows([]string{"id", "username", "created_at", "updated_at"})
 
-	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, username, created_at, updated_at FROM "auth_user" WHERE username = $1`)).
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, username, email, email_verified_at, role, is_active, suspended_reason, suspended_until, delete_after, created_at, updated_at FROM "auth_user" WHERE username = $1`)).
 		WithArgs(username).
 		WillReturnRows(rows)
 
@@ -177,7 +198,7 @@ func (suite *UserRepositoryTestSuite) TestGetUserByUsername_Error() {
 	ctx := context.Background()
 	username := "testuser"
 
-	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, username, created_at, updated_at FROM "auth_user" WHERE username = $1`)).
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, username, email, email_verified_at, role, is_active, suspended_reason, suspended_until, delete_after, created_at, updated_at FROM "auth_user" WHERE username = $1`)).
 		WithArgs(username).
 		WillReturnError(gorm.ErrInvalidDB)
 
@@ -206,7 +227,7 @@ func (suite *UserRepositoryTestSuite) TestGetPasswordByUsername_Success() {
 	rows := sqlmock.NewRows([]string{"id", "password", "username", "created_at", "updated_at"}).
 		AddRow(expectedUser.ID, expectedUser.Password, expectedUser.Username, expectedUser.CreatedAt, expectedUser.UpdatedAt)
 
-	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, password, username, created_at, updated_at FROM "auth_user" WHERE username = $1`)).
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, password, username, email, email_verified_at, role, is_active, suspended_reason, suspended_until, delete_after, created_at, updated_at FROM "auth_user" WHERE username = $1`)).
 		WithArgs(username).
 		WillReturnRows(rows)
 
@@ -225,7 +246,7 @@ func (suite *UserRepositoryTestSuite) TestGetPasswordByUsername_NotFound() {
 
 	rows := sqlmock.NewRows([]string{"id", "password", "username", "created_at", "updated_at"})
 
-	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, password, username, created_at, updated_at FROM "auth_user" WHERE username = $1`)).
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, password, username, email, email_verified_at, role, is_active, suspended_reason, suspended_until, delete_after, created_at, updated_at FROM "auth_user" WHERE username = $1`)).
 		WithArgs(username).
 		WillReturnRows(rows)
 
@@ -240,7 +261,7 @@ func (suite *UserRepositoryTestSuite) TestGetPasswordByUsername_Error() {
 	ctx := context.Background()
 	username := "testuser"
 
-	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, password, username, created_at, updated_at FROM "auth_user" WHERE username = $1`)).
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, password, username, email, email_verified_at, role, is_active, suspended_reason, suspended_until, delete_after, created_at, updated_at FROM "auth_user" WHERE username = $1`)).
 		WithArgs(username).
 		WillReturnError(gorm.ErrInvalidDB)
 
@@ -318,3 +339,197 @@ func (suite *UserRepositoryTestSuite) TestUpdateLastLogin_NoRowsAffected() {
 	assert.NoError(suite.T(), err)
 	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
 }
+
+func (suite *UserRepositoryTestSuite) TestUpdateRole_Success() {
+	ctx := context.Background()
+	username := "testuser"
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "auth_user" SET "role"=$1,"updated_at"=$2 WHERE username = $3`)).
+		WithArgs("editor", sqlmock.AnyArg(), username).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.UpdateRole(ctx, username, "editor")
+
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *UserRepositoryTestSuite) TestUpdateRole_Error() {
+	ctx := context.Background()
+	username := "testuser"
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "auth_user" SET "role"=$1,"updated_at"=$2 WHERE username = $3`)).
+		WithArgs("editor", sqlmock.AnyArg(), username).
+		WillReturnError(gorm.ErrInvalidDB)
+	suite.mock.ExpectRollback()
+
+	err := suite.repository.UpdateRole(ctx, username, "editor")
+
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), gorm.ErrInvalidDB, err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *UserRepositoryTestSuite) TestUpdatePassword_Success() {
+	ctx := context.Background()
+	username := "testuser"
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "auth_user" SET "password"=$1,"updated_at"=$2 WHERE username = $3`)).
+		WithArgs("hash", sqlmock.AnyArg(), username).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.UpdatePassword(ctx, username, "hash")
+
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *UserRepositoryTestSuite) TestUpdateEmail_Success() {
+	ctx := context.Background()
+	username := "testuser"
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "auth_user" SET "email"=$1,"email_verified_at"=$2,"updated_at"=$3 WHERE username = $4`)).
+		WithArgs("new@example.com", nil, sqlmock.AnyArg(), username).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.UpdateEmail(ctx, username, "new@example.com")
+
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *UserRepositoryTestSuite) TestVerifyEmail_Success() {
+	ctx := context.Background()
+	username := "testuser"
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "auth_user" SET "email_verified_at"=$1,"updated_at"=$2 WHERE (username = $3 AND email = $4) AND "auth_user"."deleted_at" IS NULL`)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), username, "test@example.com").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	verified, err := suite.repository.VerifyEmail(ctx, username, "test@example.com")
+
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), verified)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *UserRepositoryTestSuite) TestVerifyEmail_AddressChanged() {
+	ctx := context.Background()
+	username := "testuser"
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "auth_user" SET "email_verified_at"=$1,"updated_at"=$2 WHERE (username = $3 AND email = $4) AND "auth_user"."deleted_at" IS NULL`)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), username, "old@example.com").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectCommit()
+
+	verified, err := suite.repository.VerifyEmail(ctx, username, "old@example.com")
+
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), verified)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *UserRepositoryTestSuite) TestIsUsernameTaken_CountsDeletedUsers() {
+	ctx := context.Background()
+
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "auth_user" WHERE username = $1`)).
+		WithArgs("testuser").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	taken, err := suite.repository.IsUsernameTaken(ctx, "testuser")
+
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), taken)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *UserRepositoryTestSuite) TestSuspendUser_Success() {
+	ctx := context.Background()
+	until := time.Now().Add(time.Hour)
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "auth_user" SET "is_active"=$1,"suspended_reason"=$2,"suspended_until"=$3,"updated_at"=$4 WHERE username = $5`)).
+		WithArgs(false, "spam", &until, sqlmock.AnyArg(), "testuser").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.SuspendUser(ctx, "testuser", "spam", &until)
+
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *UserRepositoryTestSuite) TestReactivateUser_Success() {
+	ctx := context.Background()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "auth_user" SET "is_active"=$1,"suspended_reason"=$2,"suspended_until"=$3,"updated_at"=$4 WHERE username = $5`)).
+		WithArgs(true, nil, nil, sqlmock.AnyArg(), "testuser").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.ReactivateUser(ctx, "testuser")
+
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *UserRepositoryTestSuite) TestScheduleDeletion_Success() {
+	ctx := context.Background()
+	deleteAfter := time.Now().Add(24 * time.Hour)
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "auth_user" SET "delete_after"=$1,"updated_at"=$2 WHERE username = $3`)).
+		WithArgs(&deleteAfter, sqlmock.AnyArg(), "testuser").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.ScheduleDeletion(ctx, "testuser", &deleteAfter)
+
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *UserRepositoryTestSuite) TestGetUsersDueForDeletion_Success() {
+	ctx := context.Background()
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "username", "email", "role", "delete_after"}).
+		AddRow("123e4567-e89b-12d3-a456-426614174000", "testuser", "test@example.com", "author", now.Add(-time.Hour))
+
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, username, email, role, delete_after FROM "auth_user" WHERE delete_after <= $1 AND "auth_user"."deleted_at" IS NULL ORDER BY delete_after LIMIT $2`)).
+		WithArgs(now, 100).
+		WillReturnRows(rows)
+
+	users, err := suite.repository.GetUsersDueForDeletion(ctx, now, 100)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), users, 1)
+	assert.Equal(suite.T(), "testuser", users[0].Username)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *UserRepositoryTestSuite) TestDeleteUser_Success() {
+	ctx := context.Background()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "auth_user" SET "deleted_at"=$1,"email"=$2,"email_verified_at"=$3,"updated_at"=$4 WHERE username = $5 AND "auth_user"."deleted_at" IS NULL`)).
+		WithArgs(sqlmock.AnyArg(), nil, nil, sqlmock.AnyArg(), "testuser").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.DeleteUser(ctx, "testuser")
+
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
@@ -17,8 +17,42 @@ var (
 func (r routes) New(router *gin.RouterGroup, handler port.IUserHandler) {
 	router.POST("/register", handler.Register)
 	router.POST("/login", handler.Login)
+	router.POST("/login/mfa", handler.VerifyMfa)
+	router.GET("/oidc/login", handler.StartOidc)
+	router.GET("/oidc/callback", handler.CompleteOidc)
+	router.POST("/refresh", handler.Refresh)
+	router.POST("/logout", handler.Logout)
+	router.POST("/password/forgot", handler.ForgotPassword)
+	router.POST("/password/reset", handler.ResetPassword)
+	router.GET("/verify", handler.VerifyEmail)
+}
+
+func (r routes) NewWellKnown(router *gin.RouterGroup, handler port.IUserHandler) {
+	router.GET("/jwks.json", handler.Jwks)
 }
 
 func (r routes) NewProfile(router *gin.RouterGroup, handler port.IUserHandler) {
 	router.GET("/", handler.GetUser)
+	router.DELETE("/", handler.DeleteAccount)
+	router.PUT("/email", handler.ChangeEmail)
+	router.PUT("/password", handler.ChangePassword)
+	router.POST("/mfa", handler.EnrollMfa)
+	router.POST("/mfa/confirm", handler.ConfirmMfa)
+	router.POST("/mfa/recovery-codes", handler.RegenerateRecoveryCodes)
+	router.DELETE("/mfa", handler.DisableMfa)
+	router.POST("/tokens", handler.CreateApiToken)
+	router.GET("/tokens", handler.GetApiTokens)
+	router.DELETE("/tokens/:id", handler.RevokeApiToken)
+	router.GET("/sessions", handler.GetSessions)
+	router.DELETE("/sessions/:id", handler.RevokeSession)
+}
+
+func (r routes) NewAdmin(router *gin.RouterGroup, handler port.IUserHandler) {
+	router.PUT("/:username/role", handler.UpdateRole)
+	router.DELETE("/:username/sessions", handler.RevokeUserSessions)
+	router.POST("/:username/unlock", handler.UnlockUser)
+	router.POST("/:username/suspend", handler.SuspendUser)
+	router.POST("/:username/reactivate", handler.ReactivateUser)
+	router.DELETE("/:username/mfa", handler.ResetMfa)
+	router.DELETE("/:username/tokens", handler.RevokeUserApiTokens)
 }
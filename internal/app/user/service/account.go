@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"simple-blog-system/internal/app/user/model"
+	"simple-blog-system/internal/app/user/payload"
+
+	"simple-blog-system/pkg/apperror"
+	"simple-blog-system/pkg/authorization"
+	"simple-blog-system/pkg/encrypt"
+	"simple-blog-system/pkg/mailer"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// ContentAnonymize keeps the posts and comments of a deleted account under model.DeletedUsername
+	ContentAnonymize = "anonymize"
+	// ContentDelete soft deletes the posts and comments of a deleted account
+	ContentDelete = "delete"
+
+	deletionBatch = 100
+)
+
+// SuspendUser keeps a user from logging in and signs the user out everywhere, admin only
+func (s service) SuspendUser(ctx context.Context, actor string, username string, request payload.SuspendRequest) (res *payload.User, err error) {
+	err = s.admin(ctx, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	if actor == username {
+		return nil, apperror.BadRequest("you can not suspend yourself")
+	}
+	if request.Until != nil && !request.Until.After(time.Now()) {
+		return nil, apperror.BadRequest("until has to be in the future")
+	}
+
+	users, qerr := s.userRepo.GetUserByUsername(ctx, username)
+	if len(users) == 0 || qerr != nil {
+		return nil, errors.New("user not found")
+	}
+
+	qerr = s.userRepo.SuspendUser(ctx, username, request.Reason, request.Until)
+	if qerr != nil {
+		return nil, qerr
+	}
+	users[0].IsActive = false
+	users[0].SuspendedReason = request.Reason
+	users[0].SuspendedUntil = request.Until
+
+	_, err = s.revokeUserSessions(ctx, username, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &payload.User{
+		User: users[0],
+	}, nil
+}
+
+// ReactivateUser ends the suspension of a user, admin only
+func (s service) ReactivateUser(ctx context.Context, actor string, username string) (res *payload.User, err error) {
+	err = s.admin(ctx, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	users, qerr := s.userRepo.GetUserByUsername(ctx, username)
+	if len(users) == 0 || qerr != nil {
+		return nil, errors.New("user not found")
+	}
+
+	qerr = s.userRepo.ReactivateUser(ctx, username)
+	if qerr != nil {
+		return nil, qerr
+	}
+	users[0].IsActive = true
+	users[0].SuspendedReason = ""
+	users[0].SuspendedUntil = nil
+
+	return &payload.User{
+		User: users[0],
+	}, nil
+}
+
+// DeleteAccount deletes the account of a user once the grace period is over and signs the user out everywhere,
+// logging in again before then keeps the account
+func (s service) DeleteAccount(ctx context.Context, username string, request payload.DeleteAccountRequest) (res *payload.AccountDeletion, err error) {
+	users, qerr := s.userRepo.GetPasswordByUsername(ctx, username)
+	if len(users) == 0 || qerr != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if !encrypt.CheckPasswordHash(request.Password, users[0].Password) {
+		return nil, apperror.BadRequest("password is incorrect")
+	}
+
+	deleteAfter := time.Now().Add(s.options.DeletionGrace)
+	qerr = s.userRepo.ScheduleDeletion(ctx, username, &deleteAfter)
+	if qerr != nil {
+		return nil, qerr
+	}
+
+	_, err = s.revokeUserSessions(ctx, username, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if users[0].Email != "" {
+		err = s.mailer.Send(ctx, mailer.Message{
+			To:      users[0].Email,
+			Subject: "Your account will be deleted",
+			Body:    fmt.Sprintf("Hi %s,\n\nyour account will be deleted on %s. Log in before then if you want to keep it.\n", users[0].Username, deleteAfter.UTC().Format(time.RFC1123)),
+		})
+		if err != nil {
+			log.Error().Err(err).Str("username", username).Msg("failed to send account deletion mail")
+		}
+	}
+
+	return &payload.AccountDeletion{
+		DeleteAfter: deleteAfter,
+	}, nil
+}
+
+// DeleteDueAccounts deletes the accounts whose grace period is over, a failed account is tried again on the next run
+func (s service) DeleteDueAccounts(ctx context.Context) (deleted int, err error) {
+	users, err := s.userRepo.GetUsersDueForDeletion(ctx, time.Now(), deletionBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, user := range users {
+		err = s.deleteAccount(ctx, user)
+		if err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+// deleteAccount takes care of the content of user before the user itself, so every step can run again after a failure
+func (s service) deleteAccount(ctx context.Context, user model.AuthUserModel) error {
+	for _, content := range s.contents {
+		var err error
+		if s.options.DeletionContent == ContentDelete {
+			_, err = content.DeleteUserContent(ctx, user.Username)
+		} else {
+			_, err = content.AnonymizeUserContent(ctx, user.Username, model.DeletedUsername)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := s.revokeUserSessions(ctx, user.Username, "")
+	if err != nil {
+		return err
+	}
+
+	_, err = s.apiTokenRepo.RevokeUserApiTokens(ctx, user.Username)
+	if err != nil {
+		return err
+	}
+
+	err = s.resetRepo.InvalidatePasswordResets(ctx, user.Username)
+	if err != nil {
+		return err
+	}
+
+	err = s.verificationRepo.InvalidateEmailVerifications(ctx, user.Username)
+	if err != nil {
+		return err
+	}
+
+	err = s.attempts.ClearLoginAttempts(ctx, userKeyPrefix+user.Username)
+	if err != nil {
+		return err
+	}
+
+	err = s.mfaRepo.DeleteMfa(ctx, user.Username)
+	if err != nil {
+		return err
+	}
+
+	err = s.oidcRepo.DeleteUserIdentities(ctx, user.Username)
+	if err != nil {
+		return err
+	}
+
+	return s.userRepo.DeleteUser(ctx, user.Username)
+}
+
+// keepAccount lifts what ran out or is undone by logging in, a pending deletion and an expired suspension
+func (s service) keepAccount(ctx context.Context, user *model.AuthUserModel) error {
+	if user.DeleteAfter != nil {
+		err := s.userRepo.ScheduleDeletion(ctx, user.Username, nil)
+		if err != nil {
+			return err
+		}
+		user.DeleteAfter = nil
+	}
+
+	if user.SuspensionExpired(time.Now()) {
+		err := s.userRepo.ReactivateUser(ctx, user.Username)
+		if err != nil {
+			return err
+		}
+		user.IsActive = true
+		user.SuspendedReason = ""
+		user.SuspendedUntil = nil
+	}
+
+	return nil
+}
+
+// suspended is the error a suspended user gets, it tells the reason and until when
+func suspended(user model.AuthUserModel) error {
+	message := "account is suspended"
+	if user.SuspendedReason != "" {
+		message += ": " + user.SuspendedReason
+	}
+	if user.SuspendedUntil != nil {
+		message += ", until " + user.SuspendedUntil.UTC().Format(time.RFC3339)
+	}
+
+	return apperror.Forbidden(message)
+}
+
+// admin checks that actor exists and is an admin
+func (s service) admin(ctx context.Context, actor string) error {
+	actors, qerr := s.userRepo.GetUserByUsername(ctx, actor)
+	if len(actors) == 0 || qerr != nil {
+		return errors.New("user not found")
+	}
+
+	if actors[0].Role != authorization.RoleAdmin {
+		return authorization.ErrForbidden
+	}
+
+	return nil
+}
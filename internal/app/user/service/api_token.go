@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"simple-blog-system/internal/app/user/model"
+	"simple-blog-system/internal/app/user/payload"
+
+	"simple-blog-system/pkg/apperror"
+	"simple-blog-system/pkg/authorization"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// apiTokenHint is how many of the last characters of a token are kept to tell tokens apart
+	apiTokenHint = 4
+	// maxIpAddress is the size of the last_used_ip column
+	maxIpAddress = 64
+)
+
+// CreateApiToken hands out a token that acts as the user within its scopes until it expires or is revoked
+func (s service) CreateApiToken(ctx context.Context, username string, request payload.ApiTokenRequest) (res *payload.ApiToken, err error) {
+	scopes := []string{}
+	for _, scope := range request.Scopes {
+		if !authorization.IsValidScope(scope) {
+			return nil, apperror.BadRequest("unknown scope " + scope)
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.options.ApiTokenTTL)
+	if request.ExpiresAt != nil {
+		expiresAt = *request.ExpiresAt
+	}
+	if !expiresAt.After(now) {
+		return nil, apperror.BadRequest("expires_at has to be in the future")
+	}
+	if expiresAt.After(now.Add(s.options.ApiTokenMaxTTL)) {
+		return nil, apperror.BadRequest("expires_at can be at most " + s.options.ApiTokenMaxTTL.String() + " away")
+	}
+
+	secret, err := randomSecret()
+	if err != nil {
+		return nil, err
+	}
+	token := model.ApiTokenPrefix + secret
+
+	apiToken, qerr := s.apiTokenRepo.InsertApiToken(ctx, model.ApiTokenModel{
+		Username:  username,
+		Name:      request.Name,
+		Scopes:    scopes,
+		TokenHash: hashToken(token),
+		TokenHint: token[len(token)-apiTokenHint:],
+		ExpiresAt: expiresAt,
+	})
+	if qerr != nil {
+		return nil, qerr
+	}
+
+	return &payload.ApiToken{
+		Token:    token,
+		ApiToken: apiToken,
+	}, nil
+}
+
+// GetApiTokens lists the live api tokens of a user
+func (s service) GetApiTokens(ctx context.Context, username string) (res []model.ApiTokenModel, err error) {
+	return s.apiTokenRepo.GetApiTokensByUsername(ctx, username)
+}
+
+// RevokeApiToken revokes one api token of a user, it stops working at once
+func (s service) RevokeApiToken(ctx context.Context, username string, id string) error {
+	revoked, qerr := s.apiTokenRepo.RevokeApiToken(ctx, username, id)
+	if qerr != nil {
+		return qerr
+	}
+	if !revoked {
+		return errors.New("api token not found")
+	}
+
+	return nil
+}
+
+// RevokeUserApiTokens revokes every api token of a user, admin only
+func (s service) RevokeUserApiTokens(ctx context.Context, actor string, username string) (res *payload.RevokedApiTokens, err error) {
+	err = s.admin(ctx, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	users, qerr := s.userRepo.GetUserByUsername(ctx, username)
+	if len(users) == 0 || qerr != nil {
+		return nil, errors.New("user not found")
+	}
+
+	revoked, qerr := s.apiTokenRepo.RevokeUserApiTokens(ctx, username)
+	if qerr != nil {
+		return nil, qerr
+	}
+
+	return &payload.RevokedApiTokens{
+		Revoked: revoked,
+	}, nil
+}
+
+// AuthenticateApiToken looks up the user of a token with the role the user has now, so a changed role applies to existing tokens
+func (s service) AuthenticateApiToken(ctx context.Context, token string, ipAddress string) (identity *payload.ApiTokenIdentity, err error) {
+	tokens, qerr := s.apiTokenRepo.GetApiTokenByHash(ctx, hashToken(token))
+	if qerr != nil {
+		return nil, qerr
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	users, qerr := s.userRepo.GetUserByUsername(ctx, tokens[0].Username)
+	if qerr != nil {
+		return nil, qerr
+	}
+	if len(users) == 0 {
+		return nil, nil
+	}
+
+	// the last use is informational, a failed write does not fail the request
+	qerr = s.apiTokenRepo.TouchApiToken(ctx, tokens[0].ID.String(), ipAddress[:min(len(ipAddress), maxIpAddress)], time.Now())
+	if qerr != nil {
+		log.Error().Err(qerr).Str("token_id", tokens[0].ID.String()).Msg("failed to record api token use")
+	}
+
+	return &payload.ApiTokenIdentity{
+		TokenId:  tokens[0].ID.String(),
+		UserId:   users[0].ID.String(),
+		Username: users[0].Username,
+		Role:     users[0].Role,
+		Scopes:   tokens[0].Scopes,
+	}, nil
+}
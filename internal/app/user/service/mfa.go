@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"simple-blog-system/internal/app/user/model"
+	"simple-blog-system/internal/app/user/payload"
+
+	"simple-blog-system/pkg/apperror"
+	"simple-blog-system/pkg/encrypt"
+	"simple-blog-system/pkg/mailer"
+	"simple-blog-system/pkg/totp"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	recoveryCodeCount = 10
+	// mfaSkew accepts the codes of the step before and after the current one, phones drift
+	mfaSkew = 1
+)
+
+var (
+	errInvalidMfaToken = apperror.Unauthorized("invalid or expired mfa token")
+	errInvalidMfaCode  = apperror.BadRequest("invalid two-factor code")
+	errMfaOff          = apperror.BadRequest("two-factor authentication is not enabled")
+)
+
+// VerifyMfa swaps the mfa token of a login and a code for a token pair, wrong codes count as failed logins
+func (s service) VerifyMfa(ctx context.Context, request payload.MfaLoginRequest, client payload.Client) (token *payload.Token, err error) {
+	tokenHash := hashToken(request.MfaToken)
+	challenges, qerr := s.mfaRepo.GetMfaChallenge(ctx, tokenHash)
+	if qerr != nil {
+		return nil, qerr
+	}
+	if len(challenges) == 0 {
+		return nil, errInvalidMfaToken
+	}
+
+	username := challenges[0].Username
+	keys := s.options.Throttle.loginKeys(username, client)
+	err = s.checkLogin(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	users, qerr := s.userRepo.GetUserByUsername(ctx, username)
+	if qerr != nil {
+		return nil, qerr
+	}
+	if len(users) == 0 {
+		return nil, errInvalidMfaToken
+	}
+
+	mfa, err := s.enabledMfa(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.useCode(ctx, mfa, request.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, s.loginFailed(ctx, keys, errInvalidMfaCode)
+	}
+
+	used, qerr := s.mfaRepo.UseMfaChallenge(ctx, tokenHash)
+	if qerr != nil {
+		return nil, qerr
+	}
+	if !used {
+		return nil, errInvalidMfaToken
+	}
+
+	// the user may have been suspended while the login waited for the code
+	if users[0].Suspended(time.Now()) {
+		return nil, suspended(users[0])
+	}
+
+	return s.completeLogin(ctx, users[0], keys[0], client)
+}
+
+// EnrollMfa hands out a new secret, it is not asked for on login before ConfirmMfa
+func (s service) EnrollMfa(ctx context.Context, username string) (res *payload.MfaEnrollment, err error) {
+	mfa, qerr := s.mfaRepo.GetMfa(ctx, username)
+	if qerr != nil {
+		return nil, qerr
+	}
+	if len(mfa) > 0 && mfa[0].Enabled() {
+		return nil, apperror.Conflict("two-factor authentication is already enabled")
+	}
+
+	secret, err := totp.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	qerr = s.mfaRepo.SaveMfa(ctx, model.UserMfaModel{
+		Username: username,
+		Secret:   secret,
+	})
+	if qerr != nil {
+		return nil, qerr
+	}
+
+	return &payload.MfaEnrollment{
+		Secret:     secret,
+		OtpauthURI: totp.URI(s.options.MfaIssuer, username, secret),
+	}, nil
+}
+
+// ConfirmMfa turns two-factor authentication on with the first code of the authenticator app
+func (s service) ConfirmMfa(ctx context.Context, username string, request payload.MfaCodeRequest) (res *payload.RecoveryCodes, err error) {
+	mfa, qerr := s.mfaRepo.GetMfa(ctx, username)
+	if qerr != nil {
+		return nil, qerr
+	}
+	if len(mfa) == 0 || mfa[0].Enabled() {
+		return nil, apperror.BadRequest("no two-factor enrollment to confirm")
+	}
+
+	step, ok := totp.Validate(mfa[0].Secret, normalizeCode(request.Code), time.Now(), mfaSkew)
+	if !ok {
+		return nil, errInvalidMfaCode
+	}
+
+	confirmed, qerr := s.mfaRepo.ConfirmMfa(ctx, username, step)
+	if qerr != nil {
+		return nil, qerr
+	}
+	if !confirmed {
+		return nil, apperror.BadRequest("no two-factor enrollment to confirm")
+	}
+
+	return s.replaceRecoveryCodes(ctx, username)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of a user, the old ones stop working
+func (s service) RegenerateRecoveryCodes(ctx context.Context, username string, request payload.MfaCodeRequest) (res *payload.RecoveryCodes, err error) {
+	mfa, err := s.enabledMfa(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.useCode(ctx, mfa, request.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errInvalidMfaCode
+	}
+
+	return s.replaceRecoveryCodes(ctx, username)
+}
+
+// DisableMfa turns two-factor authentication off, it takes the password and a code so a stolen session alone can not
+func (s service) DisableMfa(ctx context.Context, username string, request payload.DisableMfaRequest) error {
+	users, qerr := s.userRepo.GetPasswordByUsername(ctx, username)
+	if len(users) == 0 || qerr != nil {
+		return errors.New("user not found")
+	}
+
+	if !encrypt.CheckPasswordHash(request.Password, users[0].Password) {
+		return apperror.BadRequest("password is incorrect")
+	}
+
+	mfa, err := s.enabledMfa(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	ok, err := s.useCode(ctx, mfa, request.Code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errInvalidMfaCode
+	}
+
+	qerr = s.mfaRepo.DeleteMfa(ctx, username)
+	if qerr != nil {
+		return qerr
+	}
+
+	s.mfaOffMessage(ctx, users[0])
+
+	return nil
+}
+
+// ResetMfa turns two-factor authentication off for a user who lost both the authenticator app and the recovery codes, admin only
+func (s service) ResetMfa(ctx context.Context, actor string, username string) error {
+	err := s.admin(ctx, actor)
+	if err != nil {
+		return err
+	}
+
+	users, qerr := s.userRepo.GetUserByUsername(ctx, username)
+	if len(users) == 0 || qerr != nil {
+		return errors.New("user not found")
+	}
+
+	qerr = s.mfaRepo.DeleteMfa(ctx, username)
+	if qerr != nil {
+		return qerr
+	}
+
+	s.mfaOffMessage(ctx, users[0])
+
+	return nil
+}
+
+// challenge starts the second step of a login, the mfa token is returned instead of a token pair
+func (s service) challenge(ctx context.Context, username string) (*payload.Token, error) {
+	token, err := randomSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	_, qerr := s.mfaRepo.InsertMfaChallenge(ctx, model.MfaChallengeModel{
+		Username:  username,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(s.options.MfaChallengeTTL),
+	})
+	if qerr != nil {
+		return nil, qerr
+	}
+
+	return &payload.Token{
+		ExpiresIn:   int64(s.options.MfaChallengeTTL.Seconds()),
+		MfaRequired: true,
+		MfaToken:    token,
+	}, nil
+}
+
+// enabledMfa returns the secret of a user who turned two-factor authentication on
+func (s service) enabledMfa(ctx context.Context, username string) (model.UserMfaModel, error) {
+	mfa, qerr := s.mfaRepo.GetMfa(ctx, username)
+	if qerr != nil {
+		return model.UserMfaModel{}, qerr
+	}
+	if len(mfa) == 0 || !mfa[0].Enabled() {
+		return model.UserMfaModel{}, errMfaOff
+	}
+
+	return mfa[0], nil
+}
+
+// useCode accepts a code of the authenticator app or an unused recovery code, either of them works once
+func (s service) useCode(ctx context.Context, mfa model.UserMfaModel, code string) (bool, error) {
+	code = normalizeCode(code)
+
+	step, ok := totp.Validate(mfa.Secret, code, time.Now(), mfaSkew)
+	if ok {
+		return s.mfaRepo.UseMfaStep(ctx, mfa.Username, step)
+	}
+
+	return s.mfaRepo.UseRecoveryCode(ctx, mfa.Username, hashToken(code))
+}
+
+func (s service) replaceRecoveryCodes(ctx context.Context, username string) (*payload.RecoveryCodes, error) {
+	codes := make([]string, recoveryCodeCount)
+	models := make([]model.RecoveryCodeModel, recoveryCodeCount)
+	for i := range codes {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		models[i] = model.RecoveryCodeModel{
+			Username: username,
+			CodeHash: hashToken(normalizeCode(code)),
+		}
+	}
+
+	qerr := s.mfaRepo.ReplaceRecoveryCodes(ctx, username, models)
+	if qerr != nil {
+		return nil, qerr
+	}
+
+	return &payload.RecoveryCodes{
+		Codes: codes,
+	}, nil
+}
+
+// mfaOffMessage tells the user that the second factor is gone, in case it was not them
+func (s service) mfaOffMessage(ctx context.Context, user model.AuthUserModel) {
+	if user.Email == "" {
+		return
+	}
+
+	err := s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Two-factor authentication was turned off",
+		Body:    fmt.Sprintf("Hi %s,\n\ntwo-factor authentication was turned off for your account, logging in only takes the password now.\n\nIf it was not you, change your password and turn it on again.\n", user.Username),
+	})
+	if err != nil {
+		log.Error().Err(err).Str("username", user.Username).Msg("failed to send mfa off mail")
+	}
+}
+
+// newRecoveryCode is 10 random base32 characters split in two halves, like 4k2jd-x7qpa
+func newRecoveryCode() (string, error) {
+	random := make([]byte, 7)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(random))[:10]
+
+	return code[:5] + "-" + code[5:], nil
+}
+
+// normalizeCode drops what people type around a code, spaces and the dash of recovery codes
+func normalizeCode(code string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(code))
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"simple-blog-system/internal/app/user/model"
+	"simple-blog-system/internal/app/user/payload"
+
+	"simple-blog-system/pkg/apperror"
+	"simple-blog-system/pkg/authorization"
+	"simple-blog-system/pkg/encrypt"
+	"simple-blog-system/pkg/oidc"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// maxOidcUsername leaves room for a suffix within the 50 characters of a username
+	maxOidcUsername = 40
+	// oidcUsernameTries is how many numbered usernames are tried before a random suffix is taken
+	oidcUsernameTries = 9
+)
+
+var (
+	errOidcOff          = apperror.BadRequest("single sign-on is not configured")
+	errInvalidOidcState = apperror.BadRequest("invalid or expired single sign-on state")
+	errOidcFailed       = apperror.Unauthorized("single sign-on failed")
+	errOidcUnlinked     = apperror.Forbidden("no account is linked to this identity")
+)
+
+// StartOidc begins a sign in with the identity provider, the user is sent to the returned url and comes back with a code
+func (s service) StartOidc(ctx context.Context) (res *payload.OidcLogin, err error) {
+	if s.provider == nil {
+		return nil, errOidcOff
+	}
+
+	state, err := randomSecret()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := randomSecret()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	authorizationURL, err := s.provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return nil, err
+	}
+
+	_, qerr := s.oidcRepo.InsertOidcState(ctx, model.OidcStateModel{
+		StateHash:    hashToken(state),
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(s.options.OidcStateTTL),
+	})
+	if qerr != nil {
+		return nil, qerr
+	}
+
+	return &payload.OidcLogin{
+		AuthorizationURL: authorizationURL,
+		State:            state,
+		ExpiresIn:        int64(s.options.OidcStateTTL.Seconds()),
+	}, nil
+}
+
+// CompleteOidc swaps the code the identity provider sent back for a token pair of the linked user. The identity provider
+// takes care of the factors, so users with two-factor authentication are not asked for a code.
+func (s service) CompleteOidc(ctx context.Context, request payload.OidcCallbackRequest, client payload.Client) (token *payload.Token, err error) {
+	if s.provider == nil {
+		return nil, errOidcOff
+	}
+
+	// the state is used up before anything else, a callback can not be replayed
+	states, qerr := s.oidcRepo.UseOidcState(ctx, hashToken(request.State))
+	if qerr != nil {
+		return nil, qerr
+	}
+	if len(states) == 0 {
+		return nil, errInvalidOidcState
+	}
+
+	if request.Error != "" {
+		return nil, apperror.Unauthorized("identity provider refused the sign in: " + request.Error)
+	}
+
+	claims, err := s.provider.Identify(ctx, request.Code, states[0].CodeVerifier, states[0].Nonce)
+	if err != nil {
+		// why the provider or its token was refused is logged, the caller only learns that it failed
+		log.Warn().Err(err).Msg("single sign-on failed")
+		return nil, errOidcFailed
+	}
+
+	user, err := s.oidcUser(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Suspended(time.Now()) {
+		return nil, suspended(user)
+	}
+
+	return s.completeLogin(ctx, user, s.options.Throttle.loginKeys(user.Username, client)[0], client)
+}
+
+// oidcUser is the local user of an identity, an identity seen the first time is linked to the user of its email address
+// or gets a new user
+func (s service) oidcUser(ctx context.Context, claims *oidc.Claims) (model.AuthUserModel, error) {
+	identities, qerr := s.oidcRepo.GetIdentity(ctx, claims.Issuer, claims.Subject)
+	if qerr != nil {
+		return model.AuthUserModel{}, qerr
+	}
+	if len(identities) > 0 {
+		users, qerr := s.userRepo.GetUserByUsername(ctx, identities[0].Username)
+		if qerr != nil {
+			return model.AuthUserModel{}, qerr
+		}
+		if len(users) == 0 {
+			return model.AuthUserModel{}, errOidcUnlinked
+		}
+
+		return users[0], nil
+	}
+
+	user, found, err := s.oidcEmailUser(ctx, claims)
+	if err != nil {
+		return model.AuthUserModel{}, err
+	}
+	if !found {
+		if !s.options.OidcAutoProvision {
+			return model.AuthUserModel{}, errOidcUnlinked
+		}
+
+		user, err = s.provisionUser(ctx, claims)
+		if err != nil {
+			return model.AuthUserModel{}, err
+		}
+	}
+
+	_, qerr = s.oidcRepo.InsertIdentity(ctx, model.UserIdentityModel{
+		Issuer:   claims.Issuer,
+		Subject:  claims.Subject,
+		Username: user.Username,
+	})
+	if qerr != nil {
+		return model.AuthUserModel{}, qerr
+	}
+
+	log.Info().Str("username", user.Username).Str("issuer", claims.Issuer).Bool("provisioned", !found).Msg("linked single sign-on identity")
+
+	return user, nil
+}
+
+// oidcEmailUser finds the one local user with the email address of the identity. Both sides have to have verified the
+// address, otherwise whoever typed in an address they do not own would take over the account.
+func (s service) oidcEmailUser(ctx context.Context, claims *oidc.Claims) (model.AuthUserModel, bool, error) {
+	if !s.options.OidcLinkByEmail || claims.Email == "" || !claims.EmailVerified {
+		return model.AuthUserModel{}, false, nil
+	}
+
+	users, qerr := s.userRepo.GetUserByEmail(ctx, claims.Email)
+	if qerr != nil {
+		return model.AuthUserModel{}, false, qerr
+	}
+
+	var verified []model.AuthUserModel
+	for _, user := range users {
+		if user.EmailVerified() {
+			verified = append(verified, user)
+		}
+	}
+	// the address is not unique among users, which of several accounts is meant can not be told
+	if len(verified) > 1 {
+		return model.AuthUserModel{}, false, apperror.Conflict("several accounts have the email address of this identity")
+	}
+	if len(verified) == 0 {
+		return model.AuthUserModel{}, false, nil
+	}
+
+	return verified[0], true, nil
+}
+
+// provisionUser creates a user for an identity, it has a random password and signs in through the identity provider only
+// until a password is reset
+func (s service) provisionUser(ctx context.Context, claims *oidc.Claims) (model.AuthUserModel, error) {
+	username, err := s.freeUsername(ctx, claims)
+	if err != nil {
+		return model.AuthUserModel{}, err
+	}
+
+	password, err := randomSecret()
+	if err != nil {
+		return model.AuthUserModel{}, err
+	}
+	hash, err := encrypt.HashPassword(password)
+	if err != nil {
+		return model.AuthUserModel{}, err
+	}
+
+	// an unknown role gets what a registered user gets
+	role := s.options.OidcDefaultRole
+	if !authorization.IsValidRole(role) {
+		role = authorization.RoleAuthor
+	}
+
+	user := model.AuthUserModel{
+		Username:  username,
+		Password:  hash,
+		Email:     claims.Email,
+		Role:      role,
+		IsActive:  true,
+		LastLogin: time.Now(),
+		CreatedBy: username,
+	}
+	if claims.Email != "" && claims.EmailVerified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+
+	return s.userRepo.InsertUser(ctx, user)
+}
+
+// freeUsername derives a username from the identity that nobody has, taken ones get a number and then a random suffix
+func (s service) freeUsername(ctx context.Context, claims *oidc.Claims) (string, error) {
+	base := oidcUsername(claims.PreferredUsername)
+	if base == "" {
+		base = oidcUsername(claims.Email)
+	}
+	if base == "" {
+		base = "user"
+	}
+
+	for i := 1; i <= oidcUsernameTries+1; i++ {
+		username := base
+		if i > 1 {
+			username = fmt.Sprintf("%s-%d", base, i)
+		}
+		if i > oidcUsernameTries {
+			suffix := make([]byte, 3)
+			if _, err := rand.Read(suffix); err != nil {
+				return "", err
+			}
+			username = base + "-" + hex.EncodeToString(suffix)
+		}
+
+		taken, qerr := s.userRepo.IsUsernameTaken(ctx, username)
+		if qerr != nil {
+			return "", qerr
+		}
+		if !taken {
+			return username, nil
+		}
+	}
+
+	return "", apperror.Conflict("no free username for this identity")
+}
+
+// oidcUsername keeps the lower case letters, digits, dots, dashes and underscores of name, an email address is cut at the @
+func oidcUsername(name string) string {
+	name, _, _ = strings.Cut(strings.ToLower(name), "@")
+
+	var username strings.Builder
+	for _, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '.' || r == '-' || r == '_' {
+			username.WriteRune(r)
+		}
+	}
+
+	return strings.Trim(username.String()[:min(username.Len(), maxOidcUsername)], ".-_")
+}
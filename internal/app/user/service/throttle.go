@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"simple-blog-system/internal/app/user/model"
+	"simple-blog-system/internal/app/user/payload"
+	"simple-blog-system/internal/app/user/port"
+
+	"simple-blog-system/pkg/apperror"
+	"simple-blog-system/pkg/mailer"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	userKeyPrefix = "user:"
+	ipKeyPrefix   = "ip:"
+)
+
+// Throttle slows down password guessing, failed logins are counted per username and per address
+type Throttle struct {
+	User ThrottleLimit
+	// Ip is usually looser than User since many users can share an address
+	Ip ThrottleLimit
+	// BackoffBase is the wait after the first failure past the free ones, it doubles with every further failure up to BackoffMax
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	// LockoutDuration is the wait once a key reached its LockoutAfter failures
+	LockoutDuration time.Duration
+	// Window is how long a failure is remembered, counting starts over after a quiet window
+	Window time.Duration
+}
+
+type ThrottleLimit struct {
+	// FreeAttempts is how many failures go without a wait
+	FreeAttempts int
+	// LockoutAfter is the number of failures that locks the key out, 0 never locks it out
+	LockoutAfter int
+}
+
+type loginKey struct {
+	key   string
+	limit ThrottleLimit
+}
+
+// loginKeys are the counters a login of username from client counts against
+func (t Throttle) loginKeys(username string, client payload.Client) []loginKey {
+	keys := []loginKey{{key: userKeyPrefix + username, limit: t.User}}
+	if client.IpAddress != "" {
+		keys = append(keys, loginKey{key: ipKeyPrefix + client.IpAddress, limit: t.Ip})
+	}
+
+	return keys
+}
+
+// wait is how long a key is blocked after its last failure
+func (t Throttle) wait(limit ThrottleLimit, failures int) time.Duration {
+	if limit.LockoutAfter > 0 && failures >= limit.LockoutAfter {
+		return t.LockoutDuration
+	}
+	if failures <= limit.FreeAttempts {
+		return 0
+	}
+
+	wait := t.BackoffBase
+	for i := limit.FreeAttempts + 1; i < failures && wait < t.BackoffMax; i++ {
+		wait *= 2
+	}
+
+	return min(wait, t.BackoffMax)
+}
+
+// retention is how long a counter has to be kept, the lockout may outlast the window
+func (t Throttle) retention() time.Duration {
+	return max(t.Window, t.LockoutDuration)
+}
+
+// checkLogin refuses a login while one of keys waits after its failures, the password is not even checked then
+func (s service) checkLogin(ctx context.Context, keys []loginKey) error {
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = key.key
+	}
+
+	attempts, err := s.attempts.GetLoginAttempts(ctx, names)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var wait time.Duration
+	for _, attempt := range attempts {
+		for _, key := range keys {
+			if key.key != attempt.Key {
+				continue
+			}
+			until := attempt.LastFailedAt.Add(s.options.Throttle.wait(key.limit, attempt.Failures))
+			wait = max(wait, until.Sub(now))
+		}
+	}
+
+	if wait > 0 {
+		return apperror.TooManyRequests(fmt.Sprintf("too many failed logins, try again in %s", wait.Round(time.Second)))
+	}
+
+	return nil
+}
+
+// loginFailed counts a failure against every key and tells the notifier about the keys it locks out,
+// failure is returned unless counting failed
+func (s service) loginFailed(ctx context.Context, keys []loginKey, failure error) error {
+	now := time.Now()
+	for _, key := range keys {
+		attempt, err := s.attempts.AddLoginFailure(ctx, key.key, now, now.Add(-s.options.Throttle.Window))
+		if err != nil {
+			return err
+		}
+
+		// only the failure that reaches the limit notifies, the ones after it would repeat the same news
+		if key.limit.LockoutAfter > 0 && attempt.Failures == key.limit.LockoutAfter {
+			s.notifier.NotifyLockout(ctx, lockout(attempt, now.Add(s.options.Throttle.LockoutDuration)))
+		}
+	}
+
+	return failure
+}
+
+func lockout(attempt model.LoginAttemptModel, until time.Time) payload.Lockout {
+	lockout := payload.Lockout{
+		Failures: attempt.Failures,
+		Until:    until,
+	}
+	if username, ok := strings.CutPrefix(attempt.Key, userKeyPrefix); ok {
+		lockout.Username = username
+	} else {
+		lockout.IpAddress = strings.TrimPrefix(attempt.Key, ipKeyPrefix)
+	}
+
+	return lockout
+}
+
+type lockoutMailer struct {
+	userRepo port.IUserRepository
+	mailer   mailer.IMailer
+}
+
+// NewLockoutMailer logs every lockout and mails the user whose account got locked out, when there is an address to mail to
+func NewLockoutMailer(userRepo port.IUserRepository, mailer mailer.IMailer) port.ILockoutNotifier {
+	return lockoutMailer{userRepo: userRepo, mailer: mailer}
+}
+
+func (n lockoutMailer) NotifyLockout(ctx context.Context, lockout payload.Lockout) {
+	log.Warn().Str("username", lockout.Username).Str("ip_address", lockout.IpAddress).Int("failures", lockout.Failures).Time("until", lockout.Until).Msg("login locked out")
+
+	if lockout.Username == "" {
+		return
+	}
+
+	users, err := n.userRepo.GetUserByUsername(ctx, lockout.Username)
+	if err != nil {
+		log.Error().Err(err).Str("username", lockout.Username).Msg("failed to get locked out user")
+		return
+	}
+	if len(users) == 0 || users[0].Email == "" {
+		return
+	}
+
+	err = n.mailer.Send(ctx, mailer.Message{
+		To:      users[0].Email,
+		Subject: "Your account was locked",
+		Body:    fmt.Sprintf("Hi %s,\n\nafter %d failed logins your account is locked until %s.\n\nIf it was not you, someone may be guessing your password. Choosing a new one with the forgotten password form lifts the lock, an administrator can also unlock the account.\n", users[0].Username, lockout.Failures, lockout.Until.UTC().Format(time.RFC1123)),
+	})
+	if err != nil {
+		log.Error().Err(err).Str("username", lockout.Username).Msg("failed to send lockout mail")
+	}
+}
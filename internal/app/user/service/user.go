@@ -2,90 +2,742 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"simple-blog-system/internal/app/user/model"
 	"simple-blog-system/internal/app/user/payload"
 	"simple-blog-system/internal/app/user/port"
 
-	"simple-blog-system/config"
+	"simple-blog-system/pkg/apperror"
+	"simple-blog-system/pkg/authorization"
 	"simple-blog-system/pkg/encrypt"
+	"simple-blog-system/pkg/mailer"
+	"simple-blog-system/pkg/oidc"
+	"simple-blog-system/pkg/signing"
 
+	"github.com/go-openapi/strfmt"
 	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
 )
 
+const tokenType = "Bearer"
+
+var (
+	errInvalidRefreshToken      = apperror.Unauthorized("invalid refresh token")
+	errInvalidResetToken        = apperror.BadRequest("invalid or expired reset token")
+	errInvalidVerificationToken = apperror.BadRequest("invalid or expired verification token")
+	errIncorrectLogin           = errors.New("incorrect username or password")
+)
+
+// Options are the lifetimes of the tokens the service hands out, the pages their mails link to, how logins are throttled,
+// how accounts are deleted, how the second factor is set up, how long api tokens live and who signs in through the identity provider
+type Options struct {
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+	ResetTTL   time.Duration
+	VerifyTTL  time.Duration
+	// ResetURL and VerifyURL take the token as ?token=, without them mails carry the bare token
+	ResetURL  string
+	VerifyURL string
+	Throttle  Throttle
+	// DeletionGrace is how long a deleted account can still be kept by logging in
+	DeletionGrace time.Duration
+	// DeletionContent is what happens to the posts and comments of a deleted account, ContentAnonymize or ContentDelete
+	DeletionContent string
+	// MfaIssuer names the blog in authenticator apps, MfaChallengeTTL is how long a login waits for its code
+	MfaIssuer       string
+	MfaChallengeTTL time.Duration
+	// ApiTokenTTL is the lifetime of api tokens created without expires_at, ApiTokenMaxTTL the longest one allowed
+	ApiTokenTTL    time.Duration
+	ApiTokenMaxTTL time.Duration
+	// OidcStateTTL is how long a sign in with the identity provider waits for the callback
+	OidcStateTTL time.Duration
+	// OidcAutoProvision creates a user with OidcDefaultRole for an identity that is linked to nobody,
+	// OidcLinkByEmail links it to the user with the same verified email address first
+	OidcAutoProvision bool
+	OidcLinkByEmail   bool
+	OidcDefaultRole   string
+}
+
 type service struct {
-	userRepo port.IUserRepository
+	userRepo         port.IUserRepository
+	sessionRepo      port.ISessionRepository
+	resetRepo        port.IPasswordResetRepository
+	verificationRepo port.IEmailVerificationRepository
+	mfaRepo          port.IMfaRepository
+	apiTokenRepo     port.IApiTokenRepository
+	oidcRepo         port.IOidcRepository
+	attempts         port.ILoginAttemptStore
+	revocations      port.IRevocationStore
+	mailer           mailer.IMailer
+	notifier         port.ILockoutNotifier
+	contents         []port.IUserContent
+	signer           signing.ISigner
+	// provider is nil when single sign-on is not configured
+	provider oidc.IProvider
+	options  Options
 }
 
-func New(userRepo port.IUserRepository) port.IUserService {
+func New(userRepo port.IUserRepository, sessionRepo port.ISessionRepository, resetRepo port.IPasswordResetRepository, verificationRepo port.IEmailVerificationRepository, mfaRepo port.IMfaRepository, apiTokenRepo port.IApiTokenRepository, oidcRepo port.IOidcRepository, attempts port.ILoginAttemptStore, revocations port.IRevocationStore, mailer mailer.IMailer, notifier port.ILockoutNotifier, contents []port.IUserContent, signer signing.ISigner, provider oidc.IProvider, options Options) port.IUserService {
 	return &service{
-		userRepo: userRepo,
+		userRepo:         userRepo,
+		sessionRepo:      sessionRepo,
+		resetRepo:        resetRepo,
+		verificationRepo: verificationRepo,
+		mfaRepo:          mfaRepo,
+		apiTokenRepo:     apiTokenRepo,
+		oidcRepo:         oidcRepo,
+		attempts:         attempts,
+		revocations:      revocations,
+		mailer:           mailer,
+		notifier:         notifier,
+		contents:         contents,
+		signer:           signer,
+		provider:         provider,
+		options:          options,
 	}
 }
 
-func (s *service) Register(ctx context.Context, user model.AuthUserModel) (token string, err error) {
-	username, qerr := s.userRepo.GetUserByUsername(ctx, user.Username)
+func (s *service) Register(ctx context.Context, user model.AuthUserModel, client payload.Client) (token *payload.Token, err error) {
+	if user.Username == model.DeletedUsername {
+		return nil, apperror.BadRequest("username is reserved")
+	}
+
+	// deleted accounts keep their username, their content may still point at it
+	taken, qerr := s.userRepo.IsUsernameTaken(ctx, user.Username)
 	if qerr != nil {
-		return "", qerr
+		return nil, qerr
 	}
-	if len(username) > 0 {
-		return "", errors.New("user already exists")
+	if taken {
+		return nil, errors.New("user already exists")
 	}
 
 	hash, qerr := encrypt.HashPassword(user.Password)
 	if qerr != nil {
-		return "", qerr
+		return nil, qerr
 	}
 
-	user.CreatedBy = user.Username
-	user.LastLogin = time.Now()
-	user.Password = hash
+	// only what the user may choose is taken from the request
+	user = model.AuthUserModel{
+		Username:  user.Username,
+		Password:  hash,
+		Email:     user.Email,
+		Role:      authorization.RoleAuthor,
+		IsActive:  true,
+		LastLogin: time.Now(),
+		CreatedBy: user.Username,
+	}
 	user, qerr = s.userRepo.InsertUser(ctx, user)
 	if qerr != nil {
-		return "", qerr
+		return nil, qerr
 	}
 
-	tokenString, err := createToken(user)
+	if user.Email != "" {
+		if err := s.sendVerification(ctx, user); err != nil {
+			// the account works without a verified address, a new mail can be asked for from the profile
+			log.Error().Err(err).Str("username", user.Username).Msg("failed to send verification mail")
+		}
+	}
 
-	return tokenString, err
+	return s.startSession(ctx, user, client)
 }
 
-func createToken(user model.AuthUserModel) (string, error) {
-	configData := config.GetConfig()
-	claims := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+func createToken(signer signing.ISigner, user model.AuthUserModel, sessionId strfmt.UUID4, jti string, expiresAt time.Time) (string, error) {
+	return signer.Sign(jwt.MapClaims{
 		"id":       user.ID,
 		"username": user.Username,
-		"exp":      time.Now().Add(time.Hour).Unix(),
+		"role":     user.Role,
+		"sub":      user.Username,
+		"sid":      sessionId,
+		"jti":      jti,
+		"exp":      expiresAt.Unix(),
 		"iat":      time.Now().Unix(),
 	})
-	tokenString, err := claims.SignedString([]byte(configData.JWT.SigningKey))
+}
+
+// Jwks are the public keys the access tokens of the service are verified with
+func (s service) Jwks(ctx context.Context) signing.JWKS {
+	return s.signer.JWKS()
+}
+
+// newRefreshToken is the session id followed by a random secret, the id finds the session without a lookup by hash
+func newRefreshToken(sessionId strfmt.UUID4) (string, error) {
+	secret, err := randomSecret()
+	if err != nil {
+		return "", err
+	}
+
+	return sessionId.String() + "." + secret, nil
+}
 
-	return tokenString, err
+func randomSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(secret), nil
 }
 
-func (s service) Login(ctx context.Context, user model.AuthUserModel) (token string, err error) {
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Login checks the password unless the username or the address has to wait after failed logins,
+// every failure counts against both, a success clears the failures of the username only.
+// Suspended users are refused after the password matched, logging in cancels a pending deletion of the account.
+func (s service) Login(ctx context.Context, user model.AuthUserModel, client payload.Client) (token *payload.Token, err error) {
+	keys := s.options.Throttle.loginKeys(user.Username, client)
+	err = s.checkLogin(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
 	users, qerr := s.userRepo.GetPasswordByUsername(ctx, user.Username)
-	if len(users) == 0 || qerr != nil {
-		return "", errors.New("incorrect username or password")
+	if qerr != nil {
+		return nil, errors.New("incorrect username or password")
+	}
+	// unknown usernames count as well, so they answer like known ones
+	if len(users) == 0 {
+		return nil, s.loginFailed(ctx, keys, errIncorrectLogin)
 	}
 
 	match := encrypt.CheckPasswordHash(user.Password, users[0].Password)
 	if !match {
-		return "", errors.New("incorrect username or password")
+		return nil, s.loginFailed(ctx, keys, errIncorrectLogin)
+	}
+
+	if users[0].Suspended(time.Now()) {
+		return nil, suspended(users[0])
+	}
+
+	mfa, qerr := s.mfaRepo.GetMfa(ctx, users[0].Username)
+	if qerr != nil {
+		return nil, qerr
+	}
+	if len(mfa) > 0 && mfa[0].Enabled() {
+		// the failures of the username are kept until the code matched, the password alone does not buy more guesses
+		return s.challenge(ctx, users[0].Username)
+	}
+
+	return s.completeLogin(ctx, users[0], keys[0], client)
+}
+
+// completeLogin starts a session for a user who passed every factor
+func (s service) completeLogin(ctx context.Context, user model.AuthUserModel, userKey loginKey, client payload.Client) (*payload.Token, error) {
+	qerr := s.attempts.ClearLoginAttempts(ctx, userKey.key)
+	if qerr != nil {
+		return nil, qerr
 	}
 
-	tokenString, err := createToken(users[0])
+	qerr = s.keepAccount(ctx, &user)
+	if qerr != nil {
+		return nil, qerr
+	}
 
-	users[0].LastLogin = time.Now()
-	users[0].UpdatedBy = user.Username
-	qerr = s.userRepo.UpdateLastLogin(ctx, users[0])
+	user.LastLogin = time.Now()
+	user.UpdatedBy = user.Username
+	qerr = s.userRepo.UpdateLastLogin(ctx, user)
 	if qerr != nil {
-		return "", qerr
+		return nil, qerr
+	}
+
+	return s.startSession(ctx, user, client)
+}
+
+// Refresh swaps a refresh token for a new pair, the old refresh token can not be used again
+func (s service) Refresh(ctx context.Context, refreshToken string, client payload.Client) (token *payload.Token, err error) {
+	session, err := s.session(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// the role may have changed since the last token was issued
+	users, qerr := s.userRepo.GetUserByUsername(ctx, session.Username)
+	if qerr != nil {
+		return nil, qerr
+	}
+	if len(users) == 0 {
+		return nil, errInvalidRefreshToken
+	}
+	if !users[0].Active(time.Now()) {
+		return nil, suspended(users[0])
+	}
+
+	previous := session
+	token, err = s.issueTokens(users[0], &session)
+	if err != nil {
+		return nil, err
+	}
+	session.IpAddress = client.IpAddress
+	session.UserAgent = client.UserAgent
+	session.LastSeenAt = time.Now()
+
+	rotated, err := s.sessionRepo.RotateSession(ctx, session, previous.RefreshTokenHash)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		// another request refreshed with the same token in the meantime
+		return nil, s.reused(ctx, previous)
+	}
+
+	if err := s.revokeAccess(ctx, previous); err != nil {
+		log.Warn().Err(err).Str("session", previous.ID.String()).Msg("failed to revoke the previous access token")
+	}
+
+	return token, nil
+}
+
+// Logout ends the session of the refresh token together with its current access token
+func (s service) Logout(ctx context.Context, refreshToken string) error {
+	session, err := s.session(ctx, refreshToken)
+	if err != nil {
+		return err
+	}
+
+	return s.revokeSession(ctx, session)
+}
+
+func (s service) ChangePassword(ctx context.Context, username string, currentSession string, request payload.PasswordRequest) error {
+	users, qerr := s.userRepo.GetPasswordByUsername(ctx, username)
+	if len(users) == 0 || qerr != nil {
+		return errors.New("user not found")
+	}
+
+	if !encrypt.CheckPasswordHash(request.CurrentPassword, users[0].Password) {
+		return apperror.BadRequest("current password is incorrect")
+	}
+	if request.NewPassword == request.CurrentPassword {
+		return apperror.BadRequest("new password must differ from the current password")
 	}
 
-	return tokenString, err
+	return s.setPassword(ctx, username, request.NewPassword, currentSession)
+}
+
+func (s service) ForgotPassword(ctx context.Context, username string) error {
+	users, qerr := s.userRepo.GetUserByUsername(ctx, username)
+	if qerr != nil {
+		return qerr
+	}
+	if len(users) == 0 || users[0].Email == "" {
+		return nil
+	}
+
+	// only the newest link works
+	qerr = s.resetRepo.InvalidatePasswordResets(ctx, username)
+	if qerr != nil {
+		return qerr
+	}
+
+	token, err := randomSecret()
+	if err != nil {
+		return err
+	}
+
+	_, qerr = s.resetRepo.InsertPasswordReset(ctx, model.PasswordResetModel{
+		Username:  username,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(s.options.ResetTTL),
+	})
+	if qerr != nil {
+		return qerr
+	}
+
+	err = s.mailer.Send(ctx, s.resetMessage(users[0], token))
+	if err != nil {
+		// a failed mail is not reported to the caller, it would tell that the user has an email address
+		log.Error().Err(err).Str("username", username).Msg("failed to send password reset mail")
+	}
+
+	return nil
+}
+
+func (s service) ResetPassword(ctx context.Context, request payload.ResetPasswordRequest) error {
+	resets, qerr := s.resetRepo.UsePasswordReset(ctx, hashToken(request.Token))
+	if qerr != nil {
+		return qerr
+	}
+	if len(resets) == 0 {
+		return errInvalidResetToken
+	}
+
+	return s.setPassword(ctx, resets[0].Username, request.NewPassword, "")
+}
+
+// VerifyEmail confirms the address a verification token was sent to
+func (s service) VerifyEmail(ctx context.Context, token string) error {
+	verifications, qerr := s.verificationRepo.UseEmailVerification(ctx, hashToken(token))
+	if qerr != nil {
+		return qerr
+	}
+	if len(verifications) == 0 {
+		return errInvalidVerificationToken
+	}
+
+	// the user may have changed the address after the token was sent
+	verified, qerr := s.userRepo.VerifyEmail(ctx, verifications[0].Username, verifications[0].Email)
+	if qerr != nil {
+		return qerr
+	}
+	if !verified {
+		return errInvalidVerificationToken
+	}
+
+	return nil
+}
+
+// ChangeEmail sets the email address of a user and mails a verification token to it, it also sends a new token for the same address
+func (s service) ChangeEmail(ctx context.Context, username string, email string) (res *payload.User, err error) {
+	users, qerr := s.userRepo.GetUserByUsername(ctx, username)
+	if len(users) == 0 || qerr != nil {
+		return nil, errors.New("user not found")
+	}
+
+	qerr = s.userRepo.UpdateEmail(ctx, username, email)
+	if qerr != nil {
+		return nil, qerr
+	}
+	users[0].Email = email
+	users[0].EmailVerifiedAt = nil
+
+	err = s.sendVerification(ctx, users[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return &payload.User{
+		User: users[0],
+	}, nil
+}
+
+// sendVerification mails a verification token for the current address of user, older tokens stop working
+func (s service) sendVerification(ctx context.Context, user model.AuthUserModel) error {
+	qerr := s.verificationRepo.InvalidateEmailVerifications(ctx, user.Username)
+	if qerr != nil {
+		return qerr
+	}
+
+	token, err := randomSecret()
+	if err != nil {
+		return err
+	}
+
+	_, qerr = s.verificationRepo.InsertEmailVerification(ctx, model.EmailVerificationModel{
+		Username:  user.Username,
+		Email:     user.Email,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(s.options.VerifyTTL),
+	})
+	if qerr != nil {
+		return qerr
+	}
+
+	action, link := tokenLink(s.options.VerifyURL, token)
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body:    fmt.Sprintf("Hi %s,\n\nplease confirm that this is your email address. %s within %s to verify it:\n\n%s\n\nIf you did not sign up, ignore this mail.\n", user.Username, action, s.options.VerifyTTL, link),
+	})
+}
+
+func (s service) resetMessage(user model.AuthUserModel, token string) mailer.Message {
+	action, link := tokenLink(s.options.ResetURL, token)
+
+	return mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body:    fmt.Sprintf("Hi %s,\n\nsomeone asked to reset the password of your account. %s within %s to choose a new password:\n\n%s\n\nIf it was not you, ignore this mail and your password stays the same.\n", user.Username, action, s.options.ResetTTL, link),
+	}
+}
+
+// tokenLink puts token on the page at pageURL, without a page the token is handed out as is
+func tokenLink(pageURL string, token string) (action string, link string) {
+	if pageURL == "" {
+		return "Use this token", token
+	}
+
+	separator := "?"
+	if strings.Contains(pageURL, "?") {
+		separator = "&"
+	}
+
+	return "Open this link", pageURL + separator + "token=" + url.QueryEscape(token)
+}
+
+// setPassword stores a new password and signs the user out of every session but except
+func (s service) setPassword(ctx context.Context, username string, password string, except string) error {
+	hash, err := encrypt.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	qerr := s.userRepo.UpdatePassword(ctx, username, hash)
+	if qerr != nil {
+		return qerr
+	}
+
+	qerr = s.resetRepo.InvalidatePasswordResets(ctx, username)
+	if qerr != nil {
+		return qerr
+	}
+
+	// whoever set the new password owns the account, guesses at the old one no longer matter
+	qerr = s.attempts.ClearLoginAttempts(ctx, userKeyPrefix+username)
+	if qerr != nil {
+		return qerr
+	}
+
+	_, err = s.revokeUserSessions(ctx, username, except)
+	return err
+}
+
+func (s service) PurgeExpiredSessions(ctx context.Context) (int64, error) {
+	now := time.Now()
+	sessions, err := s.sessionRepo.DeleteExpiredSessions(ctx, now)
+	if err != nil {
+		return sessions, err
+	}
+
+	resets, err := s.resetRepo.DeleteExpiredPasswordResets(ctx, now)
+	if err != nil {
+		return sessions + resets, err
+	}
+
+	verifications, err := s.verificationRepo.DeleteExpiredEmailVerifications(ctx, now)
+	if err != nil {
+		return sessions + resets + verifications, err
+	}
+
+	attempts, err := s.attempts.DeleteExpiredLoginAttempts(ctx, now.Add(-s.options.Throttle.retention()))
+	if err != nil {
+		return sessions + resets + verifications + attempts, err
+	}
+
+	challenges, err := s.mfaRepo.DeleteExpiredMfaChallenges(ctx, now)
+	if err != nil {
+		return sessions + resets + verifications + attempts + challenges, err
+	}
+
+	apiTokens, err := s.apiTokenRepo.DeleteExpiredApiTokens(ctx, now)
+	if err != nil {
+		return sessions + resets + verifications + attempts + challenges + apiTokens, err
+	}
+
+	states, err := s.oidcRepo.DeleteExpiredOidcStates(ctx, now)
+	if err != nil {
+		return sessions + resets + verifications + attempts + challenges + apiTokens + states, err
+	}
+
+	tokens, err := s.revocations.DeleteExpired(ctx, now)
+	return sessions + resets + verifications + attempts + challenges + apiTokens + states + tokens, err
+}
+
+// GetSessions lists the live sessions of a user, the one of the current request is marked as current
+func (s service) GetSessions(ctx context.Context, username string, currentSession string) (res []model.UserSessionModel, err error) {
+	sessions, qerr := s.sessionRepo.GetSessionsByUsername(ctx, username)
+	if qerr != nil {
+		return nil, qerr
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID.String() == currentSession
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession signs a user out of one of their sessions
+func (s service) RevokeSession(ctx context.Context, username string, id string) error {
+	sessions, qerr := s.sessionRepo.GetSessionById(ctx, id)
+	if qerr != nil {
+		return qerr
+	}
+	if len(sessions) == 0 || sessions[0].Username != username || sessions[0].RevokedAt != nil || !time.Now().Before(sessions[0].ExpiresAt) {
+		return errors.New("session not found")
+	}
+
+	return s.revokeSession(ctx, sessions[0])
+}
+
+// RevokeUserSessions signs a user out everywhere, admin only
+func (s service) RevokeUserSessions(ctx context.Context, actor string, username string) (res *payload.RevokedSessions, err error) {
+	actors, qerr := s.userRepo.GetUserByUsername(ctx, actor)
+	if len(actors) == 0 || qerr != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if actors[0].Role != authorization.RoleAdmin {
+		return nil, authorization.ErrForbidden
+	}
+
+	users, qerr := s.userRepo.GetUserByUsername(ctx, username)
+	if len(users) == 0 || qerr != nil {
+		return nil, errors.New("user not found")
+	}
+
+	revoked, err := s.revokeUserSessions(ctx, username, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &payload.RevokedSessions{
+		Revoked: revoked,
+	}, nil
+}
+
+// UnlockUser forgets the failed logins of a user, admin only. Failures of the addresses they came from are kept.
+func (s service) UnlockUser(ctx context.Context, actor string, username string) error {
+	actors, qerr := s.userRepo.GetUserByUsername(ctx, actor)
+	if len(actors) == 0 || qerr != nil {
+		return errors.New("user not found")
+	}
+
+	if actors[0].Role != authorization.RoleAdmin {
+		return authorization.ErrForbidden
+	}
+
+	users, qerr := s.userRepo.GetUserByUsername(ctx, username)
+	if len(users) == 0 || qerr != nil {
+		return errors.New("user not found")
+	}
+
+	return s.attempts.ClearLoginAttempts(ctx, userKeyPrefix+username)
+}
+
+// revokeUserSessions revokes every live session of a user but the one with the id except
+func (s service) revokeUserSessions(ctx context.Context, username string, except string) (int, error) {
+	sessions, qerr := s.sessionRepo.GetSessionsByUsername(ctx, username)
+	if qerr != nil {
+		return 0, qerr
+	}
+
+	qerr = s.sessionRepo.RevokeUserSessions(ctx, username, except)
+	if qerr != nil {
+		return 0, qerr
+	}
+
+	revoked := 0
+	for _, session := range sessions {
+		if session.ID.String() == except {
+			continue
+		}
+		if err := s.revokeAccess(ctx, session); err != nil {
+			return revoked, err
+		}
+		revoked++
+	}
+
+	return revoked, nil
+}
+
+func (s service) startSession(ctx context.Context, user model.AuthUserModel, client payload.Client) (*payload.Token, error) {
+	session := model.UserSessionModel{
+		ID:         strfmt.UUID4(uuid.NewString()),
+		Username:   user.Username,
+		IpAddress:  client.IpAddress,
+		UserAgent:  client.UserAgent,
+		LastSeenAt: time.Now(),
+		ExpiresAt:  time.Now().Add(s.options.RefreshTTL),
+	}
+
+	token, err := s.issueTokens(user, &session)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.sessionRepo.InsertSession(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// issueTokens creates a new token pair for the session and records it on the session
+func (s service) issueTokens(user model.AuthUserModel, session *model.UserSessionModel) (*payload.Token, error) {
+	refreshToken, err := newRefreshToken(session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	jti := uuid.NewString()
+	expiresAt := time.Now().Add(s.options.AccessTTL)
+	accessToken, err := createToken(s.signer, user, session.ID, jti, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	session.RefreshTokenHash = hashToken(refreshToken)
+	session.AccessJti = jti
+	session.AccessExpiresAt = expiresAt
+
+	return &payload.Token{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    tokenType,
+		ExpiresIn:    int64(s.options.AccessTTL.Seconds()),
+	}, nil
+}
+
+// session finds the live session of a refresh token, a token that was already rotated revokes its session
+func (s service) session(ctx context.Context, refreshToken string) (model.UserSessionModel, error) {
+	id, _, ok := strings.Cut(refreshToken, ".")
+	if !ok {
+		return model.UserSessionModel{}, errInvalidRefreshToken
+	}
+
+	sessions, qerr := s.sessionRepo.GetSessionById(ctx, id)
+	if qerr != nil {
+		return model.UserSessionModel{}, qerr
+	}
+	if len(sessions) == 0 || sessions[0].RevokedAt != nil || !time.Now().Before(sessions[0].ExpiresAt) {
+		return model.UserSessionModel{}, errInvalidRefreshToken
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashToken(refreshToken)), []byte(sessions[0].RefreshTokenHash)) != 1 {
+		return model.UserSessionModel{}, s.reused(ctx, sessions[0])
+	}
+
+	return sessions[0], nil
+}
+
+// reused revokes a session whose old refresh token showed up again, either the client or an attacker holds a stolen copy
+func (s service) reused(ctx context.Context, session model.UserSessionModel) error {
+	log.Warn().Str("session", session.ID.String()).Str("username", session.Username).Msg("refresh token reused, session revoked")
+
+	if err := s.revokeSession(ctx, session); err != nil {
+		return err
+	}
+
+	return errInvalidRefreshToken
+}
+
+func (s service) revokeSession(ctx context.Context, session model.UserSessionModel) error {
+	if err := s.sessionRepo.RevokeSession(ctx, session.ID.String()); err != nil {
+		return err
+	}
+
+	return s.revokeAccess(ctx, session)
+}
+
+func (s service) revokeAccess(ctx context.Context, session model.UserSessionModel) error {
+	if !time.Now().Before(session.AccessExpiresAt) {
+		return nil
+	}
+
+	return s.revocations.Revoke(ctx, session.AccessJti, session.AccessExpiresAt)
 }
 
 func (s service) GetUser(ctx context.Context, username string) (res *payload.User, err error) {
@@ -100,3 +752,33 @@ func (s service) GetUser(ctx context.Context, username string) (res *payload.Use
 
 	return resUser, err
 }
+
+func (s service) UpdateRole(ctx context.Context, actor string, username string, role string) (res *payload.User, err error) {
+	actors, qerr := s.userRepo.GetUserByUsername(ctx, actor)
+	if len(actors) == 0 || qerr != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if actors[0].Role != authorization.RoleAdmin {
+		return nil, authorization.ErrForbidden
+	}
+
+	if !authorization.IsValidRole(role) {
+		return nil, apperror.BadRequest("invalid role")
+	}
+
+	users, qerr := s.userRepo.GetUserByUsername(ctx, username)
+	if len(users) == 0 || qerr != nil {
+		return nil, errors.New("user not found")
+	}
+
+	qerr = s.userRepo.UpdateRole(ctx, username, role)
+	if qerr != nil {
+		return nil, qerr
+	}
+	users[0].Role = role
+
+	return &payload.User{
+		User: users[0],
+	}, nil
+}
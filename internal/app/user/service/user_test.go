@@ -2,16 +2,37 @@ package service
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"simple-blog-system/config"
 	"simple-blog-system/internal/app/user/model"
+	"simple-blog-system/internal/app/user/payload"
+	userPort "simple-blog-system/internal/app/user/port"
+	"simple-blog-system/internal/app/user/repository"
+	"simple-blog-system/pkg/apperror"
+	"simple-blog-system/pkg/authorization"
 	"simple-blog-system/pkg/encrypt"
+	"simple-blog-system/pkg/mailer"
+	"simple-blog-system/pkg/oidc"
+	"simple-blog-system/pkg/signing"
+	"simple-blog-system/pkg/totp"
 
 	"github.com/go-openapi/strfmt"
+	jwt "github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
@@ -48,6 +69,382 @@ func (m *MockUserRepository) UpdateLastLogin(ctx context.Context, user model.Aut
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) UpdateRole(ctx context.Context, username string, role string) error {
+	args := m.Called(ctx, username, role)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdatePassword(ctx context.Context, username string, password string) error {
+	args := m.Called(ctx, username, password)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateEmail(ctx context.Context, username string, email string) error {
+	args := m.Called(ctx, username, email)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) VerifyEmail(ctx context.Context, username string, email string) (bool, error) {
+	args := m.Called(ctx, username, email)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) IsUsernameTaken(ctx context.Context, username string) (bool, error) {
+	args := m.Called(ctx, username)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) SuspendUser(ctx context.Context, username string, reason string, until *time.Time) error {
+	args := m.Called(ctx, username, reason, until)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ReactivateUser(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ScheduleDeletion(ctx context.Context, username string, deleteAfter *time.Time) error {
+	args := m.Called(ctx, username, deleteAfter)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetUsersDueForDeletion(ctx context.Context, now time.Time, limit int) ([]model.AuthUserModel, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.AuthUserModel), args.Error(1)
+}
+
+func (m *MockUserRepository) DeleteUser(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetUserByEmail(ctx context.Context, email string) ([]model.AuthUserModel, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.AuthUserModel), args.Error(1)
+}
+
+// Mock for ISessionRepository
+type MockSessionRepository struct {
+	mock.Mock
+}
+
+func (m *MockSessionRepository) InsertSession(ctx context.Context, session model.UserSessionModel) (model.UserSessionModel, error) {
+	args := m.Called(ctx, session)
+	return args.Get(0).(model.UserSessionModel), args.Error(1)
+}
+
+func (m *MockSessionRepository) GetSessionById(ctx context.Context, id string) ([]model.UserSessionModel, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.UserSessionModel), args.Error(1)
+}
+
+func (m *MockSessionRepository) GetSessionsByUsername(ctx context.Context, username string) ([]model.UserSessionModel, error) {
+	args := m.Called(ctx, username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.UserSessionModel), args.Error(1)
+}
+
+func (m *MockSessionRepository) RotateSession(ctx context.Context, session model.UserSessionModel, previousHash string) (bool, error) {
+	args := m.Called(ctx, session, previousHash)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockSessionRepository) RevokeSession(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) RevokeUserSessions(ctx context.Context, username string, except string) error {
+	args := m.Called(ctx, username, except)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) DeleteExpiredSessions(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// Mock for IPasswordResetRepository
+type MockPasswordResetRepository struct {
+	mock.Mock
+}
+
+func (m *MockPasswordResetRepository) InsertPasswordReset(ctx context.Context, reset model.PasswordResetModel) (model.PasswordResetModel, error) {
+	args := m.Called(ctx, reset)
+	return args.Get(0).(model.PasswordResetModel), args.Error(1)
+}
+
+func (m *MockPasswordResetRepository) UsePasswordReset(ctx context.Context, tokenHash string) ([]model.PasswordResetModel, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.PasswordResetModel), args.Error(1)
+}
+
+func (m *MockPasswordResetRepository) InvalidatePasswordResets(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+func (m *MockPasswordResetRepository) DeleteExpiredPasswordResets(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// Mock for IEmailVerificationRepository
+type MockEmailVerificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockEmailVerificationRepository) InsertEmailVerification(ctx context.Context, verification model.EmailVerificationModel) (model.EmailVerificationModel, error) {
+	args := m.Called(ctx, verification)
+	return args.Get(0).(model.EmailVerificationModel), args.Error(1)
+}
+
+func (m *MockEmailVerificationRepository) UseEmailVerification(ctx context.Context, tokenHash string) ([]model.EmailVerificationModel, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.EmailVerificationModel), args.Error(1)
+}
+
+func (m *MockEmailVerificationRepository) InvalidateEmailVerifications(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+func (m *MockEmailVerificationRepository) DeleteExpiredEmailVerifications(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// Mock for IMfaRepository
+type MockMfaRepository struct {
+	mock.Mock
+}
+
+func (m *MockMfaRepository) GetMfa(ctx context.Context, username string) ([]model.UserMfaModel, error) {
+	args := m.Called(ctx, username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.UserMfaModel), args.Error(1)
+}
+
+func (m *MockMfaRepository) SaveMfa(ctx context.Context, mfa model.UserMfaModel) error {
+	args := m.Called(ctx, mfa)
+	return args.Error(0)
+}
+
+func (m *MockMfaRepository) ConfirmMfa(ctx context.Context, username string, step int64) (bool, error) {
+	args := m.Called(ctx, username, step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMfaRepository) UseMfaStep(ctx context.Context, username string, step int64) (bool, error) {
+	args := m.Called(ctx, username, step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMfaRepository) DeleteMfa(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+func (m *MockMfaRepository) ReplaceRecoveryCodes(ctx context.Context, username string, codes []model.RecoveryCodeModel) error {
+	args := m.Called(ctx, username, codes)
+	return args.Error(0)
+}
+
+func (m *MockMfaRepository) UseRecoveryCode(ctx context.Context, username string, codeHash string) (bool, error) {
+	args := m.Called(ctx, username, codeHash)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMfaRepository) InsertMfaChallenge(ctx context.Context, challenge model.MfaChallengeModel) (model.MfaChallengeModel, error) {
+	args := m.Called(ctx, challenge)
+	return args.Get(0).(model.MfaChallengeModel), args.Error(1)
+}
+
+func (m *MockMfaRepository) GetMfaChallenge(ctx context.Context, tokenHash string) ([]model.MfaChallengeModel, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.MfaChallengeModel), args.Error(1)
+}
+
+func (m *MockMfaRepository) UseMfaChallenge(ctx context.Context, tokenHash string) (bool, error) {
+	args := m.Called(ctx, tokenHash)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMfaRepository) DeleteExpiredMfaChallenges(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// Mock for IApiTokenRepository
+type MockApiTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockApiTokenRepository) InsertApiToken(ctx context.Context, token model.ApiTokenModel) (model.ApiTokenModel, error) {
+	args := m.Called(ctx, token)
+	return args.Get(0).(model.ApiTokenModel), args.Error(1)
+}
+
+func (m *MockApiTokenRepository) GetApiTokensByUsername(ctx context.Context, username string) ([]model.ApiTokenModel, error) {
+	args := m.Called(ctx, username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.ApiTokenModel), args.Error(1)
+}
+
+func (m *MockApiTokenRepository) GetApiTokenByHash(ctx context.Context, tokenHash string) ([]model.ApiTokenModel, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.ApiTokenModel), args.Error(1)
+}
+
+func (m *MockApiTokenRepository) TouchApiToken(ctx context.Context, id string, ipAddress string, usedAt time.Time) error {
+	args := m.Called(ctx, id, ipAddress, usedAt)
+	return args.Error(0)
+}
+
+func (m *MockApiTokenRepository) RevokeApiToken(ctx context.Context, username string, id string) (bool, error) {
+	args := m.Called(ctx, username, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockApiTokenRepository) RevokeUserApiTokens(ctx context.Context, username string) (int64, error) {
+	args := m.Called(ctx, username)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockApiTokenRepository) DeleteExpiredApiTokens(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// Mock for IOidcRepository
+type MockOidcRepository struct {
+	mock.Mock
+}
+
+func (m *MockOidcRepository) InsertOidcState(ctx context.Context, state model.OidcStateModel) (model.OidcStateModel, error) {
+	args := m.Called(ctx, state)
+	return args.Get(0).(model.OidcStateModel), args.Error(1)
+}
+
+func (m *MockOidcRepository) UseOidcState(ctx context.Context, stateHash string) ([]model.OidcStateModel, error) {
+	args := m.Called(ctx, stateHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.OidcStateModel), args.Error(1)
+}
+
+func (m *MockOidcRepository) DeleteExpiredOidcStates(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockOidcRepository) GetIdentity(ctx context.Context, issuer string, subject string) ([]model.UserIdentityModel, error) {
+	args := m.Called(ctx, issuer, subject)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.UserIdentityModel), args.Error(1)
+}
+
+func (m *MockOidcRepository) InsertIdentity(ctx context.Context, identity model.UserIdentityModel) (model.UserIdentityModel, error) {
+	args := m.Called(ctx, identity)
+	return args.Get(0).(model.UserIdentityModel), args.Error(1)
+}
+
+func (m *MockOidcRepository) DeleteUserIdentities(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+// Mock for IMailer
+type MockMailer struct {
+	mock.Mock
+}
+
+func (m *MockMailer) Send(ctx context.Context, message mailer.Message) error {
+	args := m.Called(ctx, message)
+	return args.Error(0)
+}
+
+// Mock for ILockoutNotifier
+type MockLockoutNotifier struct {
+	mock.Mock
+}
+
+func (m *MockLockoutNotifier) NotifyLockout(ctx context.Context, lockout payload.Lockout) {
+	m.Called(ctx, lockout)
+}
+
+// Mock for IUserContent
+type MockUserContent struct {
+	mock.Mock
+}
+
+func (m *MockUserContent) AnonymizeUserContent(ctx context.Context, username string, placeholder string) (int, error) {
+	args := m.Called(ctx, username, placeholder)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockUserContent) DeleteUserContent(ctx context.Context, username string) (int, error) {
+	args := m.Called(ctx, username)
+	return args.Int(0), args.Error(1)
+}
+
+// Mock for IRevocationStore
+type MockRevocationStore struct {
+	mock.Mock
+}
+
+func (m *MockRevocationStore) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	args := m.Called(ctx, jti, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockRevocationStore) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	args := m.Called(ctx, jti)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRevocationStore) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func newSigningKey() *signing.Key {
+	_, private, _ := ed25519.GenerateKey(rand.Reader)
+	key, _ := signing.NewSigningKey(private)
+	return key
+}
+
 // TestMain initializes the config before running tests
 func TestMain(m *testing.M) {
 	// Set required environment variables for testing
@@ -72,15 +469,77 @@ func TestMain(m *testing.M) {
 // Test Suite
 type UserServiceTestSuite struct {
 	suite.Suite
-	service  *service
-	userRepo *MockUserRepository
-	ctx      context.Context
+	service     *service
+	userRepo    *MockUserRepository
+	sessionRepo *MockSessionRepository
+	resetRepo   *MockPasswordResetRepository
+	verifyRepo  *MockEmailVerificationRepository
+	mfaRepo     *MockMfaRepository
+	tokenRepo   *MockApiTokenRepository
+	oidcRepo    *MockOidcRepository
+	signingKey  *signing.Key
+	attempts    userPort.ILoginAttemptStore
+	revocations *MockRevocationStore
+	mailer      *MockMailer
+	notifier    *MockLockoutNotifier
+	content     *MockUserContent
+	ctx         context.Context
 }
 
 func (suite *UserServiceTestSuite) SetupTest() {
 	suite.userRepo = new(MockUserRepository)
+	suite.sessionRepo = new(MockSessionRepository)
+	suite.resetRepo = new(MockPasswordResetRepository)
+	suite.verifyRepo = new(MockEmailVerificationRepository)
+	suite.mfaRepo = new(MockMfaRepository)
+	suite.tokenRepo = new(MockApiTokenRepository)
+	suite.oidcRepo = new(MockOidcRepository)
+	suite.signingKey = newSigningKey()
+	suite.attempts = repository.NewMemoryAttemptStore()
+	suite.revocations = new(MockRevocationStore)
+	suite.mailer = new(MockMailer)
+	suite.notifier = new(MockLockoutNotifier)
+	suite.content = new(MockUserContent)
 	suite.service = &service{
-		userRepo: suite.userRepo,
+		userRepo:         suite.userRepo,
+		sessionRepo:      suite.sessionRepo,
+		resetRepo:        suite.resetRepo,
+		verificationRepo: suite.verifyRepo,
+		mfaRepo:          suite.mfaRepo,
+		apiTokenRepo:     suite.tokenRepo,
+		oidcRepo:         suite.oidcRepo,
+		attempts:         suite.attempts,
+		revocations:      suite.revocations,
+		mailer:           suite.mailer,
+		notifier:         suite.notifier,
+		contents:         []userPort.IUserContent{suite.content},
+		signer:           signing.NewKeySet(suite.signingKey, nil, "simple-blog-system", "simple-blog-system"),
+		options: Options{
+			AccessTTL:  time.Hour,
+			RefreshTTL: 24 * time.Hour,
+			ResetTTL:   time.Hour,
+			VerifyTTL:  48 * time.Hour,
+			ResetURL:   "https://blog.example/reset",
+			VerifyURL:  "https://blog.example/verify",
+			Throttle: Throttle{
+				User:            ThrottleLimit{FreeAttempts: 3, LockoutAfter: 5},
+				Ip:              ThrottleLimit{FreeAttempts: 10, LockoutAfter: 20},
+				BackoffBase:     time.Second,
+				BackoffMax:      time.Minute,
+				LockoutDuration: time.Hour,
+				Window:          24 * time.Hour,
+			},
+			MfaIssuer:         "Blog",
+			MfaChallengeTTL:   5 * time.Minute,
+			ApiTokenTTL:       90 * 24 * time.Hour,
+			ApiTokenMaxTTL:    365 * 24 * time.Hour,
+			OidcStateTTL:      10 * time.Minute,
+			OidcAutoProvision: true,
+			OidcLinkByEmail:   true,
+			OidcDefaultRole:   authorization.RoleAuthor,
+			DeletionGrace:     30 * 24 * time.Hour,
+			DeletionContent:   ContentAnonymize,
+		},
 	}
 	suite.ctx = context.Background()
 }
@@ -96,24 +555,33 @@ func (suite *UserServiceTestSuite) TestRegister_Success() {
 	user := model.AuthUserModel{
 		Username: username,
 		Password: password,
+		Role:     authorization.RoleAdmin,
 	}
 
 	// Mock: User doesn't exist yet
-	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]model.AuthUserModel{}, nil)
+	suite.userRepo.On("IsUsernameTaken", suite.ctx, username).Return(false, nil)
 
 	// Mock: User insertion succeeds
 	suite.userRepo.On("InsertUser", suite.ctx, mock.MatchedBy(func(u model.AuthUserModel) bool {
-		return u.Username == username && u.CreatedBy == username && u.Password != password
+		return u.Username == username && u.CreatedBy == username && u.Password != password && u.Role == authorization.RoleAuthor && u.IsActive
 	})).Return(model.AuthUserModel{
 		ID:       strfmt.UUID4("user-123"),
 		Username: username,
 	}, nil)
 
-	token, err := suite.service.Register(suite.ctx, user)
+	// Mock: Session of the new user is stored
+	suite.sessionRepo.On("InsertSession", suite.ctx, mock.MatchedBy(func(s model.UserSessionModel) bool {
+		return s.Username == username && s.RefreshTokenHash != "" && s.AccessJti != ""
+	})).Return(model.UserSessionModel{}, nil)
+
+	token, err := suite.service.Register(suite.ctx, user, payload.Client{})
 
 	assert.NoError(suite.T(), err)
-	assert.NotEmpty(suite.T(), token)
+	assert.NotEmpty(suite.T(), token.AccessToken)
+	assert.NotEmpty(suite.T(), token.RefreshToken)
+	assert.Equal(suite.T(), int64(3600), token.ExpiresIn)
 	suite.userRepo.AssertExpectations(suite.T())
+	suite.sessionRepo.AssertExpectations(suite.T())
 }
 
 func (suite *UserServiceTestSuite) TestRegister_UserAlreadyExists() {
@@ -125,15 +593,10 @@ func (suite *UserServiceTestSuite) TestRegister_UserAlreadyExists() {
 		Password: password,
 	}
 
-	existingUser := model.AuthUserModel{
-		ID:       strfmt.UUID4("user-123"),
-		Username: username,
-	}
-
 	// Mock: User already exists
-	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]model.AuthUserModel{existingUser}, nil)
+	suite.userRepo.On("IsUsernameTaken", suite.ctx, username).Return(true, nil)
 
-	token, err := suite.service.Register(suite.ctx, user)
+	token, err := suite.service.Register(suite.ctx, user, payload.Client{})
 
 	assert.Error(suite.T(), err)
 	assert.Empty(suite.T(), token)
@@ -151,9 +614,9 @@ func (suite *UserServiceTestSuite) TestRegister_GetUserError() {
 	}
 
 	// Mock: Database error when checking user
-	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]model.AuthUserModel{}, errors.New("database error"))
+	suite.userRepo.On("IsUsernameTaken", suite.ctx, username).Return(false, errors.New("database error"))
 
-	token, err := suite.service.Register(suite.ctx, user)
+	token, err := suite.service.Register(suite.ctx, user, payload.Client{})
 
 	assert.Error(suite.T(), err)
 	assert.Empty(suite.T(), token)
@@ -171,12 +634,12 @@ func (suite *UserServiceTestSuite) TestRegister_InsertUserError() {
 	}
 
 	// Mock: User doesn't exist
-	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]model.AuthUserModel{}, nil)
+	suite.userRepo.On("IsUsernameTaken", suite.ctx, username).Return(false, nil)
 
 	// Mock: Insert fails
 	suite.userRepo.On("InsertUser", suite.ctx, mock.Anything).Return(model.AuthUserModel{}, errors.New("insert error"))
 
-	token, err := suite.service.Register(suite.ctx, user)
+	token, err := suite.service.Register(suite.ctx, user, payload.Client{})
 
 	assert.Error(suite.T(), err)
 	assert.Empty(suite.T(), token)
@@ -198,21 +661,37 @@ func (suite *UserServiceTestSuite) TestLogin_Success() {
 		ID:       strfmt.UUID4("user-123"),
 		Username: username,
 		Password: hashedPassword,
+		IsActive: true,
 	}
 
 	// Mock: Get user with password
 	suite.userRepo.On("GetPasswordByUsername", suite.ctx, username).Return([]model.AuthUserModel{existingUser}, nil)
 
+	// Mock: Two-factor authentication is off
+	suite.mfaRepo.On("GetMfa", suite.ctx, username).Return([]model.UserMfaModel{}, nil)
+
 	// Mock: Update last login
 	suite.userRepo.On("UpdateLastLogin", suite.ctx, mock.MatchedBy(func(u model.AuthUserModel) bool {
 		return u.Username == username && u.UpdatedBy == username
 	})).Return(nil)
 
-	token, err := suite.service.Login(suite.ctx, user)
+	// Mock: Session is stored with the hash of the refresh token
+	var session model.UserSessionModel
+	suite.sessionRepo.On("InsertSession", suite.ctx, mock.Anything).Run(func(args mock.Arguments) {
+		session = args.Get(1).(model.UserSessionModel)
+	}).Return(model.UserSessionModel{}, nil)
+
+	token, err := suite.service.Login(suite.ctx, user, payload.Client{IpAddress: "10.0.0.1", UserAgent: "curl/8.0"})
 
 	assert.NoError(suite.T(), err)
-	assert.NotEmpty(suite.T(), token)
+	assert.NotEmpty(suite.T(), token.AccessToken)
+	assert.Equal(suite.T(), "10.0.0.1", session.IpAddress)
+	assert.Equal(suite.T(), "curl/8.0", session.UserAgent)
+	assert.Equal(suite.T(), "Bearer", token.TokenType)
+	assert.True(suite.T(), strings.HasPrefix(token.RefreshToken, session.ID.String()+"."))
+	assert.Equal(suite.T(), hashToken(token.RefreshToken), session.RefreshTokenHash)
 	suite.userRepo.AssertExpectations(suite.T())
+	suite.sessionRepo.AssertExpectations(suite.T())
 }
 
 func (suite *UserServiceTestSuite) TestLogin_UserNotFound() {
@@ -227,7 +706,7 @@ func (suite *UserServiceTestSuite) TestLogin_UserNotFound() {
 	// Mock: User not found
 	suite.userRepo.On("GetPasswordByUsername", suite.ctx, username).Return([]model.AuthUserModel{}, nil)
 
-	token, err := suite.service.Login(suite.ctx, user)
+	token, err := suite.service.Login(suite.ctx, user, payload.Client{})
 
 	assert.Error(suite.T(), err)
 	assert.Empty(suite.T(), token)
@@ -247,7 +726,7 @@ func (suite *UserServiceTestSuite) TestLogin_DatabaseError() {
 	// Mock: Database error
 	suite.userRepo.On("GetPasswordByUsername", suite.ctx, username).Return([]model.AuthUserModel{}, errors.New("database error"))
 
-	token, err := suite.service.Login(suite.ctx, user)
+	token, err := suite.service.Login(suite.ctx, user, payload.Client{})
 
 	assert.Error(suite.T(), err)
 	assert.Empty(suite.T(), token)
@@ -270,12 +749,13 @@ func (suite *UserServiceTestSuite) TestLogin_IncorrectPassword() {
 		ID:       strfmt.UUID4("user-123"),
 		Username: username,
 		Password: hashedPassword,
+		IsActive: true,
 	}
 
 	// Mock: Get user with password
 	suite.userRepo.On("GetPasswordByUsername", suite.ctx, username).Return([]model.AuthUserModel{existingUser}, nil)
 
-	token, err := suite.service.Login(suite.ctx, user)
+	token, err := suite.service.Login(suite.ctx, user, payload.Client{})
 
 	assert.Error(suite.T(), err)
 	assert.Empty(suite.T(), token)
@@ -297,15 +777,19 @@ func (suite *UserServiceTestSuite) TestLogin_UpdateLastLoginError() {
 		ID:       strfmt.UUID4("user-123"),
 		Username: username,
 		Password: hashedPassword,
+		IsActive: true,
 	}
 
 	// Mock: Get user with password
 	suite.userRepo.On("GetPasswordByUsername", suite.ctx, username).Return([]model.AuthUserModel{existingUser}, nil)
 
+	// Mock: Two-factor authentication is off
+	suite.mfaRepo.On("GetMfa", suite.ctx, username).Return([]model.UserMfaModel{}, nil)
+
 	// Mock: Update last login fails
 	suite.userRepo.On("UpdateLastLogin", suite.ctx, mock.Anything).Return(errors.New("update error"))
 
-	token, err := suite.service.Login(suite.ctx, user)
+	token, err := suite.service.Login(suite.ctx, user, payload.Client{})
 
 	assert.Error(suite.T(), err)
 	assert.Empty(suite.T(), token)
@@ -364,3 +848,1547 @@ func (suite *UserServiceTestSuite) TestGetUser_DatabaseError() {
 	suite.userRepo.AssertExpectations(suite.T())
 }
 
+
+func (suite *UserServiceTestSuite) TestUpdateRole_Success() {
+	actor := "admin"
+	username := "testuser"
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, actor).Return([]model.AuthUserModel{{Username: actor, Role: authorization.RoleAdmin}}, nil)
+	suite.userRepo.On("GetUserByUsername", suite.ctx, username).Return([]model.AuthUserModel{{Username: username, Role: authorization.RoleAuthor}}, nil)
+	suite.userRepo.On("UpdateRole", suite.ctx, username, authorization.RoleEditor).Return(nil)
+
+	result, err := suite.service.UpdateRole(suite.ctx, actor, username, authorization.RoleEditor)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), authorization.RoleEditor, result.User.Role)
+	suite.userRepo.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) TestUpdateRole_NotAdminForbidden() {
+	actor := "editor"
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, actor).Return([]model.AuthUserModel{{Username: actor, Role: authorization.RoleEditor}}, nil)
+
+	result, err := suite.service.UpdateRole(suite.ctx, actor, "testuser", authorization.RoleAdmin)
+
+	assert.ErrorIs(suite.T(), err, authorization.ErrForbidden)
+	assert.Nil(suite.T(), result)
+	suite.userRepo.AssertNotCalled(suite.T(), "UpdateRole", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestUpdateRole_InvalidRole() {
+	actor := "admin"
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, actor).Return([]model.AuthUserModel{{Username: actor, Role: authorization.RoleAdmin}}, nil)
+
+	result, err := suite.service.UpdateRole(suite.ctx, actor, "testuser", "superuser")
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), "invalid role", err.Error())
+}
+
+func (suite *UserServiceTestSuite) liveSession(refreshToken string) model.UserSessionModel {
+	id, _, _ := strings.Cut(refreshToken, ".")
+	return model.UserSessionModel{
+		ID:               strfmt.UUID4(id),
+		Username:         "testuser",
+		RefreshTokenHash: hashToken(refreshToken),
+		AccessJti:        "jti-1",
+		AccessExpiresAt:  time.Now().Add(time.Hour),
+		ExpiresAt:        time.Now().Add(time.Hour),
+	}
+}
+
+func (suite *UserServiceTestSuite) TestRefresh_Success() {
+	refreshToken := "3fa85f64-5717-4562-b3fc-2c963f66afa6.secret"
+	session := suite.liveSession(refreshToken)
+
+	suite.sessionRepo.On("GetSessionById", suite.ctx, session.ID.String()).Return([]model.UserSessionModel{session}, nil)
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "testuser").Return([]model.AuthUserModel{{Username: "testuser", Role: authorization.RoleEditor, IsActive: true}}, nil)
+	suite.sessionRepo.On("RotateSession", suite.ctx, mock.MatchedBy(func(s model.UserSessionModel) bool {
+		return s.ID == session.ID && s.RefreshTokenHash != session.RefreshTokenHash && s.AccessJti != "jti-1" && s.IpAddress == "10.0.0.2"
+	}), session.RefreshTokenHash).Return(true, nil)
+	// Mock: the access token of the previous pair is revoked
+	suite.revocations.On("Revoke", suite.ctx, "jti-1", session.AccessExpiresAt).Return(nil)
+
+	token, err := suite.service.Refresh(suite.ctx, refreshToken, payload.Client{IpAddress: "10.0.0.2"})
+
+	assert.NoError(suite.T(), err)
+	assert.NotEqual(suite.T(), refreshToken, token.RefreshToken)
+	assert.True(suite.T(), strings.HasPrefix(token.RefreshToken, session.ID.String()+"."))
+	suite.sessionRepo.AssertExpectations(suite.T())
+	suite.revocations.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) TestRefresh_ReusedTokenRevokesSession() {
+	refreshToken := "3fa85f64-5717-4562-b3fc-2c963f66afa6.secret"
+	session := suite.liveSession("3fa85f64-5717-4562-b3fc-2c963f66afa6.rotated")
+
+	suite.sessionRepo.On("GetSessionById", suite.ctx, session.ID.String()).Return([]model.UserSessionModel{session}, nil)
+	suite.sessionRepo.On("RevokeSession", suite.ctx, session.ID.String()).Return(nil)
+	suite.revocations.On("Revoke", suite.ctx, "jti-1", session.AccessExpiresAt).Return(nil)
+
+	token, err := suite.service.Refresh(suite.ctx, refreshToken, payload.Client{})
+
+	assert.Nil(suite.T(), token)
+	assert.ErrorIs(suite.T(), err, errInvalidRefreshToken)
+	suite.sessionRepo.AssertNotCalled(suite.T(), "RotateSession", mock.Anything, mock.Anything, mock.Anything)
+	suite.sessionRepo.AssertExpectations(suite.T())
+	suite.revocations.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) TestRefresh_LostRaceRevokesSession() {
+	refreshToken := "3fa85f64-5717-4562-b3fc-2c963f66afa6.secret"
+	session := suite.liveSession(refreshToken)
+
+	suite.sessionRepo.On("GetSessionById", suite.ctx, session.ID.String()).Return([]model.UserSessionModel{session}, nil)
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "testuser").Return([]model.AuthUserModel{{Username: "testuser", IsActive: true}}, nil)
+	suite.sessionRepo.On("RotateSession", suite.ctx, mock.Anything, session.RefreshTokenHash).Return(false, nil)
+	suite.sessionRepo.On("RevokeSession", suite.ctx, session.ID.String()).Return(nil)
+	suite.revocations.On("Revoke", suite.ctx, "jti-1", session.AccessExpiresAt).Return(nil)
+
+	token, err := suite.service.Refresh(suite.ctx, refreshToken, payload.Client{})
+
+	assert.Nil(suite.T(), token)
+	assert.ErrorIs(suite.T(), err, errInvalidRefreshToken)
+	suite.sessionRepo.AssertExpectations(suite.T())
+	suite.revocations.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) TestRefresh_RevokedSession() {
+	refreshToken := "3fa85f64-5717-4562-b3fc-2c963f66afa6.secret"
+	session := suite.liveSession(refreshToken)
+	revokedAt := time.Now()
+	session.RevokedAt = &revokedAt
+
+	suite.sessionRepo.On("GetSessionById", suite.ctx, session.ID.String()).Return([]model.UserSessionModel{session}, nil)
+
+	token, err := suite.service.Refresh(suite.ctx, refreshToken, payload.Client{})
+
+	assert.Nil(suite.T(), token)
+	assert.ErrorIs(suite.T(), err, errInvalidRefreshToken)
+	suite.sessionRepo.AssertNotCalled(suite.T(), "RevokeSession", mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestRefresh_MalformedToken() {
+	token, err := suite.service.Refresh(suite.ctx, "not-a-token", payload.Client{})
+
+	assert.Nil(suite.T(), token)
+	assert.ErrorIs(suite.T(), err, errInvalidRefreshToken)
+	suite.sessionRepo.AssertNotCalled(suite.T(), "GetSessionById", mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestLogout_Success() {
+	refreshToken := "3fa85f64-5717-4562-b3fc-2c963f66afa6.secret"
+	session := suite.liveSession(refreshToken)
+
+	suite.sessionRepo.On("GetSessionById", suite.ctx, session.ID.String()).Return([]model.UserSessionModel{session}, nil)
+	suite.sessionRepo.On("RevokeSession", suite.ctx, session.ID.String()).Return(nil)
+	suite.revocations.On("Revoke", suite.ctx, "jti-1", session.AccessExpiresAt).Return(nil)
+
+	err := suite.service.Logout(suite.ctx, refreshToken)
+
+	assert.NoError(suite.T(), err)
+	suite.sessionRepo.AssertExpectations(suite.T())
+	suite.revocations.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) TestLogout_ExpiredAccessTokenNotRevoked() {
+	refreshToken := "3fa85f64-5717-4562-b3fc-2c963f66afa6.secret"
+	session := suite.liveSession(refreshToken)
+	session.AccessExpiresAt = time.Now().Add(-time.Minute)
+
+	suite.sessionRepo.On("GetSessionById", suite.ctx, session.ID.String()).Return([]model.UserSessionModel{session}, nil)
+	suite.sessionRepo.On("RevokeSession", suite.ctx, session.ID.String()).Return(nil)
+
+	err := suite.service.Logout(suite.ctx, refreshToken)
+
+	assert.NoError(suite.T(), err)
+	suite.revocations.AssertNotCalled(suite.T(), "Revoke", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestPurgeExpiredSessions() {
+	suite.sessionRepo.On("DeleteExpiredSessions", suite.ctx, mock.Anything).Return(int64(2), nil)
+	suite.resetRepo.On("DeleteExpiredPasswordResets", suite.ctx, mock.Anything).Return(int64(1), nil)
+	suite.verifyRepo.On("DeleteExpiredEmailVerifications", suite.ctx, mock.Anything).Return(int64(4), nil)
+	suite.revocations.On("DeleteExpired", suite.ctx, mock.Anything).Return(int64(3), nil)
+	suite.mfaRepo.On("DeleteExpiredMfaChallenges", suite.ctx, mock.Anything).Return(int64(5), nil)
+	suite.tokenRepo.On("DeleteExpiredApiTokens", suite.ctx, mock.Anything).Return(int64(6), nil)
+	suite.oidcRepo.On("DeleteExpiredOidcStates", suite.ctx, mock.Anything).Return(int64(7), nil)
+
+	purged, err := suite.service.PurgeExpiredSessions(suite.ctx)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(28), purged)
+}
+
+func (suite *UserServiceTestSuite) TestGetSessions_MarksCurrent() {
+	sessions := []model.UserSessionModel{
+		{ID: strfmt.UUID4("session-1"), Username: "testuser"},
+		{ID: strfmt.UUID4("session-2"), Username: "testuser"},
+	}
+	suite.sessionRepo.On("GetSessionsByUsername", suite.ctx, "testuser").Return(sessions, nil)
+
+	res, err := suite.service.GetSessions(suite.ctx, "testuser", "session-2")
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), res, 2)
+	assert.False(suite.T(), res[0].Current)
+	assert.True(suite.T(), res[1].Current)
+}
+
+func (suite *UserServiceTestSuite) TestRevokeSession_Success() {
+	session := suite.liveSession("session-1.secret")
+	suite.sessionRepo.On("GetSessionById", suite.ctx, "session-1").Return([]model.UserSessionModel{session}, nil)
+	suite.sessionRepo.On("RevokeSession", suite.ctx, "session-1").Return(nil)
+	suite.revocations.On("Revoke", suite.ctx, "jti-1", session.AccessExpiresAt).Return(nil)
+
+	err := suite.service.RevokeSession(suite.ctx, "testuser", "session-1")
+
+	assert.NoError(suite.T(), err)
+	suite.sessionRepo.AssertExpectations(suite.T())
+	suite.revocations.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) TestRevokeSession_OtherUserNotFound() {
+	session := suite.liveSession("session-1.secret")
+	suite.sessionRepo.On("GetSessionById", suite.ctx, "session-1").Return([]model.UserSessionModel{session}, nil)
+
+	err := suite.service.RevokeSession(suite.ctx, "otheruser", "session-1")
+
+	assert.EqualError(suite.T(), err, "session not found")
+	suite.sessionRepo.AssertNotCalled(suite.T(), "RevokeSession", mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestRevokeUserSessions_Success() {
+	first := suite.liveSession("session-1.secret")
+	second := suite.liveSession("session-2.secret")
+	second.AccessJti = "jti-2"
+
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "admin").Return([]model.AuthUserModel{{Username: "admin", Role: authorization.RoleAdmin}}, nil)
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "testuser").Return([]model.AuthUserModel{{Username: "testuser"}}, nil)
+	suite.sessionRepo.On("GetSessionsByUsername", suite.ctx, "testuser").Return([]model.UserSessionModel{first, second}, nil)
+	suite.sessionRepo.On("RevokeUserSessions", suite.ctx, "testuser", "").Return(nil)
+	suite.revocations.On("Revoke", suite.ctx, "jti-1", first.AccessExpiresAt).Return(nil)
+	suite.revocations.On("Revoke", suite.ctx, "jti-2", second.AccessExpiresAt).Return(nil)
+
+	res, err := suite.service.RevokeUserSessions(suite.ctx, "admin", "testuser")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, res.Revoked)
+	suite.sessionRepo.AssertExpectations(suite.T())
+	suite.revocations.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) TestRevokeUserSessions_NotAdminForbidden() {
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "editor").Return([]model.AuthUserModel{{Username: "editor", Role: authorization.RoleEditor}}, nil)
+
+	res, err := suite.service.RevokeUserSessions(suite.ctx, "editor", "testuser")
+
+	assert.ErrorIs(suite.T(), err, authorization.ErrForbidden)
+	assert.Nil(suite.T(), res)
+	suite.sessionRepo.AssertNotCalled(suite.T(), "RevokeUserSessions", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestChangePassword_Success() {
+	hashedPassword, _ := encrypt.HashPassword("password123")
+	current := suite.liveSession("session-1.secret")
+	other := suite.liveSession("session-2.secret")
+	other.AccessJti = "jti-2"
+
+	suite.userRepo.On("GetPasswordByUsername", suite.ctx, "testuser").Return([]model.AuthUserModel{{Username: "testuser", Password: hashedPassword, IsActive: true}}, nil)
+	suite.userRepo.On("UpdatePassword", suite.ctx, "testuser", mock.MatchedBy(func(hash string) bool {
+		return encrypt.CheckPasswordHash("newpassword", hash)
+	})).Return(nil)
+	suite.resetRepo.On("InvalidatePasswordResets", suite.ctx, "testuser").Return(nil)
+	suite.sessionRepo.On("GetSessionsByUsername", suite.ctx, "testuser").Return([]model.UserSessionModel{current, other}, nil)
+	suite.sessionRepo.On("RevokeUserSessions", suite.ctx, "testuser", "session-1").Return(nil)
+	// Mock: only the access token of the other session is revoked
+	suite.revocations.On("Revoke", suite.ctx, "jti-2", other.AccessExpiresAt).Return(nil)
+
+	err := suite.service.ChangePassword(suite.ctx, "testuser", "session-1", payload.PasswordRequest{
+		CurrentPassword: "password123",
+		NewPassword:     "newpassword",
+	})
+
+	assert.NoError(suite.T(), err)
+	suite.userRepo.AssertExpectations(suite.T())
+	suite.sessionRepo.AssertExpectations(suite.T())
+	suite.revocations.AssertExpectations(suite.T())
+	suite.revocations.AssertNumberOfCalls(suite.T(), "Revoke", 1)
+}
+
+func (suite *UserServiceTestSuite) TestChangePassword_WrongCurrentPassword() {
+	hashedPassword, _ := encrypt.HashPassword("password123")
+	suite.userRepo.On("GetPasswordByUsername", suite.ctx, "testuser").Return([]model.AuthUserModel{{Username: "testuser", Password: hashedPassword, IsActive: true}}, nil)
+
+	err := suite.service.ChangePassword(suite.ctx, "testuser", "session-1", payload.PasswordRequest{
+		CurrentPassword: "wrongpassword",
+		NewPassword:     "newpassword",
+	})
+
+	assert.EqualError(suite.T(), err, "current password is incorrect")
+	suite.userRepo.AssertNotCalled(suite.T(), "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestForgotPassword_SendsMail() {
+	var tokenHash string
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "testuser").Return([]model.AuthUserModel{{Username: "testuser", Email: "test@example.com"}}, nil)
+	suite.resetRepo.On("InvalidatePasswordResets", suite.ctx, "testuser").Return(nil)
+	suite.resetRepo.On("InsertPasswordReset", suite.ctx, mock.MatchedBy(func(r model.PasswordResetModel) bool {
+		tokenHash = r.TokenHash
+		return r.Username == "testuser" && r.ExpiresAt.After(time.Now())
+	})).Return(model.PasswordResetModel{}, nil)
+	suite.mailer.On("Send", suite.ctx, mock.MatchedBy(func(m mailer.Message) bool {
+		_, token, ok := strings.Cut(m.Body, "https://blog.example/reset?token=")
+		token, _, _ = strings.Cut(token, "\n")
+		// the mail carries the token, the database only its hash
+		return m.To == "test@example.com" && ok && hashToken(token) == tokenHash
+	})).Return(nil)
+
+	err := suite.service.ForgotPassword(suite.ctx, "testuser")
+
+	assert.NoError(suite.T(), err)
+	suite.resetRepo.AssertExpectations(suite.T())
+	suite.mailer.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) TestForgotPassword_UnknownUserAnswersTheSame() {
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "nobody").Return([]model.AuthUserModel{}, nil)
+
+	err := suite.service.ForgotPassword(suite.ctx, "nobody")
+
+	assert.NoError(suite.T(), err)
+	suite.resetRepo.AssertNotCalled(suite.T(), "InsertPasswordReset", mock.Anything, mock.Anything)
+	suite.mailer.AssertNotCalled(suite.T(), "Send", mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestForgotPassword_MailErrorIsNotReported() {
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "testuser").Return([]model.AuthUserModel{{Username: "testuser", Email: "test@example.com"}}, nil)
+	suite.resetRepo.On("InvalidatePasswordResets", suite.ctx, "testuser").Return(nil)
+	suite.resetRepo.On("InsertPasswordReset", suite.ctx, mock.Anything).Return(model.PasswordResetModel{}, nil)
+	suite.mailer.On("Send", suite.ctx, mock.Anything).Return(errors.New("connection refused"))
+
+	err := suite.service.ForgotPassword(suite.ctx, "testuser")
+
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *UserServiceTestSuite) TestResetPassword_Success() {
+	suite.resetRepo.On("UsePasswordReset", suite.ctx, hashToken("reset-token")).Return([]model.PasswordResetModel{{Username: "testuser"}}, nil)
+	suite.userRepo.On("UpdatePassword", suite.ctx, "testuser", mock.Anything).Return(nil)
+	suite.resetRepo.On("InvalidatePasswordResets", suite.ctx, "testuser").Return(nil)
+	suite.sessionRepo.On("GetSessionsByUsername", suite.ctx, "testuser").Return([]model.UserSessionModel{}, nil)
+	suite.sessionRepo.On("RevokeUserSessions", suite.ctx, "testuser", "").Return(nil)
+
+	err := suite.service.ResetPassword(suite.ctx, payload.ResetPasswordRequest{
+		Token:       "reset-token",
+		NewPassword: "newpassword",
+	})
+
+	assert.NoError(suite.T(), err)
+	suite.userRepo.AssertExpectations(suite.T())
+	suite.sessionRepo.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) TestResetPassword_InvalidToken() {
+	suite.resetRepo.On("UsePasswordReset", suite.ctx, hashToken("used-token")).Return([]model.PasswordResetModel{}, nil)
+
+	err := suite.service.ResetPassword(suite.ctx, payload.ResetPasswordRequest{
+		Token:       "used-token",
+		NewPassword: "newpassword",
+	})
+
+	assert.ErrorIs(suite.T(), err, errInvalidResetToken)
+	suite.userRepo.AssertNotCalled(suite.T(), "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestRegister_WithEmailSendsVerification() {
+	var tokenHash string
+	suite.userRepo.On("IsUsernameTaken", suite.ctx, "newuser").Return(false, nil)
+	// Mock: a verified date sent by the client is dropped
+	suite.userRepo.On("InsertUser", suite.ctx, mock.MatchedBy(func(u model.AuthUserModel) bool {
+		return u.Email == "new@example.com" && u.EmailVerifiedAt == nil
+	})).Return(model.AuthUserModel{Username: "newuser", Email: "new@example.com"}, nil)
+	suite.verifyRepo.On("InvalidateEmailVerifications", suite.ctx, "newuser").Return(nil)
+	suite.verifyRepo.On("InsertEmailVerification", suite.ctx, mock.MatchedBy(func(v model.EmailVerificationModel) bool {
+		tokenHash = v.TokenHash
+		return v.Username == "newuser" && v.Email == "new@example.com" && v.ExpiresAt.After(time.Now().Add(47*time.Hour))
+	})).Return(model.EmailVerificationModel{}, nil)
+	suite.mailer.On("Send", suite.ctx, mock.MatchedBy(func(m mailer.Message) bool {
+		_, token, ok := strings.Cut(m.Body, "https://blog.example/verify?token=")
+		token, _, _ = strings.Cut(token, "\n")
+		return m.To == "new@example.com" && ok && hashToken(token) == tokenHash
+	})).Return(nil)
+	suite.sessionRepo.On("InsertSession", suite.ctx, mock.Anything).Return(model.UserSessionModel{}, nil)
+
+	verifiedAt := time.Now()
+	token, err := suite.service.Register(suite.ctx, model.AuthUserModel{
+		Username:        "newuser",
+		Password:        "password123",
+		Email:           "new@example.com",
+		EmailVerifiedAt: &verifiedAt,
+	}, payload.Client{})
+
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), token.AccessToken)
+	suite.verifyRepo.AssertExpectations(suite.T())
+	suite.mailer.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) TestRegister_VerificationMailErrorIsNotReported() {
+	suite.userRepo.On("IsUsernameTaken", suite.ctx, "newuser").Return(false, nil)
+	suite.userRepo.On("InsertUser", suite.ctx, mock.Anything).Return(model.AuthUserModel{Username: "newuser", Email: "new@example.com"}, nil)
+	suite.verifyRepo.On("InvalidateEmailVerifications", suite.ctx, "newuser").Return(nil)
+	suite.verifyRepo.On("InsertEmailVerification", suite.ctx, mock.Anything).Return(model.EmailVerificationModel{}, nil)
+	suite.mailer.On("Send", suite.ctx, mock.Anything).Return(errors.New("connection refused"))
+	suite.sessionRepo.On("InsertSession", suite.ctx, mock.Anything).Return(model.UserSessionModel{}, nil)
+
+	token, err := suite.service.Register(suite.ctx, model.AuthUserModel{
+		Username: "newuser",
+		Password: "password123",
+		Email:    "new@example.com",
+	}, payload.Client{})
+
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), token.AccessToken)
+}
+
+func (suite *UserServiceTestSuite) TestVerifyEmail_Success() {
+	suite.verifyRepo.On("UseEmailVerification", suite.ctx, hashToken("verify-token")).Return([]model.EmailVerificationModel{{Username: "testuser", Email: "test@example.com"}}, nil)
+	suite.userRepo.On("VerifyEmail", suite.ctx, "testuser", "test@example.com").Return(true, nil)
+
+	err := suite.service.VerifyEmail(suite.ctx, "verify-token")
+
+	assert.NoError(suite.T(), err)
+	suite.userRepo.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) TestVerifyEmail_InvalidToken() {
+	suite.verifyRepo.On("UseEmailVerification", suite.ctx, hashToken("used-token")).Return([]model.EmailVerificationModel{}, nil)
+
+	err := suite.service.VerifyEmail(suite.ctx, "used-token")
+
+	assert.ErrorIs(suite.T(), err, errInvalidVerificationToken)
+	suite.userRepo.AssertNotCalled(suite.T(), "VerifyEmail", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestVerifyEmail_AddressChangedSinceMail() {
+	suite.verifyRepo.On("UseEmailVerification", suite.ctx, hashToken("verify-token")).Return([]model.EmailVerificationModel{{Username: "testuser", Email: "old@example.com"}}, nil)
+	// Mock: the stored address no longer matches the one of the token
+	suite.userRepo.On("VerifyEmail", suite.ctx, "testuser", "old@example.com").Return(false, nil)
+
+	err := suite.service.VerifyEmail(suite.ctx, "verify-token")
+
+	assert.ErrorIs(suite.T(), err, errInvalidVerificationToken)
+}
+
+func (suite *UserServiceTestSuite) TestChangeEmail_Success() {
+	verifiedAt := time.Now()
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "testuser").Return([]model.AuthUserModel{{Username: "testuser", Email: "old@example.com", EmailVerifiedAt: &verifiedAt}}, nil)
+	suite.userRepo.On("UpdateEmail", suite.ctx, "testuser", "new@example.com").Return(nil)
+	suite.verifyRepo.On("InvalidateEmailVerifications", suite.ctx, "testuser").Return(nil)
+	suite.verifyRepo.On("InsertEmailVerification", suite.ctx, mock.MatchedBy(func(v model.EmailVerificationModel) bool {
+		return v.Username == "testuser" && v.Email == "new@example.com"
+	})).Return(model.EmailVerificationModel{}, nil)
+	suite.mailer.On("Send", suite.ctx, mock.MatchedBy(func(m mailer.Message) bool {
+		return m.To == "new@example.com"
+	})).Return(nil)
+
+	result, err := suite.service.ChangeEmail(suite.ctx, "testuser", "new@example.com")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "new@example.com", result.User.Email)
+	assert.False(suite.T(), result.User.EmailVerified())
+	suite.userRepo.AssertExpectations(suite.T())
+	suite.verifyRepo.AssertExpectations(suite.T())
+	suite.mailer.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) TestChangeEmail_MailErrorIsReported() {
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "testuser").Return([]model.AuthUserModel{{Username: "testuser"}}, nil)
+	suite.userRepo.On("UpdateEmail", suite.ctx, "testuser", "new@example.com").Return(nil)
+	suite.verifyRepo.On("InvalidateEmailVerifications", suite.ctx, "testuser").Return(nil)
+	suite.verifyRepo.On("InsertEmailVerification", suite.ctx, mock.Anything).Return(model.EmailVerificationModel{}, nil)
+	suite.mailer.On("Send", suite.ctx, mock.Anything).Return(errors.New("connection refused"))
+
+	result, err := suite.service.ChangeEmail(suite.ctx, "testuser", "new@example.com")
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+}
+
+// fail counts failures of key as if they happened at failedAt
+func (suite *UserServiceTestSuite) fail(key string, failures int, failedAt time.Time) {
+	for i := 0; i < failures; i++ {
+		_, err := suite.attempts.AddLoginFailure(suite.ctx, key, failedAt, failedAt.Add(-24*time.Hour))
+		assert.NoError(suite.T(), err)
+	}
+}
+
+func (suite *UserServiceTestSuite) TestLogin_BackoffAfterFreeAttempts() {
+	client := payload.Client{IpAddress: "10.0.0.1"}
+	suite.userRepo.On("GetPasswordByUsername", suite.ctx, "testuser").Return([]model.AuthUserModel{}, nil)
+
+	// the free attempts answer as usual
+	for i := 0; i < 4; i++ {
+		_, err := suite.service.Login(suite.ctx, model.AuthUserModel{Username: "testuser", Password: "guess"}, client)
+		assert.EqualError(suite.T(), err, "incorrect username or password")
+	}
+
+	// the fourth failure makes the next login wait, the password is not checked then
+	_, err := suite.service.Login(suite.ctx, model.AuthUserModel{Username: "testuser", Password: "guess"}, client)
+
+	var appErr *apperror.Error
+	assert.ErrorAs(suite.T(), err, &appErr)
+	assert.Equal(suite.T(), http.StatusTooManyRequests, appErr.Code)
+	suite.userRepo.AssertNumberOfCalls(suite.T(), "GetPasswordByUsername", 4)
+}
+
+func (suite *UserServiceTestSuite) TestLogin_BackoffRunsOut() {
+	suite.service.options.Throttle.User.LockoutAfter = 10
+	// Mock: the fourth failure is older than its one second wait
+	suite.fail("user:testuser", 4, time.Now().Add(-2*time.Second))
+	suite.userRepo.On("GetPasswordByUsername", suite.ctx, "testuser").Return([]model.AuthUserModel{}, nil)
+
+	_, err := suite.service.Login(suite.ctx, model.AuthUserModel{Username: "testuser", Password: "guess"}, payload.Client{})
+
+	assert.EqualError(suite.T(), err, "incorrect username or password")
+}
+
+func (suite *UserServiceTestSuite) TestLogin_LockoutNotifies() {
+	suite.fail("user:testuser", 4, time.Now().Add(-time.Hour))
+	suite.userRepo.On("GetPasswordByUsername", suite.ctx, "testuser").Return([]model.AuthUserModel{}, nil)
+	suite.notifier.On("NotifyLockout", suite.ctx, mock.MatchedBy(func(l payload.Lockout) bool {
+		return l.Username == "testuser" && l.IpAddress == "" && l.Failures == 5 && l.Until.After(time.Now().Add(59*time.Minute))
+	})).Return().Once()
+
+	_, err := suite.service.Login(suite.ctx, model.AuthUserModel{Username: "testuser", Password: "guess"}, payload.Client{IpAddress: "10.0.0.1"})
+	assert.EqualError(suite.T(), err, "incorrect username or password")
+
+	_, err = suite.service.Login(suite.ctx, model.AuthUserModel{Username: "testuser", Password: "guess"}, payload.Client{IpAddress: "10.0.0.2"})
+	assert.ErrorContains(suite.T(), err, "too many failed logins")
+	suite.notifier.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) TestLogin_AddressThrottledAcrossUsers() {
+	suite.fail("ip:10.0.0.1", 20, time.Now())
+
+	_, err := suite.service.Login(suite.ctx, model.AuthUserModel{Username: "otheruser", Password: "password123"}, payload.Client{IpAddress: "10.0.0.1"})
+
+	assert.ErrorContains(suite.T(), err, "too many failed logins")
+	suite.userRepo.AssertNotCalled(suite.T(), "GetPasswordByUsername", mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestLogin_SuccessClearsUserFailures() {
+	hashedPassword, _ := encrypt.HashPassword("password123")
+	suite.fail("user:testuser", 2, time.Now())
+	suite.fail("ip:10.0.0.1", 2, time.Now())
+	suite.userRepo.On("GetPasswordByUsername", suite.ctx, "testuser").Return([]model.AuthUserModel{{Username: "testuser", Password: hashedPassword, IsActive: true}}, nil)
+	suite.mfaRepo.On("GetMfa", suite.ctx, "testuser").Return([]model.UserMfaModel{}, nil)
+	suite.userRepo.On("UpdateLastLogin", suite.ctx, mock.Anything).Return(nil)
+	suite.sessionRepo.On("InsertSession", suite.ctx, mock.Anything).Return(model.UserSessionModel{}, nil)
+
+	_, err := suite.service.Login(suite.ctx, model.AuthUserModel{Username: "testuser", Password: "password123"}, payload.Client{IpAddress: "10.0.0.1"})
+	assert.NoError(suite.T(), err)
+
+	// the address keeps its failures, a guesser could otherwise reset them with an account of their own
+	attempts, err := suite.attempts.GetLoginAttempts(suite.ctx, []string{"user:testuser", "ip:10.0.0.1"})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), attempts, 1)
+	assert.Equal(suite.T(), "ip:10.0.0.1", attempts[0].Key)
+}
+
+func (suite *UserServiceTestSuite) TestThrottleWait() {
+	throttle := suite.service.options.Throttle
+	limit := throttle.User
+
+	assert.Equal(suite.T(), time.Duration(0), throttle.wait(limit, 3))
+	assert.Equal(suite.T(), time.Second, throttle.wait(limit, 4))
+	assert.Equal(suite.T(), time.Hour, throttle.wait(limit, 5))
+	assert.Equal(suite.T(), time.Hour, throttle.wait(limit, 6))
+
+	// without a lockout the wait keeps doubling up to the maximum
+	limit.LockoutAfter = 0
+	assert.Equal(suite.T(), 4*time.Second, throttle.wait(limit, 6))
+	assert.Equal(suite.T(), time.Minute, throttle.wait(limit, 100))
+}
+
+func (suite *UserServiceTestSuite) TestUnlockUser_Success() {
+	suite.fail("user:testuser", 5, time.Now())
+	suite.fail("ip:10.0.0.1", 5, time.Now())
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "admin").Return([]model.AuthUserModel{{Username: "admin", Role: authorization.RoleAdmin}}, nil)
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "testuser").Return([]model.AuthUserModel{{Username: "testuser"}}, nil)
+
+	err := suite.service.UnlockUser(suite.ctx, "admin", "testuser")
+
+	assert.NoError(suite.T(), err)
+	attempts, _ := suite.attempts.GetLoginAttempts(suite.ctx, []string{"user:testuser", "ip:10.0.0.1"})
+	assert.Len(suite.T(), attempts, 1)
+}
+
+func (suite *UserServiceTestSuite) TestUnlockUser_NotAdminForbidden() {
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "editor").Return([]model.AuthUserModel{{Username: "editor", Role: authorization.RoleEditor}}, nil)
+
+	err := suite.service.UnlockUser(suite.ctx, "editor", "testuser")
+
+	assert.ErrorIs(suite.T(), err, authorization.ErrForbidden)
+}
+
+func (suite *UserServiceTestSuite) TestResetPassword_LiftsLockout() {
+	suite.fail("user:testuser", 5, time.Now())
+	suite.resetRepo.On("UsePasswordReset", suite.ctx, hashToken("reset-token")).Return([]model.PasswordResetModel{{Username: "testuser"}}, nil)
+	suite.userRepo.On("UpdatePassword", suite.ctx, "testuser", mock.Anything).Return(nil)
+	suite.resetRepo.On("InvalidatePasswordResets", suite.ctx, "testuser").Return(nil)
+	suite.sessionRepo.On("GetSessionsByUsername", suite.ctx, "testuser").Return([]model.UserSessionModel{}, nil)
+	suite.sessionRepo.On("RevokeUserSessions", suite.ctx, "testuser", "").Return(nil)
+
+	err := suite.service.ResetPassword(suite.ctx, payload.ResetPasswordRequest{Token: "reset-token", NewPassword: "newpassword"})
+
+	assert.NoError(suite.T(), err)
+	attempts, _ := suite.attempts.GetLoginAttempts(suite.ctx, []string{"user:testuser"})
+	assert.Empty(suite.T(), attempts)
+}
+
+func (suite *UserServiceTestSuite) TestLockoutMailer_MailsUser() {
+	notifier := NewLockoutMailer(suite.userRepo, suite.mailer)
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "testuser").Return([]model.AuthUserModel{{Username: "testuser", Email: "test@example.com"}}, nil)
+	suite.mailer.On("Send", suite.ctx, mock.MatchedBy(func(m mailer.Message) bool {
+		return m.To == "test@example.com" && strings.Contains(m.Body, "after 5 failed logins")
+	})).Return(nil)
+
+	notifier.NotifyLockout(suite.ctx, payload.Lockout{Username: "testuser", Failures: 5, Until: time.Now().Add(time.Hour)})
+	// an address has nobody to mail
+	notifier.NotifyLockout(suite.ctx, payload.Lockout{IpAddress: "10.0.0.1", Failures: 20, Until: time.Now().Add(time.Hour)})
+
+	suite.mailer.AssertNumberOfCalls(suite.T(), "Send", 1)
+}
+
+func (suite *UserServiceTestSuite) TestRegister_ReservedUsername() {
+	token, err := suite.service.Register(suite.ctx, model.AuthUserModel{Username: model.DeletedUsername, Password: "password123"}, payload.Client{})
+
+	assert.Nil(suite.T(), token)
+	assert.Equal(suite.T(), "username is reserved", err.Error())
+	suite.userRepo.AssertNotCalled(suite.T(), "InsertUser", mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestRegister_DropsAccountStatus() {
+	deleteAfter := time.Now()
+	suite.userRepo.On("IsUsernameTaken", suite.ctx, "newuser").Return(false, nil)
+	suite.userRepo.On("InsertUser", suite.ctx, mock.MatchedBy(func(u model.AuthUserModel) bool {
+		return u.IsActive && u.SuspendedReason == "" && u.DeleteAfter == nil
+	})).Return(model.AuthUserModel{Username: "newuser"}, nil)
+	suite.sessionRepo.On("InsertSession", suite.ctx, mock.Anything).Return(model.UserSessionModel{}, nil)
+
+	_, err := suite.service.Register(suite.ctx, model.AuthUserModel{
+		Username:        "newuser",
+		Password:        "password123",
+		IsActive:        false,
+		SuspendedReason: "spam",
+		DeleteAfter:     &deleteAfter,
+	}, payload.Client{})
+
+	assert.NoError(suite.T(), err)
+	suite.userRepo.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) TestLogin_SuspendedUser() {
+	hashedPassword, _ := encrypt.HashPassword("password123")
+	until := time.Now().Add(time.Hour)
+	suite.userRepo.On("GetPasswordByUsername", suite.ctx, "testuser").Return([]model.AuthUserModel{{Username: "testuser", Password: hashedPassword, SuspendedReason: "spam", SuspendedUntil: &until}}, nil)
+
+	token, err := suite.service.Login(suite.ctx, model.AuthUserModel{Username: "testuser", Password: "password123"}, payload.Client{})
+
+	assert.Nil(suite.T(), token)
+	var appErr *apperror.Error
+	assert.ErrorAs(suite.T(), err, &appErr)
+	assert.Equal(suite.T(), http.StatusForbidden, appErr.Code)
+	assert.Contains(suite.T(), err.Error(), "account is suspended: spam, until ")
+	suite.userRepo.AssertNotCalled(suite.T(), "UpdateLastLogin", mock.Anything, mock.Anything)
+	suite.sessionRepo.AssertNotCalled(suite.T(), "InsertSession", mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestLogin_SuspendedUserWrongPasswordFails() {
+	hashedPassword, _ := encrypt.HashPassword("password123")
+	suite.userRepo.On("GetPasswordByUsername", suite.ctx, "testuser").Return([]model.AuthUserModel{{Username: "testuser", Password: hashedPassword, SuspendedReason: "spam"}}, nil)
+
+	_, err := suite.service.Login(suite.ctx, model.AuthUserModel{Username: "testuser", Password: "wrongpassword"}, payload.Client{})
+
+	// the suspension is not told to whoever does not know the password
+	assert.Equal(suite.T(), "incorrect username or password", err.Error())
+}
+
+func (suite *UserServiceTestSuite) TestLogin_ExpiredSuspensionReactivates() {
+	hashedPassword, _ := encrypt.HashPassword("password123")
+	until := time.Now().Add(-time.Minute)
+	suite.userRepo.On("GetPasswordByUsername", suite.ctx, "testuser").Return([]model.AuthUserModel{{Username: "testuser", Password: hashedPassword, SuspendedReason: "spam", SuspendedUntil: &until}}, nil)
+	suite.mfaRepo.On("GetMfa", suite.ctx, "testuser").Return([]model.UserMfaModel{}, nil)
+	suite.userRepo.On("ReactivateUser", suite.ctx, "testuser").Return(nil)
+	suite.userRepo.On("UpdateLastLogin", suite.ctx, mock.Anything).Return(nil)
+	suite.sessionRepo.On("InsertSession", suite.ctx, mock.Anything).Return(model.UserSessionModel{}, nil)
+
+	token, err := suite.service.Login(suite.ctx, model.AuthUserModel{Username: "testuser", Password: "password123"}, payload.Client{})
+
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), token.AccessToken)
+	suite.userRepo.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) TestLogin_CancelsPendingDeletion() {
+	hashedPassword, _ := encrypt.HashPassword("password123")
+	deleteAfter := time.Now().Add(24 * time.Hour)
+	suite.userRepo.On("GetPasswordByUsername", suite.ctx, "testuser").Return([]model.AuthUserModel{{Username: "testuser", Password: hashedPassword, IsActive: true, DeleteAfter: &deleteAfter}}, nil)
+	suite.mfaRepo.On("GetMfa", suite.ctx, "testuser").Return([]model.UserMfaModel{}, nil)
+	suite.userRepo.On("ScheduleDeletion", suite.ctx, "testuser", (*time.Time)(nil)).Return(nil)
+	suite.userRepo.On("UpdateLastLogin", suite.ctx, mock.Anything).Return(nil)
+	suite.sessionRepo.On("InsertSession", suite.ctx, mock.Anything).Return(model.UserSessionModel{}, nil)
+
+	token, err := suite.service.Login(suite.ctx, model.AuthUserModel{Username: "testuser", Password: "password123"}, payload.Client{})
+
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), token.AccessToken)
+	suite.userRepo.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) TestRefresh_SuspendedUser() {
+	refreshToken := "3fa85f64-5717-4562-b3fc-2c963f66afa6.secret"
+	session := suite.liveSession(refreshToken)
+
+	suite.sessionRepo.On("GetSessionById", suite.ctx, session.ID.String()).Return([]model.UserSessionModel{session}, nil)
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "testuser").Return([]model.AuthUserModel{{Username: "testuser", SuspendedReason: "spam"}}, nil)
+
+	token, err := suite.service.Refresh(suite.ctx, refreshToken, payload.Client{})
+
+	assert.Nil(suite.T(), token)
+	assert.Equal(suite.T(), "account is suspended: spam", err.Error())
+	suite.sessionRepo.AssertNotCalled(suite.T(), "RotateSession", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestSuspendUser_Success() {
+	until := time.Now().Add(24 * time.Hour)
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "admin").Return([]model.AuthUserModel{{Username: "admin", Role: authorization.RoleAdmin}}, nil)
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "testuser").Return([]model.AuthUserModel{{Username: "testuser", IsActive: true}}, nil)
+	suite.userRepo.On("SuspendUser", suite.ctx, "testuser", "spam", &until).Return(nil)
+	// Mock: the user is signed out everywhere
+	suite.sessionRepo.On("GetSessionsByUsername", suite.ctx, "testuser").Return([]model.UserSessionModel{{ID: strfmt.UUID4("session-1"), AccessJti: "jti-1", AccessExpiresAt: until}}, nil)
+	suite.sessionRepo.On("RevokeUserSessions", suite.ctx, "testuser", "").Return(nil)
+	suite.revocations.On("Revoke", suite.ctx, "jti-1", until).Return(nil)
+
+	result, err := suite.service.SuspendUser(suite.ctx, "admin", "testuser", payload.SuspendRequest{Reason: "spam", Until: &until})
+
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), result.User.IsActive)
+	assert.Equal(suite.T(), "spam", result.User.SuspendedReason)
+	suite.userRepo.AssertExpectations(suite.T())
+	suite.sessionRepo.AssertExpectations(suite.T())
+	suite.revocations.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) TestSuspendUser_NotAdminForbidden() {
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "editor").Return([]model.AuthUserModel{{Username: "editor", Role: authorization.RoleEditor}}, nil)
+
+	result, err := suite.service.SuspendUser(suite.ctx, "editor", "testuser", payload.SuspendRequest{Reason: "spam"})
+
+	assert.ErrorIs(suite.T(), err, authorization.ErrForbidden)
+	assert.Nil(suite.T(), result)
+	suite.userRepo.AssertNotCalled(suite.T(), "SuspendUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestSuspendUser_Self() {
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "admin").Return([]model.AuthUserModel{{Username: "admin", Role: authorization.RoleAdmin}}, nil)
+
+	_, err := suite.service.SuspendUser(suite.ctx, "admin", "admin", payload.SuspendRequest{Reason: "spam"})
+
+	assert.Equal(suite.T(), "you can not suspend yourself", err.Error())
+}
+
+func (suite *UserServiceTestSuite) TestSuspendUser_UntilInThePast() {
+	until := time.Now().Add(-time.Hour)
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "admin").Return([]model.AuthUserModel{{Username: "admin", Role: authorization.RoleAdmin}}, nil)
+
+	_, err := suite.service.SuspendUser(suite.ctx, "admin", "testuser", payload.SuspendRequest{Reason: "spam", Until: &until})
+
+	assert.Equal(suite.T(), "until has to be in the future", err.Error())
+	suite.userRepo.AssertNotCalled(suite.T(), "SuspendUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestReactivateUser_Success() {
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "admin").Return([]model.AuthUserModel{{Username: "admin", Role: authorization.RoleAdmin}}, nil)
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "testuser").Return([]model.AuthUserModel{{Username: "testuser", SuspendedReason: "spam"}}, nil)
+	suite.userRepo.On("ReactivateUser", suite.ctx, "testuser").Return(nil)
+
+	result, err := suite.service.ReactivateUser(suite.ctx, "admin", "testuser")
+
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), result.User.IsActive)
+	assert.Empty(suite.T(), result.User.SuspendedReason)
+	suite.userRepo.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) TestReactivateUser_NotFound() {
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "admin").Return([]model.AuthUserModel{{Username: "admin", Role: authorization.RoleAdmin}}, nil)
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "ghost").Return([]model.AuthUserModel{}, nil)
+
+	result, err := suite.service.ReactivateUser(suite.ctx, "admin", "ghost")
+
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), "user not found", err.Error())
+}
+
+func (suite *UserServiceTestSuite) TestDeleteAccount_Success() {
+	hashedPassword, _ := encrypt.HashPassword("password123")
+	suite.userRepo.On("GetPasswordByUsername", suite.ctx, "testuser").Return([]model.AuthUserModel{{Username: "testuser", Password: hashedPassword, Email: "test@example.com", IsActive: true}}, nil)
+	suite.userRepo.On("ScheduleDeletion", suite.ctx, "testuser", mock.MatchedBy(func(deleteAfter *time.Time) bool {
+		return deleteAfter != nil && deleteAfter.After(time.Now().Add(29*24*time.Hour))
+	})).Return(nil)
+	suite.sessionRepo.On("GetSessionsByUsername", suite.ctx, "testuser").Return([]model.UserSessionModel{}, nil)
+	suite.sessionRepo.On("RevokeUserSessions", suite.ctx, "testuser", "").Return(nil)
+	suite.mailer.On("Send", suite.ctx, mock.MatchedBy(func(m mailer.Message) bool {
+		return m.To == "test@example.com" && strings.Contains(m.Body, "Log in before then")
+	})).Return(nil)
+
+	result, err := suite.service.DeleteAccount(suite.ctx, "testuser", payload.DeleteAccountRequest{Password: "password123"})
+
+	assert.NoError(suite.T(), err)
+	assert.WithinDuration(suite.T(), time.Now().Add(30*24*time.Hour), result.DeleteAfter, time.Minute)
+	suite.userRepo.AssertExpectations(suite.T())
+	suite.sessionRepo.AssertExpectations(suite.T())
+	suite.mailer.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) TestDeleteAccount_IncorrectPassword() {
+	hashedPassword, _ := encrypt.HashPassword("password123")
+	suite.userRepo.On("GetPasswordByUsername", suite.ctx, "testuser").Return([]model.AuthUserModel{{Username: "testuser", Password: hashedPassword, IsActive: true}}, nil)
+
+	result, err := suite.service.DeleteAccount(suite.ctx, "testuser", payload.DeleteAccountRequest{Password: "wrongpassword"})
+
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), "password is incorrect", err.Error())
+	suite.userRepo.AssertNotCalled(suite.T(), "ScheduleDeletion", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) expectAccountDeleted(username string) {
+	suite.sessionRepo.On("GetSessionsByUsername", suite.ctx, username).Return([]model.UserSessionModel{}, nil)
+	suite.sessionRepo.On("RevokeUserSessions", suite.ctx, username, "").Return(nil)
+	suite.tokenRepo.On("RevokeUserApiTokens", suite.ctx, username).Return(int64(1), nil)
+	suite.resetRepo.On("InvalidatePasswordResets", suite.ctx, username).Return(nil)
+	suite.verifyRepo.On("InvalidateEmailVerifications", suite.ctx, username).Return(nil)
+	suite.mfaRepo.On("DeleteMfa", suite.ctx, username).Return(nil)
+	suite.oidcRepo.On("DeleteUserIdentities", suite.ctx, username).Return(nil)
+	suite.userRepo.On("DeleteUser", suite.ctx, username).Return(nil)
+}
+
+func (suite *UserServiceTestSuite) TestDeleteDueAccounts_AnonymizesContent() {
+	suite.fail("user:testuser", 2, time.Now())
+	suite.userRepo.On("GetUsersDueForDeletion", suite.ctx, mock.Anything, deletionBatch).Return([]model.AuthUserModel{{Username: "testuser"}}, nil)
+	suite.content.On("AnonymizeUserContent", suite.ctx, "testuser", model.DeletedUsername).Return(3, nil)
+	suite.expectAccountDeleted("testuser")
+
+	deleted, err := suite.service.DeleteDueAccounts(suite.ctx)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, deleted)
+	suite.content.AssertNotCalled(suite.T(), "DeleteUserContent", mock.Anything, mock.Anything)
+	suite.userRepo.AssertExpectations(suite.T())
+	attempts, _ := suite.attempts.GetLoginAttempts(suite.ctx, []string{"user:testuser"})
+	assert.Empty(suite.T(), attempts)
+}
+
+func (suite *UserServiceTestSuite) TestDeleteDueAccounts_DeletesContent() {
+	suite.service.options.DeletionContent = ContentDelete
+	suite.userRepo.On("GetUsersDueForDeletion", suite.ctx, mock.Anything, deletionBatch).Return([]model.AuthUserModel{{Username: "testuser"}}, nil)
+	suite.content.On("DeleteUserContent", suite.ctx, "testuser").Return(3, nil)
+	suite.expectAccountDeleted("testuser")
+
+	deleted, err := suite.service.DeleteDueAccounts(suite.ctx)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, deleted)
+	suite.content.AssertNotCalled(suite.T(), "AnonymizeUserContent", mock.Anything, mock.Anything, mock.Anything)
+	suite.userRepo.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) TestDeleteDueAccounts_ContentErrorKeepsUser() {
+	suite.userRepo.On("GetUsersDueForDeletion", suite.ctx, mock.Anything, deletionBatch).Return([]model.AuthUserModel{{Username: "testuser"}, {Username: "other"}}, nil)
+	suite.content.On("AnonymizeUserContent", suite.ctx, "testuser", model.DeletedUsername).Return(0, errors.New("database error"))
+
+	deleted, err := suite.service.DeleteDueAccounts(suite.ctx)
+
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), 0, deleted)
+	// the next run tries again
+	suite.userRepo.AssertNotCalled(suite.T(), "DeleteUser", mock.Anything, mock.Anything)
+}
+
+// enabledMfa is the confirmed two-factor authentication of testuser
+func (suite *UserServiceTestSuite) enabledMfa(secret string) []model.UserMfaModel {
+	confirmedAt := time.Now().Add(-time.Hour)
+	return []model.UserMfaModel{{Username: "testuser", Secret: secret, ConfirmedAt: &confirmedAt}}
+}
+
+// mfaLogin is a login of testuser waiting for the code of token
+func (suite *UserServiceTestSuite) mfaLogin(token string, secret string) {
+	suite.mfaRepo.On("GetMfaChallenge", suite.ctx, hashToken(token)).Return([]model.MfaChallengeModel{{Username: "testuser", TokenHash: hashToken(token), ExpiresAt: time.Now().Add(time.Minute)}}, nil)
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "testuser").Return([]model.AuthUserModel{{Username: "testuser", IsActive: true}}, nil)
+	suite.mfaRepo.On("GetMfa", suite.ctx, "testuser").Return(suite.enabledMfa(secret), nil)
+}
+
+func (suite *UserServiceTestSuite) TestLogin_MfaEnabledAnswersWithMfaToken() {
+	hashedPassword, _ := encrypt.HashPassword("password123")
+	suite.fail("user:testuser", 2, time.Now())
+	suite.userRepo.On("GetPasswordByUsername", suite.ctx, "testuser").Return([]model.AuthUserModel{{Username: "testuser", Password: hashedPassword, IsActive: true}}, nil)
+	suite.mfaRepo.On("GetMfa", suite.ctx, "testuser").Return(suite.enabledMfa("JBSWY3DPEHPK3PXP"), nil)
+	var challenge model.MfaChallengeModel
+	suite.mfaRepo.On("InsertMfaChallenge", suite.ctx, mock.Anything).Run(func(args mock.Arguments) {
+		challenge = args.Get(1).(model.MfaChallengeModel)
+	}).Return(model.MfaChallengeModel{}, nil)
+
+	token, err := suite.service.Login(suite.ctx, model.AuthUserModel{Username: "testuser", Password: "password123"}, payload.Client{})
+
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), token.MfaRequired)
+	assert.Empty(suite.T(), token.AccessToken)
+	assert.Empty(suite.T(), token.RefreshToken)
+	assert.Equal(suite.T(), int64(300), token.ExpiresIn)
+	assert.Equal(suite.T(), hashToken(token.MfaToken), challenge.TokenHash)
+	assert.WithinDuration(suite.T(), time.Now().Add(5*time.Minute), challenge.ExpiresAt, time.Minute)
+	suite.userRepo.AssertNotCalled(suite.T(), "UpdateLastLogin", mock.Anything, mock.Anything)
+	suite.sessionRepo.AssertNotCalled(suite.T(), "InsertSession", mock.Anything, mock.Anything)
+	// the failures stay until the code matched as well
+	attempts, _ := suite.attempts.GetLoginAttempts(suite.ctx, []string{"user:testuser"})
+	assert.Len(suite.T(), attempts, 1)
+}
+
+func (suite *UserServiceTestSuite) TestVerifyMfa_TotpCode() {
+	secret, _ := totp.NewSecret()
+	code, _ := totp.Code(secret, totp.Step(time.Now()))
+	suite.fail("user:testuser", 2, time.Now())
+	suite.mfaLogin("mfa-token", secret)
+	suite.mfaRepo.On("UseMfaStep", suite.ctx, "testuser", mock.Anything).Return(true, nil)
+	suite.mfaRepo.On("UseMfaChallenge", suite.ctx, hashToken("mfa-token")).Return(true, nil)
+	suite.userRepo.On("UpdateLastLogin", suite.ctx, mock.Anything).Return(nil)
+	suite.sessionRepo.On("InsertSession", suite.ctx, mock.Anything).Return(model.UserSessionModel{}, nil)
+
+	token, err := suite.service.VerifyMfa(suite.ctx, payload.MfaLoginRequest{MfaToken: "mfa-token", Code: code}, payload.Client{})
+
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), token.AccessToken)
+	assert.False(suite.T(), token.MfaRequired)
+	suite.mfaRepo.AssertNotCalled(suite.T(), "UseRecoveryCode", mock.Anything, mock.Anything, mock.Anything)
+	attempts, _ := suite.attempts.GetLoginAttempts(suite.ctx, []string{"user:testuser"})
+	assert.Empty(suite.T(), attempts)
+}
+
+func (suite *UserServiceTestSuite) TestVerifyMfa_RecoveryCode() {
+	suite.mfaLogin("mfa-token", "JBSWY3DPEHPK3PXP")
+	suite.mfaRepo.On("UseRecoveryCode", suite.ctx, "testuser", hashToken("abcde12345")).Return(true, nil)
+	suite.mfaRepo.On("UseMfaChallenge", suite.ctx, hashToken("mfa-token")).Return(true, nil)
+	suite.userRepo.On("UpdateLastLogin", suite.ctx, mock.Anything).Return(nil)
+	suite.sessionRepo.On("InsertSession", suite.ctx, mock.Anything).Return(model.UserSessionModel{}, nil)
+
+	token, err := suite.service.VerifyMfa(suite.ctx, payload.MfaLoginRequest{MfaToken: "mfa-token", Code: "ABCDE-12345"}, payload.Client{})
+
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), token.AccessToken)
+	suite.mfaRepo.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) TestVerifyMfa_WrongCodeCountsAsFailure() {
+	suite.mfaLogin("mfa-token", "JBSWY3DPEHPK3PXP")
+	suite.mfaRepo.On("UseRecoveryCode", suite.ctx, "testuser", mock.Anything).Return(false, nil)
+
+	token, err := suite.service.VerifyMfa(suite.ctx, payload.MfaLoginRequest{MfaToken: "mfa-token", Code: "wrong-guess"}, payload.Client{IpAddress: "10.0.0.1"})
+
+	assert.Nil(suite.T(), token)
+	assert.Equal(suite.T(), errInvalidMfaCode, err)
+	suite.mfaRepo.AssertNotCalled(suite.T(), "UseMfaChallenge", mock.Anything, mock.Anything)
+	attempts, _ := suite.attempts.GetLoginAttempts(suite.ctx, []string{"user:testuser", "ip:10.0.0.1"})
+	assert.Len(suite.T(), attempts, 2)
+}
+
+func (suite *UserServiceTestSuite) TestVerifyMfa_ReusedCode() {
+	secret, _ := totp.NewSecret()
+	code, _ := totp.Code(secret, totp.Step(time.Now()))
+	suite.mfaLogin("mfa-token", secret)
+	// Mock: the code of this step was accepted before
+	suite.mfaRepo.On("UseMfaStep", suite.ctx, "testuser", mock.Anything).Return(false, nil)
+
+	_, err := suite.service.VerifyMfa(suite.ctx, payload.MfaLoginRequest{MfaToken: "mfa-token", Code: code}, payload.Client{})
+
+	assert.Equal(suite.T(), errInvalidMfaCode, err)
+	suite.sessionRepo.AssertNotCalled(suite.T(), "InsertSession", mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestVerifyMfa_InvalidToken() {
+	suite.mfaRepo.On("GetMfaChallenge", suite.ctx, hashToken("expired")).Return([]model.MfaChallengeModel{}, nil)
+
+	_, err := suite.service.VerifyMfa(suite.ctx, payload.MfaLoginRequest{MfaToken: "expired", Code: "123456"}, payload.Client{})
+
+	var appErr *apperror.Error
+	assert.ErrorAs(suite.T(), err, &appErr)
+	assert.Equal(suite.T(), http.StatusUnauthorized, appErr.Code)
+}
+
+func (suite *UserServiceTestSuite) TestEnrollMfa_Success() {
+	suite.mfaRepo.On("GetMfa", suite.ctx, "testuser").Return([]model.UserMfaModel{}, nil)
+	var saved model.UserMfaModel
+	suite.mfaRepo.On("SaveMfa", suite.ctx, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(model.UserMfaModel)
+	}).Return(nil)
+
+	res, err := suite.service.EnrollMfa(suite.ctx, "testuser")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), res.Secret, saved.Secret)
+	assert.Nil(suite.T(), saved.ConfirmedAt)
+	assert.True(suite.T(), strings.HasPrefix(res.OtpauthURI, "otpauth://totp/Blog:testuser?"))
+	assert.Contains(suite.T(), res.OtpauthURI, "secret="+res.Secret)
+}
+
+func (suite *UserServiceTestSuite) TestEnrollMfa_AlreadyEnabled() {
+	suite.mfaRepo.On("GetMfa", suite.ctx, "testuser").Return(suite.enabledMfa("JBSWY3DPEHPK3PXP"), nil)
+
+	res, err := suite.service.EnrollMfa(suite.ctx, "testuser")
+
+	assert.Nil(suite.T(), res)
+	var appErr *apperror.Error
+	assert.ErrorAs(suite.T(), err, &appErr)
+	assert.Equal(suite.T(), http.StatusConflict, appErr.Code)
+	suite.mfaRepo.AssertNotCalled(suite.T(), "SaveMfa", mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestConfirmMfa_Success() {
+	secret, _ := totp.NewSecret()
+	step := totp.Step(time.Now())
+	code, _ := totp.Code(secret, step)
+	suite.mfaRepo.On("GetMfa", suite.ctx, "testuser").Return([]model.UserMfaModel{{Username: "testuser", Secret: secret}}, nil)
+	suite.mfaRepo.On("ConfirmMfa", suite.ctx, "testuser", step).Return(true, nil)
+	var codes []model.RecoveryCodeModel
+	suite.mfaRepo.On("ReplaceRecoveryCodes", suite.ctx, "testuser", mock.Anything).Run(func(args mock.Arguments) {
+		codes = args.Get(2).([]model.RecoveryCodeModel)
+	}).Return(nil)
+
+	res, err := suite.service.ConfirmMfa(suite.ctx, "testuser", payload.MfaCodeRequest{Code: code})
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), res.Codes, recoveryCodeCount)
+	assert.Len(suite.T(), codes, recoveryCodeCount)
+	for i, code := range res.Codes {
+		assert.Regexp(suite.T(), "^[a-z2-7]{5}-[a-z2-7]{5}$", code)
+		assert.Equal(suite.T(), hashToken(normalizeCode(code)), codes[i].CodeHash)
+	}
+}
+
+func (suite *UserServiceTestSuite) TestConfirmMfa_WrongCode() {
+	suite.mfaRepo.On("GetMfa", suite.ctx, "testuser").Return([]model.UserMfaModel{{Username: "testuser", Secret: "JBSWY3DPEHPK3PXP"}}, nil)
+
+	res, err := suite.service.ConfirmMfa(suite.ctx, "testuser", payload.MfaCodeRequest{Code: "12345"})
+
+	assert.Nil(suite.T(), res)
+	assert.Equal(suite.T(), errInvalidMfaCode, err)
+	suite.mfaRepo.AssertNotCalled(suite.T(), "ConfirmMfa", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestRegenerateRecoveryCodes_MfaOff() {
+	suite.mfaRepo.On("GetMfa", suite.ctx, "testuser").Return([]model.UserMfaModel{}, nil)
+
+	res, err := suite.service.RegenerateRecoveryCodes(suite.ctx, "testuser", payload.MfaCodeRequest{Code: "123456"})
+
+	assert.Nil(suite.T(), res)
+	assert.Equal(suite.T(), errMfaOff, err)
+}
+
+func (suite *UserServiceTestSuite) TestDisableMfa_Success() {
+	hashedPassword, _ := encrypt.HashPassword("password123")
+	secret, _ := totp.NewSecret()
+	code, _ := totp.Code(secret, totp.Step(time.Now()))
+	suite.userRepo.On("GetPasswordByUsername", suite.ctx, "testuser").Return([]model.AuthUserModel{{Username: "testuser", Password: hashedPassword, Email: "test@example.com"}}, nil)
+	suite.mfaRepo.On("GetMfa", suite.ctx, "testuser").Return(suite.enabledMfa(secret), nil)
+	suite.mfaRepo.On("UseMfaStep", suite.ctx, "testuser", mock.Anything).Return(true, nil)
+	suite.mfaRepo.On("DeleteMfa", suite.ctx, "testuser").Return(nil)
+	suite.mailer.On("Send", suite.ctx, mock.MatchedBy(func(m mailer.Message) bool {
+		return m.To == "test@example.com" && strings.Contains(m.Body, "turned off")
+	})).Return(nil)
+
+	err := suite.service.DisableMfa(suite.ctx, "testuser", payload.DisableMfaRequest{Password: "password123", Code: code})
+
+	assert.NoError(suite.T(), err)
+	suite.mfaRepo.AssertExpectations(suite.T())
+	suite.mailer.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) TestDisableMfa_IncorrectPassword() {
+	hashedPassword, _ := encrypt.HashPassword("password123")
+	suite.userRepo.On("GetPasswordByUsername", suite.ctx, "testuser").Return([]model.AuthUserModel{{Username: "testuser", Password: hashedPassword}}, nil)
+
+	err := suite.service.DisableMfa(suite.ctx, "testuser", payload.DisableMfaRequest{Password: "wrongpassword", Code: "123456"})
+
+	assert.Equal(suite.T(), "password is incorrect", err.Error())
+	suite.mfaRepo.AssertNotCalled(suite.T(), "DeleteMfa", mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestResetMfa_Success() {
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "admin").Return([]model.AuthUserModel{{Username: "admin", Role: authorization.RoleAdmin}}, nil)
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "testuser").Return([]model.AuthUserModel{{Username: "testuser"}}, nil)
+	suite.mfaRepo.On("DeleteMfa", suite.ctx, "testuser").Return(nil)
+
+	err := suite.service.ResetMfa(suite.ctx, "admin", "testuser")
+
+	assert.NoError(suite.T(), err)
+	suite.mfaRepo.AssertExpectations(suite.T())
+	// no address, no mail
+	suite.mailer.AssertNotCalled(suite.T(), "Send", mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestResetMfa_NotAdminForbidden() {
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "editor").Return([]model.AuthUserModel{{Username: "editor", Role: authorization.RoleEditor}}, nil)
+
+	err := suite.service.ResetMfa(suite.ctx, "editor", "testuser")
+
+	assert.ErrorIs(suite.T(), err, authorization.ErrForbidden)
+	suite.mfaRepo.AssertNotCalled(suite.T(), "DeleteMfa", mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestCreateApiToken_Success() {
+	var inserted model.ApiTokenModel
+	suite.tokenRepo.On("InsertApiToken", suite.ctx, mock.Anything).Run(func(args mock.Arguments) {
+		inserted = args.Get(1).(model.ApiTokenModel)
+	}).Return(model.ApiTokenModel{ID: strfmt.UUID4("token-1"), Name: "ci"}, nil)
+
+	res, err := suite.service.CreateApiToken(suite.ctx, "testuser", payload.ApiTokenRequest{Name: "ci", Scopes: []string{"posts:write", "posts:read", "posts:write"}})
+
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), strings.HasPrefix(res.Token, model.ApiTokenPrefix))
+	assert.Equal(suite.T(), "token-1", res.ApiToken.ID.String())
+	assert.Equal(suite.T(), "testuser", inserted.Username)
+	assert.Equal(suite.T(), []string{"posts:write", "posts:read"}, inserted.Scopes)
+	assert.Equal(suite.T(), hashToken(res.Token), inserted.TokenHash)
+	assert.True(suite.T(), strings.HasSuffix(res.Token, inserted.TokenHint))
+	assert.Len(suite.T(), inserted.TokenHint, 4)
+	assert.WithinDuration(suite.T(), time.Now().Add(90*24*time.Hour), inserted.ExpiresAt, time.Minute)
+}
+
+func (suite *UserServiceTestSuite) TestCreateApiToken_UnknownScope() {
+	res, err := suite.service.CreateApiToken(suite.ctx, "testuser", payload.ApiTokenRequest{Name: "ci", Scopes: []string{"posts:read", "profile:write"}})
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "unknown scope profile:write")
+	suite.tokenRepo.AssertNotCalled(suite.T(), "InsertApiToken", mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestCreateApiToken_ExpiresTooLate() {
+	expiresAt := time.Now().Add(2 * 365 * 24 * time.Hour)
+
+	res, err := suite.service.CreateApiToken(suite.ctx, "testuser", payload.ApiTokenRequest{Name: "ci", Scopes: []string{"posts:read"}, ExpiresAt: &expiresAt})
+
+	assert.Nil(suite.T(), res)
+	var appErr *apperror.Error
+	assert.ErrorAs(suite.T(), err, &appErr)
+	assert.Equal(suite.T(), http.StatusBadRequest, appErr.Code)
+}
+
+func (suite *UserServiceTestSuite) TestRevokeApiToken_NotFound() {
+	suite.tokenRepo.On("RevokeApiToken", suite.ctx, "testuser", "token-1").Return(false, nil)
+
+	err := suite.service.RevokeApiToken(suite.ctx, "testuser", "token-1")
+
+	assert.EqualError(suite.T(), err, "api token not found")
+}
+
+func (suite *UserServiceTestSuite) TestRevokeUserApiTokens_Success() {
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "admin").Return([]model.AuthUserModel{{Username: "admin", Role: authorization.RoleAdmin}}, nil)
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "testuser").Return([]model.AuthUserModel{{Username: "testuser"}}, nil)
+	suite.tokenRepo.On("RevokeUserApiTokens", suite.ctx, "testuser").Return(int64(2), nil)
+
+	res, err := suite.service.RevokeUserApiTokens(suite.ctx, "admin", "testuser")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(2), res.Revoked)
+}
+
+func (suite *UserServiceTestSuite) TestRevokeUserApiTokens_NotAdminForbidden() {
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "editor").Return([]model.AuthUserModel{{Username: "editor", Role: authorization.RoleEditor}}, nil)
+
+	_, err := suite.service.RevokeUserApiTokens(suite.ctx, "editor", "testuser")
+
+	assert.ErrorIs(suite.T(), err, authorization.ErrForbidden)
+	suite.tokenRepo.AssertNotCalled(suite.T(), "RevokeUserApiTokens", mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestAuthenticateApiToken_Success() {
+	token := model.ApiTokenPrefix + "secret"
+	suite.tokenRepo.On("GetApiTokenByHash", suite.ctx, hashToken(token)).Return([]model.ApiTokenModel{{ID: strfmt.UUID4("token-1"), Username: "testuser", Scopes: []string{"posts:write"}}}, nil)
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "testuser").Return([]model.AuthUserModel{{ID: strfmt.UUID4("user-123"), Username: "testuser", Role: authorization.RoleEditor}}, nil)
+	suite.tokenRepo.On("TouchApiToken", suite.ctx, "token-1", "10.0.0.1", mock.Anything).Return(nil)
+
+	identity, err := suite.service.AuthenticateApiToken(suite.ctx, token, "10.0.0.1")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), &payload.ApiTokenIdentity{
+		TokenId:  "token-1",
+		UserId:   "user-123",
+		Username: "testuser",
+		Role:     authorization.RoleEditor,
+		Scopes:   []string{"posts:write"},
+	}, identity)
+	suite.tokenRepo.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) TestAuthenticateApiToken_TouchErrorIsNotReported() {
+	token := model.ApiTokenPrefix + "secret"
+	suite.tokenRepo.On("GetApiTokenByHash", suite.ctx, hashToken(token)).Return([]model.ApiTokenModel{{ID: strfmt.UUID4("token-1"), Username: "testuser"}}, nil)
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "testuser").Return([]model.AuthUserModel{{Username: "testuser"}}, nil)
+	suite.tokenRepo.On("TouchApiToken", suite.ctx, "token-1", "", mock.Anything).Return(errors.New("database error"))
+
+	identity, err := suite.service.AuthenticateApiToken(suite.ctx, token, "")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "testuser", identity.Username)
+}
+
+func (suite *UserServiceTestSuite) TestAuthenticateApiToken_Unknown() {
+	suite.tokenRepo.On("GetApiTokenByHash", suite.ctx, mock.Anything).Return([]model.ApiTokenModel{}, nil)
+
+	identity, err := suite.service.AuthenticateApiToken(suite.ctx, model.ApiTokenPrefix+"revoked", "10.0.0.1")
+
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), identity)
+	suite.tokenRepo.AssertNotCalled(suite.T(), "TouchApiToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// stubProvider is an identity provider on a local server, its token endpoint checks the code, the client and the PKCE
+// verifier and answers with an id_token for the nonce of the last sign in
+type stubProvider struct {
+	server    *httptest.Server
+	key       *rsa.PrivateKey
+	claims    jwt.MapClaims
+	challenge string
+	nonce     string
+}
+
+func newStubProvider() *stubProvider {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	p := &stubProvider{key: key, claims: jwt.MapClaims{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.server.URL,
+			"authorization_endpoint": p.server.URL + "/authorize",
+			"token_endpoint":         p.server.URL + "/token",
+			"jwks_uri":               p.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "key-1",
+				"use": "sig",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		clientID, secret, ok := r.BasicAuth()
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if !ok || clientID != "blog" || secret != "secret" || r.PostForm.Get("code") != "code-1" || base64.RawURLEncoding.EncodeToString(sum[:]) != p.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		claims := jwt.MapClaims{
+			"iss":   p.server.URL,
+			"aud":   "blog",
+			"sub":   "sub-1",
+			"nonce": p.nonce,
+			"iat":   time.Now().Unix(),
+			"exp":   time.Now().Add(5 * time.Minute).Unix(),
+		}
+		for name, value := range p.claims {
+			claims[name] = value
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "key-1"
+		idToken, _ := token.SignedString(p.key)
+
+		json.NewEncoder(w).Encode(map[string]string{
+			"access_token": "provider-access-token",
+			"token_type":   "Bearer",
+			"id_token":     idToken,
+		})
+	})
+	p.server = httptest.NewServer(mux)
+
+	return p
+}
+
+// startOidc signs in with a stub provider that puts claims into its id_token, the stored state is returned for UseOidcState
+func (suite *UserServiceTestSuite) startOidc(claims jwt.MapClaims) (*stubProvider, payload.OidcCallbackRequest, model.OidcStateModel) {
+	stub := newStubProvider()
+	suite.T().Cleanup(stub.server.Close)
+	for name, value := range claims {
+		stub.claims[name] = value
+	}
+	suite.service.provider = oidc.New(oidc.Config{
+		Issuer:       stub.server.URL,
+		ClientID:     "blog",
+		ClientSecret: "secret",
+		RedirectURL:  "https://blog.example/sso",
+		Scopes:       []string{"openid", "email", "profile"},
+	})
+
+	var state model.OidcStateModel
+	suite.oidcRepo.On("InsertOidcState", suite.ctx, mock.Anything).Run(func(args mock.Arguments) {
+		state = args.Get(1).(model.OidcStateModel)
+	}).Return(model.OidcStateModel{}, nil)
+
+	login, err := suite.service.StartOidc(suite.ctx)
+	suite.Require().NoError(err)
+
+	authorizationURL, err := url.Parse(login.AuthorizationURL)
+	suite.Require().NoError(err)
+	query := authorizationURL.Query()
+	assert.Equal(suite.T(), stub.server.URL+"/authorize", authorizationURL.Scheme+"://"+authorizationURL.Host+authorizationURL.Path)
+	assert.Equal(suite.T(), "code", query.Get("response_type"))
+	assert.Equal(suite.T(), "blog", query.Get("client_id"))
+	assert.Equal(suite.T(), "https://blog.example/sso", query.Get("redirect_uri"))
+	assert.Equal(suite.T(), "openid email profile", query.Get("scope"))
+	assert.Equal(suite.T(), "S256", query.Get("code_challenge_method"))
+	assert.Equal(suite.T(), login.State, query.Get("state"))
+	// only the hash of the state is stored, the verifier never leaves the server
+	assert.Equal(suite.T(), hashToken(login.State), state.StateHash)
+	assert.Equal(suite.T(), state.Nonce, query.Get("nonce"))
+	assert.NotContains(suite.T(), login.AuthorizationURL, state.CodeVerifier)
+
+	stub.challenge = query.Get("code_challenge")
+	stub.nonce = query.Get("nonce")
+
+	return stub, payload.OidcCallbackRequest{Code: "code-1", State: login.State}, state
+}
+
+func (suite *UserServiceTestSuite) expectOidcLogin(username string) {
+	suite.userRepo.On("UpdateLastLogin", suite.ctx, mock.MatchedBy(func(u model.AuthUserModel) bool {
+		return u.Username == username
+	})).Return(nil)
+	suite.sessionRepo.On("InsertSession", suite.ctx, mock.MatchedBy(func(s model.UserSessionModel) bool {
+		return s.Username == username
+	})).Return(model.UserSessionModel{}, nil)
+}
+
+func (suite *UserServiceTestSuite) TestStartOidc_NotConfigured() {
+	result, err := suite.service.StartOidc(suite.ctx)
+
+	assert.Nil(suite.T(), result)
+	assert.Equal(suite.T(), errOidcOff, err)
+}
+
+func (suite *UserServiceTestSuite) TestCompleteOidc_LinksVerifiedEmail() {
+	verifiedAt := time.Now().Add(-time.Hour)
+	stub, request, state := suite.startOidc(jwt.MapClaims{"email": "jane@example.com", "email_verified": true})
+	suite.oidcRepo.On("UseOidcState", suite.ctx, state.StateHash).Return([]model.OidcStateModel{state}, nil)
+	suite.oidcRepo.On("GetIdentity", suite.ctx, stub.server.URL, "sub-1").Return([]model.UserIdentityModel{}, nil)
+	suite.userRepo.On("GetUserByEmail", suite.ctx, "jane@example.com").Return([]model.AuthUserModel{
+		{Username: "jane", Email: "jane@example.com", EmailVerifiedAt: &verifiedAt, Role: authorization.RoleEditor, IsActive: true},
+		// an account that only claims the address is not taken over
+		{Username: "squatter", Email: "jane@example.com", IsActive: true},
+	}, nil)
+	suite.oidcRepo.On("InsertIdentity", suite.ctx, model.UserIdentityModel{Issuer: stub.server.URL, Subject: "sub-1", Username: "jane"}).Return(model.UserIdentityModel{}, nil)
+	suite.expectOidcLogin("jane")
+
+	token, err := suite.service.CompleteOidc(suite.ctx, request, payload.Client{IpAddress: "10.0.0.1"})
+
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), token.AccessToken)
+	assert.NotEmpty(suite.T(), token.RefreshToken)
+	suite.userRepo.AssertNotCalled(suite.T(), "InsertUser", mock.Anything, mock.Anything)
+	suite.oidcRepo.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) TestCompleteOidc_ProvisionsUser() {
+	stub, request, state := suite.startOidc(jwt.MapClaims{"email": "jane@example.com", "email_verified": true, "preferred_username": "Jane.Doe"})
+	suite.oidcRepo.On("UseOidcState", suite.ctx, state.StateHash).Return([]model.OidcStateModel{state}, nil)
+	suite.oidcRepo.On("GetIdentity", suite.ctx, stub.server.URL, "sub-1").Return([]model.UserIdentityModel{}, nil)
+	suite.userRepo.On("GetUserByEmail", suite.ctx, "jane@example.com").Return([]model.AuthUserModel{}, nil)
+	suite.userRepo.On("IsUsernameTaken", suite.ctx, "jane.doe").Return(true, nil)
+	suite.userRepo.On("IsUsernameTaken", suite.ctx, "jane.doe-2").Return(false, nil)
+
+	var created model.AuthUserModel
+	suite.userRepo.On("InsertUser", suite.ctx, mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(1).(model.AuthUserModel)
+	}).Return(model.AuthUserModel{ID: strfmt.UUID4("user-123"), Username: "jane.doe-2", Role: authorization.RoleAuthor, IsActive: true}, nil)
+	suite.oidcRepo.On("InsertIdentity", suite.ctx, model.UserIdentityModel{Issuer: stub.server.URL, Subject: "sub-1", Username: "jane.doe-2"}).Return(model.UserIdentityModel{}, nil)
+	suite.expectOidcLogin("jane.doe-2")
+
+	token, err := suite.service.CompleteOidc(suite.ctx, request, payload.Client{})
+
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), token.AccessToken)
+	assert.Equal(suite.T(), "jane.doe-2", created.Username)
+	assert.Equal(suite.T(), authorization.RoleAuthor, created.Role)
+	assert.True(suite.T(), created.IsActive)
+	assert.True(suite.T(), created.EmailVerified())
+	assert.NotEmpty(suite.T(), created.Password)
+	suite.oidcRepo.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) TestCompleteOidc_UnverifiedEmailIsNotLinked() {
+	suite.service.options.OidcAutoProvision = false
+	stub, request, state := suite.startOidc(jwt.MapClaims{"email": "jane@example.com", "email_verified": false})
+	suite.oidcRepo.On("UseOidcState", suite.ctx, state.StateHash).Return([]model.OidcStateModel{state}, nil)
+	suite.oidcRepo.On("GetIdentity", suite.ctx, stub.server.URL, "sub-1").Return([]model.UserIdentityModel{}, nil)
+
+	token, err := suite.service.CompleteOidc(suite.ctx, request, payload.Client{})
+
+	assert.Nil(suite.T(), token)
+	assert.Equal(suite.T(), errOidcUnlinked, err)
+	suite.userRepo.AssertNotCalled(suite.T(), "GetUserByEmail", mock.Anything, mock.Anything)
+	suite.oidcRepo.AssertNotCalled(suite.T(), "InsertIdentity", mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestCompleteOidc_LinkedIdentity() {
+	stub, request, state := suite.startOidc(nil)
+	suite.oidcRepo.On("UseOidcState", suite.ctx, state.StateHash).Return([]model.OidcStateModel{state}, nil)
+	suite.oidcRepo.On("GetIdentity", suite.ctx, stub.server.URL, "sub-1").Return([]model.UserIdentityModel{{Issuer: stub.server.URL, Subject: "sub-1", Username: "jane"}}, nil)
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "jane").Return([]model.AuthUserModel{{Username: "jane", IsActive: true}}, nil)
+	suite.expectOidcLogin("jane")
+
+	token, err := suite.service.CompleteOidc(suite.ctx, request, payload.Client{})
+
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), token.AccessToken)
+	suite.oidcRepo.AssertNotCalled(suite.T(), "InsertIdentity", mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestCompleteOidc_SuspendedUser() {
+	stub, request, state := suite.startOidc(nil)
+	suite.oidcRepo.On("UseOidcState", suite.ctx, state.StateHash).Return([]model.OidcStateModel{state}, nil)
+	suite.oidcRepo.On("GetIdentity", suite.ctx, stub.server.URL, "sub-1").Return([]model.UserIdentityModel{{Username: "jane"}}, nil)
+	suite.userRepo.On("GetUserByUsername", suite.ctx, "jane").Return([]model.AuthUserModel{{Username: "jane", IsActive: false, SuspendedReason: "spam"}}, nil)
+
+	token, err := suite.service.CompleteOidc(suite.ctx, request, payload.Client{})
+
+	assert.Nil(suite.T(), token)
+	assert.Equal(suite.T(), http.StatusForbidden, err.(*apperror.Error).Code)
+	suite.sessionRepo.AssertNotCalled(suite.T(), "InsertSession", mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestCompleteOidc_WrongNonce() {
+	_, request, state := suite.startOidc(jwt.MapClaims{"nonce": "replayed"})
+	suite.oidcRepo.On("UseOidcState", suite.ctx, state.StateHash).Return([]model.OidcStateModel{state}, nil)
+
+	token, err := suite.service.CompleteOidc(suite.ctx, request, payload.Client{})
+
+	assert.Nil(suite.T(), token)
+	assert.Equal(suite.T(), errOidcFailed, err)
+	suite.oidcRepo.AssertNotCalled(suite.T(), "GetIdentity", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestCompleteOidc_WrongAudience() {
+	_, request, state := suite.startOidc(jwt.MapClaims{"aud": "another-client"})
+	suite.oidcRepo.On("UseOidcState", suite.ctx, state.StateHash).Return([]model.OidcStateModel{state}, nil)
+
+	token, err := suite.service.CompleteOidc(suite.ctx, request, payload.Client{})
+
+	assert.Nil(suite.T(), token)
+	assert.Equal(suite.T(), errOidcFailed, err)
+}
+
+func (suite *UserServiceTestSuite) TestCompleteOidc_WrongVerifier() {
+	_, request, state := suite.startOidc(nil)
+	intercepted := state
+	intercepted.CodeVerifier = "verifier-of-another-sign-in"
+	suite.oidcRepo.On("UseOidcState", suite.ctx, state.StateHash).Return([]model.OidcStateModel{intercepted}, nil)
+
+	token, err := suite.service.CompleteOidc(suite.ctx, request, payload.Client{})
+
+	assert.Nil(suite.T(), token)
+	assert.Equal(suite.T(), errOidcFailed, err)
+}
+
+func (suite *UserServiceTestSuite) TestCompleteOidc_InvalidState() {
+	_, request, _ := suite.startOidc(nil)
+	suite.oidcRepo.On("UseOidcState", suite.ctx, mock.Anything).Return([]model.OidcStateModel{}, nil)
+
+	token, err := suite.service.CompleteOidc(suite.ctx, request, payload.Client{})
+
+	assert.Nil(suite.T(), token)
+	assert.Equal(suite.T(), errInvalidOidcState, err)
+}
+
+func (suite *UserServiceTestSuite) TestOidcUsername() {
+	assert.Equal(suite.T(), "jane.doe", oidcUsername("Jane.Doe"))
+	assert.Equal(suite.T(), "jane_doe", oidcUsername("jane_doe@example.com"))
+	assert.Equal(suite.T(), "jrgen", oidcUsername("Jürgen"))
+	assert.Equal(suite.T(), "", oidcUsername("@example.com"))
+	assert.Equal(suite.T(), strings.Repeat("a", maxOidcUsername), oidcUsername(strings.Repeat("a", 60)))
+}
+
+func (suite *UserServiceTestSuite) TestCreateToken_SignedWithKid() {
+	user := model.AuthUserModel{ID: strfmt.UUID4("user-123"), Username: "testuser", Role: authorization.RoleEditor}
+
+	token, err := createToken(suite.service.signer, user, strfmt.UUID4("session-1"), "jti-1", time.Now().Add(time.Hour))
+	suite.Require().NoError(err)
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "EdDSA", parsed.Header["alg"])
+	assert.Equal(suite.T(), suite.signingKey.ID, parsed.Header["kid"])
+
+	claims := jwt.MapClaims{}
+	err = suite.service.signer.Parse(token, claims)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "simple-blog-system", claims["iss"])
+	assert.Equal(suite.T(), "simple-blog-system", claims["aud"])
+	assert.Equal(suite.T(), "testuser", claims["username"])
+	assert.Equal(suite.T(), "session-1", claims["sid"])
+}
+
+func (suite *UserServiceTestSuite) TestCreateToken_RotatedKeyStillVerifies() {
+	user := model.AuthUserModel{Username: "testuser"}
+	token, err := createToken(suite.service.signer, user, strfmt.UUID4("session-1"), "jti-1", time.Now().Add(time.Hour))
+	suite.Require().NoError(err)
+
+	// after the rotation the previous key only verifies, until the tokens it signed expired
+	rotated := signing.NewKeySet(newSigningKey(), []*signing.Key{suite.signingKey}, "simple-blog-system", "simple-blog-system")
+	assert.NoError(suite.T(), rotated.Parse(token, jwt.MapClaims{}))
+	assert.Len(suite.T(), rotated.JWKS().Keys, 2)
+
+	retired := signing.NewKeySet(newSigningKey(), nil, "simple-blog-system", "simple-blog-system")
+	assert.ErrorIs(suite.T(), retired.Parse(token, jwt.MapClaims{}), signing.ErrUnknownKey)
+}
+
+func (suite *UserServiceTestSuite) TestCreateToken_RejectedByOtherAudience() {
+	token, err := createToken(suite.service.signer, model.AuthUserModel{Username: "testuser"}, strfmt.UUID4("session-1"), "jti-1", time.Now().Add(time.Hour))
+	suite.Require().NoError(err)
+
+	other := signing.NewKeySet(suite.signingKey, nil, "simple-blog-system", "another-service")
+	assert.ErrorIs(suite.T(), other.Parse(token, jwt.MapClaims{}), jwt.ErrTokenInvalidAudience)
+}
+
+func (suite *UserServiceTestSuite) TestCreateToken_SharedSecretRejectedByKeySet() {
+	// a token signed with HS256 and the public key as secret must not pass as signed with the key
+	forged, err := createToken(signing.NewSecret(suite.signingKey.JWK().X, "simple-blog-system", "simple-blog-system"), model.AuthUserModel{Username: "admin", Role: authorization.RoleAdmin}, strfmt.UUID4("session-1"), "jti-1", time.Now().Add(time.Hour))
+	suite.Require().NoError(err)
+
+	assert.ErrorIs(suite.T(), suite.service.signer.Parse(forged, jwt.MapClaims{}), jwt.ErrTokenSignatureInvalid)
+}
+
+func (suite *UserServiceTestSuite) TestJwks() {
+	jwks := suite.service.Jwks(suite.ctx)
+
+	assert.Len(suite.T(), jwks.Keys, 1)
+	assert.Equal(suite.T(), signing.JWK{
+		Kty: "OKP",
+		Kid: suite.signingKey.ID,
+		Use: "sig",
+		Alg: "EdDSA",
+		Crv: "Ed25519",
+		X:   suite.signingKey.JWK().X,
+	}, jwks.Keys[0])
+}
@@ -1,10 +1,16 @@
 package setup
 
 import (
+	"log"
+
 	"gorm.io/gorm"
 
+	"simple-blog-system/pkg/mailer"
+	"simple-blog-system/pkg/oidc"
+	"simple-blog-system/pkg/signing"
 	"simple-blog-system/pkg/transaction"
 
+	"simple-blog-system/config"
 	"simple-blog-system/config/db"
 
 	// healthCheckHandler "simple-blog-system/internal/app/healthcheck/handler"
@@ -26,6 +32,28 @@ import (
 	commentPorts "simple-blog-system/internal/app/comment/port"
 	commentRepo "simple-blog-system/internal/app/comment/repository"
 	commentService "simple-blog-system/internal/app/comment/service"
+
+	taxonomyHandler "simple-blog-system/internal/app/taxonomy/handler"
+	taxonomyPorts "simple-blog-system/internal/app/taxonomy/port"
+	taxonomyRepo "simple-blog-system/internal/app/taxonomy/repository"
+	taxonomyService "simple-blog-system/internal/app/taxonomy/service"
+
+	searchHandler "simple-blog-system/internal/app/search/handler"
+	searchPorts "simple-blog-system/internal/app/search/port"
+	searchRepo "simple-blog-system/internal/app/search/repository"
+	searchService "simple-blog-system/internal/app/search/service"
+
+	feedHandler "simple-blog-system/internal/app/feed/handler"
+	feedPorts "simple-blog-system/internal/app/feed/port"
+	feedService "simple-blog-system/internal/app/feed/service"
+
+	sitemapHandler "simple-blog-system/internal/app/sitemap/handler"
+	sitemapPorts "simple-blog-system/internal/app/sitemap/port"
+	sitemapService "simple-blog-system/internal/app/sitemap/service"
+
+	filterPorts "simple-blog-system/internal/app/filter/port"
+	filterRepo "simple-blog-system/internal/app/filter/repository"
+	filterService "simple-blog-system/internal/app/filter/service"
 )
 
 type InternalAppStruct struct {
@@ -35,10 +63,21 @@ type InternalAppStruct struct {
 }
 
 type initRepositoriesApp struct {
-	userRepo    userPorts.IUserRepository
-	postRepo    postPorts.IPostRepository
-	commentRepo commentPorts.ICommentRepository
-	TrxHandler  transaction.ISqlTransaction
+	userRepo     userPorts.IUserRepository
+	sessionRepo  userPorts.ISessionRepository
+	resetRepo    userPorts.IPasswordResetRepository
+	verifyRepo   userPorts.IEmailVerificationRepository
+	mfaRepo      userPorts.IMfaRepository
+	apiTokenRepo userPorts.IApiTokenRepository
+	oidcRepo     userPorts.IOidcRepository
+	attempts     userPorts.ILoginAttemptStore
+	revocations  userPorts.IRevocationStore
+	postRepo     postPorts.IPostRepository
+	commentRepo  commentPorts.ICommentRepository
+	taxonomyRepo taxonomyPorts.ITaxonomyRepository
+	searchIndex  searchPorts.ISearchIndex
+	filterRepo   filterPorts.IFilterRepository
+	TrxHandler   transaction.ISqlTransaction
 	// HealthCheckRepo healthCheckPorts.IHealthCheckRepository
 	dbInstance *gorm.DB
 	// cache      cache.ICache
@@ -46,8 +85,19 @@ type initRepositoriesApp struct {
 
 func initAppRepo(gormDB *db.GormDB, initializeApp *InternalAppStruct) {
 	initializeApp.Repositories.userRepo = userRepo.NewRepository(gormDB)
+	initializeApp.Repositories.sessionRepo = userRepo.NewSessionRepository(gormDB)
+	initializeApp.Repositories.resetRepo = userRepo.NewPasswordResetRepository(gormDB)
+	initializeApp.Repositories.verifyRepo = userRepo.NewEmailVerificationRepository(gormDB)
+	initializeApp.Repositories.mfaRepo = userRepo.NewMfaRepository(gormDB)
+	initializeApp.Repositories.apiTokenRepo = userRepo.NewApiTokenRepository(gormDB)
+	initializeApp.Repositories.oidcRepo = userRepo.NewOidcRepository(gormDB)
+	initializeApp.Repositories.attempts = userRepo.NewAttemptStore(config.GetConfig().Login.AttemptDriver, gormDB)
+	initializeApp.Repositories.revocations = userRepo.NewCachedRevocationStore(userRepo.NewRevocationStore(gormDB), config.GetConfig().JWT.RevocationCacheTTL)
 	initializeApp.Repositories.postRepo = postRepo.NewRepository(gormDB)
 	initializeApp.Repositories.commentRepo = commentRepo.NewRepository(gormDB)
+	initializeApp.Repositories.taxonomyRepo = taxonomyRepo.NewRepository(gormDB)
+	initializeApp.Repositories.searchIndex = searchRepo.NewIndex(config.GetConfig().Search.Driver, gormDB)
+	initializeApp.Repositories.filterRepo = filterRepo.NewRepository(gormDB)
 	// initializeApp.Repositories.HealthCheckRepo = healthCheckRepo.NewHealthCheckRepository(gormDB.DB, rc)
 
 	// Initiate trxRepo handler
@@ -58,24 +108,172 @@ func initAppRepo(gormDB *db.GormDB, initializeApp *InternalAppStruct) {
 }
 
 type initServicesApp struct {
-	UserService    userPorts.IUserService
-	PostService    postPorts.IPostService
-	CommentService commentPorts.ICommentService
+	UserService     userPorts.IUserService
+	Signer          signing.ISigner
+	Revocations     userPorts.IRevocationStore
+	Accounts        userPorts.IAccountStatus
+	Mailer          mailer.IMailer
+	PostService     postPorts.IPostService
+	CommentService  commentPorts.ICommentService
+	TaxonomyService taxonomyPorts.ITaxonomyService
+	SearchService   searchPorts.ISearchService
+	FeedService     feedPorts.IFeedService
+	SitemapService  sitemapPorts.ISitemapService
+	ContentFilter   filterPorts.ILearningFilter
 	// HealthCheckService healthCheckPorts.IHealthCheckService
 }
 
 func initAppService(initializeApp *InternalAppStruct) {
 	// initializeApp.Services.HealthCheckService = healthCheckService.NewService(initializeApp.Repositories.HealthCheckRepo)
-	initializeApp.Services.UserService = userService.New(initializeApp.Repositories.userRepo)
-	initializeApp.Services.PostService = postService.New(initializeApp.Repositories.postRepo, initializeApp.Repositories.userRepo)
-	initializeApp.Services.CommentService = commentService.New(initializeApp.Repositories.commentRepo, initializeApp.Repositories.userRepo, initializeApp.Repositories.postRepo)
+	initializeApp.Services.ContentFilter = newContentFilter(initializeApp.Repositories.filterRepo)
+	initializeApp.Services.Revocations = initializeApp.Repositories.revocations
+	initializeApp.Services.Signer = newSigner()
+	initializeApp.Services.Mailer = newMailer()
+	initializeApp.Services.SitemapService = sitemapService.New(initializeApp.Repositories.postRepo, sitemapService.Options{
+		BaseURL:  config.GetConfig().Site.BaseURL,
+		CacheTTL: config.GetConfig().Sitemap.CacheTTL,
+		Disallow: config.GetConfig().Sitemap.RobotsDisallow,
+	})
+	initializeApp.Services.PostService = postService.New(initializeApp.Repositories.postRepo, initializeApp.Repositories.userRepo, initializeApp.Repositories.taxonomyRepo, initializeApp.Repositories.searchIndex, initializeApp.Services.SitemapService, initializeApp.Repositories.TrxHandler, initializeApp.Services.ContentFilter, config.GetConfig().Verification.Required)
+	initializeApp.Services.CommentService = commentService.New(initializeApp.Repositories.commentRepo, initializeApp.Repositories.userRepo, initializeApp.Repositories.postRepo, initializeApp.Repositories.searchIndex, initializeApp.Services.ContentFilter, config.GetConfig().Comment.MaxDepth, config.GetConfig().Comment.Moderation, config.GetConfig().Comment.TrustedAfter, config.GetConfig().Verification.Required)
+	lockoutNotifier := userService.NewLockoutMailer(initializeApp.Repositories.userRepo, initializeApp.Services.Mailer)
+	// the post and comment services take over the content of deleted users, keeping tag counts and the search index right
+	initializeApp.Services.UserService = userService.New(initializeApp.Repositories.userRepo, initializeApp.Repositories.sessionRepo, initializeApp.Repositories.resetRepo, initializeApp.Repositories.verifyRepo, initializeApp.Repositories.mfaRepo, initializeApp.Repositories.apiTokenRepo, initializeApp.Repositories.oidcRepo, initializeApp.Repositories.attempts, initializeApp.Repositories.revocations, initializeApp.Services.Mailer, lockoutNotifier, []userPorts.IUserContent{initializeApp.Services.PostService, initializeApp.Services.CommentService}, initializeApp.Services.Signer, newOidcProvider(), userService.Options{
+		AccessTTL:         config.GetConfig().JWT.AccessTokenTTL,
+		RefreshTTL:        config.GetConfig().JWT.RefreshTokenTTL,
+		ResetTTL:          config.GetConfig().Password.ResetTTL,
+		VerifyTTL:         config.GetConfig().Verification.TTL,
+		ResetURL:          config.GetConfig().Password.ResetURL,
+		VerifyURL:         config.GetConfig().Verification.URL,
+		Throttle:          newThrottle(),
+		MfaIssuer:         config.GetConfig().Mfa.Issuer,
+		MfaChallengeTTL:   config.GetConfig().Mfa.ChallengeTTL,
+		ApiTokenTTL:       config.GetConfig().ApiToken.TTL,
+		ApiTokenMaxTTL:    config.GetConfig().ApiToken.MaxTTL,
+		OidcStateTTL:      config.GetConfig().Oidc.StateTTL,
+		OidcAutoProvision: config.GetConfig().Oidc.AutoProvision,
+		OidcLinkByEmail:   config.GetConfig().Oidc.LinkByEmail,
+		OidcDefaultRole:   config.GetConfig().Oidc.DefaultRole,
+		DeletionGrace:     config.GetConfig().Account.DeletionGrace,
+		DeletionContent:   config.GetConfig().Account.DeletionContent,
+	})
+	initializeApp.Services.Accounts = userRepo.NewAccountStatus(initializeApp.Repositories.userRepo, config.GetConfig().Account.StatusCacheTTL)
+	initializeApp.Services.TaxonomyService = taxonomyService.New(initializeApp.Repositories.taxonomyRepo, initializeApp.Repositories.userRepo)
+	initializeApp.Services.SearchService = searchService.New(initializeApp.Repositories.searchIndex, initializeApp.Repositories.userRepo, initializeApp.Repositories.postRepo, initializeApp.Repositories.commentRepo)
+	initializeApp.Services.FeedService = feedService.New(initializeApp.Repositories.postRepo, initializeApp.Repositories.taxonomyRepo, feedService.Options{
+		SiteTitle:       config.GetConfig().Site.Title,
+		SiteDescription: config.GetConfig().Site.Description,
+		BaseURL:         config.GetConfig().Site.BaseURL,
+		Items:           config.GetConfig().Feed.Items,
+		MaxItems:        config.GetConfig().Feed.MaxItems,
+	})
+}
+
+// newSigner loads the keys access tokens are signed and verified with, the server does not start without them
+func newSigner() signing.ISigner {
+	conf := config.GetConfig().JWT
+
+	signer, err := signing.New(signing.Config{
+		SigningKeyFile:       conf.SigningKeyFile,
+		VerificationKeyFiles: conf.VerificationKeyFiles,
+		Secret:               conf.SigningKey,
+		Issuer:               conf.Issuer,
+		Audience:             conf.Audience,
+	})
+	if err != nil {
+		log.Fatalln("jwt signing keys:", err)
+	}
+
+	return signer
+}
+
+// newOidcProvider is the identity provider of the config, single sign-on is off without an issuer
+func newOidcProvider() oidc.IProvider {
+	conf := config.GetConfig().Oidc
+	if conf.Issuer == "" {
+		return nil
+	}
+
+	scopes := []string{"openid"}
+	if len(conf.Scopes) == 0 {
+		scopes = append(scopes, "email", "profile")
+	}
+	for _, scope := range conf.Scopes {
+		if scope != "openid" {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return oidc.New(oidc.Config{
+		Issuer:       conf.Issuer,
+		ClientID:     conf.ClientID,
+		ClientSecret: conf.ClientSecret,
+		RedirectURL:  conf.RedirectURL,
+		Scopes:       scopes,
+	})
+}
+
+// newMailer picks the mail driver of the config, anything unknown falls back to the log
+func newMailer() mailer.IMailer {
+	conf := config.GetConfig().Mail
+
+	switch conf.Driver {
+	case "smtp":
+		return mailer.NewSMTP(conf.SMTPHost, conf.SMTPPort, conf.SMTPUsername, conf.SMTPPassword, conf.From)
+	case "file":
+		return mailer.NewFile(conf.Dir, conf.From)
+	default:
+		return mailer.NewLog()
+	}
+}
+
+func newThrottle() userService.Throttle {
+	conf := config.GetConfig().Login
+
+	return userService.Throttle{
+		User: userService.ThrottleLimit{
+			FreeAttempts: conf.UserFreeAttempts,
+			LockoutAfter: conf.UserLockoutAfter,
+		},
+		Ip: userService.ThrottleLimit{
+			FreeAttempts: conf.IpFreeAttempts,
+			LockoutAfter: conf.IpLockoutAfter,
+		},
+		BackoffBase:     conf.BackoffBase,
+		BackoffMax:      conf.BackoffMax,
+		LockoutDuration: conf.LockoutDuration,
+		Window:          conf.AttemptWindow,
+	}
+}
+
+// newContentFilter chains the content filters turned on in the config, the classifier is always on and waits until it has learned enough
+func newContentFilter(repo filterPorts.IFilterRepository) filterPorts.ILearningFilter {
+	conf := config.GetConfig().ContentFilter
+
+	filters := []filterPorts.IContentFilter{}
+	if len(conf.BannedWords) > 0 {
+		filters = append(filters, filterService.NewBannedWords(conf.BannedWords))
+	}
+	if conf.MaxLinks > 0 {
+		filters = append(filters, filterService.NewLinkLimit(conf.MaxLinks))
+	}
+	if conf.DuplicateWindow > 0 {
+		filters = append(filters, filterService.NewDuplicate(repo, conf.DuplicateWindow))
+	}
+	filters = append(filters, filterService.NewClassifier(repo, conf.SpamRejectAt, conf.SpamReviewAt, conf.SpamMinDocuments))
+
+	return filterService.New(filters...)
 }
 
 // HANDLER INIT
 type InitHandlerApp struct {
-	UserHandler    userPorts.IUserHandler
-	PostHandler    postPorts.IPostHandler
-	CommentHandler commentPorts.ICommentHandler
+	UserHandler     userPorts.IUserHandler
+	PostHandler     postPorts.IPostHandler
+	CommentHandler  commentPorts.ICommentHandler
+	TaxonomyHandler taxonomyPorts.ITaxonomyHandler
+	SearchHandler   searchPorts.ISearchHandler
+	FeedHandler     feedPorts.IFeedHandler
+	SitemapHandler  sitemapPorts.ISitemapHandler
 	// HealthCheckHandler healthCheckPorts.IHealthCheckHandler
 }
 
@@ -84,4 +282,8 @@ func initAppHandler(initializeApp *InternalAppStruct) {
 	initializeApp.Handler.UserHandler = userHandler.New(initializeApp.Services.UserService)
 	initializeApp.Handler.PostHandler = postHandler.New(initializeApp.Services.PostService)
 	initializeApp.Handler.CommentHandler = commentHandler.New(initializeApp.Services.CommentService)
+	initializeApp.Handler.TaxonomyHandler = taxonomyHandler.New(initializeApp.Services.TaxonomyService)
+	initializeApp.Handler.SearchHandler = searchHandler.New(initializeApp.Services.SearchService)
+	initializeApp.Handler.FeedHandler = feedHandler.New(initializeApp.Services.FeedService)
+	initializeApp.Handler.SitemapHandler = sitemapHandler.New(initializeApp.Services.SitemapService)
 }
@@ -4,6 +4,9 @@ import (
 	"simple-blog-system/config"
 	"simple-blog-system/config/db"
 
+	searchRepo "simple-blog-system/internal/app/search/repository"
+
+	"context"
 	"log"
 )
 
@@ -37,6 +40,13 @@ func Init() SetupData {
 
 	internalAppVar := initInternalApp(dbConn.GormDB)
 
+	// the memory search index starts empty
+	if configData.Search.Driver == searchRepo.DriverMemory {
+		if err := internalAppVar.Services.SearchService.Reindex(context.Background()); err != nil {
+			log.Println("search reindex error:", err)
+		}
+	}
+
 	return SetupData{
 		ConfigData:  configData,
 		InternalApp: internalAppVar,
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE auth_user DROP CONSTRAINT IF EXISTS auth_user_role_check;
+ALTER TABLE auth_user DROP COLUMN IF EXISTS role;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE auth_user ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'author';
+ALTER TABLE auth_user ADD CONSTRAINT auth_user_role_check CHECK (role IN ('admin', 'editor', 'author', 'reader'));
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TABLE IF EXISTS post_slug_history;
+DROP INDEX IF EXISTS posts_slug_unique;
+ALTER TABLE posts DROP COLUMN IF EXISTS slug;
+
+COMMIT;
@@ -0,0 +1,45 @@
+BEGIN;
+
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS slug VARCHAR(120) NULL;
+
+-- same rules as helper.Slugify: accents are dropped, anything but ascii letters and digits becomes a dash,
+-- at most 100 characters, "post" when nothing is left, and a -2, -3... suffix while the slug is taken
+DO $$
+DECLARE
+    post RECORD;
+    base TEXT;
+    candidate TEXT;
+    n INT;
+BEGIN
+    FOR post IN SELECT id, title FROM posts WHERE slug IS NULL ORDER BY created_at, id LOOP
+        base := regexp_replace(normalize(lower(post.title), NFD), '[\u0300-\u036f\u1ab0-\u1aff\u1dc0-\u1dff\u20d0-\u20ff\ufe20-\ufe2f]', '', 'g');
+        base := trim(both '-' from regexp_replace(base, '[^a-z0-9]+', '-', 'g'));
+        base := trim(both '-' from left(base, 100));
+        IF base = '' THEN
+            base := 'post';
+        END IF;
+
+        candidate := base;
+        n := 2;
+        WHILE EXISTS (SELECT 1 FROM posts WHERE slug = candidate) LOOP
+            candidate := base || '-' || n;
+            n := n + 1;
+        END LOOP;
+
+        UPDATE posts SET slug = candidate WHERE id = post.id;
+    END LOOP;
+END $$;
+
+ALTER TABLE posts ALTER COLUMN slug SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS posts_slug_unique ON posts (slug);
+
+CREATE TABLE IF NOT EXISTS post_slug_history (
+    id VARCHAR(50) PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    post_id VARCHAR(50) NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    slug VARCHAR(120) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS post_slug_history_slug_unique ON post_slug_history (slug);
+
+COMMIT;
@@ -0,0 +1,39 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS categories (
+    id VARCHAR(50) PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    slug VARCHAR(120) NOT NULL,
+    parent_id VARCHAR(50) NULL REFERENCES categories(id) ON DELETE SET NULL,
+    created_by VARCHAR(50) NOT NULL,
+    updated_by VARCHAR(50) NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NULL,
+    deleted_at TIMESTAMPTZ NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS categories_slug_unique ON categories (slug);
+CREATE INDEX IF NOT EXISTS categories_parent_id_idx ON categories (parent_id);
+
+CREATE TABLE IF NOT EXISTS tags (
+    id VARCHAR(50) PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    name VARCHAR(50) NOT NULL,
+    slug VARCHAR(60) NOT NULL,
+    post_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS tags_slug_unique ON tags (slug);
+
+CREATE TABLE IF NOT EXISTS post_tags (
+    post_id VARCHAR(50) NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    tag_id VARCHAR(50) NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (post_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS post_tags_tag_id_idx ON post_tags (tag_id);
+
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS category_id VARCHAR(50) NULL REFERENCES categories(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS posts_category_id_idx ON posts (category_id);
+
+COMMIT;
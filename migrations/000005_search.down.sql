@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP INDEX IF EXISTS comments_search_vector_idx;
+ALTER TABLE comments DROP COLUMN IF EXISTS search_vector;
+
+DROP INDEX IF EXISTS posts_search_vector_idx;
+ALTER TABLE posts DROP COLUMN IF EXISTS search_vector;
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP INDEX IF EXISTS posts_scheduled_publish_at_idx;
+
+UPDATE posts SET status = 'DRAFT' WHERE status = 'SCHEDULED';
+
+ALTER TABLE posts DROP COLUMN IF EXISTS publish_at;
+
+COMMIT;
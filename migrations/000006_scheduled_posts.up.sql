@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS publish_at TIMESTAMPTZ NULL;
+
+-- the scheduler only ever looks for due scheduled posts
+CREATE INDEX IF NOT EXISTS posts_scheduled_publish_at_idx ON posts (publish_at) WHERE status = 'SCHEDULED' AND deleted_at IS NULL;
+
+COMMIT;
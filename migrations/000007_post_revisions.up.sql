@@ -0,0 +1,22 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS post_revisions (
+    id VARCHAR(50) PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    post_id VARCHAR(50) NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    title VARCHAR(100) NOT NULL,
+    body TEXT NOT NULL,
+    created_by VARCHAR(50) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS post_revisions_post_revision_unique ON post_revisions (post_id, revision);
+CREATE INDEX IF NOT EXISTS post_revisions_post_created_idx ON post_revisions (post_id, created_at DESC, id DESC);
+
+-- existing posts start their history with their current content
+INSERT INTO post_revisions (post_id, revision, title, body, created_by, created_at)
+SELECT id, 1, title, body, COALESCE(updated_by, created_by), COALESCE(updated_at, created_at)
+FROM posts
+WHERE NOT EXISTS (SELECT 1 FROM post_revisions WHERE post_revisions.post_id = posts.id);
+
+COMMIT;
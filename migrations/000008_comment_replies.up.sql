@@ -0,0 +1,9 @@
+BEGIN;
+
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS parent_id VARCHAR(50) NULL REFERENCES comments(id) ON DELETE CASCADE;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS depth INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS comments_post_created_idx ON comments (post_id, created_at, id);
+CREATE INDEX IF NOT EXISTS comments_parent_id_idx ON comments (parent_id);
+
+COMMIT;
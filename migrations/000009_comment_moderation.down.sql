@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP INDEX IF EXISTS comments_username_status_idx;
+DROP INDEX IF EXISTS comments_pending_idx;
+
+ALTER TABLE posts DROP COLUMN IF EXISTS comment_moderation;
+ALTER TABLE comments DROP COLUMN IF EXISTS status;
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+
+-- comments written before moderation existed stay visible
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'approved';
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS comment_moderation VARCHAR(20) NULL;
+
+CREATE INDEX IF NOT EXISTS comments_pending_idx ON comments (created_at, id) WHERE status = 'pending' AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS comments_username_status_idx ON comments (username, status);
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP INDEX IF EXISTS posts_username_created_idx;
+DROP INDEX IF EXISTS comments_username_created_idx;
+
+DROP TABLE IF EXISTS spam_documents;
+DROP TABLE IF EXISTS spam_tokens;
+
+COMMIT;
@@ -0,0 +1,21 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS spam_tokens (
+    token VARCHAR(64) PRIMARY KEY,
+    spam BIGINT NOT NULL DEFAULT 0,
+    ham BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS spam_documents (
+    id SMALLINT PRIMARY KEY CHECK (id = 1),
+    spam BIGINT NOT NULL DEFAULT 0,
+    ham BIGINT NOT NULL DEFAULT 0
+);
+
+INSERT INTO spam_documents (id, spam, ham) VALUES (1, 0, 0) ON CONFLICT (id) DO NOTHING;
+
+-- duplicate detection looks up the recent content of one user
+CREATE INDEX IF NOT EXISTS comments_username_created_idx ON comments (username, created_at);
+CREATE INDEX IF NOT EXISTS posts_username_created_idx ON posts (username, created_at);
+
+COMMIT;
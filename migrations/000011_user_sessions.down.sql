@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS revoked_tokens;
+DROP TABLE IF EXISTS user_sessions;
+
+COMMIT;
@@ -0,0 +1,28 @@
+BEGIN;
+
+-- one row per login, the refresh token is rotated in place and only its hash is stored
+CREATE TABLE IF NOT EXISTS user_sessions (
+    id VARCHAR(50) PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    username VARCHAR(50) NOT NULL,
+    refresh_token_hash VARCHAR(64) NOT NULL,
+    access_jti VARCHAR(50) NOT NULL,
+    access_expires_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS user_sessions_refresh_token_hash_idx ON user_sessions (refresh_token_hash);
+CREATE INDEX IF NOT EXISTS user_sessions_username_idx ON user_sessions (username);
+CREATE INDEX IF NOT EXISTS user_sessions_expires_at_idx ON user_sessions (expires_at);
+
+-- access tokens revoked before they expire, rows are purged once the token has expired
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti VARCHAR(50) PRIMARY KEY NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS revoked_tokens_expires_at_idx ON revoked_tokens (expires_at);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS last_seen_at;
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS user_agent;
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS ip_address;
+
+COMMIT;
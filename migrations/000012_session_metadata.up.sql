@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS ip_address VARCHAR(64) NULL;
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS user_agent VARCHAR(512) NULL;
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+COMMIT;
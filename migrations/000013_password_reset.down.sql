@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TABLE IF EXISTS password_resets;
+
+ALTER TABLE auth_user DROP COLUMN IF EXISTS email;
+
+COMMIT;
@@ -0,0 +1,19 @@
+BEGIN;
+
+ALTER TABLE auth_user ADD COLUMN IF NOT EXISTS email VARCHAR(254) NULL;
+
+-- only the hash of a reset token is stored, a token is used once
+CREATE TABLE IF NOT EXISTS password_resets (
+    id VARCHAR(50) PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    username VARCHAR(50) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS password_resets_token_hash_idx ON password_resets (token_hash);
+CREATE INDEX IF NOT EXISTS password_resets_username_idx ON password_resets (username);
+CREATE INDEX IF NOT EXISTS password_resets_expires_at_idx ON password_resets (expires_at);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TABLE IF EXISTS email_verifications;
+
+ALTER TABLE auth_user DROP COLUMN IF EXISTS email_verified_at;
+
+COMMIT;
@@ -0,0 +1,20 @@
+BEGIN;
+
+ALTER TABLE auth_user ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMPTZ NULL;
+
+-- a token verifies the address it was sent to, changing the address needs a new token
+CREATE TABLE IF NOT EXISTS email_verifications (
+    id VARCHAR(50) PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    username VARCHAR(50) NOT NULL,
+    email VARCHAR(254) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS email_verifications_token_hash_idx ON email_verifications (token_hash);
+CREATE INDEX IF NOT EXISTS email_verifications_username_idx ON email_verifications (username);
+CREATE INDEX IF NOT EXISTS email_verifications_expires_at_idx ON email_verifications (expires_at);
+
+COMMIT;
@@ -0,0 +1,12 @@
+BEGIN;
+
+-- failed logins are counted per key, a key is user:<username> or ip:<address>
+CREATE TABLE IF NOT EXISTS login_attempts (
+    key TEXT PRIMARY KEY NOT NULL,
+    failures INT NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS login_attempts_last_failed_at_idx ON login_attempts (last_failed_at);
+
+COMMIT;
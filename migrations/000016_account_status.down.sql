@@ -0,0 +1,10 @@
+BEGIN;
+
+DROP INDEX IF EXISTS auth_user_delete_after_idx;
+DROP INDEX IF EXISTS auth_user_username_idx;
+
+ALTER TABLE auth_user DROP COLUMN IF EXISTS delete_after;
+ALTER TABLE auth_user DROP COLUMN IF EXISTS suspended_until;
+ALTER TABLE auth_user DROP COLUMN IF EXISTS suspended_reason;
+
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+
+-- registrations wrote is_active as false while nothing read it, every account that is not deleted is active
+UPDATE auth_user SET is_active = TRUE WHERE deleted_at IS NULL;
+
+ALTER TABLE auth_user ADD COLUMN IF NOT EXISTS suspended_reason VARCHAR(500) NULL;
+ALTER TABLE auth_user ADD COLUMN IF NOT EXISTS suspended_until TIMESTAMPTZ NULL;
+-- accounts whose owner asked for deletion are deleted once delete_after passed
+ALTER TABLE auth_user ADD COLUMN IF NOT EXISTS delete_after TIMESTAMPTZ NULL;
+
+CREATE INDEX IF NOT EXISTS auth_user_username_idx ON auth_user (username);
+CREATE INDEX IF NOT EXISTS auth_user_delete_after_idx ON auth_user (delete_after) WHERE delete_after IS NOT NULL AND deleted_at IS NULL;
+
+COMMIT;
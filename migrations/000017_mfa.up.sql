@@ -0,0 +1,38 @@
+BEGIN;
+
+-- one TOTP secret per user, confirmed_at stays empty until the first code from the authenticator app came back
+CREATE TABLE IF NOT EXISTS user_mfa (
+    username VARCHAR(50) PRIMARY KEY NOT NULL,
+    secret VARCHAR(64) NOT NULL,
+    confirmed_at TIMESTAMPTZ NULL,
+    -- the newest time step a code was accepted for, older and equal steps are refused so a code works once
+    last_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- only the hash of a recovery code is stored, a code is used once
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id VARCHAR(50) PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    username VARCHAR(50) NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS mfa_recovery_codes_username_idx ON mfa_recovery_codes (username);
+
+-- a login whose password matched and that waits for the second factor
+CREATE TABLE IF NOT EXISTS mfa_challenges (
+    id VARCHAR(50) PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    username VARCHAR(50) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS mfa_challenges_token_hash_idx ON mfa_challenges (token_hash);
+CREATE INDEX IF NOT EXISTS mfa_challenges_expires_at_idx ON mfa_challenges (expires_at);
+
+COMMIT;
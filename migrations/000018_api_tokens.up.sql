@@ -0,0 +1,24 @@
+BEGIN;
+
+-- personal access tokens for automation, only the hash of a token is stored
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id VARCHAR(50) PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    username VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    -- json array of scopes like posts:write
+    scopes TEXT NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    -- the last characters of the token, so a user can tell their tokens apart
+    token_hint VARCHAR(8) NOT NULL,
+    last_used_at TIMESTAMPTZ NULL,
+    last_used_ip VARCHAR(64) NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS api_tokens_token_hash_idx ON api_tokens (token_hash);
+CREATE INDEX IF NOT EXISTS api_tokens_username_idx ON api_tokens (username);
+CREATE INDEX IF NOT EXISTS api_tokens_expires_at_idx ON api_tokens (expires_at);
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS user_identities;
+DROP TABLE IF EXISTS oidc_states;
+
+COMMIT;
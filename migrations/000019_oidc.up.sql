@@ -0,0 +1,30 @@
+BEGIN;
+
+-- a sign in with the identity provider that was started and waits for the callback, the state is only stored as hash
+CREATE TABLE IF NOT EXISTS oidc_states (
+    id VARCHAR(50) PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    state_hash VARCHAR(64) NOT NULL,
+    -- the PKCE verifier and nonce the callback is checked with
+    code_verifier VARCHAR(128) NOT NULL,
+    nonce VARCHAR(128) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS oidc_states_state_hash_idx ON oidc_states (state_hash);
+CREATE INDEX IF NOT EXISTS oidc_states_expires_at_idx ON oidc_states (expires_at);
+
+-- links the subject of an identity provider to a local user, a user can have several identities
+CREATE TABLE IF NOT EXISTS user_identities (
+    id VARCHAR(50) PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    issuer VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    username VARCHAR(50) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS user_identities_issuer_subject_idx ON user_identities (issuer, subject);
+CREATE INDEX IF NOT EXISTS user_identities_username_idx ON user_identities (username);
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+UPDATE tags SET post_count = (
+    SELECT COUNT(*) FROM post_tags
+    JOIN posts ON posts.id = post_tags.post_id AND posts.deleted_at IS NULL
+    WHERE post_tags.tag_id = tags.id
+);
+
+COMMIT;
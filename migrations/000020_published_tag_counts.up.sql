@@ -0,0 +1,10 @@
+BEGIN;
+
+-- tag counts only count published posts from now on
+UPDATE tags SET post_count = (
+    SELECT COUNT(*) FROM post_tags
+    JOIN posts ON posts.id = post_tags.post_id AND posts.deleted_at IS NULL AND posts.status = 'PUBLISH'
+    WHERE post_tags.tag_id = tags.id
+);
+
+COMMIT;
@@ -0,0 +1,52 @@
+package apperror
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Error is a business error that carries the HTTP status it should be answered with
+type Error struct {
+	Code    int
+	Type    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// New creates an error answered with the given status code, the type is derived from the status text
+//
+// Ex: New(403, "...") => Type "Forbidden"
+func New(code int, message string) *Error {
+	return &Error{
+		Code:    code,
+		Type:    strings.ReplaceAll(http.StatusText(code), " ", ""),
+		Message: message,
+	}
+}
+
+func BadRequest(message string) *Error {
+	return New(http.StatusBadRequest, message)
+}
+
+func Unauthorized(message string) *Error {
+	return New(http.StatusUnauthorized, message)
+}
+
+func Forbidden(message string) *Error {
+	return New(http.StatusForbidden, message)
+}
+
+func Conflict(message string) *Error {
+	return New(http.StatusConflict, message)
+}
+
+func UnprocessableEntity(message string) *Error {
+	return New(http.StatusUnprocessableEntity, message)
+}
+
+func TooManyRequests(message string) *Error {
+	return New(http.StatusTooManyRequests, message)
+}
@@ -0,0 +1,79 @@
+package authorization
+
+import (
+	"net/http"
+
+	"simple-blog-system/pkg/apperror"
+)
+
+// Role of auth_user, ordered from the most to the least privileged
+const (
+	RoleAdmin  = "admin"
+	RoleEditor = "editor"
+	RoleAuthor = "author"
+	RoleReader = "reader"
+)
+
+// Resources of the API an api token can be scoped to
+const (
+	ResourcePosts    = "posts"
+	ResourceComments = "comments"
+	ResourceTaxonomy = "taxonomy"
+	ResourceSearch   = "search"
+	ResourceProfile  = "profile"
+	ResourceAdmin    = "admin"
+)
+
+// Scopes an api token can be created with, writing the profile and the admin API take a login
+var scopes = map[string]bool{
+	ResourcePosts + ":read":     true,
+	ResourcePosts + ":write":    true,
+	ResourceComments + ":read":  true,
+	ResourceComments + ":write": true,
+	ResourceTaxonomy + ":read":  true,
+	ResourceTaxonomy + ":write": true,
+	ResourceSearch + ":read":    true,
+	ResourceProfile + ":read":   true,
+}
+
+var ErrForbidden = apperror.Forbidden("you are not allowed to perform this action")
+
+// ErrEmailNotVerified is answered when publishing or commenting needs a verified email address
+var ErrEmailNotVerified = apperror.Forbidden("verify your email address before publishing or commenting")
+
+func IsValidRole(role string) bool {
+	switch role {
+	case RoleAdmin, RoleEditor, RoleAuthor, RoleReader:
+		return true
+	}
+
+	return false
+}
+
+// IsModerator admins and editors can moderate content of every user
+func IsModerator(role string) bool {
+	return role == RoleAdmin || role == RoleEditor
+}
+
+// CanCreatePost readers can only comment, every other role can write posts
+func CanCreatePost(role string) bool {
+	return IsModerator(role) || role == RoleAuthor
+}
+
+// CanManage checks whether actor with role may update or delete content owned by owner
+func CanManage(role string, actor string, owner string) bool {
+	return IsModerator(role) || (actor != "" && actor == owner)
+}
+
+func IsValidScope(scope string) bool {
+	return scopes[scope]
+}
+
+// Scope is the scope a request with method needs on resource, reading for GET and HEAD and writing otherwise
+func Scope(resource string, method string) string {
+	if method == http.MethodGet || method == http.MethodHead {
+		return resource + ":read"
+	}
+
+	return resource + ":write"
+}
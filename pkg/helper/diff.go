@@ -0,0 +1,106 @@
+package helper
+
+import "strings"
+
+// Operations of a DiffLine
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// DiffLines compare two texts line by line with the Myers algorithm, applying the result to from gives to
+//
+// Ex: "a\nb" => "a\nc" => [{equal a}, {delete b}, {insert c}]
+func DiffLines(from, to string) []DiffLine {
+	a, b := splitLines(from), splitLines(to)
+	n, m := len(a), len(b)
+	offset := n + m
+
+	// v[k+offset] is the furthest x reached on diagonal k, trace keeps v before every step d
+	v := make([]int, 2*offset+2)
+	trace := [][]int{}
+
+	for d := 0; d <= offset; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+2]...))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(a, b, trace)
+			}
+		}
+	}
+
+	return nil
+}
+
+func backtrack(a, b []string, trace [][]int) []DiffLine {
+	x, y := len(a), len(b)
+	lines := []DiffLine{}
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		// trace[d] starts at diagonal -d
+		v := func(k int) int { return trace[d][k+d] }
+		k := x - y
+
+		prevK := k - 1
+		if k == -d || (k != d && v(k-1) < v(k+1)) {
+			prevK = k + 1
+		}
+
+		prevX := 0
+		if d > 0 {
+			prevX = v(prevK)
+		}
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			lines = append(lines, DiffLine{Op: DiffEqual, Text: a[x-1]})
+			x--
+			y--
+		}
+
+		if d > 0 {
+			if x == prevX {
+				lines = append(lines, DiffLine{Op: DiffInsert, Text: b[y-1]})
+			} else {
+				lines = append(lines, DiffLine{Op: DiffDelete, Text: a[x-1]})
+			}
+		}
+
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+
+	return lines
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+}
@@ -10,10 +10,13 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 	"gorm.io/gorm"
+
+	"simple-blog-system/pkg/apperror"
 )
 
 type Response struct {
 	Data      interface{} `json:"data"`
+	Meta      interface{} `json:"meta,omitempty"`
 	Success   bool        `json:"success"`
 	Message   string      `json:"message"`
 	RequestId any         `json:"request_id"`
@@ -120,6 +123,12 @@ func ResponseError(c *gin.Context, err error, opts ...interface{}) {
 		t = "NotFound"
 	}
 
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		code = appErr.Code
+		t = appErr.Type
+	}
+
 	requestID, _ := c.Get("requestID")
 	SaveAuditLog(c, d)
 	c.AbortWithStatusJSON(code, &Response{
@@ -0,0 +1,59 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+type fileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFile writes every message to its own .eml file in dir instead of sending it, for local development and tests
+func NewFile(dir string, from string) IMailer {
+	return &fileMailer{
+		dir:  dir,
+		from: from,
+	}
+}
+
+func (m *fileMailer) Send(ctx context.Context, message Message) error {
+	msg, err := format(m.from, message)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(m.dir, 0o700); err != nil {
+		return err
+	}
+
+	recipient := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == os.PathSeparator {
+			return '_'
+		}
+		return r
+	}, message.To)
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), recipient)
+
+	return os.WriteFile(filepath.Join(m.dir, name), msg, 0o600)
+}
+
+type logMailer struct{}
+
+// NewLog writes every message to the log instead of sending it, for local development.
+// Messages carry secrets like reset tokens, so it must not be used in production.
+func NewLog() IMailer {
+	return logMailer{}
+}
+
+func (m logMailer) Send(ctx context.Context, message Message) error {
+	log.Info().Str("to", message.To).Str("subject", message.Subject).Msg(message.Body)
+	return nil
+}
@@ -0,0 +1,247 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"simple-blog-system/pkg/apperror"
+)
+
+const (
+	DefaultLimit = 10
+	MaxLimit     = 100
+)
+
+var (
+	ErrInvalidCursor = apperror.BadRequest("invalid cursor")
+	ErrInvalidPage   = apperror.BadRequest("page must be a positive number")
+	ErrInvalidLimit  = apperror.BadRequest("limit must be a positive number")
+	ErrCursorAndPage = apperror.BadRequest("use either cursor or page, not both")
+)
+
+// Key is the position of a row in lists ordered by (created_at, id)
+type Key struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"i"`
+}
+
+type cursor struct {
+	Key
+	Prev bool `json:"p,omitempty"`
+}
+
+// Page selects a page of a list, by page number when Number is set and by keyset cursor otherwise
+type Page struct {
+	Limit  int
+	Number int
+	// Ascending lists oldest first, cursors only work with the order they were issued for
+	Ascending bool
+	cursor    *cursor
+}
+
+// Meta describes the returned page, total and total_pages are only known in offset mode
+type Meta struct {
+	Limit      int    `json:"limit"`
+	Page       int    `json:"page,omitempty"`
+	Total      *int64 `json:"total,omitempty"`
+	TotalPages *int   `json:"total_pages,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+// FromContext reads cursor, page and limit from the query string, a limit above MaxLimit is lowered to MaxLimit
+func FromContext(c *gin.Context) (Page, error) {
+	return New(c.Query("cursor"), c.Query("page"), c.Query("limit"))
+}
+
+func New(cursorValue string, pageValue string, limitValue string) (Page, error) {
+	page := Page{Limit: DefaultLimit}
+
+	if limitValue != "" {
+		limit, err := strconv.Atoi(limitValue)
+		if err != nil || limit < 1 {
+			return Page{}, ErrInvalidLimit
+		}
+		page.Limit = min(limit, MaxLimit)
+	}
+
+	if pageValue != "" {
+		if cursorValue != "" {
+			return Page{}, ErrCursorAndPage
+		}
+
+		number, err := strconv.Atoi(pageValue)
+		if err != nil || number < 1 {
+			return Page{}, ErrInvalidPage
+		}
+		page.Number = number
+	}
+
+	if cursorValue != "" {
+		c, err := decodeCursor(cursorValue)
+		if err != nil {
+			return Page{}, ErrInvalidCursor
+		}
+		page.cursor = &c
+	}
+
+	return page, nil
+}
+
+// IsOffset reports whether the page is selected by page number
+func (p Page) IsOffset() bool {
+	return p.Number > 0
+}
+
+func (p Page) Offset() int {
+	if p.Number < 1 {
+		return 0
+	}
+	return (p.Number - 1) * p.Limit
+}
+
+// AsOffset turns the page into offset mode for lists that are not ordered by (created_at, id)
+func (p Page) AsOffset() Page {
+	if p.Limit < 1 {
+		p.Limit = DefaultLimit
+	}
+	if p.Number < 1 {
+		p.Number = 1
+	}
+	p.cursor = nil
+	return p
+}
+
+// Next returns the page that follows p according to meta, false when p is the last page
+func (p Page) Next(meta Meta) (Page, bool) {
+	if p.IsOffset() {
+		if meta.TotalPages == nil || p.Number >= *meta.TotalPages {
+			return p, false
+		}
+		p.Number++
+		return p, true
+	}
+
+	if meta.NextCursor == "" {
+		return p, false
+	}
+
+	c, err := decodeCursor(meta.NextCursor)
+	if err != nil {
+		return p, false
+	}
+	p.cursor = &c
+	return p, true
+}
+
+// Scope orders query newest first, or oldest first when the page is Ascending, by the created_at and id columns
+// of table and limits it to the page, in cursor mode one extra row is fetched to know whether there is a following page
+func (p Page) Scope(table string) func(db *gorm.DB) *gorm.DB {
+	createdAt, id := table+".created_at", table+".id"
+	keyset := "(" + createdAt + ", " + id + ")"
+	asc := createdAt + " ASC, " + id + " ASC"
+	desc := createdAt + " DESC, " + id + " DESC"
+
+	// forward walks the list in its own order, backward walks it reversed from the cursor
+	forward, forwardOp, backward, backwardOp := desc, " < ", asc, " > "
+	if p.Ascending {
+		forward, forwardOp, backward, backwardOp = asc, " > ", desc, " < "
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		if p.IsOffset() {
+			return db.Order(forward).Limit(p.Limit).Offset(p.Offset())
+		}
+
+		if p.cursor == nil {
+			return db.Order(forward).Limit(p.Limit + 1)
+		}
+
+		if p.cursor.Prev {
+			return db.Where(keyset+backwardOp+"(?, ?)", p.cursor.CreatedAt, p.cursor.ID).
+				Order(backward).
+				Limit(p.Limit + 1)
+		}
+
+		return db.Where(keyset+forwardOp+"(?, ?)", p.cursor.CreatedAt, p.cursor.ID).
+			Order(forward).
+			Limit(p.Limit + 1)
+	}
+}
+
+// Count returns the number of rows of query in offset mode, it does not touch query
+func (p Page) Count(query *gorm.DB) (total int64, err error) {
+	if !p.IsOffset() {
+		return 0, nil
+	}
+
+	err = query.Session(&gorm.Session{}).Count(&total).Error
+	return total, err
+}
+
+// Paginate trims the rows fetched with Scope to the page and builds its Meta, key gives the position of a row
+func Paginate[T any](p Page, rows []T, total int64, key func(T) Key) ([]T, Meta) {
+	meta := Meta{Limit: p.Limit}
+
+	if p.IsOffset() {
+		totalPages := int((total + int64(p.Limit) - 1) / int64(p.Limit))
+		meta.Page = p.Number
+		meta.Total = &total
+		meta.TotalPages = &totalPages
+		return rows, meta
+	}
+
+	hasMore := len(rows) > p.Limit
+	if hasMore {
+		rows = rows[:p.Limit]
+	}
+
+	backward := p.cursor != nil && p.cursor.Prev
+	if backward {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+
+	if len(rows) == 0 {
+		// keep a way back to where the empty page was requested from
+		if p.cursor != nil && backward {
+			meta.NextCursor = encodeCursor(cursor{Key: p.cursor.Key})
+		} else if p.cursor != nil {
+			meta.PrevCursor = encodeCursor(cursor{Key: p.cursor.Key, Prev: true})
+		}
+		return rows, meta
+	}
+
+	if hasMore || backward {
+		meta.NextCursor = encodeCursor(cursor{Key: key(rows[len(rows)-1])})
+	}
+	if (hasMore && backward) || (p.cursor != nil && !backward) {
+		meta.PrevCursor = encodeCursor(cursor{Key: key(rows[0]), Prev: true})
+	}
+
+	return rows, meta
+}
+
+func encodeCursor(c cursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(value string) (c cursor, err error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return c, err
+	}
+
+	err = json.Unmarshal(raw, &c)
+	if err == nil && (c.ID == "" || c.CreatedAt.IsZero()) {
+		err = ErrInvalidCursor
+	}
+	return c, err
+}
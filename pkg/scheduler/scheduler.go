@@ -0,0 +1,32 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Job is a unit of background work, it is run again on the next tick whatever it returns
+type Job func(ctx context.Context) error
+
+// Every runs job on every interval until ctx is done, a run that is still going delays the next tick
+func Every(ctx context.Context, name string, interval time.Duration, job Job) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Info().Str("job", name).Dur("interval", interval).Msg("scheduler started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Str("job", name).Msg("scheduler stopped")
+			return
+		case <-ticker.C:
+			err := job(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Error().Err(err).Str("job", name).Msg("scheduled job failed")
+			}
+		}
+	}
+}
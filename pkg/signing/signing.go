@@ -0,0 +1,189 @@
+package signing
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+)
+
+// leeway is the clock skew accepted between the replica that signed a token and the one that verifies it
+const leeway = 30 * time.Second
+
+var ErrUnknownKey = errors.New("token is signed with an unknown key")
+
+// ISigner signs the access tokens of the API and verifies them, the public keys are published as a JWKS
+// so other services can verify the tokens too
+type ISigner interface {
+	// Sign adds the issuer and audience to claims and signs them with the active key
+	Sign(claims jwt.MapClaims) (string, error)
+
+	// Parse verifies token against the keys of the set, its algorithm, issuer, audience and expiry, and fills claims
+	Parse(token string, claims jwt.Claims) error
+
+	// JWKS are the public keys tokens are verified with, empty for a shared secret
+	JWKS() JWKS
+}
+
+type Config struct {
+	// SigningKeyFile is a PEM private key tokens are signed with, RSA, ECDSA P-256 or Ed25519
+	SigningKeyFile string
+	// VerificationKeyFiles are PEM keys tokens are accepted with besides the signing key, the keys before and after a rotation
+	VerificationKeyFiles []string
+	// Secret signs with HS256 when there is no SigningKeyFile, it can not be published and only suits a single service
+	Secret   string
+	Issuer   string
+	Audience string
+}
+
+type signer struct {
+	active   *Key
+	keys     map[string]*Key
+	methods  []string
+	issuer   string
+	audience string
+}
+
+// New loads the keys of config, the signing key is always accepted as well
+func New(config Config) (ISigner, error) {
+	if config.SigningKeyFile == "" {
+		if config.Secret == "" {
+			return nil, errors.New("neither a signing key file nor a secret is configured")
+		}
+
+		return NewSecret(config.Secret, config.Issuer, config.Audience), nil
+	}
+
+	pemBytes, err := os.ReadFile(config.SigningKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	active, err := ParsePrivateKey(pemBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", config.SigningKeyFile, err)
+	}
+
+	keys := []*Key{}
+	for _, file := range config.VerificationKeyFiles {
+		pemBytes, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		key, err := ParsePublicKey(pemBytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		keys = append(keys, key)
+	}
+
+	return NewKeySet(active, keys, config.Issuer, config.Audience), nil
+}
+
+// NewKeySet signs with active and verifies with active and keys
+func NewKeySet(active *Key, keys []*Key, issuer string, audience string) ISigner {
+	s := &signer{
+		active:   active,
+		keys:     map[string]*Key{},
+		issuer:   issuer,
+		audience: audience,
+	}
+	for _, key := range append([]*Key{active}, keys...) {
+		if _, ok := s.keys[key.ID]; ok {
+			continue
+		}
+		s.keys[key.ID] = key
+		s.methods = append(s.methods, key.Algorithm)
+	}
+
+	return s
+}
+
+// NewSecret signs and verifies with HS256 and secret
+func NewSecret(secret string, issuer string, audience string) ISigner {
+	return &signer{
+		active: &Key{
+			Algorithm: jwt.SigningMethodHS256.Alg(),
+			private:   []byte(secret),
+			public:    []byte(secret),
+		},
+		methods:  []string{jwt.SigningMethodHS256.Alg()},
+		issuer:   issuer,
+		audience: audience,
+	}
+}
+
+func (s *signer) Sign(claims jwt.MapClaims) (string, error) {
+	claims["iss"] = s.issuer
+	claims["aud"] = s.audience
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(s.active.Algorithm), claims)
+	if s.active.ID != "" {
+		token.Header["kid"] = s.active.ID
+	}
+
+	return token.SignedString(s.active.private)
+}
+
+func (s *signer) Parse(tokenString string, claims jwt.Claims) error {
+	_, err := jwt.ParseWithClaims(tokenString, claims, s.key,
+		jwt.WithValidMethods(s.methods),
+		jwt.WithIssuer(s.issuer),
+		jwt.WithAudience(s.audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(leeway),
+	)
+
+	return err
+}
+
+// key is the key the token names with its kid, a key only verifies tokens of its own algorithm
+func (s *signer) key(token *jwt.Token) (interface{}, error) {
+	if s.keys == nil {
+		return s.active.public, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("key %s is not used with %s", kid, token.Method.Alg())
+	}
+
+	return key.public, nil
+}
+
+// JWKS lists the signing key first and the others by kid, so the answer stays the same between replicas
+func (s *signer) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	if s.keys == nil {
+		return set
+	}
+
+	set.Keys = append(set.Keys, s.active.JWK())
+	ids := []string{}
+	for id := range s.keys {
+		if id != s.active.ID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		set.Keys = append(set.Keys, s.keys[id].JWK())
+	}
+
+	return set
+}
+
+// Key is a key of the set, ID is its RFC 7638 thumbprint so every replica derives the same kid from the same key
+type Key struct {
+	ID        string
+	Algorithm string
+	private   crypto.PrivateKey
+	public    crypto.PublicKey
+}
@@ -0,0 +1,64 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// MaxURLs is the most URLs the sitemap protocol allows in one file, more are split over a sitemap index
+const MaxURLs = 50000
+
+const ContentType = "application/xml; charset=utf-8"
+
+// URL is a page of the site or, in an index, one of its sitemaps
+type URL struct {
+	Loc     string
+	LastMod time.Time
+}
+
+type urlSet struct {
+	XMLName xml.Name `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []entry  `xml:"url"`
+}
+
+type index struct {
+	XMLName  xml.Name `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 sitemapindex"`
+	Sitemaps []entry  `xml:"sitemap"`
+}
+
+type entry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// URLSet renders urls as a sitemap, callers keep it to MaxURLs
+func URLSet(urls []URL) ([]byte, error) {
+	return marshal(urlSet{URLs: entries(urls)})
+}
+
+// Index renders a sitemap index of the sitemaps at urls
+func Index(urls []URL) ([]byte, error) {
+	return marshal(index{Sitemaps: entries(urls)})
+}
+
+func entries(urls []URL) []entry {
+	res := make([]entry, 0, len(urls))
+	for _, url := range urls {
+		e := entry{Loc: url.Loc}
+		if !url.LastMod.IsZero() {
+			e.LastMod = url.LastMod.UTC().Format(time.RFC3339)
+		}
+		res = append(res, e)
+	}
+
+	return res
+}
+
+func marshal(doc interface{}) ([]byte, error) {
+	body, err := xml.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), body...), nil
+}
@@ -0,0 +1,143 @@
+package validations
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	"simple-blog-system/pkg/apperror"
+)
+
+// SortField is one field of a sort expression, Desc when the field is prefixed with "-"
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// ParseSort parse a comma separated sort expression, every field must be one of allowed and appear once
+//
+// Ex: -created_at,title => [{created_at true}, {title false}]
+func ParseSort(value string, allowed ...string) ([]SortField, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	var (
+		fields = []SortField{}
+		seen   = map[string]bool{}
+	)
+
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		field := SortField{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+
+		if field.Field == "" {
+			return nil, errors.New("sort contains an empty field")
+		}
+
+		if !contains(allowed, field.Field) {
+			return nil, fmt.Errorf("sort field %q is not allowed, use one of %s", field.Field, strings.Join(allowed, ", "))
+		}
+
+		if seen[field.Field] {
+			return nil, fmt.Errorf("sort field %q is repeated", field.Field)
+		}
+
+		seen[field.Field] = true
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+// SortFields validate a string field that must be a sort expression over the listed fields
+//
+// Usage: `validate:"sort_fields=created_at title"`
+func SortFields(fl validator.FieldLevel) bool {
+	_, err := ParseSort(fl.Field().String(), SplitBySpaceWithQuote(fl.Param())...)
+
+	return err == nil
+}
+
+// New create a validator with the struct validations registered, fields are reported by their form or json name
+func New() *validator.Validate {
+	validate := validator.New()
+
+	for tag, validationFunc := range structValidations() {
+		err := validate.RegisterValidation(tag, validationFunc)
+		if err != nil {
+			panic(fmt.Errorf("can not register validation function: %s", tag))
+		}
+	}
+
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, key := range []string{"form", "json"} {
+			name := strings.Split(field.Tag.Get(key), ",")[0]
+			if name != "" && name != "-" {
+				return name
+			}
+		}
+
+		return field.Name
+	})
+
+	return validate
+}
+
+// BadRequest translate validation errors into a bad request that explains every invalid field
+func BadRequest(err error) error {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+
+	messages := make([]string, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		messages = append(messages, fieldMessage(fieldErr))
+	}
+
+	return apperror.BadRequest(strings.Join(messages, "; "))
+}
+
+func fieldMessage(fieldErr validator.FieldError) string {
+	field := fieldErr.Field()
+
+	switch fieldErr.Tag() {
+	case StructValidationSortFields:
+		_, err := ParseSort(fmt.Sprint(fieldErr.Value()), SplitBySpaceWithQuote(fieldErr.Param())...)
+		if err != nil {
+			return err.Error()
+		}
+	case "required":
+		return fmt.Sprintf("%s is required", field)
+	case "required_if":
+		param := SplitBySpaceWithQuote(fieldErr.Param())
+		if len(param) == 2 {
+			return fmt.Sprintf("%s is required when %s is %s", field, strings.ToLower(param[0]), UnQuote(param[1]))
+		}
+		return fmt.Sprintf("%s is required", field)
+	case StructValidationTimeAfterNow:
+		return fmt.Sprintf("%s must be in the future", field)
+	case "oneof":
+		return fmt.Sprintf("%s must be one of %s", field, strings.Join(SplitBySpaceWithQuote(fieldErr.Param()), ", "))
+	case "max":
+		return fmt.Sprintf("%s must be at most %s characters", field, fieldErr.Param())
+	case "datetime":
+		return fmt.Sprintf("%s must be formatted as %s", field, fieldErr.Param())
+	}
+
+	return fmt.Sprintf("%s is invalid", field)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -18,11 +18,11 @@ const (
 	StructValidationGreaterThanEqualFieldIfFieldEqual = "gte_field_if_field_eq"
 	StructValidationMinimumFieldIfFieldEqual          = "min_field_if_field_eq"
 	StructValidationMaximumFieldIfFieldEqual          = "max_field_if_field_eq"
+	StructValidationSortFields                        = "sort_fields"
 )
 
-// InitStructValidation init struct validation
-func InitStructValidation() {
-	structValidation := map[string]func(fl validator.FieldLevel) bool{
+func structValidations() map[string]func(fl validator.FieldLevel) bool {
+	return map[string]func(fl validator.FieldLevel) bool{
 		StructValidationTimeAfterNow:                      TimeAfterNow,
 		StructValidationTimeAfterField:                    TimeAfterField,
 		StructValidationMinimumIfFieldEqual:               MinIfFieldEqual,
@@ -31,7 +31,13 @@ func InitStructValidation() {
 		StructValidationGreaterThanEqualFieldIfFieldEqual: GTEFieldIfFieldEqual,
 		StructValidationMinimumFieldIfFieldEqual:          MinFieldIfFieldEqual,
 		StructValidationMaximumFieldIfFieldEqual:          MaxFieldIfFieldEqual,
+		StructValidationSortFields:                        SortFields,
 	}
+}
+
+// InitStructValidation init struct validation
+func InitStructValidation() {
+	structValidation := structValidations()
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		for tag, validationFunc := range structValidation {